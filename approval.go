@@ -0,0 +1,160 @@
+package configurable
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrApprovalRequired is returned by Set, SetInFile and UnsetInFile for a
+// flag registered WithApproval. Such changes must be staged with Propose,
+// which records who asked for them.
+var ErrApprovalRequired = errors.New("flag requires approval")
+
+// ErrNoRequester is returned by Propose when requester is empty, since a
+// change nobody asked for cannot be checked against its approver.
+var ErrNoRequester = errors.New("a change needs a named requester")
+
+// ErrSelfApproval is returned by Approve when the approver proposed the
+// change.
+var ErrSelfApproval = errors.New("a change cannot be approved by its requester")
+
+// PendingChange is a staged change to one flag. Value holds secrets in the
+// clear.
+type PendingChange struct {
+	// ID is the token that approves or rejects the change.
+	ID        string
+	Name      string
+	Value     interface{}
+	Requester string
+	Proposed  time.Time
+}
+
+// approvals holds the staged changes.
+type approvals struct {
+	mu      sync.Mutex
+	pending map[string]PendingChange
+}
+
+// WithApproval makes runtime changes to the flag wait for a second person, as
+// regulated deployments require. Set refuses such a change with
+// ErrApprovalRequired; Propose stages it, and it takes effect when Approve is
+// called. Files, providers and the environment are not gated.
+func WithApproval() FlagOption {
+	return func(m *flagMeta) {
+		m.approval = true
+	}
+}
+
+// RequireApproval is WithApproval for flags that are already registered.
+func (c *Configurable) RequireApproval(names ...string) {
+	for _, name := range names {
+		if _, ok := c.flags[name]; !ok {
+			panic(fmt.Sprintf("configurable: RequireApproval on %s, which is not a flag", name))
+		}
+		c.metaFor(name).approval = true
+	}
+}
+
+// NeedsApproval reports whether changes to name must be staged with Propose
+// rather than applied by Set.
+func (c *Configurable) NeedsApproval(name string) bool {
+	m, ok := c.meta[name]
+	return ok && m.approval
+}
+
+// Propose stages a change to name on behalf of requester, whether or not the
+// flag needs approval, and returns it. The value is checked against the
+// flag's type and constraints now; validators and policies run when it is
+// approved, against the values of that moment.
+func (c *Configurable) Propose(name string, value interface{}, requester string) (PendingChange, error) {
+	if c.IsReadOnly() {
+		return PendingChange{}, &ReadOnlyError{Op: "propose", Name: name}
+	}
+	if requester == "" {
+		return PendingChange{}, ErrNoRequester
+	}
+	if err := c.checkRaw(name, value); err != nil {
+		return PendingChange{}, err
+	}
+	v, err := c.convert(name, value)
+	if err != nil {
+		return PendingChange{}, err
+	}
+	if err := c.checkEntries(name, v); err != nil {
+		return PendingChange{}, err
+	}
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return PendingChange{}, err
+	}
+	p := PendingChange{ID: hex.EncodeToString(id), Name: name, Value: v, Requester: requester, Proposed: c.clock().Now()}
+	c.approvals.mu.Lock()
+	defer c.approvals.mu.Unlock()
+	if c.approvals.pending == nil {
+		c.approvals.pending = make(map[string]PendingChange)
+	}
+	c.approvals.pending[p.ID] = p
+	return p, nil
+}
+
+// PendingChanges lists the staged changes, oldest first.
+func (c *Configurable) PendingChanges() []PendingChange {
+	c.approvals.mu.Lock()
+	defer c.approvals.mu.Unlock()
+	changes := make([]PendingChange, 0, len(c.approvals.pending))
+	for _, p := range c.approvals.pending {
+		changes = append(changes, p)
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Proposed.Before(changes[j].Proposed)
+	})
+	return changes
+}
+
+// Approve applies the staged change id on behalf of approver, who must be
+// named and must not be its requester. The change is checked as Set checks
+// it; if that fails it stays staged, so it can be approved again once the
+// conflict is resolved, or rejected.
+func (c *Configurable) Approve(id, approver string) error {
+	if c.IsReadOnly() {
+		return &ReadOnlyError{Op: "approve", Name: id}
+	}
+	c.approvals.mu.Lock()
+	p, ok := c.approvals.pending[id]
+	if ok && approver != "" && approver != p.Requester {
+		// Taken out while it is applied, so that two approvers cannot both
+		// apply it.
+		delete(c.approvals.pending, id)
+	}
+	c.approvals.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("no pending change %s", id)
+	}
+	if approver == "" || approver == p.Requester {
+		return ErrSelfApproval
+	}
+	if err := c.set(p.Name, p.Value); err != nil {
+		c.approvals.mu.Lock()
+		c.approvals.pending[id] = p
+		c.approvals.mu.Unlock()
+		return err
+	}
+	c.logger().Info("configurable: change approved", "flag", p.Name, "requester", p.Requester, "approver", approver)
+	return nil
+}
+
+// Reject drops the staged change id.
+func (c *Configurable) Reject(id string) error {
+	c.approvals.mu.Lock()
+	defer c.approvals.mu.Unlock()
+	if _, ok := c.approvals.pending[id]; !ok {
+		return fmt.Errorf("no pending change %s", id)
+	}
+	delete(c.approvals.pending, id)
+	return nil
+}
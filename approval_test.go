@@ -0,0 +1,84 @@
+package configurable
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApproval(t *testing.T) {
+	newConf := func(t *testing.T) *Configurable {
+		conf := newTestConfigurable(t)
+		conf.NewInt("workers", 4, "workers", WithApproval())
+		conf.NewString("region", "eu", "region")
+		conf.NewBoundedInt("replicas", 3, 1, 9, "replicas")
+		conf.SetArgs([]string{})
+		assert.NoError(t, conf.Parse(""))
+		return conf
+	}
+
+	t.Run("test set is refused", func(t *testing.T) {
+		conf := newConf(t)
+		assert.ErrorIs(t, conf.Set("workers", "8"), ErrApprovalRequired)
+		assert.Equal(t, 4, *conf.Int("workers"))
+		assert.Empty(t, conf.PendingChanges())
+
+		assert.NoError(t, conf.Set("region", "us"))
+		assert.Equal(t, "us", *conf.String("region"))
+
+		_, err := conf.Propose("workers", "8", "")
+		assert.ErrorIs(t, err, ErrNoRequester)
+		p, err := conf.Propose("workers", "8", "bob")
+		assert.NoError(t, err)
+		assert.Equal(t, []PendingChange{p}, conf.PendingChanges())
+		assert.Equal(t, 8, p.Value)
+		assert.ErrorIs(t, conf.Approve(p.ID, ""), ErrSelfApproval)
+		assert.NoError(t, conf.Approve(p.ID, "alice"))
+		assert.Equal(t, 8, *conf.Int("workers"))
+		assert.Empty(t, conf.PendingChanges())
+		assert.Equal(t, SourceSet, conf.sources["workers"].Kind)
+		assert.EqualError(t, conf.Approve(p.ID, "alice"), "no pending change "+p.ID)
+	})
+
+	t.Run("test four eyes", func(t *testing.T) {
+		conf := newConf(t)
+		conf.RequireApproval("replicas")
+		assert.True(t, conf.NeedsApproval("replicas"))
+		p, err := conf.Propose("replicas", 5, "bob")
+		assert.NoError(t, err)
+		assert.ErrorIs(t, conf.Approve(p.ID, "bob"), ErrSelfApproval)
+		assert.Equal(t, 3, *conf.Int("replicas"))
+		assert.NoError(t, conf.Approve(p.ID, "alice"))
+		assert.Equal(t, 5, *conf.Int("replicas"))
+
+		_, err = conf.Propose("replicas", 12, "bob")
+		var constraint *ConstraintError
+		assert.ErrorAs(t, err, &constraint)
+		assert.Empty(t, conf.PendingChanges())
+		assert.Panics(t, func() { conf.RequireApproval("missing") })
+	})
+
+	t.Run("test failed approval stays staged", func(t *testing.T) {
+		conf := newConf(t)
+		conf.AddPolicy(func(d Diff) error {
+			return errors.New("frozen")
+		})
+		p, err := conf.Propose("region", "us", "bob")
+		assert.NoError(t, err)
+		assert.Error(t, conf.Approve(p.ID, "alice"))
+		assert.Len(t, conf.PendingChanges(), 1)
+		assert.NoError(t, conf.Reject(p.ID))
+		assert.Empty(t, conf.PendingChanges())
+		assert.Error(t, conf.Reject(p.ID))
+	})
+
+	t.Run("test read-only", func(t *testing.T) {
+		conf := newConf(t)
+		conf.ReadOnly(true)
+		assert.ErrorIs(t, conf.Set("workers", 8), ErrReadOnly)
+		_, err := conf.Propose("workers", 8, "bob")
+		assert.ErrorIs(t, err, ErrReadOnly)
+		assert.Empty(t, conf.PendingChanges())
+	})
+}
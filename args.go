@@ -0,0 +1,258 @@
+package configurable
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// UnknownFlagError is returned by Parse when args name a flag that was never
+// registered. Suggestions lists registered names close to the mistyped one.
+type UnknownFlagError struct {
+	Name        string
+	Suggestions []string
+}
+
+func (e *UnknownFlagError) Error() string {
+	msg := "flag provided but not defined: -" + e.Name
+	if len(e.Suggestions) == 0 {
+		return msg
+	}
+	return msg + " (did you mean -" + strings.Join(e.Suggestions, ", -") + "?)"
+}
+
+// AmbiguousFlagError is returned by Parse when abbreviations are allowed and
+// an abbreviated flag matches more than one registered name.
+type AmbiguousFlagError struct {
+	Name    string
+	Matches []string
+}
+
+func (e *AmbiguousFlagError) Error() string {
+	return "flag -" + e.Name + " is ambiguous: could be -" + strings.Join(e.Matches, ", -")
+}
+
+// RepeatedFlagError is returned by Parse when a scalar flag whose repeat
+// policy is RepeatError appears more than once.
+type RepeatedFlagError struct {
+	Name string
+}
+
+func (e *RepeatedFlagError) Error() string {
+	return "flag -" + e.Name + " given more than once"
+}
+
+// normalizeArgs walks args the way flag.FlagSet.Parse will and reports
+// problems the flag package cannot explain well on its own.
+func (c *Configurable) normalizeArgs(args []string) ([]string, error) {
+	out := make([]string, 0, len(args))
+	seen := make(map[string]bool)
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if len(arg) < 2 || arg[0] != '-' || arg == "--" {
+			return append(out, args[i:]...), nil
+		}
+		dashes := "-"
+		if strings.HasPrefix(arg, "--") {
+			dashes = "--"
+		}
+		name, value, hasValue := strings.Cut(arg[len(dashes):], "=")
+		if name == "" || name[0] == '-' || name[0] == '=' {
+			// Malformed; leave the error to the flag package.
+			out = append(out, arg)
+			continue
+		}
+		f := c.fs.Lookup(name)
+		if f == nil && (name == "h" || name == "help") {
+			out = append(out, arg)
+			continue
+		}
+		if f == nil && strings.HasPrefix(name, negationPrefix) {
+			negated, err := c.negate(name[len(negationPrefix):], value, hasValue)
+			if err != nil {
+				return nil, err
+			}
+			if negated != nil {
+				f, value, hasValue = negated, "false", true
+			}
+		}
+		if f == nil && c.parseOptions.AllowAbbreviations {
+			var err error
+			if f, err = c.expandAbbreviation(name); err != nil {
+				return nil, err
+			}
+		}
+		if f == nil {
+			return nil, &UnknownFlagError{Name: name, Suggestions: c.suggest(name)}
+		}
+		if primary, ok := c.aliases[f.Name]; ok {
+			f = c.fs.Lookup(primary)
+		}
+
+		tokens := []string{dashes + f.Name}
+		if hasValue {
+			tokens[0] += "=" + value
+		} else if !isBoolFlag(f) && i+1 < len(args) {
+			i++
+			value = args[i]
+			tokens = append(tokens, value)
+		}
+		if err := c.checkLength(f.Name, value); err != nil {
+			return nil, err
+		}
+		if seen[f.Name] && !c.accumulates(f) {
+			switch c.repeatPolicy(f.Name) {
+			case RepeatFirstWins:
+				continue
+			case RepeatError:
+				return nil, &RepeatedFlagError{Name: f.Name}
+			}
+		}
+		seen[f.Name] = true
+		out = append(out, tokens...)
+	}
+	return out, nil
+}
+
+// accumulates reports whether repeated occurrences of f add to its value
+// rather than replace it, as they do for list and map flags.
+func (c *Configurable) accumulates(f *flag.Flag) bool {
+	switch f.Value.(type) {
+	case *ListFlag, typedList, *MapFlag:
+		return true
+	}
+	return false
+}
+
+// repeatPolicy returns the policy for name: its own, if it was registered
+// WithRepeatPolicy, or the one in ParseOptions.
+func (c *Configurable) repeatPolicy(name string) RepeatPolicy {
+	if m, ok := c.meta[name]; ok && m.repeat != nil {
+		return *m.repeat
+	}
+	return c.parseOptions.RepeatPolicy
+}
+
+// negationPrefix turns a boolean flag off: --no-cache is --cache=false.
+const negationPrefix = "no-"
+
+// negate resolves the flag named by a --no-<name> argument. It returns nil
+// when name is not a boolean flag, leaving the argument to be reported as
+// unknown. An explicit value is only accepted when it is true, since
+// --no-cache=false would be a double negative.
+func (c *Configurable) negate(name, value string, hasValue bool) (*flag.Flag, error) {
+	f := c.fs.Lookup(name)
+	if f == nil && c.parseOptions.AllowAbbreviations {
+		var err error
+		if f, err = c.expandAbbreviation(name); err != nil {
+			return nil, err
+		}
+	}
+	if f == nil || !isBoolFlag(f) {
+		return nil, nil
+	}
+	if hasValue {
+		if v, err := strconv.ParseBool(value); err != nil || !v {
+			return nil, fmt.Errorf("invalid value %q for flag -%s%s: use -%s=%s instead", value, negationPrefix, name, f.Name, value)
+		}
+	}
+	return f, nil
+}
+
+// expandAbbreviation returns the only flag whose name starts with prefix, nil
+// when none does, or an *AmbiguousFlagError when several do.
+func (c *Configurable) expandAbbreviation(prefix string) (*flag.Flag, error) {
+	var matches []*flag.Flag
+	c.fs.VisitAll(func(f *flag.Flag) {
+		if strings.HasPrefix(f.Name, prefix) {
+			matches = append(matches, f)
+		}
+	})
+	switch len(matches) {
+	case 0:
+		return nil, nil
+	case 1:
+		return matches[0], nil
+	}
+	names := make([]string, len(matches))
+	for i, f := range matches {
+		names[i] = f.Name
+	}
+	return nil, &AmbiguousFlagError{Name: prefix, Matches: names}
+}
+
+// fail reports err the way flag.FlagSet does, printing it followed by usage,
+// and then applies the FlagSet's error handling.
+func (c *Configurable) fail(err error) error {
+	fmt.Fprintln(c.out(), err)
+	c.PrintUsage()
+	return c.handleError(err)
+}
+
+func isBoolFlag(f *flag.Flag) bool {
+	b, ok := f.Value.(interface{ IsBoolFlag() bool })
+	return ok && b.IsBoolFlag()
+}
+
+// maxSuggestions caps how many names an UnknownFlagError offers.
+const maxSuggestions = 3
+
+// suggest returns the registered flag names closest to name. Names that only
+// differ in '-', '_' and '.' separators, such as time-out and timeout, always
+// match; otherwise the edit distance must be small relative to the length.
+func (c *Configurable) suggest(name string) []string {
+	type candidate struct {
+		name     string
+		distance int
+	}
+	var candidates []candidate
+	target := stripSeparators(name)
+	c.fs.VisitAll(func(f *flag.Flag) {
+		d := levenshtein(target, stripSeparators(f.Name))
+		if d <= 2 || d <= len(name)/3 || strings.HasPrefix(f.Name, name) {
+			candidates = append(candidates, candidate{f.Name, d})
+		}
+	})
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].distance < candidates[j].distance
+	})
+	var names []string
+	for i := 0; i < len(candidates) && i < maxSuggestions; i++ {
+		names = append(names, candidates[i].name)
+	}
+	return names
+}
+
+func stripSeparators(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '-', '_', '.':
+			return -1
+		}
+		return r
+	}, strings.ToLower(s))
+}
+
+// levenshtein returns the edit distance between a and b.
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
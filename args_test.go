@@ -0,0 +1,213 @@
+package configurable
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnknownFlagSuggestions(t *testing.T) {
+	os.Clearenv()
+
+	newConf := func(t *testing.T) *Configurable {
+		conf := newTestConfigurable(t)
+		conf.NewDuration("timeout", 0, "timeout")
+		conf.NewBool("verbose", false, "verbose")
+		conf.NewString("listen", ":80", "listen address")
+		conf.SetOutput(&bytes.Buffer{})
+		return conf
+	}
+
+	t.Run("test separator typo", func(t *testing.T) {
+		conf := newConf(t)
+		err := conf.ParseArgs([]string{"--time-out=5s"})
+		var unknown *UnknownFlagError
+		assert.ErrorAs(t, err, &unknown)
+		assert.Equal(t, "time-out", unknown.Name)
+		assert.Equal(t, []string{"timeout"}, unknown.Suggestions)
+		assert.EqualError(t, err, "flag provided but not defined: -time-out (did you mean -timeout?)")
+	})
+
+	t.Run("test edit distance", func(t *testing.T) {
+		conf := newConf(t)
+		err := conf.ParseArgs([]string{"-verbos"})
+		assert.EqualError(t, err, "flag provided but not defined: -verbos (did you mean -verbose?)")
+	})
+
+	t.Run("test no close match", func(t *testing.T) {
+		conf := newConf(t)
+		err := conf.ParseArgs([]string{"-database"})
+		assert.EqualError(t, err, "flag provided but not defined: -database")
+	})
+
+	t.Run("test values are not mistaken for flags", func(t *testing.T) {
+		conf := newConf(t)
+		assert.NoError(t, conf.ParseArgs([]string{"-listen", "-weird-value", "-verbose", "arg", "-not-a-flag"}))
+	})
+
+	t.Run("test error is printed with usage", func(t *testing.T) {
+		conf := newConf(t)
+		var buf bytes.Buffer
+		conf.SetOutput(&buf)
+		assert.Error(t, conf.ParseArgs([]string{"--time-out"}))
+		assert.Contains(t, buf.String(), "did you mean -timeout?")
+		assert.Contains(t, buf.String(), "Usage of")
+	})
+}
+
+func TestLevenshtein(t *testing.T) {
+	assert.Equal(t, 0, levenshtein("abc", "abc"))
+	assert.Equal(t, 1, levenshtein("verbose", "verbos"))
+	assert.Equal(t, 3, levenshtein("kitten", "sitting"))
+	assert.Equal(t, 4, levenshtein("", "port"))
+}
+
+func TestAbbreviations(t *testing.T) {
+	os.Clearenv()
+
+	newConf := func(t *testing.T, allow bool) *Configurable {
+		conf := newTestConfigurable(t)
+		conf.NewBool("verbose", false, "verbose")
+		conf.NewBool("version", false, "version")
+		conf.NewInt("port", 80, "port")
+		conf.NewInt("port-admin", 81, "admin port")
+		conf.SetParseOptions(ParseOptions{AllowAbbreviations: allow})
+		conf.SetOutput(&bytes.Buffer{})
+		return conf
+	}
+
+	t.Run("test unique prefix", func(t *testing.T) {
+		conf := newConf(t, true)
+		assert.NoError(t, conf.ParseArgs([]string{"--verb", "--port-a=9000"}))
+		assert.True(t, *conf.Bool("verbose"))
+		assert.Equal(t, 9000, *conf.Int("port-admin"))
+	})
+
+	t.Run("test prefix with separate value", func(t *testing.T) {
+		conf := newConf(t, true)
+		assert.NoError(t, conf.ParseArgs([]string{"-port-ad", "9001"}))
+		assert.Equal(t, 9001, *conf.Int("port-admin"))
+	})
+
+	t.Run("test exact name wins over longer names", func(t *testing.T) {
+		conf := newConf(t, true)
+		assert.NoError(t, conf.ParseArgs([]string{"--port=8080"}))
+		assert.Equal(t, 8080, *conf.Int("port"))
+		assert.Equal(t, 81, *conf.Int("port-admin"))
+	})
+
+	t.Run("test ambiguous prefix", func(t *testing.T) {
+		conf := newConf(t, true)
+		err := conf.ParseArgs([]string{"--ver"})
+		var ambiguous *AmbiguousFlagError
+		assert.ErrorAs(t, err, &ambiguous)
+		assert.Equal(t, []string{"verbose", "version"}, ambiguous.Matches)
+		assert.EqualError(t, err, "flag -ver is ambiguous: could be -verbose, -version")
+	})
+
+	t.Run("test disabled by default", func(t *testing.T) {
+		conf := newConf(t, false)
+		var unknown *UnknownFlagError
+		assert.ErrorAs(t, conf.ParseArgs([]string{"--verb"}), &unknown)
+	})
+}
+
+func TestNegatedBooleans(t *testing.T) {
+	os.Clearenv()
+
+	newConf := func(t *testing.T) *Configurable {
+		conf := newTestConfigurable(t)
+		conf.NewBool("cache", true, "enable the cache")
+		conf.NewString("name", "", "name")
+		conf.SetOutput(&bytes.Buffer{})
+		return conf
+	}
+
+	t.Run("test --no- prefix", func(t *testing.T) {
+		conf := newConf(t)
+		assert.NoError(t, conf.ParseArgs([]string{"--no-cache"}))
+		assert.False(t, *conf.Bool("cache"))
+	})
+
+	t.Run("test --no-name=true", func(t *testing.T) {
+		conf := newConf(t)
+		assert.NoError(t, conf.ParseArgs([]string{"-no-cache=true"}))
+		assert.False(t, *conf.Bool("cache"))
+	})
+
+	t.Run("test double negative is rejected", func(t *testing.T) {
+		conf := newConf(t)
+		assert.EqualError(t, conf.ParseArgs([]string{"--no-cache=false"}),
+			`invalid value "false" for flag -no-cache: use -cache=false instead`)
+	})
+
+	t.Run("test only booleans can be negated", func(t *testing.T) {
+		conf := newConf(t)
+		var unknown *UnknownFlagError
+		assert.ErrorAs(t, conf.ParseArgs([]string{"--no-name"}), &unknown)
+	})
+
+	t.Run("test registered no- flag wins", func(t *testing.T) {
+		conf := newConf(t)
+		conf.NewBool("no-cache", false, "explicit")
+		assert.NoError(t, conf.ParseArgs([]string{"--no-cache"}))
+		assert.True(t, *conf.Bool("cache"))
+		assert.True(t, *conf.Bool("no-cache"))
+	})
+
+	t.Run("test usage advertises --no-", func(t *testing.T) {
+		conf := newConf(t)
+		assert.Contains(t, conf.Usage(), "  -[no-]cache  enable the cache (default: true)\n")
+		assert.Contains(t, conf.Docs(), "Disable with `--no-cache`.")
+	})
+}
+
+func TestRepeatPolicy(t *testing.T) {
+	os.Clearenv()
+
+	newConf := func(t *testing.T, policy RepeatPolicy) *Configurable {
+		conf := newTestConfigurable(t)
+		conf.NewInt("port", 80, "port")
+		conf.NewBool("cache", true, "cache")
+		conf.NewList("tags", []string{}, "tags")
+		conf.NewString("user", "", "user", WithRepeatPolicy(RepeatLastWins))
+		conf.SetParseOptions(ParseOptions{RepeatPolicy: policy})
+		conf.SetOutput(&bytes.Buffer{})
+		return conf
+	}
+
+	t.Run("test last wins by default", func(t *testing.T) {
+		conf := newConf(t, RepeatLastWins)
+		assert.NoError(t, conf.ParseArgs([]string{"-port", "1", "-port=2"}))
+		assert.Equal(t, 2, *conf.Int("port"))
+	})
+
+	t.Run("test first wins", func(t *testing.T) {
+		conf := newConf(t, RepeatFirstWins)
+		assert.NoError(t, conf.ParseArgs([]string{"-port", "1", "-port", "2", "-cache", "--no-cache"}))
+		assert.Equal(t, 1, *conf.Int("port"))
+		assert.True(t, *conf.Bool("cache"))
+	})
+
+	t.Run("test error", func(t *testing.T) {
+		conf := newConf(t, RepeatError)
+		err := conf.ParseArgs([]string{"-port=1", "-port=2"})
+		var repeated *RepeatedFlagError
+		assert.ErrorAs(t, err, &repeated)
+		assert.EqualError(t, err, "flag -port given more than once")
+	})
+
+	t.Run("test per-flag override", func(t *testing.T) {
+		conf := newConf(t, RepeatError)
+		assert.NoError(t, conf.ParseArgs([]string{"-user=a", "-user=b"}))
+		assert.Equal(t, "b", *conf.String("user"))
+	})
+
+	t.Run("test lists accumulate", func(t *testing.T) {
+		conf := newConf(t, RepeatError)
+		assert.NoError(t, conf.ParseArgs([]string{"-tags=a", "-tags=b"}))
+		assert.Equal(t, []string{"a", "b"}, *conf.List("tags"))
+	})
+}
@@ -0,0 +1,67 @@
+package configurable
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// redacted replaces the value of secret flags in human-facing output.
+const redacted = "[redacted]"
+
+// BannerOptions describes the program for Banner.
+type BannerOptions struct {
+	AppName string
+	Version string
+	// Profile is the deployment profile the program selected, if any.
+	Profile string
+}
+
+// Banner renders a multi-line startup summary: the program name and version,
+// the profile, the configuration files loaded, and every flag whose value
+// differs from its default together with where that value came from. Flags
+// registered WithSecret are redacted.
+func (c *Configurable) Banner(opts BannerOptions) string {
+	var sb strings.Builder
+	title := strings.TrimSpace(opts.AppName + " " + opts.Version)
+	if title == "" {
+		title = c.fs.Name()
+	}
+	sb.WriteString(title)
+	sb.WriteString("\n")
+	if opts.Profile != "" {
+		fmt.Fprintf(&sb, "  profile: %s\n", opts.Profile)
+	}
+	if files := c.report.Files(); len(files) > 0 {
+		fmt.Fprintf(&sb, "  config:  %s\n", strings.Join(files, ", "))
+	}
+
+	type row struct{ name, value, source string }
+	var rows []row
+	nameWidth, valueWidth := 0, 0
+	c.mu.RLock()
+	for name := range c.flags {
+		f := c.fs.Lookup(name)
+		if f == nil || f.Value.String() == f.DefValue {
+			continue
+		}
+		value := f.Value.String()
+		if m, ok := c.meta[name]; ok && m.secret {
+			value = redacted
+		}
+		rows = append(rows, row{name, value, c.sources[name].String()})
+		nameWidth = max(nameWidth, len(name))
+		valueWidth = max(valueWidth, len(value))
+	}
+	c.mu.RUnlock()
+	if len(rows) == 0 {
+		sb.WriteString("  settings: defaults\n")
+		return sb.String()
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i].name < rows[j].name })
+	sb.WriteString("  settings:\n")
+	for _, r := range rows {
+		fmt.Fprintf(&sb, "    %-*s = %-*s (%s)\n", nameWidth, r.name, valueWidth, r.value, r.source)
+	}
+	return sb.String()
+}
@@ -0,0 +1,42 @@
+package configurable
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBanner(t *testing.T) {
+	os.Clearenv()
+
+	file := filepath.Join(t.TempDir(), "app.json")
+	assert.NoError(t, os.WriteFile(file, []byte(`{"workers": 8}`), 0644))
+
+	conf := newTestConfigurable(t)
+	conf.NewInt("workers", 1, "workers")
+	conf.NewInt("port", 80, "port")
+	conf.NewString("db-password", "", "database password", WithSecret())
+	conf.NewString("region", "us-east-1", "region")
+	conf.SetArgs([]string{"-port", "8080"})
+	conf.SetEnv("db-password", "hunter2")
+	assert.NoError(t, conf.Parse(file))
+
+	banner := conf.Banner(BannerOptions{AppName: "api", Version: "1.2.3", Profile: "production"})
+	assert.Equal(t, "api 1.2.3\n"+
+		"  profile: production\n"+
+		"  config:  "+file+"\n"+
+		"  settings:\n"+
+		"    db-password = [redacted] (env db-password)\n"+
+		"    port        = 8080       (flag)\n"+
+		"    workers     = 8          (file "+file+")\n", banner)
+	assert.NotContains(t, banner, "hunter2")
+	assert.NotContains(t, banner, "region")
+}
+
+func TestBannerDefaults(t *testing.T) {
+	conf := newTestConfigurable(t)
+	conf.NewInt("port", 80, "port")
+	assert.Equal(t, "svc\n  settings: defaults\n", conf.Banner(BannerOptions{AppName: "svc"}))
+}
@@ -0,0 +1,204 @@
+//go:build !(js && wasm) && !tinygo
+
+// Package boltstore keeps runtime settings in a local bbolt database, for
+// desktop and agent deployments that change settings at runtime and need
+// them to survive restarts.
+//
+// Every save writes a new version holding the complete document, in one
+// transaction, so the history of settings is kept and any version can be
+// restored. As a configurable.Provider the Store loads the latest version.
+package boltstore
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"time"
+
+	"github.com/andreimerlescu/configurable"
+	bolt "go.etcd.io/bbolt"
+)
+
+var versionsBucket = []byte("versions")
+
+// ErrNoVersion is returned for a version the store does not have.
+var ErrNoVersion = errors.New("no such version")
+
+// Store is a versioned settings document in a bbolt file.
+type Store struct {
+	db   *bolt.DB
+	path string
+}
+
+// Version is one saved state of the settings.
+type Version struct {
+	ID     uint64                 `json:"-"`
+	Saved  time.Time              `json:"saved"`
+	Values map[string]interface{} `json:"values"`
+}
+
+// Open opens the database at path, creating it if needed.
+func Open(path string) (*Store, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, err
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(versionsBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &Store{db: db, path: path}, nil
+}
+
+// Close closes the database.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+func (s *Store) Name() string {
+	return "bolt:" + s.path
+}
+
+// Load returns the values of the latest version, or no values if nothing
+// has been saved.
+func (s *Store) Load(ctx context.Context) (map[string]interface{}, error) {
+	v, err := s.Latest()
+	if errors.Is(err, ErrNoVersion) {
+		return map[string]interface{}{}, nil
+	}
+	return v.Values, err
+}
+
+// Latest returns the latest version.
+func (s *Store) Latest() (Version, error) {
+	var v Version
+	err := s.db.View(func(tx *bolt.Tx) error {
+		key, data := tx.Bucket(versionsBucket).Cursor().Last()
+		if key == nil {
+			return ErrNoVersion
+		}
+		return decode(key, data, &v)
+	})
+	return v, err
+}
+
+// Version returns the version id.
+func (s *Store) Version(id uint64) (Version, error) {
+	var v Version
+	err := s.db.View(func(tx *bolt.Tx) error {
+		key := itob(id)
+		data := tx.Bucket(versionsBucket).Get(key)
+		if data == nil {
+			return fmt.Errorf("version %d: %w", id, ErrNoVersion)
+		}
+		return decode(key, data, &v)
+	})
+	return v, err
+}
+
+// History returns every version, oldest first.
+func (s *Store) History() ([]Version, error) {
+	var history []Version
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(versionsBucket).ForEach(func(key, data []byte) error {
+			var v Version
+			if err := decode(key, data, &v); err != nil {
+				return err
+			}
+			history = append(history, v)
+			return nil
+		})
+	})
+	return history, err
+}
+
+// Save writes values on top of the latest version as a new version and
+// returns its ID. Durations are stored in their string form so they load
+// back into duration flags.
+func (s *Store) Save(values map[string]interface{}) (uint64, error) {
+	var id uint64
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(versionsBucket)
+		v := Version{Saved: time.Now().UTC(), Values: make(map[string]interface{})}
+		if key, data := b.Cursor().Last(); key != nil {
+			var latest Version
+			if err := decode(key, data, &latest); err != nil {
+				return err
+			}
+			maps.Copy(v.Values, latest.Values)
+		}
+		for name, value := range values {
+			if d, ok := value.(time.Duration); ok {
+				value = d.String()
+			}
+			v.Values[name] = value
+		}
+		return s.put(b, v, &id)
+	})
+	return id, err
+}
+
+// Rollback saves the values of version id as a new version and returns its
+// ID. The versions in between are kept.
+func (s *Store) Rollback(id uint64) (uint64, error) {
+	old, err := s.Version(id)
+	if err != nil {
+		return 0, err
+	}
+	var newID uint64
+	err = s.db.Update(func(tx *bolt.Tx) error {
+		return s.put(tx.Bucket(versionsBucket), Version{Saved: time.Now().UTC(), Values: old.Values}, &newID)
+	})
+	return newID, err
+}
+
+// Set applies value to the flag name with conf.Set, which validates it, and
+// then saves it. If saving fails the value stays applied but will not
+// survive a restart.
+func (s *Store) Set(conf configurable.IConfigurable, name string, value interface{}) error {
+	if err := conf.Set(name, value); err != nil {
+		return err
+	}
+	if _, err := s.Save(map[string]interface{}{name: value}); err != nil {
+		return fmt.Errorf("%s was set but not saved: %w", name, err)
+	}
+	return nil
+}
+
+func (s *Store) put(b *bolt.Bucket, v Version, id *uint64) error {
+	seq, err := b.NextSequence()
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	*id = seq
+	return b.Put(itob(seq), data)
+}
+
+func decode(key, data []byte, v *Version) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+	v.ID = binary.BigEndian.Uint64(key)
+	return nil
+}
+
+// itob encodes a version ID so that keys sort in version order.
+func itob(id uint64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, id)
+	return b
+}
@@ -0,0 +1,85 @@
+//go:build !(js && wasm) && !tinygo
+
+package boltstore
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/andreimerlescu/configurable"
+	"github.com/stretchr/testify/assert"
+)
+
+func newConf(t *testing.T) configurable.IConfigurable {
+	conf := configurable.New(configurable.WithFlagSet(flag.NewFlagSet(t.Name(), flag.ContinueOnError)), configurable.WithArgs([]string{}))
+	conf.NewBoundedInt("workers", 4, 1, 64, "workers")
+	conf.NewDuration("interval", time.Minute, "sync interval")
+	return conf
+}
+
+func TestStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "settings.db")
+	store, err := Open(path)
+	assert.NoError(t, err)
+
+	t.Run("test empty store", func(t *testing.T) {
+		data, err := store.Load(context.Background())
+		assert.NoError(t, err)
+		assert.Empty(t, data)
+		_, err = store.Latest()
+		assert.ErrorIs(t, err, ErrNoVersion)
+	})
+
+	t.Run("test set and history", func(t *testing.T) {
+		conf := newConf(t)
+		conf.AddProvider(store)
+		assert.NoError(t, conf.Parse(""))
+		assert.NoError(t, store.Set(conf, "workers", 8))
+		assert.NoError(t, store.Set(conf, "interval", 30*time.Second))
+		assert.Error(t, store.Set(conf, "workers", 100))
+
+		history, err := store.History()
+		assert.NoError(t, err)
+		assert.Len(t, history, 2)
+		assert.Equal(t, uint64(1), history[0].ID)
+		assert.Equal(t, map[string]interface{}{"workers": "8"}, stringify(history[0].Values))
+		assert.Equal(t, map[string]interface{}{"workers": "8", "interval": "30s"}, stringify(history[1].Values))
+	})
+
+	t.Run("test restart", func(t *testing.T) {
+		assert.NoError(t, store.Close())
+		store, err = Open(path)
+		assert.NoError(t, err)
+		conf := newConf(t)
+		conf.AddProvider(store)
+		assert.NoError(t, conf.Parse(""))
+		assert.Equal(t, 8, conf.View().Int("workers"))
+		assert.Equal(t, 30*time.Second, conf.View().Duration("interval"))
+	})
+
+	t.Run("test rollback", func(t *testing.T) {
+		id, err := store.Rollback(1)
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(3), id)
+		latest, err := store.Latest()
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{"workers": "8"}, stringify(latest.Values))
+		_, err = store.Rollback(42)
+		assert.ErrorIs(t, err, ErrNoVersion)
+	})
+
+	assert.NoError(t, store.Close())
+}
+
+// stringify renders values as strings, since numbers load as json.Number.
+func stringify(values map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(values))
+	for k, v := range values {
+		out[k] = fmt.Sprint(v)
+	}
+	return out
+}
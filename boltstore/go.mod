@@ -0,0 +1,21 @@
+module github.com/andreimerlescu/configurable/boltstore
+
+go 1.23.0
+
+require (
+	github.com/andreimerlescu/configurable v1.0.0
+	github.com/stretchr/testify v1.10.0
+	go.etcd.io/bbolt v1.3.11
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/fsnotify/fsnotify v1.8.0 // indirect
+	github.com/go-ini/ini v1.67.0 // indirect
+	github.com/pelletier/go-toml/v2 v2.2.3 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	golang.org/x/crypto v0.41.0 // indirect
+	golang.org/x/sys v0.35.0 // indirect
+	golang.org/x/term v0.34.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
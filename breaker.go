@@ -0,0 +1,158 @@
+package configurable
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// ErrBreakerOpen is returned to a watching provider for updates ignored
+// while its circuit breaker is open.
+var ErrBreakerOpen = errors.New("circuit breaker open")
+
+// BreakerPolicy opens a circuit breaker around a provider that keeps failing
+// or keeps changing its values. A document that would open the breaker is
+// not applied. When the breaker opens, the provider's last-known-good
+// document, the one in place before it started flapping, is restored, and
+// its loads and updates are ignored while the breaker is open, so a flapping
+// source does not thrash OnChange listeners.
+type BreakerPolicy struct {
+	// MaxFailures opens the breaker after this many consecutive failed loads
+	// or rejected updates. Zero disables the check.
+	MaxFailures int
+	// MaxChanges opens the breaker when the provider delivers more than this
+	// many different documents within Window. Zero disables the check.
+	MaxChanges int
+	Window     time.Duration
+	// Cooldown is how long the breaker stays open. The next load or update
+	// after it is accepted, and closes the breaker unless it trips it again.
+	Cooldown time.Duration
+}
+
+// WithBreaker guards the provider with a circuit breaker.
+func WithBreaker(policy BreakerPolicy) SourceOption {
+	return func(s *remoteSource) {
+		s.breaker = &breaker{policy: policy}
+	}
+}
+
+type breaker struct {
+	policy BreakerPolicy
+
+	mu       sync.Mutex
+	failures int
+	changes  []time.Time
+	last     map[string]interface{}
+	// good is the last document applied while the provider was not
+	// changing, which is restored when the breaker opens.
+	good      map[string]interface{}
+	openUntil time.Time
+}
+
+// allow reports whether the breaker is closed at now. A nil breaker always
+// allows.
+func (b *breaker) allow(now time.Time) bool {
+	if b == nil {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return !now.Before(b.openUntil)
+}
+
+// admit counts data as a change if it differs from the last document
+// applied, before it is applied. It returns why the breaker opened, in which
+// case data must not be applied, or "" if it did not.
+func (b *breaker) admit(now time.Time, data map[string]interface{}) string {
+	if b == nil {
+		return ""
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.expire(now)
+	if b.last == nil || reflect.DeepEqual(data, b.last) {
+		return ""
+	}
+	b.changes = append(b.changes, now)
+	if p := b.policy; p.MaxChanges > 0 && len(b.changes) > p.MaxChanges {
+		return b.open(now, fmt.Sprintf("%d changes within %s", len(b.changes), p.Window))
+	}
+	return ""
+}
+
+// record counts the outcome of applying data, which failed with err if it
+// is not nil. It returns why the breaker opened, or "" if it did not.
+func (b *breaker) record(now time.Time, data map[string]interface{}, err error) string {
+	if b == nil {
+		return ""
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if err != nil {
+		b.failures++
+		if p := b.policy; p.MaxFailures > 0 && b.failures >= p.MaxFailures {
+			return b.open(now, fmt.Sprintf("%d consecutive failures, last: %v", b.failures, err))
+		}
+		return ""
+	}
+	b.failures = 0
+	b.last = data
+	b.expire(now)
+	if len(b.changes) == 0 {
+		b.good = data
+	}
+	return ""
+}
+
+// expire forgets the changes that fell out of the window.
+func (b *breaker) expire(now time.Time) {
+	kept := b.changes[:0]
+	for _, t := range b.changes {
+		if now.Sub(t) < b.policy.Window {
+			kept = append(kept, t)
+		}
+	}
+	b.changes = kept
+}
+
+// lastKnownGood returns the document to restore when the breaker opens, or
+// nil if none was applied.
+func (b *breaker) lastKnownGood() map[string]interface{} {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.good
+}
+
+func (b *breaker) open(now time.Time, reason string) string {
+	b.openUntil = now.Add(b.policy.Cooldown)
+	b.failures = 0
+	b.changes = nil
+	return reason
+}
+
+// tripped records that the breaker of s opened, and restores the provider's
+// last-known-good document, or its cached copy if the breaker has none.
+func (c *Configurable) tripped(s *remoteSource, reason string) {
+	if reason == "" {
+		return
+	}
+	name := s.provider.Name()
+	c.logger().Warn("configurable: provider circuit breaker open", "provider", name, "reason", reason)
+	c.problem(BreakerOpen, name, "", reason)
+	good := s.breaker.lastKnownGood()
+	if good == nil {
+		if cached, err := c.readCache(name); err == nil {
+			good = cached
+		}
+	}
+	if good == nil {
+		return
+	}
+	if err := c.setValuesFromMap(SourceRemote, name, good); err != nil {
+		c.logger().Warn("configurable: cannot restore last-known-good values", "provider", name, "error", err)
+		return
+	}
+	c.writeCache(name, good)
+}
@@ -0,0 +1,89 @@
+package configurable
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBreaker(t *testing.T) {
+	os.Clearenv()
+
+	t.Run("test cooldown", func(t *testing.T) {
+		b := &breaker{policy: BreakerPolicy{MaxFailures: 2, Cooldown: time.Minute}}
+		now := time.Now()
+		errDown := errors.New("down")
+		assert.Empty(t, b.record(now, nil, errDown))
+		assert.Equal(t, "2 consecutive failures, last: down", b.record(now, nil, errDown))
+		assert.False(t, b.allow(now.Add(59*time.Second)))
+		assert.True(t, b.allow(now.Add(time.Minute)))
+		assert.Empty(t, b.record(now.Add(time.Minute), nil, errDown))
+
+		var none *breaker
+		assert.True(t, none.allow(now))
+		assert.Empty(t, none.record(now, nil, errDown))
+	})
+
+	t.Run("test flapping watch", func(t *testing.T) {
+		conf := newTestConfigurable(t)
+		conf.SetLogger(slog.New(slog.NewTextHandler(io.Discard, nil)))
+		conf.NewInt("port", 80, "port")
+		p := &pushProvider{fakeProvider: fakeProvider{name: "push"}, docs: make(chan map[string]interface{}), results: make(chan error)}
+		conf.AddProvider(p, WithBreaker(BreakerPolicy{MaxChanges: 2, Window: time.Hour, Cooldown: time.Hour}))
+		var notified int
+		conf.OnChange(func(View, []string) { notified++ })
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go conf.WatchProviders(ctx)
+
+		for _, port := range []int{8080, 8081, 8082} {
+			p.docs <- map[string]interface{}{"port": port}
+			assert.NoError(t, <-p.results)
+		}
+		p.docs <- map[string]interface{}{"port": 8083}
+		assert.ErrorIs(t, <-p.results, ErrBreakerOpen, "the update that opens the breaker is not applied")
+		assert.Equal(t, 8080, conf.View().Int("port"), "the last-known-good document is restored")
+		p.docs <- map[string]interface{}{"port": 9000}
+		assert.ErrorIs(t, <-p.results, ErrBreakerOpen)
+		assert.Equal(t, 8080, conf.View().Int("port"))
+		assert.Equal(t, 4, notified)
+
+		problems := conf.Problems()
+		assert.Len(t, problems, 1)
+		assert.Equal(t, "breaker-open push: 3 changes within 1h0m0s", problems[0].String())
+		assert.Equal(t, "circuit breaker open", conf.Health().Sources[0].Error)
+	})
+
+	t.Run("test failing provider", func(t *testing.T) {
+		conf := newTestConfigurable(t)
+		conf.SetLogger(slog.New(slog.NewTextHandler(io.Discard, nil)))
+		conf.NewInt("port", 80, "port")
+		p := &flakyProvider{fakeProvider: fakeProvider{name: "consul", data: map[string]interface{}{"port": 8080}}, failures: 10}
+		conf.AddProvider(p, WithFailurePolicy(UseDefaults), WithBreaker(BreakerPolicy{MaxFailures: 2, Cooldown: time.Hour}))
+		for i := 0; i < 4; i++ {
+			assert.NoError(t, conf.LoadProviders(context.Background()))
+		}
+		assert.Equal(t, 2, p.loads)
+	})
+
+	t.Run("test restores cached copy", func(t *testing.T) {
+		dir := t.TempDir()
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, "consul.json"), []byte(`{"port": 8080}`), 0o600))
+		conf := newTestConfigurable(t)
+		conf.SetLogger(slog.New(slog.NewTextHandler(io.Discard, nil)))
+		conf.SetCacheDir(dir)
+		conf.NewInt("port", 80, "port")
+		p := &flakyProvider{fakeProvider: fakeProvider{name: "consul"}, failures: 10}
+		conf.AddProvider(p, WithFailurePolicy(UseDefaults), WithBreaker(BreakerPolicy{MaxFailures: 1, Cooldown: time.Hour}))
+		assert.NoError(t, conf.LoadProviders(context.Background()))
+		assert.Equal(t, 8080, conf.View().Int("port"))
+	})
+}
@@ -0,0 +1,144 @@
+package configurable
+
+import "time"
+
+// FlagBuilder registers a flag fluently:
+//
+//	port := conf.Flag("port").Int(8080).Usage("listen port").Env("PORT").Required().Register()
+//
+// Choosing the type, with Int, String and so on, returns a TypedFlag on which
+// the remaining settings are chained.
+type FlagBuilder struct {
+	c    *Configurable
+	name string
+}
+
+// Flag starts registering the flag name.
+func (c *Configurable) Flag(name string) *FlagBuilder {
+	return &FlagBuilder{c: c, name: name}
+}
+
+func (b *FlagBuilder) Int(value int) *TypedFlag[int] {
+	return &TypedFlag[int]{register: func(usage string, opts []FlagOption) *int {
+		return b.c.NewInt(b.name, value, usage, opts...)
+	}}
+}
+
+func (b *FlagBuilder) Int64(value int64) *TypedFlag[int64] {
+	return &TypedFlag[int64]{register: func(usage string, opts []FlagOption) *int64 {
+		return b.c.NewInt64(b.name, value, usage, opts...)
+	}}
+}
+
+func (b *FlagBuilder) Float64(value float64) *TypedFlag[float64] {
+	return &TypedFlag[float64]{register: func(usage string, opts []FlagOption) *float64 {
+		return b.c.NewFloat64(b.name, value, usage, opts...)
+	}}
+}
+
+func (b *FlagBuilder) String(value string) *TypedFlag[string] {
+	return &TypedFlag[string]{register: func(usage string, opts []FlagOption) *string {
+		return b.c.NewString(b.name, value, usage, opts...)
+	}}
+}
+
+func (b *FlagBuilder) Bool(value bool) *TypedFlag[bool] {
+	return &TypedFlag[bool]{register: func(usage string, opts []FlagOption) *bool {
+		return b.c.NewBool(b.name, value, usage, opts...)
+	}}
+}
+
+func (b *FlagBuilder) Duration(value time.Duration) *TypedFlag[time.Duration] {
+	return &TypedFlag[time.Duration]{register: func(usage string, opts []FlagOption) *time.Duration {
+		return b.c.NewDuration(b.name, value, usage, opts...)
+	}}
+}
+
+func (b *FlagBuilder) List(value []string) *TypedFlag[[]string] {
+	return &TypedFlag[[]string]{register: func(usage string, opts []FlagOption) *[]string {
+		return b.c.NewList(b.name, value, usage, opts...)
+	}}
+}
+
+func (b *FlagBuilder) Map(value map[string]string) *TypedFlag[map[string]string] {
+	return &TypedFlag[map[string]string]{register: func(usage string, opts []FlagOption) *map[string]string {
+		return b.c.NewMap(b.name, value, usage, opts...)
+	}}
+}
+
+// TypedFlag collects the settings of a flag of type T until Register.
+type TypedFlag[T any] struct {
+	usage    string
+	opts     []FlagOption
+	register func(usage string, opts []FlagOption) *T
+}
+
+// Usage sets the one-line description.
+func (f *TypedFlag[T]) Usage(usage string) *TypedFlag[T] {
+	f.usage = usage
+	return f
+}
+
+// Env is WithEnv.
+func (f *TypedFlag[T]) Env(vars ...string) *TypedFlag[T] {
+	return f.Option(WithEnv(vars...))
+}
+
+// Required is WithRequired.
+func (f *TypedFlag[T]) Required() *TypedFlag[T] {
+	return f.Option(WithRequired())
+}
+
+// Validate is WithValidator for a function of the flag's type.
+func (f *TypedFlag[T]) Validate(fn func(v T) error) *TypedFlag[T] {
+	return f.Option(WithValidator(func(v interface{}) error {
+		return fn(v.(T))
+	}))
+}
+
+// Secret is WithSecret.
+func (f *TypedFlag[T]) Secret() *TypedFlag[T] {
+	return f.Option(WithSecret())
+}
+
+// Hidden is WithHidden.
+func (f *TypedFlag[T]) Hidden() *TypedFlag[T] {
+	return f.Option(WithHidden())
+}
+
+// Deprecated is WithDeprecated.
+func (f *TypedFlag[T]) Deprecated(message string) *TypedFlag[T] {
+	return f.Option(WithDeprecated(message))
+}
+
+// Short is WithShort.
+func (f *TypedFlag[T]) Short(name string) *TypedFlag[T] {
+	return f.Option(WithShort(name))
+}
+
+// Group is WithGroup.
+func (f *TypedFlag[T]) Group(group string) *TypedFlag[T] {
+	return f.Option(WithGroup(group))
+}
+
+// Help is WithHelp.
+func (f *TypedFlag[T]) Help(text string) *TypedFlag[T] {
+	return f.Option(WithHelp(text))
+}
+
+// Example is WithExample.
+func (f *TypedFlag[T]) Example(example string) *TypedFlag[T] {
+	return f.Option(WithExample(example))
+}
+
+// Option adds any other FlagOption, such as WithMaxLength.
+func (f *TypedFlag[T]) Option(opts ...FlagOption) *TypedFlag[T] {
+	f.opts = append(f.opts, opts...)
+	return f
+}
+
+// Register registers the flag and returns a pointer to its value, as the
+// New* methods do.
+func (f *TypedFlag[T]) Register() *T {
+	return f.register(f.usage, f.opts)
+}
@@ -0,0 +1,93 @@
+package configurable
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFlagBuilder(t *testing.T) {
+	os.Clearenv()
+
+	portRange := func(v int) error {
+		if v < 1 || v > 65535 {
+			return errors.New("out of range")
+		}
+		return nil
+	}
+
+	t.Run("test register", func(t *testing.T) {
+		conf := newTestConfigurable(t)
+		port := conf.Flag("port").Int(8080).Env("PORT", "HTTP_PORT").Validate(portRange).Usage("listen port").Register()
+		timeout := conf.Flag("timeout").Duration(time.Second).Register()
+		tags := conf.Flag("tags").List([]string{"a"}).Register()
+		assert.Equal(t, 8080, *port)
+		assert.Equal(t, time.Second, *timeout)
+		assert.Equal(t, []string{"a"}, *tags)
+		assert.Equal(t, "listen port", conf.fs.Lookup("port").Usage)
+
+		conf.SetEnv("HTTP_PORT", "9090")
+		conf.SetArgs([]string{})
+		assert.NoError(t, conf.Parse(""))
+		assert.Equal(t, 9090, *port)
+	})
+
+	t.Run("test validate", func(t *testing.T) {
+		conf := newTestConfigurable(t)
+		conf.Flag("port").Int(8080).Validate(portRange).Register()
+		conf.SetArgs([]string{"-port", "0"})
+		assert.EqualError(t, conf.Parse(""), "port: out of range")
+	})
+
+	t.Run("test required", func(t *testing.T) {
+		conf := newTestConfigurable(t)
+		conf.Flag("token").String("").Required().Secret().Register()
+		conf.Flag("region").String("").Required().Register()
+		conf.Flag("port").Int(80).Required().Register()
+		conf.SetArgs([]string{"-port", "80"})
+		err := conf.Parse("")
+		var required *RequiredFlagsError
+		assert.ErrorAs(t, err, &required)
+		assert.EqualError(t, err, "missing required flags: region, token")
+	})
+
+	t.Run("test short names", func(t *testing.T) {
+		conf := newTestConfigurable(t)
+		verbose := conf.Flag("verbose").Bool(false).Short("v").Usage("log more").Register()
+		port := conf.Flag("port").Int(80).Short("p").Usage("port").Register()
+		assert.NoError(t, conf.ParseArgs([]string{"-v", "-p", "8080"}))
+		assert.True(t, *verbose)
+		assert.Equal(t, 8080, *port)
+		assert.Equal(t, "flag", conf.sources["port"].String())
+
+		usage := conf.Usage()
+		assert.Contains(t, usage, "  -p, -port          port (default: 80)\n")
+		assert.Contains(t, usage, "  -v, -[no-]verbose  log more (default: false)\n")
+		assert.NotContains(t, usage, "  -p ")
+	})
+
+	t.Run("test hidden, deprecated and groups", func(t *testing.T) {
+		var logs bytes.Buffer
+		conf := newTestConfigurable(t)
+		conf.SetLogger(slog.New(slog.NewTextHandler(&logs, nil)))
+		conf.Flag("debug-internals").Bool(false).Hidden().Register()
+		conf.Flag("listen").String(":80").Usage("address").Register()
+		conf.Flag("db-host").String("localhost").Usage("database host").Group("Database").Register()
+		conf.Flag("db").String("").Usage("database URL").Deprecated("use --db-host").Group("Database").Register()
+
+		usage := conf.Usage()
+		assert.NotContains(t, usage, "debug-internals")
+		assert.NotContains(t, conf.Docs(), "debug-internals")
+		assert.Regexp(t, `(?s)-listen .*\n\nDatabase:\n  -db-host .*\n  -db +database URL \(default: \) DEPRECATED: use --db-host\n$`, usage)
+		assert.Contains(t, conf.Docs(), "**Deprecated:** use --db-host")
+
+		conf.SetArgs([]string{"-db", "postgres://x", "-debug-internals"})
+		assert.NoError(t, conf.Parse(""))
+		assert.Contains(t, logs.String(), `msg="configurable: flag is deprecated" flag=db source=flag message="use --db-host"`)
+	})
+}
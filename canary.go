@@ -0,0 +1,186 @@
+package configurable
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"sync"
+)
+
+// CanaryStatus is where this instance stands in a canary rollout.
+type CanaryStatus struct {
+	Instance string `json:"instance"`
+	// Percent is the share of the fleet that applies changes to canary
+	// flags, from 0 to 100.
+	Percent int `json:"percent"`
+	// Bucket is this instance's place in the fleet, from 0 to 99. It is
+	// derived from the instance ID, so it stays the same across restarts.
+	Bucket int `json:"bucket"`
+	// Included reports whether Bucket is below Percent.
+	Included bool `json:"included"`
+	// Held lists the canary flags with a change waiting for the rollout to
+	// include this instance, sorted by name.
+	Held []string `json:"held,omitempty"`
+}
+
+// canary holds the changes to canary flags that this instance is not yet
+// included in, the latest per flag.
+type canary struct {
+	mu    sync.Mutex
+	held  map[string]heldChange
+	funcs []func(CanaryStatus)
+}
+
+type heldChange struct {
+	provider string
+	value    interface{}
+}
+
+// WithCanary makes changes to the flag that a watched provider delivers
+// wait until the canary percentage registered with NewCanaryPercent
+// includes this instance, so a change can be rolled out to a share of the
+// fleet first. Loads at startup, files and Set are not held.
+func WithCanary() FlagOption {
+	return func(m *flagMeta) {
+		m.canary = true
+	}
+}
+
+// NewCanaryPercent registers the int flag holding the share of the fleet,
+// from 0 to 100, that applies changes to flags registered WithCanary. Each
+// instance falls in the share or not by a hash of its instance ID, so raising
+// the percentage only ever adds instances. Changes held by an instance are
+// applied as soon as the percentage includes it; lowering the percentage does
+// not undo changes already applied. It panics if percent is out of range.
+func (c *Configurable) NewCanaryPercent(name string, percent int, usage string, opts ...FlagOption) *int {
+	if percent < 0 || percent > 100 {
+		panic(fmt.Sprintf("configurable: canary percentage %d is not between 0 and 100", percent))
+	}
+	opts = append(opts, WithValidator(func(v interface{}) error {
+		if p := v.(int); p < 0 || p > 100 {
+			return fmt.Errorf("canary percentage %d is not between 0 and 100", p)
+		}
+		return nil
+	}))
+	c.canaryFlag = name
+	p := c.NewInt(name, percent, usage, opts...)
+	c.OnKeyChange(name, func(ChangeEvent) {
+		c.releaseCanary()
+	})
+	return p
+}
+
+// Canary returns where this instance stands in the canary rollout. Without
+// a flag registered with NewCanaryPercent, every instance is included.
+func (c *Configurable) Canary() CanaryStatus {
+	h := fnv.New64a()
+	h.Write([]byte(c.instance()))
+	s := CanaryStatus{Instance: c.instance(), Percent: 100, Bucket: int(h.Sum64() % 100)}
+	if c.canaryFlag != "" {
+		s.Percent, _ = c.current().values[c.canaryFlag].(int)
+	}
+	s.Included = s.Bucket < s.Percent
+	c.canary.mu.Lock()
+	for name := range c.canary.held {
+		s.Held = append(s.Held, name)
+	}
+	c.canary.mu.Unlock()
+	sort.Strings(s.Held)
+	return s
+}
+
+// OnCanary registers fn to be called with the canary status whenever a
+// change is held or the held changes are applied, so the rollout can be
+// reported back, as the webhook package does.
+func (c *Configurable) OnCanary(fn func(CanaryStatus)) {
+	c.canary.mu.Lock()
+	defer c.canary.mu.Unlock()
+	c.canary.funcs = append(c.canary.funcs, fn)
+}
+
+// holdCanary takes the canary flags out of a document delivered by the
+// watch of provider if this instance is not included, and returns the rest
+// to apply now.
+func (c *Configurable) holdCanary(provider string, data map[string]interface{}) map[string]interface{} {
+	if c.canaryFlag == "" {
+		return data
+	}
+	known := make(map[string]interface{})
+	var unknown []string
+	c.flatten("", data, known, &unknown)
+	included := c.Canary().Included
+	now := make(map[string]interface{}, len(data))
+	held := false
+	c.canary.mu.Lock()
+	for name, value := range known {
+		if m, ok := c.meta[name]; !ok || !m.canary {
+			now[name] = value
+			continue
+		}
+		// A newer change supersedes one still held, either way.
+		delete(c.canary.held, name)
+		if included {
+			now[name] = value
+			continue
+		}
+		if c.canary.held == nil {
+			c.canary.held = make(map[string]heldChange)
+		}
+		c.canary.held[name] = heldChange{provider: provider, value: value}
+		held = true
+	}
+	c.canary.mu.Unlock()
+	if !held {
+		return data
+	}
+	// Unknown keys stay in the document, to be reported as usual.
+	for _, key := range unknown {
+		now[key] = nil
+	}
+	c.notifyCanary()
+	return now
+}
+
+// releaseCanary applies the held changes once this instance is included.
+func (c *Configurable) releaseCanary() {
+	if !c.Canary().Included {
+		return
+	}
+	c.canary.mu.Lock()
+	held := c.canary.held
+	c.canary.held = nil
+	c.canary.mu.Unlock()
+	if len(held) == 0 {
+		return
+	}
+	byProvider := make(map[string]map[string]interface{})
+	for name, h := range held {
+		if byProvider[h.provider] == nil {
+			byProvider[h.provider] = make(map[string]interface{})
+		}
+		byProvider[h.provider][name] = h.value
+	}
+	for provider, data := range byProvider {
+		err := c.loading(remoteName(provider), func() error {
+			return c.setValuesFromMap(SourceRemote, provider, data)
+		})
+		if err != nil {
+			c.logger().Warn("configurable: provider update rejected", "provider", provider, "error", err)
+			c.problem(RejectedUpdate, provider, "", err.Error())
+		}
+	}
+	c.notifyCanary()
+}
+
+func (c *Configurable) notifyCanary() {
+	c.canary.mu.Lock()
+	funcs := c.canary.funcs
+	c.canary.mu.Unlock()
+	if len(funcs) == 0 {
+		return
+	}
+	s := c.Canary()
+	for _, fn := range funcs {
+		fn(s)
+	}
+}
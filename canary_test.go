@@ -0,0 +1,82 @@
+package configurable
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanary(t *testing.T) {
+	os.Clearenv()
+
+	t.Run("test buckets", func(t *testing.T) {
+		conf := newTestConfigurable(t)
+		assert.True(t, conf.Canary().Included, "without a percentage every instance is included")
+		conf.NewCanaryPercent("canary", 25, "canary percentage")
+		assert.Panics(t, func() { conf.NewCanaryPercent("other", 101, "canary percentage") })
+		conf.SetArgs([]string{})
+		assert.NoError(t, conf.Parse(""))
+
+		included := 0
+		for i := 0; i < 400; i++ {
+			conf.SetInstanceID(fmt.Sprintf("web-%d", i))
+			s := conf.Canary()
+			assert.Equal(t, s, conf.Canary(), "the bucket is deterministic")
+			assert.Equal(t, s.Bucket < 25, s.Included)
+			if s.Included {
+				included++
+			}
+		}
+		assert.InDelta(t, 100, included, 40)
+		assert.Error(t, conf.Set("canary", 150))
+	})
+
+	t.Run("test watched changes", func(t *testing.T) {
+		conf := newTestConfigurable(t)
+		conf.NewCanaryPercent("canary", 0, "canary percentage")
+		conf.NewBool("new-checkout", false, "new checkout", WithCanary())
+		conf.NewInt("port", 80, "port")
+		// An instance in the last tenth of the fleet.
+		for i := 0; conf.Canary().Bucket < 90; i++ {
+			conf.SetInstanceID(fmt.Sprintf("web-%d", i))
+		}
+		var mu sync.Mutex
+		var reports []CanaryStatus
+		conf.OnCanary(func(s CanaryStatus) {
+			mu.Lock()
+			defer mu.Unlock()
+			reports = append(reports, s)
+		})
+		conf.SetArgs([]string{})
+		assert.NoError(t, conf.Parse(""))
+		p := &pushProvider{fakeProvider: fakeProvider{name: "push"}, docs: make(chan map[string]interface{}), results: make(chan error)}
+		conf.AddProvider(p)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go conf.WatchProviders(ctx)
+
+		p.docs <- map[string]interface{}{"canary": 50, "new-checkout": true, "port": 8080}
+		assert.NoError(t, <-p.results)
+		assert.Equal(t, 8080, conf.View().Int("port"))
+		assert.False(t, conf.View().Bool("new-checkout"))
+		assert.Equal(t, []string{"new-checkout"}, conf.Canary().Held)
+
+		p.docs <- map[string]interface{}{"canary": 100}
+		assert.NoError(t, <-p.results)
+		assert.True(t, conf.View().Bool("new-checkout"))
+		assert.Equal(t, "remote push", conf.sources["new-checkout"].String())
+		assert.Empty(t, conf.Canary().Held)
+
+		mu.Lock()
+		defer mu.Unlock()
+		assert.Len(t, reports, 2)
+		assert.False(t, reports[0].Included)
+		assert.Equal(t, []string{"new-checkout"}, reports[0].Held)
+		assert.True(t, reports[1].Included)
+		assert.Empty(t, reports[1].Held)
+	})
+}
@@ -0,0 +1,131 @@
+package configurable
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+)
+
+// ChangeFunc is called after configuration values change. v is a snapshot
+// of the new values and changed lists the flags whose values differ.
+type ChangeFunc func(v View, changed []string)
+
+// ChangeEvent is the change of one flag.
+type ChangeEvent struct {
+	Name string
+	Old  interface{}
+	New  interface{}
+	// Source is where the new value came from, such as "file config.yaml",
+	// "remote consul" or "set".
+	Source string
+	// Batch identifies the load or Set that made the change. Every event it
+	// caused has the same Batch, and later batches have greater ones.
+	Batch uint64
+	// Changed lists every flag the batch changed, sorted by name, so a
+	// handler can tell a one-flag tweak from a reload.
+	Changed []string
+}
+
+// KeyChangeFunc is called with the change of the flag it was registered for.
+type KeyChangeFunc func(e ChangeEvent)
+
+// OnChange registers fn to be called whenever a load changes at least one
+// value.
+func (c *Configurable) OnChange(fn ChangeFunc) {
+	c.changeMu.Lock()
+	defer c.changeMu.Unlock()
+	c.changeFuncs = append(c.changeFuncs, fn)
+}
+
+// OnKeyChange registers fn to be called whenever the value of the flag name
+// changes. Unlike OnChange functions, it is called for every change even
+// when SetChangeDebounce is on, since coalescing would lose the old values.
+func (c *Configurable) OnKeyChange(name string, fn KeyChangeFunc) {
+	if _, ok := c.flags[name]; !ok {
+		panic(fmt.Sprintf("configurable: OnKeyChange on %s, which is not a flag", name))
+	}
+	c.changeMu.Lock()
+	defer c.changeMu.Unlock()
+	if c.keyChangeFuncs == nil {
+		c.keyChangeFuncs = make(map[string][]KeyChangeFunc)
+	}
+	c.keyChangeFuncs[name] = append(c.keyChangeFuncs[name], fn)
+}
+
+// OnChangeOf is OnKeyChange with the old and new values as the flag's type
+// T, such as int or []string. It panics if the flag does not hold a T.
+func OnChangeOf[T any](c IConfigurable, name string, fn func(old, new T, e ChangeEvent)) {
+	var zero T
+	if v, ok := c.View().Lookup(name); ok {
+		if _, ok := v.(T); !ok {
+			panic(fmt.Sprintf("configurable: OnChangeOf[%T] on %s, which holds %T", zero, name, v))
+		}
+	}
+	c.OnKeyChange(name, func(e ChangeEvent) {
+		fn(e.Old.(T), e.New.(T), e)
+	})
+}
+
+// changeEvents compares two generations, returning an event for every flag
+// whose value differs, sorted by name, or nil if nobody is listening. The
+// caller holds c.mu.
+func (c *Configurable) changeEvents(before, after *snapshot) []ChangeEvent {
+	c.changeMu.Lock()
+	listening := len(c.changeFuncs) > 0 || len(c.keyChangeFuncs) > 0
+	c.changeMu.Unlock()
+	if !listening {
+		return nil
+	}
+	var changed []string
+	for name, value := range after.values {
+		if !reflect.DeepEqual(before.values[name], value) {
+			changed = append(changed, name)
+		}
+	}
+	sort.Strings(changed)
+	events := make([]ChangeEvent, len(changed))
+	for i, name := range changed {
+		source, ok := c.sources[name]
+		if !ok {
+			source = ValueSource{Kind: SourceDefault}
+		}
+		events[i] = ChangeEvent{
+			Name:    name,
+			Old:     before.values[name],
+			New:     after.values[name],
+			Source:  source.String(),
+			Batch:   after.id,
+			Changed: changed,
+		}
+	}
+	return events
+}
+
+// notifyChanges calls the change functions with the events of a change to
+// the generation after.
+func (c *Configurable) notifyChanges(after *snapshot, events []ChangeEvent) {
+	if len(events) == 0 {
+		return
+	}
+	// Copied, so functions can be registered while these run.
+	c.changeMu.Lock()
+	keyFuncs := make([][]KeyChangeFunc, len(events))
+	for i, e := range events {
+		keyFuncs[i] = slices.Clone(c.keyChangeFuncs[e.Name])
+	}
+	funcs := slices.Clone(c.changeFuncs)
+	c.changeMu.Unlock()
+	for i, e := range events {
+		for _, fn := range keyFuncs[i] {
+			fn(e)
+		}
+	}
+	changed := events[0].Changed
+	if len(funcs) == 0 || c.deferChanges(changed) {
+		return
+	}
+	for _, fn := range funcs {
+		fn(after, changed)
+	}
+}
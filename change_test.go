@@ -0,0 +1,69 @@
+package configurable
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOnChange(t *testing.T) {
+	os.Clearenv()
+
+	conf := newTestConfigurable(t)
+	conf.NewInt("port", 80, "port")
+	conf.NewString("name", "api", "name")
+
+	var calls [][]string
+	var last View
+	conf.OnChange(func(v View, changed []string) {
+		calls = append(calls, changed)
+		last = v
+	})
+
+	assert.NoError(t, conf.LoadData("json", []byte(`{"port": 8080, "name": "api"}`)))
+	assert.Equal(t, [][]string{{"port"}}, calls)
+	assert.Equal(t, 8080, last.Int("port"))
+
+	assert.NoError(t, conf.LoadData("json", []byte(`{"port": 8080}`)))
+	assert.Len(t, calls, 1, "no change, no call")
+
+	assert.NoError(t, conf.ParseArgs([]string{"-name", "web", "-port", "9090"}))
+	assert.Equal(t, []string{"name", "port"}, calls[1])
+}
+
+func TestOnKeyChange(t *testing.T) {
+	os.Clearenv()
+
+	conf := newTestConfigurable(t)
+	conf.NewInt("port", 80, "port")
+	conf.NewString("name", "api", "name")
+	conf.NewList("hosts", []string{"a"}, "hosts")
+
+	var events []ChangeEvent
+	conf.OnKeyChange("port", func(e ChangeEvent) { events = append(events, e) })
+	var hosts [][]string
+	OnChangeOf(conf, "hosts", func(old, new []string, e ChangeEvent) {
+		hosts = append(hosts, old, new)
+	})
+
+	assert.NoError(t, conf.LoadData("json", []byte(`{"port": 8080, "name": "web"}`)))
+	assert.NoError(t, conf.Set("port", 9090))
+	assert.NoError(t, conf.LoadData("json", []byte(`{"name": "api"}`)))
+	if assert.Len(t, events, 2) {
+		assert.Equal(t, 80, events[0].Old)
+		assert.Equal(t, 8080, events[0].New)
+		assert.Equal(t, "file", events[0].Source)
+		assert.Equal(t, []string{"name", "port"}, events[0].Changed)
+		assert.Equal(t, 9090, events[1].New)
+		assert.Equal(t, "set", events[1].Source)
+		assert.Equal(t, []string{"port"}, events[1].Changed)
+		assert.Greater(t, events[1].Batch, events[0].Batch)
+	}
+
+	assert.NoError(t, conf.Set("hosts", []string{"b", "c"}))
+	assert.Equal(t, [][]string{{"a"}, {"b", "c"}}, hosts)
+
+	assert.Panics(t, func() { conf.OnKeyChange("missing", func(ChangeEvent) {}) })
+	assert.Panics(t, func() { OnChangeOf(conf, "port", func(old, new string, e ChangeEvent) {}) })
+}
@@ -0,0 +1,69 @@
+// Package cliconfig registers a Configurable's flags on a urfave/cli App, so
+// applications get the Configurable's file, environment and provider
+// resolution while cli parses the command line.
+package cliconfig
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/andreimerlescu/configurable"
+	"github.com/urfave/cli/v2"
+)
+
+// Bind adds conf's flags to app and, before any command runs, parses the
+// flags given into conf and loads filename, as Parse does. Values are
+// checked by conf rather than by cli. An app.Before already set runs
+// afterwards.
+func Bind(app *cli.App, conf configurable.IConfigurable, filename string) {
+	flags := conf.Flags()
+	for _, info := range flags {
+		var aliases []string
+		if info.Short != "" {
+			aliases = []string{info.Short}
+		}
+		var f cli.Flag
+		switch {
+		case info.Type == "bool":
+			value, _ := strconv.ParseBool(info.Default)
+			f = &cli.BoolFlag{Name: info.Name, Aliases: aliases, Usage: info.Usage, Value: value, Hidden: info.Hidden}
+		case repeated(info.Type):
+			f = &cli.StringSliceFlag{Name: info.Name, Aliases: aliases, Usage: info.Usage, DefaultText: info.Default, Hidden: info.Hidden}
+		default:
+			f = &cli.StringFlag{Name: info.Name, Aliases: aliases, Usage: info.Usage, DefaultText: info.Default, Hidden: info.Hidden}
+		}
+		app.Flags = append(app.Flags, f)
+	}
+	before := app.Before
+	app.Before = func(ctx *cli.Context) error {
+		var args []string
+		for _, info := range flags {
+			if !ctx.IsSet(info.Name) {
+				continue
+			}
+			switch {
+			case info.Type == "bool":
+				args = append(args, "-"+info.Name+"="+strconv.FormatBool(ctx.Bool(info.Name)))
+			case repeated(info.Type):
+				for _, item := range ctx.StringSlice(info.Name) {
+					args = append(args, "-"+info.Name+"="+item)
+				}
+			default:
+				args = append(args, "-"+info.Name+"="+ctx.String(info.Name))
+			}
+		}
+		conf.SetArgs(args)
+		if err := conf.Parse(filename); err != nil {
+			return err
+		}
+		if before != nil {
+			return before(ctx)
+		}
+		return nil
+	}
+}
+
+// repeated reports whether flags of type typ accumulate repeated values.
+func repeated(typ string) bool {
+	return typ == "list" || typ == "map" || strings.HasPrefix(typ, "[]")
+}
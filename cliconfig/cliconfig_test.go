@@ -0,0 +1,46 @@
+package cliconfig
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/andreimerlescu/configurable"
+	"github.com/stretchr/testify/assert"
+	"github.com/urfave/cli/v2"
+)
+
+func TestBind(t *testing.T) {
+	os.Clearenv()
+	conf := configurable.New(configurable.WithFlagSet(flag.NewFlagSet(t.Name(), flag.ContinueOnError)))
+	port := conf.NewInt("port", 80, "port", configurable.WithShort("p"))
+	host := conf.NewString("host", "localhost", "host")
+	verbose := conf.NewBool("verbose", false, "verbose")
+	hosts := conf.NewList("hosts", nil, "hosts")
+	labels := conf.NewMap("labels", nil, "labels")
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	assert.NoError(t, os.WriteFile(path, []byte(`{"host": "example.com"}`), 0600))
+
+	var ran, beforeRan bool
+	app := &cli.App{
+		Name:   "app",
+		Before: func(*cli.Context) error { beforeRan = true; return nil },
+		Action: func(ctx *cli.Context) error {
+			ran = true
+			assert.Equal(t, 8080, *port)
+			assert.Equal(t, "example.com", *host)
+			assert.True(t, *verbose)
+			assert.Equal(t, []string{"a", "b"}, *hosts)
+			assert.Equal(t, map[string]string{"team": "core"}, *labels)
+			assert.Equal(t, "now", ctx.Args().First())
+			return nil
+		},
+	}
+	Bind(app, conf, path)
+
+	assert.NoError(t, app.Run([]string{"app", "-p", "8080", "--verbose", "--hosts", "a", "--hosts", "b", "--labels", "team=core", "now"}))
+	assert.True(t, ran)
+	assert.True(t, beforeRan)
+}
@@ -0,0 +1,148 @@
+package configurable
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock is the source of time for everything time-dependent: debouncing,
+// staggered changes, freeze windows and overrides, retry backoff, circuit
+// breakers, staleness of sources and the times recorded on problems and
+// pending changes. Tests can inject a ManualClock to drive them without
+// sleeping.
+type Clock interface {
+	Now() time.Time
+	// AfterFunc calls f once d has passed, as time.AfterFunc does.
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// Timer is a call scheduled by Clock.AfterFunc. *time.Timer is one.
+type Timer interface {
+	Stop() bool
+	Reset(d time.Duration) bool
+}
+
+// realClock is the system clock.
+type realClock struct{}
+
+func (realClock) Now() time.Time { return time.Now() }
+
+func (realClock) AfterFunc(d time.Duration, f func()) Timer {
+	return time.AfterFunc(d, f)
+}
+
+// SystemClock returns the system clock, the default Clock.
+func SystemClock() Clock {
+	return realClock{}
+}
+
+// SetClock sets the clock; the default is the system clock. Set it before
+// anything is loaded or watched.
+func (c *Configurable) SetClock(clock Clock) {
+	c.clockSrc = clock
+}
+
+// WithClock is SetClock.
+func WithClock(clock Clock) Option {
+	return func(c *Configurable) {
+		c.SetClock(clock)
+	}
+}
+
+func (c *Configurable) clock() Clock {
+	if c.clockSrc == nil {
+		return realClock{}
+	}
+	return c.clockSrc
+}
+
+// ManualClock is a Clock that only moves when Advance or Set is called, for
+// deterministic tests. Scheduled calls run in the goroutine that moves the
+// clock, in the order they fall due, before it returns.
+type ManualClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*manualTimer
+}
+
+// NewManualClock returns a ManualClock reading now.
+func NewManualClock(now time.Time) *ManualClock {
+	return &ManualClock{now: now}
+}
+
+func (m *ManualClock) Now() time.Time {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.now
+}
+
+func (m *ManualClock) AfterFunc(d time.Duration, f func()) Timer {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	t := &manualTimer{clock: m, f: f, at: m.now.Add(d), active: true}
+	m.timers = append(m.timers, t)
+	return t
+}
+
+// Advance moves the clock forward by d, running the calls that fall due.
+func (m *ManualClock) Advance(d time.Duration) {
+	m.Set(m.Now().Add(d))
+}
+
+// Set moves the clock to t, running the calls that fall due. A t before the
+// current time only turns the clock back.
+func (m *ManualClock) Set(t time.Time) {
+	for {
+		m.mu.Lock()
+		var next *manualTimer
+		active := m.timers[:0]
+		for _, timer := range m.timers {
+			if !timer.active {
+				continue
+			}
+			active = append(active, timer)
+			if !timer.at.After(t) && (next == nil || timer.at.Before(next.at)) {
+				next = timer
+			}
+		}
+		m.timers = active
+		if next == nil {
+			m.now = t
+			m.mu.Unlock()
+			return
+		}
+		if next.at.After(m.now) {
+			m.now = next.at
+		}
+		next.active = false
+		m.mu.Unlock()
+		next.f()
+	}
+}
+
+type manualTimer struct {
+	clock  *ManualClock
+	f      func()
+	at     time.Time
+	active bool
+}
+
+func (t *manualTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	was := t.active
+	t.active = false
+	return was
+}
+
+func (t *manualTimer) Reset(d time.Duration) bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	was := t.active
+	t.at = t.clock.now.Add(d)
+	if !was {
+		t.active = true
+		t.clock.timers = append(t.clock.timers, t)
+	}
+	return was
+}
@@ -0,0 +1,112 @@
+package configurable
+
+import (
+	"context"
+	"flag"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClock(t *testing.T) {
+	os.Clearenv()
+	start := time.Date(2026, 3, 2, 8, 59, 0, 0, time.UTC)
+
+	t.Run("test manual clock", func(t *testing.T) {
+		clock := NewManualClock(start)
+		var fired []string
+		clock.AfterFunc(2*time.Second, func() { fired = append(fired, "b") })
+		clock.AfterFunc(time.Second, func() { fired = append(fired, "a") })
+		stopped := clock.AfterFunc(time.Second, func() { fired = append(fired, "stopped") })
+		assert.True(t, stopped.Stop())
+		assert.False(t, stopped.Stop())
+
+		clock.Advance(1500 * time.Millisecond)
+		assert.Equal(t, []string{"a"}, fired)
+		assert.Equal(t, start.Add(1500*time.Millisecond), clock.Now())
+		clock.Advance(time.Hour)
+		assert.Equal(t, []string{"a", "b"}, fired)
+
+		assert.False(t, stopped.Reset(time.Second), "a stopped timer can be reset")
+		clock.Advance(time.Second)
+		assert.Equal(t, []string{"a", "b", "stopped"}, fired)
+	})
+
+	t.Run("test change debounce", func(t *testing.T) {
+		clock := NewManualClock(start)
+		conf := newTestConfigurable(t)
+		conf.SetClock(clock)
+		conf.NewInt("port", 80, "port")
+		conf.SetArgs([]string{})
+		assert.NoError(t, conf.Parse(""))
+		conf.SetChangeDebounce(time.Second)
+		var got [][]string
+		conf.OnChange(func(v View, changed []string) { got = append(got, changed) })
+
+		assert.NoError(t, conf.Set("port", 8080))
+		clock.Advance(999 * time.Millisecond)
+		assert.NoError(t, conf.Set("port", 8081))
+		clock.Advance(999 * time.Millisecond)
+		assert.Empty(t, got)
+		clock.Advance(time.Millisecond)
+		assert.Equal(t, [][]string{{"port"}}, got)
+	})
+
+	t.Run("test freeze", func(t *testing.T) {
+		clock := NewManualClock(start)
+		conf := New(WithFlagSet(flag.NewFlagSet(t.Name(), flag.ContinueOnError)), WithClock(clock))
+		conf.NewFreezeWindows("freeze", []string{"09:00-17:00"}, "freeze windows")
+		conf.NewInt("port", 80, "port")
+		conf.SetArgs([]string{})
+		assert.NoError(t, conf.Parse(""))
+
+		assert.NoError(t, conf.Set("port", 8080))
+		clock.Advance(time.Minute)
+		assert.ErrorIs(t, conf.Set("port", 8081), ErrFrozen)
+		conf.OverrideFreeze("alice", "INC-42", time.Minute)
+		assert.NoError(t, conf.Set("port", 8081))
+		clock.Advance(time.Minute)
+		assert.ErrorIs(t, conf.Set("port", 8082), ErrFrozen, "the override has expired")
+	})
+
+	t.Run("test stagger", func(t *testing.T) {
+		clock := NewManualClock(start)
+		conf := newTestConfigurable(t)
+		conf.SetClock(clock)
+		conf.SetInstanceID("web-1")
+		conf.NewString("mode", "a", "mode", WithStagger(time.Hour))
+		conf.SetArgs([]string{})
+		assert.NoError(t, conf.Parse(""))
+		p := &pushProvider{fakeProvider: fakeProvider{name: "push"}, docs: make(chan map[string]interface{}), results: make(chan error)}
+		conf.AddProvider(p)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go conf.WatchProviders(ctx)
+
+		p.docs <- map[string]interface{}{"mode": "b"}
+		assert.NoError(t, <-p.results)
+		delay := conf.staggerDelay("mode")
+		clock.Advance(delay - time.Nanosecond)
+		assert.Equal(t, "a", conf.View().String("mode"))
+		clock.Advance(time.Nanosecond)
+		assert.Equal(t, "b", conf.View().String("mode"))
+	})
+
+	t.Run("test staleness", func(t *testing.T) {
+		clock := NewManualClock(start)
+		conf := newTestConfigurable(t)
+		conf.SetClock(clock)
+		conf.NewInt("port", 80, "port")
+		conf.AddProvider(&fakeProvider{name: "remote", data: map[string]interface{}{"port": 8080}}, WithMaxAge(time.Minute))
+		conf.SetArgs([]string{})
+		assert.NoError(t, conf.Parse(""))
+		assert.NoError(t, conf.LoadProviders(context.Background()))
+
+		clock.Advance(time.Minute)
+		assert.True(t, conf.Health().Healthy)
+		clock.Advance(time.Nanosecond)
+		assert.False(t, conf.Health().Healthy)
+	})
+}
@@ -0,0 +1,132 @@
+package configurable
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+)
+
+// ErrClosed is returned by Watch and WatchProviders once Close has been
+// called.
+var ErrClosed = errors.New("configurable is closed")
+
+// closers holds what Close stops: the file watches and the provider watches
+// still running, and the hooks registered with OnClose.
+type closers struct {
+	mu     sync.Mutex
+	closed bool
+	next   int
+	fns    map[int]func() error
+	hooks  map[int]func() error
+}
+
+// add registers fn in *fns. The caller holds mu.
+func (cl *closers) add(fns *map[int]func() error, fn func() error) (remove func(), err error) {
+	if cl.closed {
+		return nil, ErrClosed
+	}
+	if *fns == nil {
+		*fns = make(map[int]func() error)
+	}
+	id := cl.next
+	cl.next++
+	(*fns)[id] = fn
+	return func() {
+		cl.mu.Lock()
+		defer cl.mu.Unlock()
+		delete(*fns, id)
+	}, nil
+}
+
+// take returns the functions in *fns, newest first as with deferred calls,
+// and clears it. The caller holds mu.
+func (cl *closers) take(fns *map[int]func() error) []func() error {
+	ids := make([]int, 0, len(*fns))
+	for id := range *fns {
+		ids = append(ids, id)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(ids)))
+	taken := make([]func() error, len(ids))
+	for i, id := range ids {
+		taken[i] = (*fns)[id]
+	}
+	*fns = nil
+	return taken
+}
+
+// onClose registers fn to be run by Close. The returned remove unregisters
+// it, for work that stops on its own first.
+func (c *Configurable) onClose(fn func() error) (remove func(), err error) {
+	c.closers.mu.Lock()
+	defer c.closers.mu.Unlock()
+	return c.closers.add(&c.closers.fns, fn)
+}
+
+// OnClose registers fn to be run by Close, so that integrations running in
+// the background, such as webhook notifiers, stop with the Configurable. The
+// functions run last, newest first, after held change notifications have
+// been delivered. The returned remove unregisters fn. Once the Configurable
+// is closed, OnClose returns ErrClosed.
+func (c *Configurable) OnClose(fn func() error) (remove func(), err error) {
+	c.closers.mu.Lock()
+	defer c.closers.mu.Unlock()
+	return c.closers.add(&c.closers.hooks, fn)
+}
+
+// SetContext ties everything the Configurable runs in the background to
+// ctx: once ctx is done, the Configurable is closed as by Close. Tests and
+// servers can scope it to their own lifetime this way.
+func (c *Configurable) SetContext(ctx context.Context) {
+	context.AfterFunc(ctx, func() {
+		if err := c.Close(); err != nil {
+			c.logger().Warn("configurable: close failed", "error", err)
+		}
+	})
+}
+
+// WithContext is SetContext.
+func WithContext(ctx context.Context) Option {
+	return func(c *Configurable) {
+		c.SetContext(ctx)
+	}
+}
+
+// Close stops everything the Configurable runs in the background: it stops
+// file watches, cancels the watches of WatchProviders and waits for them to
+// return, drops changes waiting WithStagger, delivers change notifications
+// held by SetChangeDebounce rather than dropping them, and then runs the
+// functions registered with OnClose, which close webhook notifiers. There is
+// no audit log to flush: the audit trail of freeze overrides is written to
+// the logger as each entry happens. Values stay readable and can still be
+// loaded and set, but Watch and WatchProviders return ErrClosed. Closing
+// again does nothing. It must not be called from a change function, which a
+// watch may be waiting on.
+func (c *Configurable) Close() error {
+	c.closers.mu.Lock()
+	if c.closers.closed {
+		c.closers.mu.Unlock()
+		return nil
+	}
+	c.closers.closed = true
+	fns := c.closers.take(&c.closers.fns)
+	hooks := c.closers.take(&c.closers.hooks)
+	c.closers.mu.Unlock()
+
+	var errs []error
+	for _, fn := range fns {
+		errs = append(errs, fn())
+	}
+	c.staggered.stop()
+	c.changeMu.Lock()
+	d := c.changeDebounce
+	c.changeMu.Unlock()
+	if d != nil {
+		d.stop()
+		c.flushChanges()
+	}
+	for _, fn := range hooks {
+		errs = append(errs, fn())
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,99 @@
+//go:build !(js && wasm) && !tinygo
+
+package configurable
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/goleak"
+)
+
+func TestClose(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+	os.Clearenv()
+	path := filepath.Join(t.TempDir(), "config.json")
+	assert.NoError(t, os.WriteFile(path, []byte(`{"port": 8080}`), 0o644))
+	conf := newTestConfigurable(t)
+	conf.NewInt("port", 80, "port")
+	conf.NewString("mode", "a", "mode", WithStagger(time.Hour))
+	for i := 0; conf.staggerDelay("mode") < time.Minute; i++ {
+		conf.SetInstanceID(fmt.Sprintf("web-%d", i))
+	}
+	conf.SetArgs([]string{})
+	assert.NoError(t, conf.Parse(path))
+	conf.SetChangeDebounce(time.Hour)
+	changes := make(chan []string, 10)
+	conf.OnChange(func(v View, changed []string) {
+		changes <- changed
+	})
+
+	_, err := conf.Watch(path)
+	assert.NoError(t, err)
+	p := &pushProvider{fakeProvider: fakeProvider{name: "push"}, docs: make(chan map[string]interface{}), results: make(chan error)}
+	conf.AddProvider(p)
+	watching := make(chan error)
+	go func() { watching <- conf.WatchProviders(context.Background()) }()
+	p.docs <- map[string]interface{}{"mode": "b"}
+	assert.NoError(t, <-p.results)
+	assert.NoError(t, conf.Set("port", 9000))
+	var flushed bool
+	_, err = conf.OnClose(func() error {
+		flushed = len(changes) == 1
+		return nil
+	})
+	assert.NoError(t, err)
+
+	assert.NoError(t, conf.Close())
+	assert.True(t, flushed, "OnClose functions run after held notifications are delivered")
+	assert.ErrorIs(t, <-watching, context.Canceled)
+	assert.Equal(t, []string{"port"}, <-changes, "held notifications are delivered")
+	assert.Empty(t, conf.staggered.timers)
+
+	assert.NoError(t, os.WriteFile(path, []byte(`{"port": 9090}`), 0o644))
+	time.Sleep(3 * fileWatchQuiet)
+	assert.Equal(t, 9000, conf.View().Int("port"), "the file is no longer watched")
+	assert.Equal(t, "a", conf.View().String("mode"))
+
+	_, err = conf.Watch(path)
+	assert.ErrorIs(t, err, ErrClosed)
+	assert.ErrorIs(t, conf.WatchProviders(context.Background()), ErrClosed)
+	_, err = conf.OnClose(func() error { return nil })
+	assert.ErrorIs(t, err, ErrClosed)
+	assert.NoError(t, conf.Close())
+	assert.NoError(t, conf.Set("port", 9001), "values can still be set")
+}
+
+func TestSetContext(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+	os.Clearenv()
+	path := filepath.Join(t.TempDir(), "config.json")
+	assert.NoError(t, os.WriteFile(path, []byte(`{"port": 8080}`), 0o644))
+	ctx, cancel := context.WithCancel(context.Background())
+	conf := New(WithFlagSet(flag.NewFlagSet(t.Name(), flag.ContinueOnError)), WithContext(ctx))
+	conf.NewInt("port", 80, "port")
+	conf.SetArgs([]string{})
+	assert.NoError(t, conf.Parse(path))
+	_, err := conf.Watch(path)
+	assert.NoError(t, err)
+	p := &pushProvider{fakeProvider: fakeProvider{name: "push"}, docs: make(chan map[string]interface{}), results: make(chan error)}
+	conf.AddProvider(p)
+	watching := make(chan error)
+	go func() { watching <- conf.WatchProviders(context.Background()) }()
+	p.docs <- map[string]interface{}{"port": 9000}
+	assert.NoError(t, <-p.results)
+
+	cancel()
+	assert.ErrorIs(t, <-watching, context.Canceled)
+	assert.Eventually(t, func() bool {
+		_, err := conf.Watch(path)
+		return errors.Is(err, ErrClosed)
+	}, time.Second, 5*time.Millisecond)
+}
@@ -0,0 +1,35 @@
+// Package cobraconfig registers a Configurable's flags on a cobra.Command,
+// so commands get the Configurable's file, environment and provider
+// resolution while cobra parses the command line.
+package cobraconfig
+
+import (
+	"github.com/andreimerlescu/configurable"
+	"github.com/andreimerlescu/configurable/pflagset"
+	"github.com/spf13/cobra"
+)
+
+// Bind adds conf's flags to cmd as persistent flags and, before cmd or any
+// of its subcommands runs, parses the flags given into conf and loads
+// filename, as Parse does. A PersistentPreRun or PersistentPreRunE already
+// set on cmd runs afterwards. Subcommands with their own persistent pre-run
+// hide cmd's unless cobra.EnableTraverseRunHooks is set.
+func Bind(cmd *cobra.Command, conf configurable.IConfigurable, filename string) {
+	fs := pflagset.New(conf)
+	cmd.PersistentFlags().AddFlagSet(fs.FlagSet)
+	pre, preE := cmd.PersistentPreRun, cmd.PersistentPreRunE
+	cmd.PersistentPreRun = nil
+	cmd.PersistentPreRunE = func(c *cobra.Command, args []string) error {
+		conf.SetArgs(fs.ConfigArgs())
+		if err := conf.Parse(filename); err != nil {
+			return err
+		}
+		if preE != nil {
+			return preE(c, args)
+		}
+		if pre != nil {
+			pre(c, args)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,40 @@
+package cobraconfig
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/andreimerlescu/configurable"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBind(t *testing.T) {
+	os.Clearenv()
+	conf := configurable.New(configurable.WithFlagSet(flag.NewFlagSet(t.Name(), flag.ContinueOnError)))
+	port := conf.NewInt("port", 80, "port", configurable.WithShort("p"))
+	host := conf.NewString("host", "localhost", "host")
+	verbose := conf.NewBool("verbose", false, "verbose")
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	assert.NoError(t, os.WriteFile(path, []byte(`{"host": "example.com"}`), 0600))
+
+	var ran, preRan bool
+	root := &cobra.Command{Use: "app", PersistentPreRun: func(*cobra.Command, []string) { preRan = true }}
+	serve := &cobra.Command{Use: "serve", Run: func(cmd *cobra.Command, args []string) {
+		ran = true
+		assert.Equal(t, 8080, *port)
+		assert.Equal(t, "example.com", *host)
+		assert.True(t, *verbose)
+		assert.Equal(t, []string{"now"}, args)
+	}}
+	root.AddCommand(serve)
+	Bind(root, conf, path)
+
+	root.SetArgs([]string{"serve", "-p", "8080", "--verbose", "now"})
+	assert.NoError(t, root.Execute())
+	assert.True(t, ran)
+	assert.True(t, preRan)
+}
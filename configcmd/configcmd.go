@@ -0,0 +1,130 @@
+// Package configcmd implements the config subcommands every CLI ends up
+// writing, against one configuration file:
+//
+//	myapp config get <key>
+//	myapp config set <key> <value>
+//	myapp config unset <key>
+//	myapp config list
+//
+// set checks the value against the flag's type and constraints before the
+// file is touched, and edits only that key, under a lock shared with other
+// processes editing the same file:
+//
+//	cmd := &configcmd.Command{Config: config, File: path}
+//	if err := cmd.Run(os.Args[2:]); err != nil {
+//		log.Fatal(err)
+//	}
+package configcmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/andreimerlescu/configurable"
+)
+
+// ErrUsage is returned by Run for arguments naming no subcommand, or the
+// wrong number of arguments for one.
+var ErrUsage = errors.New("usage: config get <key> | set <key> <value> | unset <key> | list")
+
+// redacted replaces the values of secret flags in list output.
+const redacted = "[redacted]"
+
+// Command runs the config subcommands.
+type Command struct {
+	Config configurable.IConfigurable
+	// File is the configuration file set and unset edit, usually the one
+	// passed to Parse.
+	File string
+	// Out receives the output of get and list. It defaults to os.Stdout.
+	Out io.Writer
+}
+
+// Run runs the subcommand named by args, the arguments after "config".
+func (cmd *Command) Run(args []string) error {
+	if len(args) == 0 {
+		return ErrUsage
+	}
+	switch sub, rest := args[0], args[1:]; {
+	case sub == "get" && len(rest) == 1:
+		return cmd.Get(rest[0])
+	case sub == "set" && len(rest) == 2:
+		return cmd.Set(rest[0], rest[1])
+	case sub == "unset" && len(rest) == 1:
+		return cmd.Unset(rest[0])
+	case sub == "list" && len(rest) == 0:
+		return cmd.List()
+	}
+	return ErrUsage
+}
+
+// Get prints the current value of the flag name, from whichever source set
+// it.
+func (cmd *Command) Get(name string) error {
+	info, ok := cmd.lookup(name)
+	if !ok {
+		return fmt.Errorf("no flag named %s", name)
+	}
+	_, err := fmt.Fprintln(cmd.out(), text(info.Value))
+	return err
+}
+
+// Set stores value for the flag name in the file.
+func (cmd *Command) Set(name, value string) error {
+	return cmd.Config.SetInFile(cmd.File, name, value)
+}
+
+// Unset removes the flag name from the file.
+func (cmd *Command) Unset(name string) error {
+	return cmd.Config.UnsetInFile(cmd.File, name)
+}
+
+// List prints every visible flag with its value and the source of the value.
+// Secret values are redacted.
+func (cmd *Command) List() error {
+	w := tabwriter.NewWriter(cmd.out(), 0, 4, 2, ' ', 0)
+	for _, info := range cmd.Config.Flags() {
+		if info.Hidden {
+			continue
+		}
+		value := text(info.Value)
+		if info.Secret {
+			value = redacted
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", info.Name, value, info.Source)
+	}
+	return w.Flush()
+}
+
+func (cmd *Command) lookup(name string) (configurable.FlagInfo, bool) {
+	for _, info := range cmd.Config.Flags() {
+		if info.Name == name {
+			return info, true
+		}
+	}
+	return configurable.FlagInfo{}, false
+}
+
+func (cmd *Command) out() io.Writer {
+	if cmd.Out == nil {
+		return os.Stdout
+	}
+	return cmd.Out
+}
+
+// text renders a value for the terminal: strings as they are, anything else
+// as JSON, so lists and maps can be told apart from strings.
+func text(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	b, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(b)
+}
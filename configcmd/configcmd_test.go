@@ -0,0 +1,53 @@
+package configcmd
+
+import (
+	"bytes"
+	"flag"
+	"path/filepath"
+	"testing"
+
+	"github.com/andreimerlescu/configurable"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommand(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "app.json")
+	newConf := func() configurable.IConfigurable {
+		conf := configurable.New(configurable.WithFlagSet(flag.NewFlagSet(t.Name(), flag.ContinueOnError)))
+		conf.NewInt("port", 80, "port")
+		conf.NewList("tags", []string{"a"}, "tags")
+		conf.NewString("token", "s3cret", "token", configurable.WithSecret())
+		conf.SetArgs([]string{})
+		return conf
+	}
+
+	conf := newConf()
+	var out bytes.Buffer
+	cmd := &Command{Config: conf, File: file, Out: &out}
+	assert.NoError(t, cmd.Run([]string{"set", "port", "8080"}))
+	assert.ErrorContains(t, cmd.Run([]string{"set", "port", "eighty"}), "eighty")
+	assert.ErrorIs(t, cmd.Run([]string{"set", "port"}), ErrUsage)
+	assert.ErrorIs(t, cmd.Run(nil), ErrUsage)
+
+	conf = newConf()
+	assert.NoError(t, conf.Parse(file))
+	cmd = &Command{Config: conf, File: file, Out: &out}
+	assert.NoError(t, cmd.Run([]string{"get", "port"}))
+	assert.NoError(t, cmd.Run([]string{"get", "tags"}))
+	assert.Equal(t, "8080\n[\"a\"]\n", out.String())
+	assert.EqualError(t, cmd.Run([]string{"get", "prot"}), "no flag named prot")
+
+	out.Reset()
+	assert.NoError(t, cmd.Run([]string{"list"}))
+	assert.Equal(t, "port   8080        file "+file+"\ntags   [\"a\"]       default\ntoken  [redacted]  default\n", out.String())
+
+	conf.ReadOnly(true)
+	assert.ErrorIs(t, cmd.Run([]string{"set", "port", "9090"}), configurable.ErrReadOnly)
+	assert.ErrorIs(t, cmd.Run([]string{"unset", "port"}), configurable.ErrReadOnly)
+	conf.ReadOnly(false)
+
+	assert.NoError(t, cmd.Run([]string{"unset", "port"}))
+	conf = newConf()
+	assert.NoError(t, conf.Parse(file))
+	assert.Equal(t, 80, *conf.Int("port"))
+}
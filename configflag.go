@@ -0,0 +1,33 @@
+package configurable
+
+import "path/filepath"
+
+// configFlag and configFormatFlag name the flags EnableConfigFlag registers.
+const (
+	configFlag       = "config"
+	configFormatFlag = "config-format"
+)
+
+// EnableConfigFlag registers --config and --config-format flags. Parse loads
+// the file named by --config, in place of the file it is given, so the path
+// need not be plumbed through the application; --config-format names its
+// format (json, yaml, toml or ini) when the file's extension does not.
+func (c *Configurable) EnableConfigFlag() {
+	if _, ok := c.flags[configFlag]; ok {
+		return
+	}
+	c.NewString(configFlag, "", "configuration file to load", WithEarly())
+	c.NewString(configFormatFlag, "", "format of the configuration file: json, yaml, toml or ini (default: from its extension)", WithEarly())
+}
+
+// configFile returns the file Parse loads, given filename, and its format.
+func (c *Configurable) configFile(filename string) (string, string) {
+	format := filepath.Ext(filename)
+	if path, ok := c.flags[configFlag].(*string); ok && *path != "" {
+		filename, format = *path, filepath.Ext(*path)
+	}
+	if f, ok := c.flags[configFormatFlag].(*string); ok && *f != "" {
+		format = *f
+	}
+	return filename, format
+}
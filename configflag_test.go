@@ -0,0 +1,68 @@
+package configurable
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigFlag(t *testing.T) {
+	os.Clearenv()
+	dir := t.TempDir()
+	yamlFile := filepath.Join(dir, "app.conf")
+	assert.NoError(t, os.WriteFile(yamlFile, []byte("port: 8080\n"), 0600))
+	jsonFile := filepath.Join(dir, "app.json")
+	assert.NoError(t, os.WriteFile(jsonFile, []byte(`{"port": 9090}`), 0600))
+
+	t.Run("test path and format", func(t *testing.T) {
+		conf := newTestConfigurable(t)
+		port := conf.NewInt("port", 80, "port")
+		conf.EnableConfigFlag()
+		conf.EnableConfigFlag()
+		conf.SetArgs([]string{"--config", yamlFile, "--config-format", "yaml"})
+		assert.NoError(t, conf.Parse(""))
+		assert.Equal(t, 8080, *port)
+	})
+
+	t.Run("test overrides parse argument", func(t *testing.T) {
+		conf := newTestConfigurable(t)
+		port := conf.NewInt("port", 80, "port")
+		conf.EnableConfigFlag()
+		conf.SetArgs([]string{"--config=" + jsonFile})
+		assert.NoError(t, conf.Parse(filepath.Join(dir, "missing.json")))
+		assert.Equal(t, 9090, *port)
+	})
+
+	t.Run("test format for parse argument", func(t *testing.T) {
+		conf := newTestConfigurable(t)
+		port := conf.NewInt("port", 80, "port")
+		conf.EnableConfigFlag()
+		conf.SetArgs([]string{"--config-format", "yaml"})
+		assert.NoError(t, conf.Parse(yamlFile))
+		assert.Equal(t, 8080, *port)
+
+		conf = newTestConfigurable(t)
+		conf.NewInt("port", 80, "port")
+		conf.EnableConfigFlag()
+		conf.SetArgs([]string{})
+		assert.Error(t, conf.Parse(yamlFile), ".conf is no format")
+	})
+}
+
+func TestConfigFlagCommandLineWins(t *testing.T) {
+	os.Clearenv()
+	dir := t.TempDir()
+	file := filepath.Join(dir, "app.json")
+	assert.NoError(t, os.WriteFile(file, []byte(`{"port": 9090, "host": "file"}`), 0600))
+
+	conf := newTestConfigurable(t)
+	conf.EnableConfigFlag()
+	port := conf.NewInt("port", 8080, "port")
+	host := conf.NewString("host", "default", "host")
+	conf.SetArgs([]string{"-port", "7070", "--config", file})
+	assert.NoError(t, conf.Parse(""))
+	assert.Equal(t, 7070, *port)
+	assert.Equal(t, "file", *host)
+}
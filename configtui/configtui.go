@@ -0,0 +1,225 @@
+// Package configtui is a terminal UI for browsing and editing a
+// Configurable, for on-call changes over SSH. It lists the flags with their
+// current values and sources, and edits write the selected file through
+// SetInFile, so every value is checked before the file is touched:
+//
+//	if err := configtui.Run(config, "/etc/myapp/config.yaml"); err != nil {
+//		log.Fatal(err)
+//	}
+//
+// It is a separate module so programs that do not use it do not depend on
+// bubbletea.
+package configtui
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/andreimerlescu/configurable"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// redacted replaces the values of secret flags.
+const redacted = "[redacted]"
+
+// Run shows the UI for conf and file until the user quits.
+func Run(conf configurable.IConfigurable, file string) error {
+	_, err := tea.NewProgram(New(conf, file), tea.WithAltScreen()).Run()
+	return err
+}
+
+// Model is the bubbletea model behind Run, for programs embedding the UI in
+// their own.
+type Model struct {
+	conf    configurable.IConfigurable
+	file    string
+	rows    []configurable.FlagInfo
+	cursor  int
+	editing bool
+	input   []rune
+	status  string
+}
+
+// New returns a Model for conf, saving edits to file.
+func New(conf configurable.IConfigurable, file string) *Model {
+	m := &Model{conf: conf, file: file}
+	m.refresh()
+	return m
+}
+
+// refresh reloads the rows from the Configurable.
+func (m *Model) refresh() {
+	m.rows = m.rows[:0]
+	for _, info := range m.conf.Flags() {
+		if !info.Hidden {
+			m.rows = append(m.rows, info)
+		}
+	}
+	if m.cursor >= len(m.rows) {
+		m.cursor = max(len(m.rows)-1, 0)
+	}
+}
+
+func (m *Model) Init() tea.Cmd {
+	return nil
+}
+
+func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	if m.editing {
+		return m, m.edit(key)
+	}
+	switch key.String() {
+	case "ctrl+c", "q":
+		return m, tea.Quit
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case "down", "j":
+		if m.cursor < len(m.rows)-1 {
+			m.cursor++
+		}
+	case "enter", "e":
+		if len(m.rows) > 0 {
+			row := m.rows[m.cursor]
+			m.editing = true
+			m.input = nil
+			if !row.Secret {
+				m.input = []rune(editText(row.Value))
+			}
+			m.status = ""
+		}
+	case "u":
+		if len(m.rows) > 0 {
+			m.save(m.rows[m.cursor].Name, nil)
+		}
+	}
+	return m, nil
+}
+
+// edit handles a key while a value is being edited.
+func (m *Model) edit(key tea.KeyMsg) tea.Cmd {
+	switch key.Type {
+	case tea.KeyCtrlC:
+		return tea.Quit
+	case tea.KeyEsc:
+		m.editing = false
+		m.status = "edit cancelled"
+	case tea.KeyEnter:
+		value := string(m.input)
+		if m.save(m.rows[m.cursor].Name, &value) {
+			m.editing = false
+		}
+	case tea.KeyBackspace:
+		if len(m.input) > 0 {
+			m.input = m.input[:len(m.input)-1]
+		}
+	case tea.KeyRunes, tea.KeySpace:
+		m.input = append(m.input, key.Runes...)
+	}
+	return nil
+}
+
+// save writes value for name to the file, or removes name if value is nil,
+// and reports whether the file was written. A saved value is also applied,
+// unless a source that overrides files, such as a flag or the environment,
+// set the current one.
+func (m *Model) save(name string, value *string) bool {
+	row := m.rows[m.cursor]
+	if value == nil {
+		if err := m.conf.UnsetInFile(m.file, name); err != nil {
+			m.status = "error: " + err.Error()
+			return false
+		}
+		m.status = fmt.Sprintf("removed %s from %s; this takes effect at the next load", name, m.file)
+		return true
+	}
+	if err := m.conf.SetInFile(m.file, name, *value); err != nil {
+		m.status = "error: " + err.Error()
+		return false
+	}
+	m.status = fmt.Sprintf("saved %s to %s", name, m.file)
+	switch {
+	case row.Source == "default" || strings.HasPrefix(row.Source, "file") || row.Source == "set":
+		if err := m.conf.Set(name, *value); err != nil {
+			m.status += "; not applied: " + err.Error()
+		}
+	default:
+		m.status += "; the value from " + row.Source + " still takes precedence"
+	}
+	m.refresh()
+	return true
+}
+
+func (m *Model) View() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\n\n", m.file)
+	width := 0
+	for _, row := range m.rows {
+		width = max(width, len(row.Name))
+	}
+	for i, row := range m.rows {
+		cursor := "  "
+		if i == m.cursor {
+			cursor = "> "
+		}
+		value := text(row.Value)
+		if row.Secret {
+			value = redacted
+		}
+		if i == m.cursor && m.editing {
+			value = string(m.input) + "█"
+		}
+		fmt.Fprintf(&b, "%s%-*s  %s  (%s)\n", cursor, width, row.Name, value, row.Source)
+	}
+	if len(m.rows) > 0 && m.rows[m.cursor].Usage != "" {
+		fmt.Fprintf(&b, "\n%s: %s\n", m.rows[m.cursor].Type, m.rows[m.cursor].Usage)
+	}
+	if m.status != "" {
+		fmt.Fprintf(&b, "\n%s\n", m.status)
+	}
+	if m.editing {
+		b.WriteString("\nenter save • esc cancel\n")
+	} else {
+		b.WriteString("\n↑/↓ move • enter edit • u remove from file • q quit\n")
+	}
+	return b.String()
+}
+
+// text renders a value for display: strings as they are, anything else as
+// JSON.
+func text(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	b, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(b)
+}
+
+// editText renders a value in the form SetInFile reads back from a string:
+// list items and map entries separated by commas.
+func editText(value interface{}) string {
+	switch v := value.(type) {
+	case []string:
+		return strings.Join(v, ",")
+	case map[string]string:
+		pairs := make([]string, 0, len(v))
+		for k, val := range v {
+			pairs = append(pairs, k+"="+val)
+		}
+		sort.Strings(pairs)
+		return strings.Join(pairs, ",")
+	case []int, []float64, []bool:
+		return strings.Trim(strings.Join(strings.Fields(fmt.Sprint(v)), ","), "[]")
+	}
+	return text(value)
+}
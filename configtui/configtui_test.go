@@ -0,0 +1,53 @@
+package configtui
+
+import (
+	"flag"
+	"path/filepath"
+	"testing"
+
+	"github.com/andreimerlescu/configurable"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestModel(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "app.json")
+	conf := configurable.New(configurable.WithFlagSet(flag.NewFlagSet(t.Name(), flag.ContinueOnError)))
+	conf.NewBoundedInt("port", 80, 1, 65535, "listen port")
+	conf.NewList("tags", []string{"a", "b"}, "tags")
+	conf.NewString("token", "s3cret", "token", configurable.WithSecret())
+
+	keys := func(m *Model, msgs ...tea.KeyMsg) {
+		for _, msg := range msgs {
+			m.Update(msg)
+		}
+	}
+	typed := func(s string) tea.KeyMsg { return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)} }
+	backspace := tea.KeyMsg{Type: tea.KeyBackspace}
+	enter := tea.KeyMsg{Type: tea.KeyEnter}
+
+	m := New(conf, file)
+	assert.Contains(t, m.View(), "> port   80  (default)")
+	assert.Contains(t, m.View(), `tags   ["a","b"]  (default)`)
+	assert.Contains(t, m.View(), "token  [redacted]")
+
+	keys(m, enter, backspace, backspace, typed("99999"), enter)
+	assert.True(t, m.editing, "an invalid value keeps the editor open")
+	assert.Contains(t, m.View(), "error: ")
+
+	keys(m, backspace, backspace, backspace, backspace, backspace, typed("443"), enter)
+	assert.False(t, m.editing)
+	assert.Equal(t, 443, *conf.Int("port"))
+	assert.Contains(t, m.View(), "> port   443  (set)")
+
+	keys(m, typed("j"), enter, typed(",c"), enter)
+	assert.Equal(t, []string{"a", "b", "c"}, *conf.List("tags"))
+
+	keys(m, typed("k"), typed("u"))
+	assert.Contains(t, m.View(), "removed port from "+file)
+	reload := configurable.New(configurable.WithFlagSet(flag.NewFlagSet(t.Name(), flag.ContinueOnError)))
+	port := reload.NewInt("port", 80, "port")
+	reload.NewList("tags", nil, "tags")
+	assert.NoError(t, reload.LoadFile(file))
+	assert.Equal(t, 80, *port)
+}
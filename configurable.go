@@ -1,17 +1,30 @@
 package configurable
 
 import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding"
 	"encoding/json"
 	"errors"
 	"flag"
 	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"reflect"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/go-ini/ini"
+	"github.com/pelletier/go-toml/v2"
 	"gopkg.in/yaml.v3"
 )
 
@@ -19,46 +32,258 @@ import (
 type IConfigurable interface {
 	// Existing methods
 	Int(name string) *int
-	NewInt(name string, value int, usage string) *int
+	NewInt(name string, value int, usage string, opts ...FlagOption) *int
 
 	Int64(name string) *int64
-	NewInt64(name string, value int64, usage string) *int64
+	NewInt64(name string, value int64, usage string, opts ...FlagOption) *int64
 
 	Float64(name string) *float64
-	NewFloat64(name string, value float64, usage string) *float64
+	NewFloat64(name string, value float64, usage string, opts ...FlagOption) *float64
 
 	String(name string) *string
-	NewString(name, value, usage string) *string
+	NewString(name, value, usage string, opts ...FlagOption) *string
 
 	Bool(name string) *bool
-	NewBool(name string, value bool, usage string) *bool
+	NewBool(name string, value bool, usage string, opts ...FlagOption) *bool
 
 	Duration(name string) *time.Duration
-	NewDuration(name string, value time.Duration, usage string) *time.Duration
+	NewDuration(name string, value time.Duration, usage string, opts ...FlagOption) *time.Duration
 
 	List(name string) *[]string
-	NewList(name string, value []string, usage string) *[]string
+	NewList(name string, value []string, usage string, opts ...FlagOption) *[]string
 
 	Map(name string) *map[string]string
-	NewMap(name string, value map[string]string, usage string) *map[string]string
+	NewMap(name string, value map[string]string, usage string, opts ...FlagOption) *map[string]string
+
+	NewTextVar(name string, p encoding.TextUnmarshaler, usage string, opts ...FlagOption)
+
+	JSON(name string) *json.RawMessage
+	NewJSON(name string, value json.RawMessage, usage string, opts ...FlagOption) *json.RawMessage
+
+	IntSlice(name string) *[]int
+	NewIntSlice(name string, value []int, usage string, opts ...FlagOption) *[]int
+
+	Float64Slice(name string) *[]float64
+	NewFloat64Slice(name string, value []float64, usage string, opts ...FlagOption) *[]float64
+
+	BoolSlice(name string) *[]bool
+	NewBoolSlice(name string, value []bool, usage string, opts ...FlagOption) *[]bool
+
+	Flag(name string) *FlagBuilder
+
+	NewBoundedInt(name string, value, min, max int, usage string, opts ...FlagOption) *int
+	NewBoundedFloat64(name string, value, min, max float64, usage string, opts ...FlagOption) *float64
 
 	LoadFile(filename string) error
+	LoadData(format string, data []byte) error
+	LoadEnvFile(filename string) error
+	WriteFile(filename string) error
+	Dump(format string) ([]byte, error)
 	Parse(filename string) error
 
+	SetArgs(args []string)
+	FlagSet() *flag.FlagSet
+	ParseArgs(args []string) error
+	SetParseOptions(opts ParseOptions)
+	ParseReport(filename string) (Report, error)
+	Banner(opts BannerOptions) string
+
+	SetTenantLoader(loader TenantLoader)
+	TenantFiles(pattern string) TenantLoader
+	Tenant(id string) View
+	LoadTenant(id string) (View, error)
+	InvalidateTenant(id string)
+	SetEnv(key, value string)
+	SetPrecedence(kinds ...SourceKind)
+	Source(name string) ValueSource
+	SetEnvPrefix(prefix string)
+	BindEnv(name string, vars ...string) error
+	SetEnvDelimiter(delimiter string)
+	BindEnvPrefixToMap(name, prefix string) error
+	LoadDownwardAPI(name, filename string) error
+	SetVariables(namespace string, vars map[string]string)
+	EnableEnvFile()
+	EnableConfigFlag()
+
 	Usage() string
+	UsageFull() string
+	Docs() string
+	Schema() ([]byte, error)
+	Flags() []FlagInfo
+	Manifest() ([]byte, error)
+	PrintUsage()
+	SetOutput(w io.Writer)
+	SetUsageFunc(fn UsageFunc)
+
+	View() View
+	ViewContext(ctx context.Context) View
+	OnChange(fn ChangeFunc)
+	OnKeyChange(name string, fn KeyChangeFunc)
+	AddHooks(h Hooks)
+	SetChangeDebounce(quiet time.Duration)
+
+	AddProvider(p Provider, opts ...SourceOption)
+	SetCacheDir(dir string)
+	SetFileMode(mode fs.FileMode)
+	EditFile(filename string, edit func() error) error
+	SetInFile(filename, name string, value interface{}) error
+	UnsetInFile(filename, name string) error
+	Unmarshal(target interface{}) error
+	LoadProviders(ctx context.Context) error
+	WatchProviders(ctx context.Context) error
+	Watch(filename string) (stop func(), err error)
+	SetContext(ctx context.Context)
+	OnClose(fn func() error) (remove func(), err error)
+	Close() error
+	LoadLastKnownGood() error
+	SetLogger(logger *slog.Logger)
+	SetClock(clock Clock)
+	SetTrustedKeys(keys ...ed25519.PublicKey)
+
+	ValidateWith(fn ValidateFunc)
+	Validate() error
+	Require(names ...string)
+	AddValidator(name string, fn func(v interface{}) error)
+	AddPolicy(fn PolicyFunc)
+	NewFreezeWindows(name string, windows []string, usage string, opts ...FlagOption) *[]string
+	Frozen() (window string, frozen bool)
+	OverrideFreeze(actor, reason string, d time.Duration)
+
+	Set(name string, value interface{}) error
+	RequireApproval(names ...string)
+	NeedsApproval(name string) bool
+	Propose(name string, value interface{}, requester string) (PendingChange, error)
+	PendingChanges() []PendingChange
+	Approve(id, approver string) error
+	Reject(id string) error
+	ReadOnly(enabled bool)
+	IsReadOnly() bool
+
+	Problems() []Problem
+	UnboundKeys() []UnboundKey
+	Health() HealthStatus
+
+	SetLeadership(l Leadership)
+	SetInstanceID(id string)
+	NewCanaryPercent(name string, percent int, usage string, opts ...FlagOption) *int
+	Canary() CanaryStatus
+	OnCanary(fn func(CanaryStatus))
+	ApplyReplicated(data map[string]interface{}) error
 }
 
 type Configurable struct {
 	flags map[string]interface{}
+	meta  map[string]*flagMeta
+	fs    *flag.FlagSet
+	args  []string
+	env   map[string]string
+	// envFile holds definitions read from env files; the process
+	// environment takes precedence over them.
+	envFile map[string]string
+	// envMu guards env and envFile.
+	envMu sync.RWMutex
+	ini   *ini.File
+
+	output    io.Writer
+	usageFunc UsageFunc
+
+	parseOptions ParseOptions
+
+	sources map[string]ValueSource
+	report  *Report
+	unbound map[string]UnboundKey
+
+	tenantMu     sync.Mutex
+	tenantLoader TenantLoader
+	tenants      map[string]*snapshot
+
+	changeFuncs    []ChangeFunc
+	keyChangeFuncs map[string][]KeyChangeFunc
+	hooks          []Hooks
+
+	// changeMu guards the change functions and debounced notifications.
+	changeMu       sync.Mutex
+	changeDebounce *debouncer
+	pendingChanges map[string]struct{}
+	flushMu        sync.Mutex
+
+	problemsMu sync.Mutex
+	problems   []Problem
+
+	healthMu    sync.Mutex
+	health      map[string]*sourceHealth
+	healthOrder []string
+
+	// mu serializes writers and flag registration, and is read-locked by
+	// readers of the flag storage, sources and read-only mode. Readers of View never take it: they load the
+	// newest generation, which is never modified once published.
+	mu         sync.RWMutex
+	generation atomic.Pointer[snapshot]
+
+	// variables maps "namespace.key" to the values set by SetVariables.
+	variables atomic.Pointer[map[string]string]
+
+	providers []*remoteSource
+	cacheDir  string
+	fileMode  fs.FileMode
+	log       *slog.Logger
+	clockSrc  Clock
+
+	trustedKeys []ed25519.PublicKey
+	readOnly    bool
+	approvals   approvals
+	closers     closers
+	staggered   staggered
+	canary      canary
+	instanceID  string
+	leadership  Leadership
+
+	envPrefix     string
+	envDelimiter  string
+	errorHandling *flag.ErrorHandling
+	// portable resolves relative paths against the executable's directory.
+	portable bool
+	// precedence ranks sources, highest first; nil is defaultPrecedence.
+	precedence []SourceKind
+
+	// aliases maps the short names given WithShort to flag names.
+	aliases map[string]string
+	// order lists flag names in the order they were registered.
+	order []string
+
+	validators []ValidateFunc
+	policies   []PolicyFunc
+	// freezeFlag names the flag registered with NewFreezeWindows.
+	freezeFlag     string
+	freezeOverride *freezeOverride
+	// canaryFlag names the flag registered with NewCanaryPercent.
+	canaryFlag string
+	// parsed is set once Parse succeeds; later loads are reloads, validated
+	// before they are applied.
+	parsed bool
 }
 
-func New() IConfigurable {
-	return &Configurable{flags: make(map[string]interface{})}
+// New returns a Configurable that registers flags on flag.CommandLine, as
+// adjusted by opts.
+func New(opts ...Option) IConfigurable {
+	c := &Configurable{
+		flags:   make(map[string]interface{}),
+		meta:    make(map[string]*flagMeta),
+		sources: make(map[string]ValueSource),
+		report:  &Report{},
+		fs:      flag.CommandLine,
+		env:     make(map[string]string),
+	}
+	c.applyOptions(opts)
+	return c
 }
 
-func (c *Configurable) NewInt(name string, value int, usage string) *int {
-	ptr := flag.Int(name, value, usage)
+func (c *Configurable) NewInt(name string, value int, usage string, opts ...FlagOption) *int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ptr := c.fs.Int(name, value, usage)
 	c.flags[name] = ptr
+	c.annotate(name, opts)
 	return ptr
 }
 
@@ -76,9 +301,12 @@ func (c *Configurable) Int64(name string) *int64 {
 	return val
 }
 
-func (c *Configurable) NewInt64(name string, value int64, usage string) *int64 {
-	var i = flag.Int64(name, value, usage)
+func (c *Configurable) NewInt64(name string, value int64, usage string, opts ...FlagOption) *int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var i = c.fs.Int64(name, value, usage)
 	c.flags[name] = i
+	c.annotate(name, opts)
 	return i
 }
 
@@ -88,9 +316,12 @@ func (c *Configurable) Float64(name string) *float64 {
 	return val
 }
 
-func (c *Configurable) NewFloat64(name string, value float64, usage string) *float64 {
-	var i = flag.Float64(name, value, usage)
+func (c *Configurable) NewFloat64(name string, value float64, usage string, opts ...FlagOption) *float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var i = c.fs.Float64(name, value, usage)
 	c.flags[name] = i
+	c.annotate(name, opts)
 	return i
 }
 
@@ -100,9 +331,12 @@ func (c *Configurable) Duration(name string) *time.Duration {
 	return val
 }
 
-func (c *Configurable) NewDuration(name string, value time.Duration, usage string) *time.Duration {
-	var i = flag.Duration(name, value, usage)
+func (c *Configurable) NewDuration(name string, value time.Duration, usage string, opts ...FlagOption) *time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var i = c.fs.Duration(name, value, usage)
 	c.flags[name] = i
+	c.annotate(name, opts)
 	return i
 }
 
@@ -112,9 +346,12 @@ func (c *Configurable) String(name string) *string {
 	return val
 }
 
-func (c *Configurable) NewString(name string, value string, usage string) *string {
-	var s = flag.String(name, value, usage)
+func (c *Configurable) NewString(name string, value string, usage string, opts ...FlagOption) *string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var s = c.fs.String(name, value, usage)
 	c.flags[name] = s
+	c.annotate(name, opts)
 	return s
 }
 
@@ -124,9 +361,12 @@ func (c *Configurable) Bool(name string) *bool {
 	return val
 }
 
-func (c *Configurable) NewBool(name string, value bool, usage string) *bool {
-	var b = flag.Bool(name, value, usage)
+func (c *Configurable) NewBool(name string, value bool, usage string, opts ...FlagOption) *bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var b = c.fs.Bool(name, value, usage)
 	c.flags[name] = b
+	c.annotate(name, opts)
 	return b
 }
 
@@ -150,10 +390,13 @@ func (l *ListFlag) Set(value string) error {
 	return nil
 }
 
-func (c *Configurable) NewList(name string, value []string, usage string) *[]string {
+func (c *Configurable) NewList(name string, value []string, usage string, opts ...FlagOption) *[]string {
 	l := &ListFlag{values: &value}
-	flag.Var(l, name, usage)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.fs.Var(l, name, usage)
 	c.flags[name] = l
+	c.annotate(name, opts)
 	return l.values
 }
 
@@ -184,6 +427,9 @@ func (m *MapFlag) Set(value string) error {
 	if m.values == nil {
 		m.values = &map[string]string{}
 	}
+	if *m.values == nil {
+		*m.values = map[string]string{}
+	}
 	pairs := strings.Split(value, ",")
 	for _, pair := range pairs {
 		kv := strings.SplitN(pair, "=", 2)
@@ -195,10 +441,13 @@ func (m *MapFlag) Set(value string) error {
 	return nil
 }
 
-func (c *Configurable) NewMap(name string, value map[string]string, usage string) *map[string]string {
+func (c *Configurable) NewMap(name string, value map[string]string, usage string, opts ...FlagOption) *map[string]string {
 	m := &MapFlag{values: &value}
-	flag.Var(m, name, usage)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.fs.Var(m, name, usage)
 	c.flags[name] = m
+	c.annotate(name, opts)
 	return m.values
 }
 
@@ -210,74 +459,506 @@ func (c *Configurable) Map(name string) *map[string]string {
 	return nil
 }
 
+// SetArgs replaces the argument vector used by Parse. By default Parse reads
+// os.Args[1:], which is meaningless when the package is embedded in a server
+// or compiled for wasm.
+func (c *Configurable) SetArgs(args []string) {
+	c.args = args
+}
+
+// FlagSet returns the FlagSet the flags are registered on, for code that
+// expects one. Values changed through it directly bypass the checks, sources
+// and change notifications of ParseArgs, so prefer passing it arguments
+// through SetArgs or ParseArgs.
+func (c *Configurable) FlagSet() *flag.FlagSet {
+	return c.fs
+}
+
+// Parse loads filename, then the command-line arguments, the providers and
+// the environment, each overriding the ones before. Flags registered
+// WithEarly, such as --config, are taken from the arguments first, so they
+// can decide what is loaded.
 func (c *Configurable) Parse(filename string) error {
-	flag.Parse()
-	if filename != "" {
-		return c.LoadFile(filename)
+	args := c.args
+	if args == nil {
+		args = os.Args[1:]
+	}
+	if c.wantsHelp(args) {
+		return c.ParseArgs(args)
+	}
+	args, err := c.parseEarly(args)
+	if err != nil {
+		return err
+	}
+	filename, format := c.configFile(filename)
+	for _, kind := range c.parseOrder() {
+		switch kind {
+		case SourceFile:
+			if filename != "" {
+				err = c.loadFileAs(filename, format)
+			}
+		case SourceFlag:
+			err = c.ParseArgs(args)
+		case SourceRemote:
+			err = c.LoadProviders(context.Background())
+		case SourceEnv:
+			c.applyEnv()
+		}
+		if err != nil {
+			return err
+		}
 	}
+	if err := c.checkRequired(); err != nil {
+		return err
+	}
+	c.warnDeprecated()
+	if err := c.Validate(); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	c.parsed = true
+	c.mu.Unlock()
+	c.saveLastKnownGood()
 	return nil
 }
 
+// ParseArgs parses args (without the program name) into the registered flags.
+// Besides -h and -help, it recognizes -help-full, which prints UsageFull.
+func (c *Configurable) ParseArgs(args []string) error {
+	if c.wantsFullHelp(args) {
+		fmt.Fprint(c.out(), c.UsageFull())
+		return c.handleError(flag.ErrHelp)
+	}
+	args, err := c.normalizeArgs(args)
+	if err != nil {
+		return c.fail(err)
+	}
+	if err := c.parseNormalized(args); err != nil {
+		return err
+	}
+	return c.loadEnvFiles()
+}
+
+// parseNormalized parses args already put in order by normalizeArgs.
+func (c *Configurable) parseNormalized(args []string) error {
+	var limitErr error
+	err := c.update(func() error {
+		// Values a higher source set are put back once the FlagSet has
+		// parsed over them.
+		held := make(map[string]interface{})
+		for name, ptr := range c.flags {
+			if c.outranked(name, SourceFlag) {
+				held[name] = valueOf(ptr)
+			}
+		}
+		if err := c.fs.Parse(args); err != nil {
+			return err
+		}
+		c.fs.Visit(func(f *flag.Flag) {
+			name := f.Name
+			if alias, ok := c.aliases[name]; ok {
+				name = alias
+			}
+			if v, ok := held[name]; ok {
+				assign(c.flags[name], v)
+				return
+			}
+			if err := c.checkEntries(f.Name, valueOf(c.flags[f.Name])); err != nil && limitErr == nil {
+				limitErr = err
+			}
+			if _, ok := c.flags[f.Name]; ok {
+				c.sources[f.Name] = ValueSource{Kind: SourceFlag}
+				r := c.report.source(SourceFlag, "")
+				r.Keys = appendUnique(r.Keys, f.Name)
+			}
+		})
+		return limitErr
+	})
+	if limitErr != nil {
+		return c.fail(limitErr)
+	}
+	if err != nil {
+		return err
+	}
+	c.invalidateTenants()
+	return nil
+}
+
+// handleError applies the FlagSet's error handling to an error raised by the
+// package itself rather than by flag.FlagSet.Parse.
+func (c *Configurable) handleError(err error) error {
+	switch c.fs.ErrorHandling() {
+	case flag.ExitOnError:
+		if err == flag.ErrHelp {
+			os.Exit(0)
+		}
+		os.Exit(2)
+	case flag.PanicOnError:
+		panic(err)
+	}
+	return err
+}
+
+// wantsFullHelp reports whether args ask for extended help, unless the
+// program registered its own help-full flag.
+func (c *Configurable) wantsFullHelp(args []string) bool {
+	if c.fs.Lookup(helpFullFlag) != nil {
+		return false
+	}
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if len(arg) < 2 || arg[0] != '-' || arg == "--" {
+			return false
+		}
+		name, _, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if name == helpFullFlag {
+			return true
+		}
+		// Skip the value of a flag, which may read like the help flag.
+		if f := c.fs.Lookup(name); f != nil && !hasValue && !isBoolFlag(f) {
+			i++
+		}
+	}
+	return false
+}
+
 func (c *Configurable) LoadFile(filename string) error {
-	data, err := os.ReadFile(filename)
+	return c.loadFileAs(filename, filepath.Ext(filename))
+}
+
+// loadFileAs is LoadFile for a file in the given format.
+func (c *Configurable) loadFileAs(filename, format string) error {
+	filename = c.resolvePath(filename)
+	source := ValueSource{Kind: SourceFile, Name: filename}.String()
+	err := c.loading(source, func() error {
+		return c.loadFile(filename, format)
+	})
+	c.recordLoad(source, err, false)
+	return err
+}
+
+func (c *Configurable) loadFile(filename, format string) error {
+	var data []byte
+	err := c.parseOptions.FileRetry.do(context.Background(), c.clock(), transientFileError, c.retrying(ValueSource{Kind: SourceFile, Name: filename}.String()), func() (err error) {
+		data, err = readFile(filename, c.parseOptions.MaxFileSize)
+		return err
+	})
 	if err != nil {
 		return err
 	}
-	ext := strings.ToLower(filepath.Ext(filename))
-	switch ext {
-	case ".json":
-		return c.loadJSON(data)
-	case ".yaml", ".yml":
-		return c.loadYAML(data)
-	case ".ini":
-		return c.loadINI(data)
+	if data, err = c.verify(filename, data); err != nil {
+		return err
+	}
+	return c.load(filename, format, data)
+}
+
+// LoadData applies an in-memory document. format is a file extension with or
+// without the leading dot ("json", ".yaml", "ini").
+func (c *Configurable) LoadData(format string, data []byte) error {
+	if max := c.parseOptions.MaxFileSize; max > 0 && int64(len(data)) > max {
+		return fmt.Errorf("document is larger than %d bytes: %w", max, ErrLimitExceeded)
+	}
+	return c.loading(SourceFile.String(), func() error {
+		return c.load("", format, data)
+	})
+}
+
+// load decodes data and applies it, attributing the values to source (a file
+// path, or empty for in-memory documents).
+func (c *Configurable) load(source, format string, data []byte) error {
+	if strings.TrimPrefix(strings.ToLower(format), ".") == envFormat {
+		return c.loadEnvDefs(source, data)
+	}
+	c.mu.RLock()
+	values, cfg, err := decode(format, data, c.flags)
+	c.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+	if cfg != nil {
+		c.mu.Lock()
+		c.ini = cfg
+		c.mu.Unlock()
+	}
+	return c.setValuesFromMap(SourceFile, source, values)
+}
+
+// decode parses a document in the given format. For INI it also returns the
+// parsed file, which WriteFile edits in place, and reads the keys naming flags
+// as the flags' types.
+func decode(format string, data []byte, flags map[string]interface{}) (map[string]interface{}, *ini.File, error) {
+	switch strings.TrimPrefix(strings.ToLower(format), ".") {
+	case "json":
+		values, err := decodeJSON(data)
+		return values, nil, err
+	case "yaml", "yml":
+		values, err := decodeYAML(data)
+		return values, nil, err
+	case "toml":
+		values, err := decodeTOML(data)
+		return values, nil, err
+	case "ini":
+		return decodeINI(data, flags)
 	default:
-		return errors.New("unsupported file extension")
+		return nil, nil, errors.New("unsupported file extension")
 	}
 }
 
-func (c *Configurable) loadJSON(data []byte) error {
+func decodeJSON(data []byte) (map[string]interface{}, error) {
 	var jsonData map[string]interface{}
-	if err := json.Unmarshal(data, &jsonData); err != nil {
+	if err := unmarshalJSON(data, &jsonData); err != nil {
+		return nil, err
+	}
+	return jsonData, nil
+}
+
+// unmarshalJSON is json.Unmarshal keeping numbers as json.Number, so integers
+// above 2^53 are not rounded through float64 before they reach their flag.
+func unmarshalJSON(data []byte, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(v); err != nil {
 		return err
 	}
-	return c.setValuesFromMap(jsonData)
+	if _, err := dec.Token(); err != io.EOF {
+		return errors.New("invalid character after top-level value")
+	}
+	return nil
 }
 
-func (c *Configurable) loadYAML(data []byte) error {
+func decodeYAML(data []byte) (map[string]interface{}, error) {
 	var yamlData map[string]interface{}
 	if err := yaml.Unmarshal(data, &yamlData); err != nil {
-		return err
+		return nil, err
 	}
-	return c.setValuesFromMap(yamlData)
+	return yamlData, nil
 }
 
-func (c *Configurable) loadINI(data []byte) error {
-	cfg, err := ini.Load(data)
+// decodeTOML parses a TOML document. Local dates and times, which have no Go
+// time.Time equivalent, are kept in their TOML text form.
+func decodeTOML(data []byte) (map[string]interface{}, error) {
+	var tomlData map[string]interface{}
+	if err := toml.Unmarshal(data, &tomlData); err != nil {
+		return nil, err
+	}
+	return localTimesToText(tomlData).(map[string]interface{}), nil
+}
+
+// localTimesToText replaces the TOML local date and time values in v with
+// their text.
+func localTimesToText(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for k, item := range val {
+			val[k] = localTimesToText(item)
+		}
+	case []interface{}:
+		for i, item := range val {
+			val[i] = localTimesToText(item)
+		}
+	case toml.LocalDate, toml.LocalTime, toml.LocalDateTime:
+		return fmt.Sprint(val)
+	}
+	return v
+}
+
+// decodeINI reads the keys of the default section, and those of each section
+// that prefixes the name of one of flags: "port" in [server] is server.port.
+// Other sections belong to other programs sharing the file and are skipped,
+// so they are not unknown keys. Keys naming one of flags are read with the
+// accessor for the flag's type, and a key repeated with a "[]" suffix
+// ("tags[] = a") collects its values into a list.
+func decodeINI(data []byte, flags map[string]interface{}) (map[string]interface{}, *ini.File, error) {
+	cfg, err := ini.LoadSources(ini.LoadOptions{AllowShadows: true}, data)
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
 	iniData := make(map[string]interface{})
-	for key := range c.flags {
-		if val := cfg.Section("").Key(key).String(); val != "" {
-			iniData[key] = val
+	for _, section := range cfg.Sections() {
+		prefix := ""
+		if section.Name() != ini.DefaultSection {
+			prefix = section.Name() + "."
+			if !hasPrefix(flags, prefix) {
+				continue
+			}
+		}
+		for _, key := range section.Keys() {
+			if name, ok := strings.CutSuffix(key.Name(), "[]"); ok {
+				var items []interface{}
+				for _, item := range key.ValueWithShadows() {
+					items = append(items, item)
+				}
+				iniData[prefix+name] = items
+				continue
+			}
+			if key.String() == "" {
+				continue
+			}
+			value, err := iniValue(key, flags[prefix+key.Name()])
+			if err != nil {
+				return nil, nil, err
+			}
+			iniData[prefix+key.Name()] = value
 		}
 	}
-	return c.setValuesFromMap(iniData)
+	return iniData, cfg, nil
 }
 
-func (c *Configurable) setValuesFromMap(data map[string]interface{}) error {
-	for key, value := range data {
-		if flagVal, exists := c.flags[key]; exists {
-			if err := c.setValue(flagVal, value); err != nil {
-				return fmt.Errorf("error setting key %s: %w", key, err)
+// hasPrefix reports whether the name of one of flags starts with prefix.
+func hasPrefix(flags map[string]interface{}, prefix string) bool {
+	for name := range flags {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// iniKey returns the section of cfg holding the flag name, and the key it
+// has there: the longest section that prefixes name, or the default section.
+func iniKey(cfg *ini.File, name string) (*ini.Section, string) {
+	section, key := cfg.Section(ini.DefaultSection), name
+	for _, s := range cfg.Sections() {
+		rest, ok := strings.CutPrefix(name, s.Name()+".")
+		if ok && s.Name() != ini.DefaultSection && len(rest) < len(key) {
+			section, key = s, rest
+		}
+	}
+	return section, key
+}
+
+// iniValue reads key as the type of the flag storage ptr, or as a string.
+func iniValue(key *ini.Key, ptr interface{}) (value interface{}, err error) {
+	switch ptr.(type) {
+	case *int:
+		value, err = key.Int()
+	case *int64:
+		value, err = key.Int64()
+	case *float64:
+		value, err = key.Float64()
+	case *bool:
+		// INI has its own spellings, such as on and yes, whatever
+		// LenientBools says.
+		value, err = key.Bool()
+	case *time.Duration:
+		value, err = key.Duration()
+	case *ListFlag, typedList:
+		value = key.Strings(",")
+	default:
+		value = key.String()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ini key %s: %w", key.Name(), err)
+	}
+	return value, nil
+}
+
+// setValuesFromMap applies a decoded document from source, which is of the
+// given kind. Nothing is applied unless every value is accepted.
+func (c *Configurable) setValuesFromMap(kind SourceKind, source string, data map[string]interface{}) error {
+	known := make(map[string]interface{})
+	var unknown []string
+	c.mu.RLock()
+	c.flatten("", data, known, &unknown)
+	c.mu.RUnlock()
+	if c.parseOptions.Strict && len(unknown) > 0 {
+		sort.Strings(unknown)
+		return &UnknownKeysError{Source: ValueSource{Kind: kind, Name: source}.String(), Keys: unknown}
+	}
+	keys := make([]string, 0, len(known))
+	for name := range known {
+		keys = append(keys, name)
+	}
+	sort.Strings(keys)
+	var applied Diff
+	err := c.update(func() error {
+		keys = c.holdForLeader(kind, source, keys)
+		staged := c.values()
+		// Values a higher source holds are checked but not applied.
+		var outranked []string
+		for _, name := range keys {
+			raw, err := c.withUnit(name, known[name])
+			if err != nil {
+				return fmt.Errorf("error setting key %s: %w", name, err)
+			}
+			known[name] = c.withPath(name, raw)
+			if err := c.checkRaw(name, known[name]); err != nil {
+				return err
 			}
+			value, err := c.convert(name, known[name])
+			if err != nil {
+				return fmt.Errorf("error setting key %s: %w", name, err)
+			}
+			if c.outranked(name, kind) {
+				outranked = append(outranked, name)
+				continue
+			}
+			staged[name] = mergeValue(staged[name], value)
+			if err := c.checkEntries(name, staged[name]); err != nil {
+				return err
+			}
+		}
+		keys = slices.DeleteFunc(keys, func(name string) bool {
+			return slices.Contains(outranked, name)
+		})
+		if c.parsed {
+			if err := c.admit(ValueSource{Kind: kind, Name: source}, staged); err != nil {
+				return err
+			}
+		}
+		var err error
+		if applied, err = c.preApply(ValueSource{Kind: kind, Name: source}, staged); err != nil {
+			return err
 		}
+		// Every value has been checked, so nothing below can leave the
+		// flags half updated.
+		for _, name := range keys {
+			if err := c.setValue(c.flags[name], known[name]); err != nil {
+				return fmt.Errorf("error setting key %s: %w", name, err)
+			}
+			c.sources[name] = ValueSource{Kind: kind, Name: source}
+		}
+		r := c.report.source(kind, source)
+		r.Keys = appendUnique(r.Keys, keys...)
+		r.Unknown = appendUnique(r.Unknown, unknown...)
+		if c.parseOptions.RetainUnknown {
+			c.retainUnbound(ValueSource{Kind: kind, Name: source}.String(), unknown)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	sort.Strings(unknown)
+	for _, key := range unknown {
+		c.problem(UnknownKey, key, ValueSource{Kind: kind, Name: source}.String(), "matches no flag")
 	}
+	c.invalidateTenants()
+	c.postApply(applied)
 	return nil
 }
 
+// flatten maps data onto registered flag names. Mappings that do not
+// correspond to a flag are flattened into dotted names, so "server: {port: 80}"
+// sets the flag "server.port". Values for registered flags are stored in
+// known; names matching no flag are appended to unknown.
+func (c *Configurable) flatten(prefix string, data map[string]interface{}, known map[string]interface{}, unknown *[]string) {
+	for key, value := range data {
+		name := prefix + key
+		if _, exists := c.flags[name]; exists {
+			known[name] = value
+			continue
+		}
+		if nested, ok := value.(map[string]interface{}); ok {
+			c.flatten(name+".", nested, known, unknown)
+			continue
+		}
+		*unknown = append(*unknown, name)
+	}
+}
+
 func (c *Configurable) setValue(flagVal interface{}, value interface{}) error {
+	value = c.expand(value)
 	switch ptr := flagVal.(type) {
 	case *int:
 		intVal, err := toInt(value)
@@ -304,17 +985,13 @@ func (c *Configurable) setValue(flagVal interface{}, value interface{}) error {
 		}
 		*ptr = strVal
 	case *bool:
-		boolVal, err := toBool(value)
+		boolVal, err := toBool(value, c.parseOptions.LenientBools)
 		if err != nil {
 			return err
 		}
 		*ptr = boolVal
 	case *time.Duration:
-		strVal, err := toString(value)
-		if err != nil {
-			return err
-		}
-		duration, err := time.ParseDuration(strVal)
+		duration, err := toDuration(value)
 		if err != nil {
 			return err
 		}
@@ -325,14 +1002,27 @@ func (c *Configurable) setValue(flagVal interface{}, value interface{}) error {
 			return err
 		}
 		*ptr.values = append(*ptr.values, listVal...)
+	case typedList:
+		return ptr.add(value, c.parseOptions.LenientBools)
 	case *MapFlag:
 		mapVal, err := toStringMap(value)
 		if err != nil {
 			return err
 		}
+		if *ptr.values == nil {
+			*ptr.values = make(map[string]string, len(mapVal))
+		}
 		for k, v := range mapVal {
 			(*ptr.values)[k] = v
 		}
+	case *TextFlag:
+		strVal, err := toString(value)
+		if err != nil {
+			return err
+		}
+		return ptr.Set(strVal)
+	case *JSONFlag:
+		return ptr.set(value)
 	default:
 		return fmt.Errorf("unsupported flag type for key %v", ptr)
 	}
@@ -341,8 +1031,18 @@ func (c *Configurable) setValue(flagVal interface{}, value interface{}) error {
 
 func toInt(value interface{}) (int, error) {
 	switch v := value.(type) {
+	case int:
+		return v, nil
+	case int64:
+		return int(v), nil
 	case float64:
 		return int(v), nil
+	case json.Number:
+		if n, err := strconv.Atoi(v.String()); err == nil {
+			return n, nil
+		}
+		f, err := v.Float64()
+		return int(f), err
 	case string:
 		return strconv.Atoi(v)
 	default:
@@ -352,8 +1052,18 @@ func toInt(value interface{}) (int, error) {
 
 func toInt64(value interface{}) (int64, error) {
 	switch v := value.(type) {
+	case int:
+		return int64(v), nil
+	case int64:
+		return v, nil
 	case float64:
 		return int64(v), nil
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n, nil
+		}
+		f, err := v.Float64()
+		return int64(f), err
 	case string:
 		return strconv.ParseInt(v, 10, 64)
 	default:
@@ -363,8 +1073,14 @@ func toInt64(value interface{}) (int64, error) {
 
 func toFloat64(value interface{}) (float64, error) {
 	switch v := value.(type) {
+	case int:
+		return float64(v), nil
+	case int64:
+		return float64(v), nil
 	case float64:
 		return v, nil
+	case json.Number:
+		return v.Float64()
 	case string:
 		return strconv.ParseFloat(v, 64)
 	default:
@@ -376,8 +1092,14 @@ func toString(value interface{}) (string, error) {
 	switch v := value.(type) {
 	case string:
 		return v, nil
+	case int:
+		return strconv.Itoa(v), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
 	case float64:
 		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case json.Number:
+		return v.String(), nil
 	case bool:
 		return strconv.FormatBool(v), nil
 	default:
@@ -385,19 +1107,46 @@ func toString(value interface{}) (string, error) {
 	}
 }
 
-func toBool(value interface{}) (bool, error) {
+// toBool converts value to a bool. lenient also accepts yes/no, on/off, y/n
+// and the numbers 1 and 0.
+func toBool(value interface{}, lenient bool) (bool, error) {
 	switch v := value.(type) {
 	case bool:
 		return v, nil
 	case string:
+		if lenient {
+			switch strings.ToLower(strings.TrimSpace(v)) {
+			case "yes", "y", "on":
+				return true, nil
+			case "no", "n", "off":
+				return false, nil
+			}
+		}
 		return strconv.ParseBool(v)
-	default:
-		return false, fmt.Errorf("cannot convert %v to bool", value)
 	}
+	if lenient {
+		if n, err := toFloat64(value); err == nil && (n == 0 || n == 1) {
+			return n == 1, nil
+		}
+	}
+	return false, fmt.Errorf("cannot convert %v to bool", value)
+}
+
+func toDuration(value interface{}) (time.Duration, error) {
+	if d, ok := value.(time.Duration); ok {
+		return d, nil
+	}
+	strVal, err := toString(value)
+	if err != nil {
+		return 0, err
+	}
+	return time.ParseDuration(strVal)
 }
 
 func toStringSlice(value interface{}) ([]string, error) {
 	switch v := value.(type) {
+	case []string:
+		return append([]string{}, v...), nil
 	case []interface{}:
 		var result []string
 		for _, item := range v {
@@ -420,6 +1169,12 @@ func toStringSlice(value interface{}) ([]string, error) {
 
 func toStringMap(value interface{}) (map[string]string, error) {
 	switch v := value.(type) {
+	case map[string]string:
+		result := make(map[string]string, len(v))
+		for key, val := range v {
+			result[key] = val
+		}
+		return result, nil
 	case map[string]interface{}:
 		result := make(map[string]string)
 		for key, val := range v {
@@ -449,20 +1204,163 @@ func toStringMap(value interface{}) (map[string]string, error) {
 	}
 }
 
-func (c *Configurable) checkAndSetFromEnv(name string) {
-	if val, exists := os.LookupEnv(name); exists {
-		if flagVal, exists := c.flags[name]; exists {
-			c.setValue(flagVal, val)
+// SetEnv defines an environment value visible only to this Configurable. It
+// takes precedence over the process environment and is the only environment
+// available in wasm and TinyGo builds.
+func (c *Configurable) SetEnv(key, value string) {
+	c.envMu.Lock()
+	defer c.envMu.Unlock()
+	c.env[key] = value
+}
+
+// SetEnvPrefix makes each flag read the environment variable named by the
+// prefix and the flag name in upper case, with every character other than a
+// letter or digit replaced by '_': with prefix "MYAPP", db-host reads
+// MYAPP_DB_HOST. Without a prefix the variable has the flag's own name.
+// Flags that are already registered are renamed too, and it panics if two
+// of them would then read the same variable.
+func (c *Configurable) SetEnvPrefix(prefix string) {
+	c.envPrefix = prefix
+	c.checkEnvCollisions()
+}
+
+// SetEnvDelimiter sets the string that separates nested keys in environment
+// variable names when an env prefix is set: with "__", server.port reads
+// MYAPP_SERVER__PORT, so it cannot be confused with a server-port flag. The
+// default is "_". Registering a flag that reads the same variable as an
+// earlier flag panics.
+func (c *Configurable) SetEnvDelimiter(delimiter string) {
+	c.envDelimiter = delimiter
+	c.checkEnvCollisions()
+}
+
+// envName returns the environment variable read for the flag name. With a
+// prefix, the dots separating nested keys become the env delimiter, so
+// server.port reads MYAPP_SERVER_PORT, or MYAPP_SERVER__PORT with "__".
+func (c *Configurable) envName(name string) string {
+	if c.envPrefix == "" {
+		return name
+	}
+	delimiter := c.envDelimiter
+	if delimiter == "" {
+		delimiter = "_"
+	}
+	parts := strings.Split(name, ".")
+	for i, part := range parts {
+		parts[i] = strings.Map(func(r rune) rune {
+			switch {
+			case r >= 'a' && r <= 'z':
+				return r - 'a' + 'A'
+			case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+				return r
+			}
+			return '_'
+		}, part)
+	}
+	return c.envPrefix + "_" + strings.Join(parts, delimiter)
+}
+
+// envVars returns the environment variables the flag name reads.
+func (c *Configurable) envVars(name string) []string {
+	if m, exists := c.meta[name]; exists && len(m.env) > 0 {
+		return m.env
+	}
+	return []string{c.envName(name)}
+}
+
+// checkEnvCollision panics if name reads an environment variable that an
+// earlier flag already reads, since one of them would silently shadow the
+// other.
+func (c *Configurable) checkEnvCollision(name string) {
+	if err := c.envCollision(name); err != nil {
+		panic("configurable: " + err.Error())
+	}
+}
+
+// envCollision reports an environment variable that name and an earlier
+// flag both read.
+func (c *Configurable) envCollision(name string) error {
+	for _, key := range c.envVars(name) {
+		for _, other := range c.order {
+			if other != name && slices.Contains(c.envVars(other), key) {
+				return fmt.Errorf("flag %s reads environment variable %s, already read by flag %s", name, key, other)
+			}
 		}
 	}
+	return nil
 }
 
-func (c *Configurable) Usage() string {
-	var sb strings.Builder
-	fmt.Fprintf(&sb, "Usage of %s:\n", os.Args[0])
-	flag.VisitAll(func(f *flag.Flag) {
-		fmt.Fprintf(&sb, "  -%s: %s (default: %s)\n", f.Name, f.Usage, f.DefValue)
-	})
-	return sb.String()
+// checkEnvCollisions runs checkEnvCollision for every registered flag.
+func (c *Configurable) checkEnvCollisions() {
+	for _, name := range c.order {
+		c.checkEnvCollision(name)
+	}
+}
+
+// lookupFlagEnv returns the first environment variable set among those the
+// flag name reads, and its value.
+func (c *Configurable) lookupFlagEnv(name string) (key, value string, ok bool) {
+	for _, key := range c.envVars(name) {
+		if value, ok := c.lookupEnv(key); ok {
+			return key, c.parseOptions.EnvTrim.apply(value), true
+		}
+	}
+	return "", "", false
+}
+
+func (c *Configurable) lookupEnv(key string) (string, bool) {
+	c.envMu.RLock()
+	defer c.envMu.RUnlock()
+	if val, ok := c.env[key]; ok {
+		return val, true
+	}
+	if val, ok := lookupEnv(key); ok {
+		return val, true
+	}
+	val, ok := c.envFile[key]
+	return val, ok
+}
+
+// checkAndSetFromEnv applies the environment variable for name, if one is
+// set, publishing a new generation when that changes the value.
+func (c *Configurable) checkAndSetFromEnv(name string) {
+	if _, _, exists := c.envValue(name); !exists {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	before := valueOf(c.flags[name])
+	c.setFromEnv(name)
+	if !reflect.DeepEqual(before, valueOf(c.flags[name])) {
+		c.publish()
+	}
 }
 
+// setFromEnv applies the environment variable for name. Values the flag
+// would reject are ignored. The caller holds c.mu.
+func (c *Configurable) setFromEnv(name string) {
+	if c.outranked(name, SourceEnv) {
+		return
+	}
+	if key, val, exists := c.envValue(name); exists {
+		source := ValueSource{Kind: SourceEnv, Name: key}
+		if err := c.checkRaw(name, val); err != nil {
+			c.problem(InvalidEnv, name, source.String(), err.Error())
+			return
+		}
+		value, err := c.convert(name, val)
+		if err != nil {
+			c.problem(InvalidEnv, name, source.String(), err.Error())
+			return
+		}
+		// The variable holds the whole value, so lists and maps are
+		// replaced rather than merged and repeated lookups are idempotent.
+		assign(c.flags[name], value)
+		c.sources[name] = source
+		r := c.report.source(SourceEnv, "")
+		if !slices.Contains(r.Keys, name) {
+			r.Keys = append(r.Keys, name)
+			r.Vars = append(r.Vars, key)
+		}
+	}
+}
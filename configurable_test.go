@@ -1,7 +1,9 @@
 package configurable
 
 import (
+	"flag"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -79,3 +81,171 @@ func TestConfigurable(t *testing.T) {
 		assert.Contains(t, usage, "test_int64")
 	})
 }
+
+// newTestConfigurable returns a Configurable bound to its own FlagSet so tests
+// can register the same names without colliding on flag.CommandLine.
+func newTestConfigurable(t *testing.T) *Configurable {
+	c := New().(*Configurable)
+	c.fs = flag.NewFlagSet(t.Name(), flag.ContinueOnError)
+	return c
+}
+
+func TestParseArgs(t *testing.T) {
+	os.Clearenv()
+
+	t.Run("test ParseArgs", func(t *testing.T) {
+		conf := newTestConfigurable(t)
+		port := conf.NewInt("port", 80, "port")
+		assert.NoError(t, conf.ParseArgs([]string{"-port", "8080"}))
+		assert.Equal(t, 8080, *port)
+	})
+
+	t.Run("test SetArgs", func(t *testing.T) {
+		conf := newTestConfigurable(t)
+		name := conf.NewString("name", "", "name")
+		conf.SetArgs([]string{"-name=embedded"})
+		assert.NoError(t, conf.Parse(""))
+		assert.Equal(t, "embedded", *name)
+	})
+}
+
+func TestInMemory(t *testing.T) {
+	os.Clearenv()
+
+	t.Run("test LoadData", func(t *testing.T) {
+		conf := newTestConfigurable(t)
+		name := conf.NewString("name", "", "name")
+		assert.NoError(t, conf.LoadData("json", []byte(`{"name": "browser"}`)))
+		assert.Equal(t, "browser", *name)
+		assert.Error(t, conf.LoadData(".hcl", []byte(`name = "x"`)))
+	})
+
+	t.Run("test large JSON integers", func(t *testing.T) {
+		conf := newTestConfigurable(t)
+		id := conf.NewInt64("account-id", 0, "account ID")
+		shard := conf.NewInt("shard", 0, "shard")
+		name := conf.NewString("name", "", "name")
+		tags := conf.NewList("tags", nil, "tags")
+		src := `{"account-id": 9007199254740993, "shard": 3.0, "name": 1234567890123456789, "tags": [9007199254740993]}`
+		assert.NoError(t, conf.LoadData("json", []byte(src)))
+		assert.Equal(t, int64(9007199254740993), *id)
+		assert.Equal(t, 3, *shard)
+		assert.Equal(t, "1234567890123456789", *name)
+		assert.Equal(t, []string{"9007199254740993"}, *tags)
+
+		data, err := conf.Dump("json")
+		assert.NoError(t, err)
+		reload := newTestConfigurable(t)
+		reloaded := reload.NewInt64("account-id", 0, "account ID")
+		assert.NoError(t, reload.LoadData("json", data))
+		assert.Equal(t, int64(9007199254740993), *reloaded)
+
+		assert.Error(t, conf.LoadData("json", []byte(`{"shard": 1} {}`)))
+	})
+
+	t.Run("test SetEnv", func(t *testing.T) {
+		conf := newTestConfigurable(t)
+		conf.NewBool("debug", false, "debug")
+		conf.SetEnv("debug", "true")
+		assert.True(t, *conf.Bool("debug"))
+	})
+}
+
+func TestLoadINI(t *testing.T) {
+	os.Clearenv()
+
+	t.Run("test typed keys", func(t *testing.T) {
+		conf := newTestConfigurable(t)
+		port := conf.NewInt("port", 0, "port")
+		ratio := conf.NewFloat64("ratio", 0, "ratio")
+		debug := conf.NewBool("debug", false, "debug")
+		timeout := conf.NewDuration("timeout", 0, "timeout")
+		tags := conf.NewList("tags", nil, "tags")
+		src := "port = 8080\nratio = 0.5\ndebug = on\ntimeout = 1m30s\ntags = a, b\n"
+		assert.NoError(t, conf.LoadData("ini", []byte(src)))
+		assert.Equal(t, 8080, *port)
+		assert.Equal(t, 0.5, *ratio)
+		assert.True(t, *debug)
+		assert.Equal(t, 90*time.Second, *timeout)
+		assert.Equal(t, []string{"a", "b"}, *tags)
+	})
+
+	t.Run("test invalid typed key", func(t *testing.T) {
+		conf := newTestConfigurable(t)
+		port := conf.NewInt("port", 80, "port")
+		err := conf.LoadData("ini", []byte("port = eighty\n"))
+		assert.ErrorContains(t, err, "ini key port: ")
+		assert.Equal(t, 80, *port)
+	})
+
+	t.Run("test array keys", func(t *testing.T) {
+		conf := newTestConfigurable(t)
+		hosts := conf.NewList("hosts", nil, "hosts")
+		assert.NoError(t, conf.LoadData("ini", []byte("hosts[] = a.example\nhosts[] = b.example\n")))
+		assert.Equal(t, []string{"a.example", "b.example"}, *hosts)
+
+		assert.NoError(t, conf.Set("hosts", []string{"c.example", "d.example"}))
+		data, err := conf.Dump("ini")
+		assert.NoError(t, err)
+		assert.Regexp(t, `hosts\[\]\s*= c.example\nhosts\[\]\s*= d.example\n`, string(data))
+	})
+
+	t.Run("test sections", func(t *testing.T) {
+		conf := New(WithFlagSet(flag.NewFlagSet(t.Name(), flag.ContinueOnError)), WithStrict())
+		name := conf.NewString("name", "", "name")
+		port := conf.NewInt("server.port", 0, "port")
+		src := "name = api\n\n[server]\nport = 8080\n\n[other.program]\nretries = 3\n"
+		assert.NoError(t, conf.LoadData("ini", []byte(src)), "sections of no flag are not unknown keys")
+		assert.Equal(t, "api", *name)
+		assert.Equal(t, 8080, *port)
+
+		assert.NoError(t, conf.Set("server.port", 9090))
+		data, err := conf.Dump("ini")
+		assert.NoError(t, err)
+		assert.Regexp(t, `\[server\]\nport\s*= 9090\n`, string(data))
+		assert.NotContains(t, string(data), "server.port")
+
+		var unknown *UnknownKeysError
+		assert.ErrorAs(t, conf.LoadData("ini", []byte("name = api\nretries = 3\n")), &unknown, "keys of the default section are top-level")
+		assert.Equal(t, []string{"retries"}, unknown.Keys)
+	})
+}
+
+func TestLoadTOML(t *testing.T) {
+	os.Clearenv()
+
+	conf := newTestConfigurable(t)
+	name := conf.NewString("name", "", "name")
+	port := conf.NewInt("server.port", 0, "port")
+	big := conf.NewInt64("server.id", 0, "id")
+	ratio := conf.NewFloat64("ratio", 0, "ratio")
+	debug := conf.NewBool("debug", false, "debug")
+	timeout := conf.NewDuration("timeout", 0, "timeout")
+	tags := conf.NewList("tags", nil, "tags")
+	day := conf.NewString("day", "", "day")
+	src := `
+name = "api"
+ratio = 0.5
+debug = true
+timeout = "1m30s"
+tags = ["a", "b"]
+day = 2024-05-01
+
+[server]
+port = 8080
+id = 9007199254740993
+`
+	file := filepath.Join(t.TempDir(), "config.toml")
+	assert.NoError(t, os.WriteFile(file, []byte(src), 0644))
+	assert.NoError(t, conf.LoadFile(file))
+	assert.Equal(t, "api", *name)
+	assert.Equal(t, 8080, *port)
+	assert.Equal(t, int64(9007199254740993), *big)
+	assert.Equal(t, 0.5, *ratio)
+	assert.True(t, *debug)
+	assert.Equal(t, 90*time.Second, *timeout)
+	assert.Equal(t, []string{"a", "b"}, *tags)
+	assert.Equal(t, "2024-05-01", *day)
+
+	assert.Error(t, conf.LoadData("toml", []byte("name = ")))
+}
@@ -0,0 +1,258 @@
+// Package configweb serves a small web page for administering a
+// Configurable: it lists every flag with its value and source, and edits the
+// flags chosen as safe to change at runtime.
+//
+// The Handler is meant to be mounted under a prefix of an existing server,
+// behind the application's own authentication:
+//
+//	h := configweb.New(config, requireAdmin, configweb.WithEditable("log.level", "workers"))
+//	mux.Handle("/admin/config/", http.StripPrefix("/admin/config", h))
+//
+// Besides the page it serves a JSON API:
+//
+//	GET /api/flags         every flag, as FlagInfo with an editable field
+//	PUT /api/flags/{name}  {"value": ...} sets the flag with Set
+//
+// A flag that needs approval is proposed on behalf of the principal instead,
+// and the response is 202 Accepted with the {"pending": id} to approve.
+//
+// Edits go through Set, so they are checked by the flag's type, constraints,
+// validators and policies, and fail in read-only mode. Values and defaults of
+// secret flags are not sent; editable secrets can be replaced but not read.
+//
+// An Authorizer narrows this per principal: which flags each one may see,
+// change, and read the secrets of. The auth middleware names the principal
+// of each request with NewContext.
+package configweb
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/andreimerlescu/configurable"
+)
+
+//go:embed index.html
+var page []byte
+
+// maxBody bounds the body of an edit.
+const maxBody = 1 << 20
+
+// Flag is a flag as the API reports it.
+type Flag struct {
+	configurable.FlagInfo
+	Editable bool `json:"editable"`
+}
+
+// Action is what a principal asks to do with a flag.
+type Action string
+
+const (
+	// Read is seeing a flag, and its value unless it is a secret.
+	Read Action = "read"
+	// ReadSecret is seeing the value of a secret flag.
+	ReadSecret Action = "read-secret"
+	// Write is changing a flag.
+	Write Action = "write"
+)
+
+// Authorizer decides whether principal may perform action on the flag key.
+// A non-nil error refuses it and is shown to the principal.
+type Authorizer interface {
+	Authorize(principal, key string, action Action) error
+}
+
+// AuthorizerFunc is an Authorizer written as a function.
+type AuthorizerFunc func(principal, key string, action Action) error
+
+func (f AuthorizerFunc) Authorize(principal, key string, action Action) error {
+	return f(principal, key, action)
+}
+
+type principalKey struct{}
+
+// NewContext returns ctx naming principal, such as a user or role, as the
+// one making the request. Auth middleware calls it for the Authorizer.
+func NewContext(ctx context.Context, principal string) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFromContext returns the principal NewContext stored in ctx, or ""
+// if there is none.
+func PrincipalFromContext(ctx context.Context) string {
+	principal, _ := ctx.Value(principalKey{}).(string)
+	return principal
+}
+
+// Handler serves the page and its API.
+type Handler struct {
+	conf     configurable.IConfigurable
+	editable map[string]bool
+	auth     Authorizer
+	mux      *http.ServeMux
+	h        http.Handler
+}
+
+// Option configures a Handler.
+type Option func(*Handler)
+
+// WithEditable lets the page change the named flags. No flag is editable
+// by default, so only flags the application rereads at runtime should be
+// listed.
+func WithEditable(names ...string) Option {
+	return func(h *Handler) {
+		for _, name := range names {
+			h.editable[name] = true
+		}
+	}
+}
+
+// WithAuthorizer checks every read and edit with a. Without one, every
+// principal sees every flag that is not hidden, can edit the editable ones,
+// and reads no secrets.
+func WithAuthorizer(a Authorizer) Option {
+	return func(h *Handler) {
+		h.auth = a
+	}
+}
+
+// New returns a Handler for conf wrapped in auth, which must reject requests
+// from anyone not allowed to administer the configuration. A nil auth
+// panics, since serving the handler unprotected is a programming error.
+func New(conf configurable.IConfigurable, auth func(http.Handler) http.Handler, opts ...Option) *Handler {
+	if auth == nil {
+		panic("configweb: New without an auth middleware")
+	}
+	h := &Handler{conf: conf, editable: make(map[string]bool), mux: http.NewServeMux()}
+	for _, opt := range opts {
+		opt(h)
+	}
+	h.mux.HandleFunc("GET /{$}", h.index)
+	h.mux.HandleFunc("GET /api/flags", h.list)
+	h.mux.HandleFunc("PUT /api/flags/{name}", h.set)
+	h.h = auth(h.mux)
+	return h
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.h.ServeHTTP(w, r)
+}
+
+func (h *Handler) index(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Content-Security-Policy", "default-src 'self'; script-src 'unsafe-inline'; style-src 'unsafe-inline'")
+	w.Write(page)
+}
+
+func (h *Handler) list(w http.ResponseWriter, r *http.Request) {
+	principal := PrincipalFromContext(r.Context())
+	var flags []Flag
+	for _, info := range h.conf.Flags() {
+		if info.Hidden || h.authorize(principal, info.Name, Read) != nil {
+			continue
+		}
+		flags = append(flags, h.flag(principal, info))
+	}
+	writeJSON(w, http.StatusOK, flags)
+}
+
+func (h *Handler) set(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	principal := PrincipalFromContext(r.Context())
+	info, ok := h.lookup(name)
+	// A flag the principal may not read is reported as missing, so the
+	// API does not reveal that it exists.
+	if !ok || h.authorize(principal, name, Read) != nil {
+		writeError(w, http.StatusNotFound, "no flag named "+name)
+		return
+	}
+	if !h.editable[name] {
+		writeError(w, http.StatusForbidden, name+" is not editable")
+		return
+	}
+	if err := h.authorize(principal, name, Write); err != nil {
+		writeError(w, http.StatusForbidden, err.Error())
+		return
+	}
+	// Requiring JSON makes browsers send a preflight for cross-site edits,
+	// which the handler does not answer.
+	if mt, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mt != "application/json" {
+		writeError(w, http.StatusUnsupportedMediaType, "edits must be sent as application/json")
+		return
+	}
+	var body struct {
+		Value interface{} `json:"value"`
+	}
+	if err := json.NewDecoder(io.LimitReader(r.Body, maxBody)).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request: "+err.Error())
+		return
+	}
+	if h.conf.NeedsApproval(name) {
+		p, err := h.conf.Propose(name, body.Value, principal)
+		if err != nil {
+			writeError(w, editStatus(err), err.Error())
+			return
+		}
+		writeJSON(w, http.StatusAccepted, map[string]string{"pending": p.ID})
+		return
+	}
+	if err := h.conf.Set(name, body.Value); err != nil {
+		writeError(w, editStatus(err), err.Error())
+		return
+	}
+	info, _ = h.lookup(name)
+	writeJSON(w, http.StatusOK, h.flag(principal, info))
+}
+
+// editStatus returns the status reporting a failed edit.
+func editStatus(err error) int {
+	switch {
+	case errors.Is(err, configurable.ErrReadOnly):
+		return http.StatusConflict
+	case errors.Is(err, configurable.ErrFrozen):
+		return http.StatusLocked
+	}
+	return http.StatusUnprocessableEntity
+}
+
+// lookup returns the FlagInfo of name, leaving out hidden flags.
+func (h *Handler) lookup(name string) (configurable.FlagInfo, bool) {
+	for _, info := range h.conf.Flags() {
+		if info.Name == name && !info.Hidden {
+			return info, true
+		}
+	}
+	return configurable.FlagInfo{}, false
+}
+
+// flag returns info as principal may see it.
+func (h *Handler) flag(principal string, info configurable.FlagInfo) Flag {
+	if info.Secret && (h.auth == nil || h.auth.Authorize(principal, info.Name, ReadSecret) != nil) {
+		info.Value, info.Default = nil, ""
+	}
+	editable := h.editable[info.Name] && h.authorize(principal, info.Name, Write) == nil
+	return Flag{FlagInfo: info, Editable: editable}
+}
+
+// authorize asks the Authorizer, allowing everything without one.
+func (h *Handler) authorize(principal, key string, action Action) error {
+	if h.auth == nil {
+		return nil
+	}
+	return h.auth.Authorize(principal, key, action)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]string{"error": msg})
+}
@@ -0,0 +1,237 @@
+package configweb
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andreimerlescu/configurable"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler(t *testing.T) {
+	newHandler := func(t *testing.T) (configurable.IConfigurable, http.Handler) {
+		conf := configurable.New(configurable.WithFlagSet(flag.NewFlagSet(t.Name(), flag.ContinueOnError)))
+		conf.NewInt("workers", 4, "worker count", configurable.WithValidator(configurable.Check(func(n int) error {
+			if n < 1 {
+				return errors.New("must be positive")
+			}
+			return nil
+		})))
+		conf.NewString("token", "hunter2", "api token", configurable.WithSecret())
+		conf.NewString("addr", ":8080", "listen address")
+		conf.NewBool("internal", false, "internal", configurable.WithHidden())
+		conf.NewFreezeWindows("freeze", nil, "freeze windows", configurable.WithHidden())
+		conf.SetArgs([]string{})
+		assert.NoError(t, conf.Parse(""))
+		auth := func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("Authorization") != "Bearer admin" {
+					http.Error(w, "forbidden", http.StatusForbidden)
+					return
+				}
+				next.ServeHTTP(w, r)
+			})
+		}
+		return conf, New(conf, auth, WithEditable("workers", "token"))
+	}
+	do := func(h http.Handler, method, path, body string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, path, strings.NewReader(body))
+		r.Header.Set("Authorization", "Bearer admin")
+		if body != "" {
+			r.Header.Set("Content-Type", "application/json")
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	t.Run("test page", func(t *testing.T) {
+		_, h := newHandler(t)
+		w := do(h, "GET", "/", "")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "api/flags")
+	})
+
+	t.Run("test auth", func(t *testing.T) {
+		_, h := newHandler(t)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", "/api/flags", nil))
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Panics(t, func() { New(configurable.New(), nil) })
+	})
+
+	t.Run("test list", func(t *testing.T) {
+		_, h := newHandler(t)
+		w := do(h, "GET", "/api/flags", "")
+		assert.Equal(t, http.StatusOK, w.Code)
+		var flags []Flag
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &flags))
+		assert.Len(t, flags, 3)
+		byName := map[string]Flag{}
+		for _, f := range flags {
+			byName[f.Name] = f
+		}
+		assert.True(t, byName["workers"].Editable)
+		assert.False(t, byName["addr"].Editable)
+		assert.Equal(t, "default", byName["addr"].Source)
+		assert.Nil(t, byName["token"].Value)
+		assert.NotContains(t, w.Body.String(), "hunter2")
+	})
+
+	t.Run("test edit", func(t *testing.T) {
+		conf, h := newHandler(t)
+		w := do(h, "PUT", "/api/flags/workers", `{"value": "8"}`)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var f Flag
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &f))
+		assert.Equal(t, "set", f.Source)
+		assert.Equal(t, 8, *conf.Int("workers"))
+
+		w = do(h, "PUT", "/api/flags/token", `{"value": "s3cret"}`)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.NotContains(t, w.Body.String(), "s3cret")
+		assert.Equal(t, "s3cret", *conf.String("token"))
+	})
+
+	t.Run("test rejected edits", func(t *testing.T) {
+		conf, h := newHandler(t)
+		assert.Equal(t, http.StatusUnprocessableEntity, do(h, "PUT", "/api/flags/workers", `{"value": 0}`).Code)
+		assert.Equal(t, http.StatusUnprocessableEntity, do(h, "PUT", "/api/flags/workers", `{"value": "many"}`).Code)
+		assert.Equal(t, 4, *conf.Int("workers"))
+		assert.Equal(t, http.StatusForbidden, do(h, "PUT", "/api/flags/addr", `{"value": ":9090"}`).Code)
+		assert.Equal(t, http.StatusNotFound, do(h, "PUT", "/api/flags/internal", `{"value": true}`).Code)
+		assert.Equal(t, http.StatusBadRequest, do(h, "PUT", "/api/flags/workers", `{`).Code)
+
+		r := httptest.NewRequest("PUT", "/api/flags/workers", strings.NewReader(`{"value": 8}`))
+		r.Header.Set("Authorization", "Bearer admin")
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+
+		now := time.Now().UTC()
+		assert.NoError(t, conf.Set("freeze", []string{now.Add(-time.Hour).Format(time.RFC3339) + "/" + now.Add(time.Hour).Format(time.RFC3339)}))
+		assert.Equal(t, http.StatusLocked, do(h, "PUT", "/api/flags/workers", `{"value": 8}`).Code)
+
+		conf.ReadOnly(true)
+		w = do(h, "PUT", "/api/flags/workers", `{"value": 8}`)
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.Contains(t, w.Body.String(), "read-only")
+	})
+}
+
+func TestAuthorizer(t *testing.T) {
+	conf := configurable.New(configurable.WithFlagSet(flag.NewFlagSet(t.Name(), flag.ContinueOnError)))
+	conf.NewInt("workers", 4, "worker count")
+	conf.NewString("db.password", "hunter2", "database password", configurable.WithSecret())
+	conf.NewString("billing.plan", "free", "billing plan")
+	conf.SetArgs([]string{})
+	assert.NoError(t, conf.Parse(""))
+
+	// Operators change workers; DBAs also read and change the password;
+	// billing is visible to nobody but the billing team.
+	authz := AuthorizerFunc(func(principal, key string, action Action) error {
+		switch {
+		case strings.HasPrefix(key, "billing."):
+			if principal != "billing" {
+				return errors.New("billing only")
+			}
+		case key == "db.password":
+			if action != Read && principal != "dba" {
+				return errors.New("DBAs only")
+			}
+		case action == Write && principal == "viewer":
+			return errors.New("read only")
+		}
+		return nil
+	})
+	auth := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(NewContext(r.Context(), r.Header.Get("X-User"))))
+		})
+	}
+	h := New(conf, auth, WithEditable("workers", "db.password", "billing.plan"), WithAuthorizer(authz))
+	do := func(user, method, path, body string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, path, strings.NewReader(body))
+		r.Header.Set("X-User", user)
+		r.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+	list := func(user string) map[string]Flag {
+		var flags []Flag
+		assert.NoError(t, json.Unmarshal(do(user, "GET", "/api/flags", "").Body.Bytes(), &flags))
+		byName := map[string]Flag{}
+		for _, f := range flags {
+			byName[f.Name] = f
+		}
+		return byName
+	}
+
+	t.Run("test read", func(t *testing.T) {
+		viewer := list("viewer")
+		assert.Len(t, viewer, 2)
+		assert.NotContains(t, viewer, "billing.plan")
+		assert.Nil(t, viewer["db.password"].Value)
+		assert.False(t, viewer["workers"].Editable)
+		assert.False(t, viewer["db.password"].Editable)
+
+		dba := list("dba")
+		assert.Equal(t, "hunter2", dba["db.password"].Value)
+		assert.True(t, dba["db.password"].Editable)
+		assert.True(t, dba["workers"].Editable)
+
+		assert.Contains(t, list("billing"), "billing.plan")
+	})
+
+	t.Run("test write", func(t *testing.T) {
+		w := do("viewer", "PUT", "/api/flags/workers", `{"value": 8}`)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Contains(t, w.Body.String(), "read only")
+		assert.Equal(t, http.StatusNotFound, do("viewer", "PUT", "/api/flags/billing.plan", `{"value": "pro"}`).Code)
+		assert.Equal(t, http.StatusForbidden, do("operator", "PUT", "/api/flags/db.password", `{"value": "x"}`).Code)
+		assert.Equal(t, "hunter2", *conf.String("db.password"))
+
+		assert.Equal(t, http.StatusOK, do("operator", "PUT", "/api/flags/workers", `{"value": 8}`).Code)
+		assert.Equal(t, 8, *conf.Int("workers"))
+		w = do("dba", "PUT", "/api/flags/db.password", `{"value": "s3cret"}`)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "s3cret")
+	})
+}
+
+func TestApproval(t *testing.T) {
+	conf := configurable.New(configurable.WithFlagSet(flag.NewFlagSet(t.Name(), flag.ContinueOnError)))
+	conf.NewInt("workers", 4, "worker count", configurable.WithApproval())
+	conf.SetArgs([]string{})
+	assert.NoError(t, conf.Parse(""))
+	auth := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(NewContext(r.Context(), "bob")))
+		})
+	}
+	h := New(conf, auth, WithEditable("workers"))
+
+	r := httptest.NewRequest("PUT", "/api/flags/workers", strings.NewReader(`{"value": 8}`))
+	r.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	var body map[string]string
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, 4, *conf.Int("workers"))
+
+	pending := conf.PendingChanges()
+	assert.Len(t, pending, 1)
+	assert.Equal(t, body["pending"], pending[0].ID)
+	assert.Equal(t, "bob", pending[0].Requester)
+	assert.ErrorIs(t, conf.Approve(pending[0].ID, "bob"), configurable.ErrSelfApproval)
+	assert.NoError(t, conf.Approve(pending[0].ID, "alice"))
+	assert.Equal(t, 8, *conf.Int("workers"))
+}
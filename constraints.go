@@ -0,0 +1,286 @@
+package configurable
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ConstraintError reports a value rejected by a constraint declared when its
+// flag was registered, such as the bounds of NewBoundedInt.
+type ConstraintError struct {
+	Name   string
+	Value  interface{}
+	Reason string
+}
+
+func (e *ConstraintError) Error() string {
+	return fmt.Sprintf("invalid value %v for %s: %s", e.Value, e.Name, e.Reason)
+}
+
+// bounds is an inclusive numeric range.
+type bounds struct {
+	min, max float64
+}
+
+func (b *bounds) String() string {
+	return formatBound(b.min) + ".." + formatBound(b.max)
+}
+
+func formatBound(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// NewBoundedInt registers an int flag whose value must lie within [min, max]
+// wherever it comes from. It panics if value itself is out of range.
+func (c *Configurable) NewBoundedInt(name string, value, min, max int, usage string, opts ...FlagOption) *int {
+	c.checkDefault(name, value, float64(value), float64(min), float64(max))
+	opts = append(opts, withBounds(float64(min), float64(max)))
+	return c.NewInt(name, value, usage, opts...)
+}
+
+// NewBoundedFloat64 registers a float64 flag whose value must lie within
+// [min, max] wherever it comes from. It panics if value itself is out of
+// range.
+func (c *Configurable) NewBoundedFloat64(name string, value, min, max float64, usage string, opts ...FlagOption) *float64 {
+	c.checkDefault(name, value, value, min, max)
+	opts = append(opts, withBounds(min, max))
+	return c.NewFloat64(name, value, usage, opts...)
+}
+
+func (c *Configurable) checkDefault(name string, value interface{}, v, min, max float64) {
+	if min > max {
+		panic(fmt.Sprintf("configurable: %s has an empty range %s..%s", name, formatBound(min), formatBound(max)))
+	}
+	if v < min || v > max {
+		panic(fmt.Sprintf("configurable: default %v for %s is outside %s..%s", value, name, formatBound(min), formatBound(max)))
+	}
+}
+
+func withBounds(min, max float64) FlagOption {
+	return func(m *flagMeta) {
+		m.bounds = &bounds{min: min, max: max}
+	}
+}
+
+// constrained reports whether values of the flag must be checked before they
+// are accepted.
+func (m *flagMeta) constrained() bool {
+	return m.bounds != nil || m.text != nil
+}
+
+// checkConstraints checks value, a candidate for the flag name, against the
+// constraints it was registered with.
+func (c *Configurable) checkConstraints(name string, value interface{}) error {
+	m, ok := c.meta[name]
+	if !ok {
+		return nil
+	}
+	if b := m.bounds; b != nil {
+		var v float64
+		switch n := value.(type) {
+		case int:
+			v = float64(n)
+		case float64:
+			v = n
+		}
+		if v < b.min || v > b.max {
+			reason := "must be between " + formatBound(b.min) + " and " + formatBound(b.max)
+			return &ConstraintError{Name: name, Value: value, Reason: reason}
+		}
+	}
+	if s, ok := value.(string); ok && m.text != nil {
+		if reason := m.text.check(s); reason != "" {
+			return &ConstraintError{Name: name, Value: strconv.Quote(s), Reason: reason}
+		}
+	}
+	return nil
+}
+
+// textRules constrain the values of a string flag.
+type textRules struct {
+	minLength int
+	maxLength int
+	charset   string
+	lowercase bool
+	dnsLabel  bool
+}
+
+// maxDNSLabel is the longest label RFC 1123 allows.
+const maxDNSLabel = 63
+
+func (m *flagMeta) textRules() *textRules {
+	if m.text == nil {
+		m.text = &textRules{}
+	}
+	return m.text
+}
+
+// WithMinLength requires values of a string flag to be at least n characters
+// long.
+func WithMinLength(n int) FlagOption {
+	return func(m *flagMeta) {
+		m.textRules().minLength = n
+	}
+}
+
+// WithMaxLength requires values of a string flag to be at most n characters
+// long.
+func WithMaxLength(n int) FlagOption {
+	return func(m *flagMeta) {
+		m.textRules().maxLength = n
+	}
+}
+
+// WithCharset requires values of a string flag to consist only of characters
+// in allowed.
+func WithCharset(allowed string) FlagOption {
+	return func(m *flagMeta) {
+		m.textRules().charset = allowed
+	}
+}
+
+// WithLowercase requires values of a string flag to be lowercase.
+func WithLowercase() FlagOption {
+	return func(m *flagMeta) {
+		m.textRules().lowercase = true
+	}
+}
+
+// WithDNSLabel requires values of a string flag to be RFC 1123 DNS labels,
+// such as Kubernetes namespace and service names.
+func WithDNSLabel() FlagOption {
+	return func(m *flagMeta) {
+		m.textRules().dnsLabel = true
+	}
+}
+
+// check returns why s breaks the rules, or "" if it does not.
+func (r *textRules) check(s string) string {
+	n := utf8.RuneCountInString(s)
+	switch {
+	case n < r.minLength:
+		return fmt.Sprintf("must be at least %d characters", r.minLength)
+	case r.maxLength > 0 && n > r.maxLength:
+		return fmt.Sprintf("must be at most %d characters", r.maxLength)
+	case r.charset != "" && !onlyFrom(s, r.charset):
+		return fmt.Sprintf("must only contain characters from %q", r.charset)
+	case r.lowercase && strings.ToLower(s) != s:
+		return "must be lowercase"
+	case r.dnsLabel && !isDNSLabel(s):
+		return "must be a DNS label: up to 63 lowercase letters, digits and '-', starting and ending with a letter or digit"
+	}
+	return ""
+}
+
+func onlyFrom(s, allowed string) bool {
+	for _, r := range s {
+		if !strings.ContainsRune(allowed, r) {
+			return false
+		}
+	}
+	return true
+}
+
+func isDNSLabel(s string) bool {
+	if s == "" || len(s) > maxDNSLabel || s[0] == '-' || s[len(s)-1] == '-' {
+		return false
+	}
+	for _, r := range s {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '-' {
+			return false
+		}
+	}
+	return true
+}
+
+// describe lists the rules for generated docs, such as
+// "at most 63 characters, lowercase".
+func (r *textRules) describe() string {
+	var parts []string
+	switch {
+	case r.minLength > 0 && r.maxLength > 0:
+		parts = append(parts, fmt.Sprintf("%d to %d characters", r.minLength, r.maxLength))
+	case r.minLength > 0:
+		parts = append(parts, fmt.Sprintf("at least %d characters", r.minLength))
+	case r.maxLength > 0:
+		parts = append(parts, fmt.Sprintf("at most %d characters", r.maxLength))
+	}
+	if r.charset != "" {
+		parts = append(parts, fmt.Sprintf("characters from `%s`", r.charset))
+	}
+	if r.lowercase {
+		parts = append(parts, "lowercase")
+	}
+	if r.dnsLabel {
+		parts = append(parts, "a DNS label")
+	}
+	return strings.Join(parts, ", ")
+}
+
+// patterns returns ECMA-262 regular expressions equivalent to the rules, for
+// JSON Schema.
+func (r *textRules) patterns() []string {
+	var patterns []string
+	if r.charset != "" {
+		var sb strings.Builder
+		for _, c := range r.charset {
+			if strings.ContainsRune(`\]^-[`, c) {
+				sb.WriteByte('\\')
+			}
+			sb.WriteRune(c)
+		}
+		patterns = append(patterns, "^["+sb.String()+"]*$")
+	}
+	if r.lowercase {
+		patterns = append(patterns, "^[^A-Z]*$")
+	}
+	if r.dnsLabel {
+		patterns = append(patterns, "^[a-z0-9]([-a-z0-9]{0,61}[a-z0-9])?$")
+	}
+	return patterns
+}
+
+// checkedValue guards a constrained flag on the command line, restoring the
+// previous value when a new one is rejected.
+type checkedValue struct {
+	flag.Value
+	c    *Configurable
+	name string
+}
+
+func (v *checkedValue) Set(s string) error {
+	prev := v.Value.String()
+	if err := v.Value.Set(s); err != nil {
+		return err
+	}
+	if err := v.c.checkConstraints(v.name, valueOf(v.c.flags[v.name])); err != nil {
+		_ = v.Value.Set(prev)
+		// The flag package already names the flag and value.
+		return errors.New(err.(*ConstraintError).Reason)
+	}
+	return nil
+}
+
+func (v *checkedValue) Get() interface{} {
+	if g, ok := v.Value.(flag.Getter); ok {
+		return g.Get()
+	}
+	return v.Value.String()
+}
+
+// guard installs a checkedValue in front of name's flag.Value once the flag
+// has constraints.
+func (c *Configurable) guard(name string) {
+	f := c.fs.Lookup(name)
+	if f == nil {
+		return
+	}
+	if _, ok := f.Value.(*checkedValue); ok {
+		return
+	}
+	f.Value = &checkedValue{Value: f.Value, c: c, name: name}
+}
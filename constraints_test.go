@@ -0,0 +1,144 @@
+package configurable
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBoundedFlags(t *testing.T) {
+	newConf := func(t *testing.T) *Configurable {
+		conf := newTestConfigurable(t)
+		conf.NewBoundedInt("port", 8080, 1, 65535, "listen port")
+		conf.NewBoundedFloat64("ratio", 0.5, 0, 1, "sample ratio")
+		conf.SetOutput(&bytes.Buffer{})
+		conf.SetArgs([]string{})
+		return conf
+	}
+
+	t.Run("test command line", func(t *testing.T) {
+		conf := newConf(t)
+		assert.NoError(t, conf.ParseArgs([]string{"-port", "443", "-ratio", "1"}))
+		assert.Equal(t, 443, *conf.Int("port"))
+
+		conf = newConf(t)
+		err := conf.ParseArgs([]string{"-port", "70000"})
+		assert.EqualError(t, err, `invalid value "70000" for flag -port: must be between 1 and 65535`)
+		assert.Equal(t, 8080, *conf.Int("port"))
+	})
+
+	t.Run("test files", func(t *testing.T) {
+		conf := newConf(t)
+		err := conf.LoadData("json", []byte(`{"port": 0, "ratio": 0.25}`))
+		var constraint *ConstraintError
+		assert.ErrorAs(t, err, &constraint)
+		assert.EqualError(t, err, "error setting key port: invalid value 0 for port: must be between 1 and 65535")
+		assert.Equal(t, 8080, *conf.Int("port"))
+		assert.Equal(t, 0.5, *conf.Float64("ratio"))
+	})
+
+	t.Run("test env and overrides", func(t *testing.T) {
+		conf := newConf(t)
+		conf.SetEnv("ratio", "1.5")
+		assert.NoError(t, conf.Parse(""))
+		assert.Equal(t, 0.5, *conf.Float64("ratio"))
+
+		ctx := WithOverrides(context.Background(), map[string]any{"port": -1})
+		assert.Equal(t, 8080, conf.ViewContext(ctx).Int("port"))
+	})
+
+	t.Run("test invalid defaults panic", func(t *testing.T) {
+		conf := newTestConfigurable(t)
+		assert.Panics(t, func() { conf.NewBoundedInt("port", 0, 1, 65535, "") })
+		assert.Panics(t, func() { conf.NewBoundedFloat64("ratio", 0, 1, 0, "") })
+	})
+
+	t.Run("test usage and docs", func(t *testing.T) {
+		conf := newConf(t)
+		assert.Contains(t, conf.Usage(), "  -port   listen port (default: 8080) (range: 1..65535)\n")
+		assert.Contains(t, conf.Docs(), "Range: `0` to `1`")
+	})
+
+	t.Run("test schema", func(t *testing.T) {
+		conf := newConf(t)
+		conf.NewList("tags", []string{"a"}, "tags")
+		data, err := conf.Schema()
+		assert.NoError(t, err)
+		var schema struct {
+			Type       string                            `json:"type"`
+			Properties map[string]map[string]interface{} `json:"properties"`
+		}
+		assert.NoError(t, json.Unmarshal(data, &schema))
+		assert.Equal(t, "object", schema.Type)
+		assert.Equal(t, map[string]interface{}{
+			"type":        "integer",
+			"description": "listen port",
+			"default":     8080.0,
+			"minimum":     1.0,
+			"maximum":     65535.0,
+		}, schema.Properties["port"])
+		assert.Equal(t, "array", schema.Properties["tags"]["type"])
+		assert.Equal(t, []interface{}{"a"}, schema.Properties["tags"]["default"])
+	})
+}
+
+func TestStringConstraints(t *testing.T) {
+	newConf := func(t *testing.T) *Configurable {
+		conf := newTestConfigurable(t)
+		conf.NewString("namespace", "default", "namespace", WithDNSLabel())
+		conf.NewString("code", "", "code", WithMinLength(2), WithMaxLength(4), WithCharset("ABC123"))
+		conf.NewString("user", "", "user", WithLowercase())
+		conf.SetOutput(&bytes.Buffer{})
+		conf.SetArgs([]string{})
+		return conf
+	}
+
+	t.Run("test values", func(t *testing.T) {
+		tests := []struct {
+			name, value, reason string
+		}{
+			{"namespace", "team-a", ""},
+			{"namespace", "Team-A", "must be a DNS label"},
+			{"namespace", "-team", "must be a DNS label"},
+			{"code", "AB12", ""},
+			{"code", "A", "must be at least 2 characters"},
+			{"code", "ABC123", "must be at most 4 characters"},
+			{"code", "AZ", `must only contain characters from "ABC123"`},
+			{"user", "émile", ""},
+			{"user", "Émile", "must be lowercase"},
+		}
+		for _, tt := range tests {
+			err := newConf(t).ParseArgs([]string{"-" + tt.name, tt.value})
+			if tt.reason == "" {
+				assert.NoError(t, err, tt.value)
+				continue
+			}
+			assert.ErrorContains(t, err, tt.reason, tt.value)
+		}
+	})
+
+	t.Run("test files", func(t *testing.T) {
+		conf := newConf(t)
+		err := conf.LoadData("yaml", []byte("namespace: Prod"))
+		assert.EqualError(t, err, `error setting key namespace: invalid value "Prod" for namespace: must be a DNS label: up to 63 lowercase letters, digits and '-', starting and ending with a letter or digit`)
+		assert.Equal(t, "default", *conf.String("namespace"))
+	})
+
+	t.Run("test docs and schema", func(t *testing.T) {
+		conf := newConf(t)
+		assert.Contains(t, conf.Docs(), "Must be 2 to 4 characters, characters from `ABC123`.")
+		data, err := conf.Schema()
+		assert.NoError(t, err)
+		var schema struct {
+			Properties map[string]map[string]interface{} `json:"properties"`
+		}
+		assert.NoError(t, json.Unmarshal(data, &schema))
+		assert.Equal(t, 2.0, schema.Properties["code"]["minLength"])
+		assert.Equal(t, 4.0, schema.Properties["code"]["maxLength"])
+		assert.Equal(t, "^[ABC123]*$", schema.Properties["code"]["pattern"])
+		assert.Equal(t, "^[a-z0-9]([-a-z0-9]{0,61}[a-z0-9])?$", schema.Properties["namespace"]["pattern"])
+	})
+}
@@ -0,0 +1,244 @@
+// Package consul is a configurable.Provider reading the keys under a prefix
+// in Consul's KV store, over its HTTP API.
+//
+// Each key below the prefix sets the flag named by the rest of the key, with
+// slashes read as dots: under the prefix "myapp/", the key "myapp/db/host"
+// sets db.host. Values are the text a flag would accept on the command line.
+// Keys that match no flag are reported like unknown keys in a file.
+package consul
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andreimerlescu/configurable"
+)
+
+// DefaultWait is how long a blocking query waits for a change before Consul
+// answers with the unchanged keys.
+const DefaultWait = 5 * time.Minute
+
+// timeoutSlack is how much longer than the wait a request may take. Consul
+// adds up to wait/16 of jitter to a blocking query.
+const timeoutSlack = 30 * time.Second
+
+// Watch pauses between queries that return early without a change, as they
+// do when the index is reset or missing, starting at minBackoff and doubling
+// up to maxBackoff, so that it never spins on the agent.
+const (
+	minBackoff = time.Second
+	maxBackoff = time.Minute
+)
+
+// Provider reads the keys under a prefix.
+type Provider struct {
+	addr   string
+	prefix string
+	token  string
+	tls    *tls.Config
+	client *http.Client
+	wait   time.Duration
+	clock  configurable.Clock
+}
+
+// Option configures a Provider.
+type Option func(*Provider)
+
+// WithToken sets the ACL token sent with each request.
+func WithToken(token string) Option {
+	return func(p *Provider) {
+		p.token = token
+	}
+}
+
+// WithTLS sets the TLS configuration for an https address, such as a client
+// certificate and the CA that signed the agent's certificate.
+func WithTLS(config *tls.Config) Option {
+	return func(p *Provider) {
+		p.tls = config
+	}
+}
+
+// WithClient sets the HTTP client, in place of the one New builds, and
+// overrides WithTLS. Its timeout must be longer than the wait of a blocking
+// query.
+func WithClient(client *http.Client) Option {
+	return func(p *Provider) {
+		p.client = client
+	}
+}
+
+// WithWait sets how long each blocking query of Watch waits. The default is
+// DefaultWait.
+func WithWait(d time.Duration) Option {
+	return func(p *Provider) {
+		p.wait = d
+	}
+}
+
+// WithClock sets the clock Watch pauses on between queries that return
+// early, so tests can drive it with a configurable.ManualClock. The default
+// is the system clock.
+func WithClock(clock configurable.Clock) Option {
+	return func(p *Provider) {
+		p.clock = clock
+	}
+}
+
+// New returns a Provider for the keys under prefix on the agent at addr,
+// such as "http://127.0.0.1:8500". The prefix is a folder: "myapp" reads the
+// keys under "myapp/", not those under "myapp-staging/".
+func New(addr, prefix string, opts ...Option) *Provider {
+	if !strings.Contains(addr, "://") {
+		addr = "http://" + addr
+	}
+	prefix = strings.TrimPrefix(prefix, "/")
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+	p := &Provider{addr: strings.TrimSuffix(addr, "/"), prefix: prefix, wait: DefaultWait, clock: configurable.SystemClock()}
+	for _, opt := range opts {
+		opt(p)
+	}
+	if p.client == nil {
+		// The timeout keeps a stalled agent from hanging a Load whose ctx has
+		// no deadline.
+		p.client = &http.Client{Timeout: p.wait + p.wait/16 + timeoutSlack}
+		if p.tls != nil {
+			p.client.Transport = &http.Transport{Proxy: http.ProxyFromEnvironment, TLSClientConfig: p.tls}
+		}
+	}
+	return p
+}
+
+func (p *Provider) Name() string {
+	return "consul:" + p.prefix
+}
+
+// Load returns the keys under the prefix.
+func (p *Provider) Load(ctx context.Context) (map[string]interface{}, error) {
+	data, _, err := p.get(ctx, 0)
+	return data, err
+}
+
+// Watch applies the keys under the prefix, then again each time a blocking
+// query returns them changed, until ctx is done or a query fails. A deleted
+// key leaves its flag at the value it had.
+func (p *Provider) Watch(ctx context.Context, apply func(map[string]interface{}) error) error {
+	data, index, err := p.get(ctx, 0)
+	if err != nil {
+		return err
+	}
+	// A query for index 0 never blocks, so Consul advises treating a
+	// missing or zero index as 1.
+	index = max(index, 1)
+	_ = apply(data)
+	var backoff time.Duration
+	for {
+		start := p.clock.Now()
+		next, nextIndex, err := p.get(ctx, index)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+		nextIndex = max(nextIndex, 1)
+		switch {
+		case nextIndex > index:
+			index = nextIndex
+			backoff = 0
+			_ = apply(next)
+			continue
+		case nextIndex < index:
+			// The index went backwards, as it does when the store is
+			// restored: start over from the new index, as Consul advises.
+			index = nextIndex
+			_ = apply(next)
+		}
+		if p.clock.Now().Sub(start) >= p.wait {
+			// The wait ran out without a change.
+			backoff = 0
+			continue
+		}
+		backoff = min(max(2*backoff, minBackoff), maxBackoff)
+		if err := p.pause(ctx, backoff); err != nil {
+			return err
+		}
+	}
+}
+
+// pause waits on the clock for d, or until ctx is done.
+func (p *Provider) pause(ctx context.Context, d time.Duration) error {
+	elapsed := make(chan struct{})
+	timer := p.clock.AfterFunc(d, func() { close(elapsed) })
+	select {
+	case <-ctx.Done():
+		timer.Stop()
+		return ctx.Err()
+	case <-elapsed:
+		return nil
+	}
+}
+
+// kvPair is an entry of Consul's KV listing.
+type kvPair struct {
+	Key   string
+	Value []byte
+}
+
+// get lists the keys under the prefix, blocking until the index passes
+// index if it is not 0, and returns them with the index of the answer.
+func (p *Provider) get(ctx context.Context, index uint64) (map[string]interface{}, uint64, error) {
+	query := url.Values{"recurse": {"true"}}
+	if index > 0 {
+		query.Set("index", strconv.FormatUint(index, 10))
+		query.Set("wait", fmt.Sprintf("%ds", int(p.wait.Seconds())))
+	}
+	u := p.addr + "/v1/kv/" + (&url.URL{Path: p.prefix}).EscapedPath() + "?" + query.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	if p.token != "" {
+		req.Header.Set("X-Consul-Token", p.token)
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("reading %s: %w", p.prefix, err)
+	}
+	defer resp.Body.Close()
+	next, _ := strconv.ParseUint(resp.Header.Get("X-Consul-Index"), 10, 64)
+	data := make(map[string]interface{})
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		// No keys under the prefix yet.
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return data, next, nil
+	default:
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, 0, fmt.Errorf("reading %s: %s: %s", p.prefix, resp.Status, strings.TrimSpace(string(body)))
+	}
+	var pairs []kvPair
+	if err := json.NewDecoder(resp.Body).Decode(&pairs); err != nil {
+		return nil, 0, fmt.Errorf("reading %s: %w", p.prefix, err)
+	}
+	for _, pair := range pairs {
+		name, ok := strings.CutPrefix(pair.Key, p.prefix)
+		// Keys ending in a slash are folders.
+		if !ok || name == "" || strings.HasSuffix(name, "/") {
+			continue
+		}
+		data[strings.ReplaceAll(name, "/", ".")] = string(pair.Value)
+	}
+	return data, next, nil
+}
@@ -0,0 +1,225 @@
+package consul
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"flag"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/andreimerlescu/configurable"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeConsul serves a KV store, answering blocking queries when the store
+// changes or the wait runs out.
+type fakeConsul struct {
+	mu      sync.Mutex
+	kv      map[string]string
+	index   uint64
+	changed chan struct{}
+	// timedOut receives whenever a blocking query's wait runs out.
+	timedOut chan struct{}
+	requests int
+}
+
+func newFakeConsul(kv map[string]string) *fakeConsul {
+	return &fakeConsul{kv: kv, index: 10, changed: make(chan struct{}), timedOut: make(chan struct{}, 10)}
+}
+
+func (f *fakeConsul) put(key, value string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.kv[key] = value
+	f.index++
+	close(f.changed)
+	f.changed = make(chan struct{})
+}
+
+// restore replaces the store with kv at index, which may be lower than the
+// current one, as restoring a snapshot does.
+func (f *fakeConsul) restore(kv map[string]string, index uint64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.kv = kv
+	f.index = index
+	close(f.changed)
+	f.changed = make(chan struct{})
+}
+
+// served returns how many requests have been answered.
+func (f *fakeConsul) served() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.requests
+}
+
+func (f *fakeConsul) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("X-Consul-Token") != "secret" {
+		http.Error(w, "ACL not found", http.StatusForbidden)
+		return
+	}
+	prefix := strings.TrimPrefix(r.URL.Path, "/v1/kv/")
+	f.mu.Lock()
+	changed := f.changed
+	index := f.index
+	f.mu.Unlock()
+	if q := r.URL.Query().Get("index"); q == strconv.FormatUint(index, 10) {
+		select {
+		case <-changed:
+		case <-time.After(50 * time.Millisecond):
+			select {
+			case f.timedOut <- struct{}{}:
+			default:
+			}
+		case <-r.Context().Done():
+			return
+		}
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests++
+	if f.index > 0 {
+		w.Header().Set("X-Consul-Index", strconv.FormatUint(f.index, 10))
+	}
+	type pair struct {
+		Key   string
+		Value []byte
+	}
+	var pairs []pair
+	for k, v := range f.kv {
+		if strings.HasPrefix(k, prefix) {
+			pairs = append(pairs, pair{k, []byte(v)})
+		}
+	}
+	if len(pairs) == 0 {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	_ = json.NewEncoder(w).Encode(pairs)
+}
+
+func TestProvider(t *testing.T) {
+	t.Run("test load", func(t *testing.T) {
+		srv := httptest.NewServer(newFakeConsul(map[string]string{"myapp/": "", "myapp/db/host": "db.internal", "myapp/workers": "8", "other/workers": "1"}))
+		defer srv.Close()
+		conf := configurable.New(configurable.WithFlagSet(flag.NewFlagSet(t.Name(), flag.ContinueOnError)))
+		conf.NewString("db.host", "localhost", "database host")
+		conf.NewInt("workers", 1, "workers")
+		conf.SetArgs([]string{})
+		p := New(strings.TrimPrefix(srv.URL, "http://"), "myapp/", WithToken("secret"))
+		assert.Equal(t, "consul:myapp/", p.Name())
+		conf.AddProvider(p)
+		assert.NoError(t, conf.Parse(""))
+		assert.Equal(t, "db.internal", conf.View().String("db.host"))
+		assert.Equal(t, 8, conf.View().Int("workers"))
+	})
+
+	t.Run("test errors", func(t *testing.T) {
+		srv := httptest.NewServer(newFakeConsul(map[string]string{}))
+		defer srv.Close()
+		data, err := New(srv.URL, "myapp/", WithToken("secret")).Load(context.Background())
+		assert.NoError(t, err)
+		assert.Empty(t, data)
+		_, err = New(srv.URL, "myapp/").Load(context.Background())
+		assert.ErrorContains(t, err, "403")
+	})
+
+	t.Run("test timeout", func(t *testing.T) {
+		assert.Greater(t, New("127.0.0.1:8500", "myapp/").client.Timeout, DefaultWait)
+		assert.Greater(t, New("127.0.0.1:8500", "myapp/", WithTLS(&tls.Config{})).client.Timeout, DefaultWait)
+		client := &http.Client{}
+		assert.Same(t, client, New("127.0.0.1:8500", "myapp/", WithClient(client)).client)
+
+		stalled := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-r.Context().Done()
+		}))
+		defer stalled.Close()
+		p := New(stalled.URL, "myapp/")
+		p.client.Timeout = 20 * time.Millisecond
+		_, err := p.Load(context.Background())
+		assert.ErrorContains(t, err, "Client.Timeout")
+	})
+
+	watch := func(t *testing.T, p *Provider) (<-chan map[string]interface{}, func() error) {
+		docs := make(chan map[string]interface{}, 10)
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error)
+		go func() {
+			done <- p.Watch(ctx, func(data map[string]interface{}) error {
+				docs <- data
+				return nil
+			})
+		}()
+		return docs, func() error {
+			cancel()
+			return <-done
+		}
+	}
+
+	t.Run("test watch", func(t *testing.T) {
+		consul := newFakeConsul(map[string]string{"myapp/workers": "2", "myapp-staging/workers": "9"})
+		srv := httptest.NewServer(consul)
+		defer srv.Close()
+		clock := configurable.NewManualClock(time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC))
+		p := New(srv.URL, "myapp", WithToken("secret"), WithWait(time.Second), WithClock(clock))
+		assert.Equal(t, "consul:myapp/", p.Name())
+		docs, stop := watch(t, p)
+		assert.Equal(t, map[string]interface{}{"workers": "2"}, <-docs, "sibling folders are not read")
+		// The second query is sent once the first has been handled, after a
+		// pause since the fake's wait runs out early.
+		<-consul.timedOut
+		assert.Eventually(t, func() bool {
+			clock.Advance(minBackoff)
+			return len(consul.timedOut) > 0
+		}, 2*time.Second, 10*time.Millisecond)
+		assert.Empty(t, docs, "waits that run out apply nothing")
+
+		consul.put("myapp/workers", "4")
+		assert.Eventually(t, func() bool {
+			clock.Advance(maxBackoff)
+			return len(docs) > 0
+		}, 2*time.Second, 10*time.Millisecond)
+		assert.Equal(t, map[string]interface{}{"workers": "4"}, <-docs)
+
+		consul.restore(map[string]string{"myapp/workers": "3"}, 5)
+		assert.Equal(t, map[string]interface{}{"workers": "3"}, <-docs, "a restored store is applied")
+		served := consul.served()
+		time.Sleep(20 * time.Millisecond)
+		assert.Equal(t, served, consul.served(), "the watch pauses before blocking on the new index")
+		for len(consul.timedOut) > 0 {
+			<-consul.timedOut
+		}
+		clock.Advance(minBackoff)
+		<-consul.timedOut
+		assert.ErrorIs(t, stop(), context.Canceled)
+	})
+
+	t.Run("test watch does not spin", func(t *testing.T) {
+		consul := newFakeConsul(map[string]string{"myapp/workers": "2"})
+		consul.index = 0
+		srv := httptest.NewServer(consul)
+		defer srv.Close()
+		clock := configurable.NewManualClock(time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC))
+		p := New(srv.URL, "myapp/", WithToken("secret"), WithWait(time.Second), WithClock(clock))
+		docs, stop := watch(t, p)
+		assert.Equal(t, map[string]interface{}{"workers": "2"}, <-docs)
+		assert.Eventually(t, func() bool { return consul.served() == 2 }, time.Second, time.Millisecond)
+		time.Sleep(20 * time.Millisecond)
+		assert.Equal(t, 2, consul.served(), "a missing index pauses the watch")
+		clock.Advance(minBackoff)
+		assert.Eventually(t, func() bool { return consul.served() == 3 }, time.Second, time.Millisecond)
+		time.Sleep(20 * time.Millisecond)
+		clock.Advance(minBackoff)
+		assert.Equal(t, 3, consul.served(), "the pause doubles")
+		clock.Advance(minBackoff)
+		assert.Eventually(t, func() bool { return consul.served() == 4 }, time.Second, time.Millisecond)
+		assert.ErrorIs(t, stop(), context.Canceled)
+	})
+}
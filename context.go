@@ -0,0 +1,46 @@
+package configurable
+
+import (
+	"context"
+	"maps"
+)
+
+type overridesKey struct{}
+
+// WithOverrides returns a copy of ctx carrying per-request overrides, for
+// canary users or debug sessions that should see different values without
+// mutating global state. Keys use flag names (nested mappings are flattened
+// into dotted names). Overrides already in ctx are kept unless a key is
+// overridden again.
+func WithOverrides(ctx context.Context, overrides map[string]any) context.Context {
+	merged := make(map[string]any)
+	if parent, ok := ctx.Value(overridesKey{}).(map[string]any); ok {
+		for k, v := range parent {
+			merged[k] = v
+		}
+	}
+	for k, v := range overrides {
+		merged[k] = v
+	}
+	return context.WithValue(ctx, overridesKey{}, merged)
+}
+
+// ViewContext returns a View of the current values with the overrides carried
+// by ctx applied. Overrides naming no flag, or whose value cannot be converted
+// to the flag's type, are ignored.
+func (c *Configurable) ViewContext(ctx context.Context) View {
+	view := &snapshot{values: maps.Clone(c.current().values), fs: c.fs}
+	overrides, ok := ctx.Value(overridesKey{}).(map[string]any)
+	if !ok {
+		return view
+	}
+	known := make(map[string]interface{})
+	var unknown []string
+	c.flatten("", overrides, known, &unknown)
+	for name, raw := range known {
+		if value, err := c.convert(name, raw); err == nil {
+			view.values[name] = value
+		}
+	}
+	return view
+}
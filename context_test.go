@@ -0,0 +1,51 @@
+package configurable
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestViewContext(t *testing.T) {
+	os.Clearenv()
+
+	conf := newTestConfigurable(t)
+	conf.NewFloat64("sampling.rate", 0.01, "sampling rate")
+	conf.NewDuration("timeout", time.Second, "timeout")
+	conf.NewBool("debug", false, "debug")
+	conf.NewString("region", "us", "region")
+
+	t.Run("test no overrides", func(t *testing.T) {
+		view := conf.ViewContext(context.Background())
+		assert.Equal(t, 0.01, view.Float64("sampling.rate"))
+	})
+
+	t.Run("test overrides", func(t *testing.T) {
+		ctx := WithOverrides(context.Background(), map[string]any{
+			"sampling": map[string]any{"rate": 1.0},
+			"timeout":  5 * time.Second,
+			"debug":    "true",
+		})
+		view := conf.ViewContext(ctx)
+		assert.Equal(t, 1.0, view.Float64("sampling.rate"))
+		assert.Equal(t, 5*time.Second, view.Duration("timeout"))
+		assert.True(t, view.Bool("debug"))
+		assert.False(t, *conf.Bool("debug"))
+	})
+
+	t.Run("test nested contexts merge", func(t *testing.T) {
+		ctx := WithOverrides(context.Background(), map[string]any{"debug": true, "region": "eu"})
+		ctx = WithOverrides(ctx, map[string]any{"region": "ap"})
+		view := conf.ViewContext(ctx)
+		assert.True(t, view.Bool("debug"))
+		assert.Equal(t, "ap", view.String("region"))
+	})
+
+	t.Run("test invalid overrides are ignored", func(t *testing.T) {
+		ctx := WithOverrides(context.Background(), map[string]any{"timeout": "soon", "missing": 1})
+		assert.Equal(t, time.Second, conf.ViewContext(ctx).Duration("timeout"))
+	})
+}
@@ -0,0 +1,165 @@
+package configurable
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+// maxDebounces bounds how many quiet periods a steady stream of events can
+// postpone a debounced call by.
+const maxDebounces = 10
+
+// debouncer calls fn once events stop arriving for quiet, or at the latest
+// maxDebounces quiet periods after the first event it has not yet answered.
+type debouncer struct {
+	clock Clock
+	quiet time.Duration
+	fn    func()
+
+	mu    sync.Mutex
+	timer Timer
+	first time.Time
+}
+
+func newDebouncer(clock Clock, quiet time.Duration, fn func()) *debouncer {
+	return &debouncer{clock: clock, quiet: quiet, fn: fn}
+}
+
+// trigger records an event, postponing the call.
+func (d *debouncer) trigger() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	now := d.clock.Now()
+	if d.first.IsZero() {
+		d.first = now
+	}
+	wait := d.quiet
+	if deadline := d.first.Add(maxDebounces * d.quiet); now.Add(wait).After(deadline) {
+		wait = deadline.Sub(now)
+	}
+	if d.timer == nil {
+		d.timer = d.clock.AfterFunc(wait, d.fire)
+	} else {
+		d.timer.Reset(wait)
+	}
+}
+
+func (d *debouncer) fire() {
+	d.mu.Lock()
+	d.first = time.Time{}
+	d.mu.Unlock()
+	d.fn()
+}
+
+// stop drops a pending call.
+func (d *debouncer) stop() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.timer != nil {
+		d.timer.Stop()
+	}
+	d.first = time.Time{}
+}
+
+// SetChangeDebounce coalesces change notifications: rather than calling the
+// OnChange functions after every load, they are called once the values have
+// stopped changing for quiet, with the newest values and every flag changed
+// since the last call. A flag changed and changed back within the quiet
+// period is still listed. Zero, the default, notifies after every load.
+func (c *Configurable) SetChangeDebounce(quiet time.Duration) {
+	c.changeMu.Lock()
+	defer c.changeMu.Unlock()
+	if c.changeDebounce != nil {
+		c.changeDebounce.stop()
+		c.changeDebounce = nil
+	}
+	if quiet > 0 {
+		c.changeDebounce = newDebouncer(c.clock(), quiet, c.flushChanges)
+	}
+}
+
+// WithChangeDebounce is SetChangeDebounce.
+func WithChangeDebounce(quiet time.Duration) Option {
+	return func(c *Configurable) {
+		c.SetChangeDebounce(quiet)
+	}
+}
+
+// WithWatchDebounce holds the documents a watched provider delivers until it
+// has been quiet for quiet, then applies only the newest one, so a burst of
+// updates causes a single reload. The watch is told each update succeeded;
+// one rejected once applied is logged and listed by Problems.
+func WithWatchDebounce(quiet time.Duration) SourceOption {
+	return func(s *remoteSource) {
+		s.debounce = quiet
+	}
+}
+
+// deferChanges adds changed to the pending notification and reports whether
+// notifications are debounced.
+func (c *Configurable) deferChanges(changed []string) bool {
+	c.changeMu.Lock()
+	defer c.changeMu.Unlock()
+	if c.changeDebounce == nil {
+		return false
+	}
+	if c.pendingChanges == nil {
+		c.pendingChanges = make(map[string]struct{})
+	}
+	for _, name := range changed {
+		c.pendingChanges[name] = struct{}{}
+	}
+	c.changeDebounce.trigger()
+	return true
+}
+
+// flushChanges calls the change functions with the pending notification.
+func (c *Configurable) flushChanges() {
+	c.changeMu.Lock()
+	changed := make([]string, 0, len(c.pendingChanges))
+	for name := range c.pendingChanges {
+		changed = append(changed, name)
+	}
+	c.pendingChanges = nil
+	funcs := slices.Clone(c.changeFuncs)
+	c.changeMu.Unlock()
+	if len(changed) == 0 {
+		return
+	}
+	sort.Strings(changed)
+	// Serialize calls, which the timer may otherwise overlap.
+	c.flushMu.Lock()
+	defer c.flushMu.Unlock()
+	view := c.current()
+	for _, fn := range funcs {
+		fn(view, changed)
+	}
+}
+
+// debounceApply wraps apply so that it runs on the newest document once
+// quiet has passed since the last one. A document still held when ctx is
+// done is dropped.
+func (c *Configurable) debounceApply(ctx context.Context, quiet time.Duration, apply func(map[string]interface{}) error) func(map[string]interface{}) error {
+	var mu sync.Mutex
+	var pending map[string]interface{}
+	d := newDebouncer(c.clock(), quiet, func() {
+		mu.Lock()
+		data := pending
+		pending = nil
+		mu.Unlock()
+		if data != nil && ctx.Err() == nil {
+			_ = apply(data)
+		}
+	})
+	context.AfterFunc(ctx, d.stop)
+	return func(data map[string]interface{}) error {
+		mu.Lock()
+		pending = data
+		mu.Unlock()
+		d.trigger()
+		return nil
+	}
+}
@@ -0,0 +1,116 @@
+package configurable
+
+import (
+	"context"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDebounce(t *testing.T) {
+	os.Clearenv()
+
+	t.Run("test debouncer", func(t *testing.T) {
+		var calls atomic.Int32
+		d := newDebouncer(realClock{}, 20*time.Millisecond, func() { calls.Add(1) })
+		for i := 0; i < 5; i++ {
+			d.trigger()
+		}
+		assert.Eventually(t, func() bool { return calls.Load() == 1 }, time.Second, time.Millisecond)
+		time.Sleep(40 * time.Millisecond)
+		assert.Equal(t, int32(1), calls.Load())
+
+		d.trigger()
+		d.stop()
+		time.Sleep(40 * time.Millisecond)
+		assert.Equal(t, int32(1), calls.Load())
+	})
+
+	t.Run("test steady stream", func(t *testing.T) {
+		var calls atomic.Int32
+		d := newDebouncer(realClock{}, 5*time.Millisecond, func() { calls.Add(1) })
+		defer d.stop()
+		deadline := time.Now().Add(200 * time.Millisecond)
+		for time.Now().Before(deadline) && calls.Load() == 0 {
+			d.trigger()
+			time.Sleep(time.Millisecond)
+		}
+		assert.NotZero(t, calls.Load())
+	})
+
+	t.Run("test change debounce", func(t *testing.T) {
+		conf := newTestConfigurable(t)
+		conf.NewInt("port", 80, "port")
+		conf.NewString("host", "localhost", "host")
+		conf.SetArgs([]string{})
+		assert.NoError(t, conf.Parse(""))
+		conf.SetChangeDebounce(20 * time.Millisecond)
+
+		var mu sync.Mutex
+		var got [][]string
+		var port int
+		conf.OnChange(func(v View, changed []string) {
+			mu.Lock()
+			defer mu.Unlock()
+			got = append(got, changed)
+			port = v.Int("port")
+		})
+		assert.NoError(t, conf.LoadData("json", []byte(`{"port": 8080}`)))
+		assert.NoError(t, conf.LoadData("json", []byte(`{"port": 8081}`)))
+		assert.NoError(t, conf.LoadData("json", []byte(`{"host": "example.com"}`)))
+		assert.Eventually(t, func() bool {
+			mu.Lock()
+			defer mu.Unlock()
+			return len(got) == 1
+		}, time.Second, time.Millisecond)
+		mu.Lock()
+		assert.Equal(t, [][]string{{"host", "port"}}, got)
+		assert.Equal(t, 8081, port)
+		mu.Unlock()
+	})
+
+	t.Run("test registering during notifications", func(t *testing.T) {
+		conf := newTestConfigurable(t)
+		conf.NewInt("port", 80, "port")
+		conf.SetChangeDebounce(time.Millisecond)
+		var calls atomic.Int32
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for i := 0; i < 100; i++ {
+				conf.OnChange(func(View, []string) { calls.Add(1) })
+				time.Sleep(100 * time.Microsecond)
+			}
+		}()
+		for port := 8080; port < 8100; port++ {
+			assert.NoError(t, conf.Set("port", port))
+			time.Sleep(500 * time.Microsecond)
+		}
+		<-done
+		assert.Eventually(t, func() bool { return calls.Load() > 0 }, time.Second, time.Millisecond)
+	})
+
+	t.Run("test watch debounce", func(t *testing.T) {
+		conf := newTestConfigurable(t)
+		conf.NewInt("port", 80, "port")
+		p := &pushProvider{fakeProvider: fakeProvider{name: "push"}, docs: make(chan map[string]interface{}), results: make(chan error)}
+		conf.AddProvider(p, WithWatchDebounce(20*time.Millisecond))
+		var notified atomic.Int32
+		conf.OnChange(func(View, []string) { notified.Add(1) })
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go conf.WatchProviders(ctx)
+
+		for _, port := range []int{8080, 8081, 8082} {
+			p.docs <- map[string]interface{}{"port": port}
+			assert.NoError(t, <-p.results)
+		}
+		assert.Eventually(t, func() bool { return conf.View().Int("port") == 8082 }, time.Second, time.Millisecond)
+		assert.Equal(t, int32(1), notified.Load())
+	})
+}
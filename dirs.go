@@ -0,0 +1,48 @@
+package configurable
+
+import "path/filepath"
+
+// DefaultConfigDir returns the directory app should keep its configuration
+// in: $XDG_CONFIG_HOME/app (~/.config/app) on Linux and other Unix systems,
+// ~/Library/Application Support/app on macOS and %APPDATA%\app on Windows. It
+// is meant as a flag default:
+//
+//	dir := config.NewString("config-dir", configurable.DefaultConfigDir("myapp"), "configuration directory", configurable.WithPath())
+//
+// When the platform directory cannot be determined, as when $HOME is unset,
+// it returns app, a path relative to the working directory.
+func DefaultConfigDir(app string) string {
+	return appDir(configDir, app)
+}
+
+// DefaultCacheDir returns the directory for app's disposable cached data:
+// $XDG_CACHE_HOME/app (~/.cache/app), ~/Library/Caches/app on macOS and
+// %LOCALAPPDATA%\app on Windows. It falls back like DefaultConfigDir.
+func DefaultCacheDir(app string) string {
+	return appDir(cacheDir, app)
+}
+
+// DefaultDataDir returns the directory for app's persistent data:
+// $XDG_DATA_HOME/app (~/.local/share/app), ~/Library/Application Support/app
+// on macOS and %LOCALAPPDATA%\app on Windows. It falls back like
+// DefaultConfigDir.
+func DefaultDataDir(app string) string {
+	return appDir(dataDir, app)
+}
+
+// userDirKind names one of the per-user base directories.
+type userDirKind int
+
+const (
+	configDir userDirKind = iota
+	cacheDir
+	dataDir
+)
+
+func appDir(kind userDirKind, app string) string {
+	base, err := userDir(kind)
+	if err != nil || base == "" {
+		return app
+	}
+	return filepath.Join(base, app)
+}
@@ -0,0 +1,26 @@
+//go:build linux
+
+package configurable
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultDirs(t *testing.T) {
+	t.Setenv("HOME", "/home/me")
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv("XDG_CACHE_HOME", "/var/cache/me")
+	t.Setenv("XDG_DATA_HOME", "")
+	assert.Equal(t, "/home/me/.config/myapp", DefaultConfigDir("myapp"))
+	assert.Equal(t, "/var/cache/me/myapp", DefaultCacheDir("myapp"))
+	assert.Equal(t, "/home/me/.local/share/myapp", DefaultDataDir("myapp"))
+
+	t.Setenv("XDG_DATA_HOME", "/data")
+	assert.Equal(t, filepath.Join("/data", "myapp"), DefaultDataDir("myapp"))
+
+	t.Setenv("HOME", "")
+	assert.Equal(t, "myapp", DefaultConfigDir("myapp"))
+}
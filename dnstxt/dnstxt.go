@@ -0,0 +1,172 @@
+// Package dnstxt is a configurable.Provider reading configuration from the
+// TXT records of a DNS name, a lightweight channel for hosts that can resolve
+// names but cannot reach a configuration service.
+//
+// Each TXT record holds one key=value pair, the key being a flag name:
+//
+//	config.edge.example.com. 300 IN TXT "log-level=debug"
+//	config.edge.example.com. 300 IN TXT "limits.rate=50"
+//
+// As a configurable.Watcher the provider queries the name again when the
+// records' TTL runs out and applies the answer if it changed.
+package dnstxt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/andreimerlescu/configurable"
+	"github.com/miekg/dns"
+)
+
+// DefaultMinRefresh is the shortest interval between queries when the
+// records' TTL is shorter.
+const DefaultMinRefresh = 30 * time.Second
+
+// Provider reads configuration from the TXT records of a DNS name.
+type Provider struct {
+	name       string
+	server     string
+	minRefresh time.Duration
+	client     *dns.Client
+	clock      configurable.Clock
+
+	mu  sync.Mutex
+	ttl time.Duration
+}
+
+// Option configures a Provider.
+type Option func(*Provider)
+
+// WithServer queries addr ("host:port") instead of the first nameserver in
+// /etc/resolv.conf.
+func WithServer(addr string) Option {
+	return func(p *Provider) {
+		p.server = addr
+	}
+}
+
+// WithMinRefresh sets the shortest interval between queries. The default is
+// DefaultMinRefresh.
+func WithMinRefresh(d time.Duration) Option {
+	return func(p *Provider) {
+		p.minRefresh = d
+	}
+}
+
+// WithClock sets the clock Watch waits on between queries, so tests can
+// drive it with a configurable.ManualClock. The default is the system clock.
+func WithClock(clock configurable.Clock) Option {
+	return func(p *Provider) {
+		p.clock = clock
+	}
+}
+
+// New returns a Provider for the TXT records of name.
+func New(name string, opts ...Option) *Provider {
+	p := &Provider{name: dns.Fqdn(name), minRefresh: DefaultMinRefresh, client: &dns.Client{}, clock: configurable.SystemClock()}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+func (p *Provider) Name() string {
+	return "dns:" + strings.TrimSuffix(p.name, ".")
+}
+
+// Load queries the TXT records and returns their key=value pairs.
+func (p *Provider) Load(ctx context.Context) (map[string]interface{}, error) {
+	server, err := p.resolver()
+	if err != nil {
+		return nil, err
+	}
+	msg := new(dns.Msg)
+	msg.SetQuestion(p.name, dns.TypeTXT)
+	resp, _, err := p.client.ExchangeContext(ctx, msg, server)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Rcode != dns.RcodeSuccess {
+		return nil, fmt.Errorf("%s: %s", p.name, dns.RcodeToString[resp.Rcode])
+	}
+	data := make(map[string]interface{})
+	var ttl uint32
+	found := false
+	for _, rr := range resp.Answer {
+		txt, ok := rr.(*dns.TXT)
+		if !ok {
+			continue
+		}
+		// Records longer than 255 bytes are split into several strings.
+		record := strings.Join(txt.Txt, "")
+		key, value, ok := strings.Cut(record, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("%s: TXT record %q is not key=value", p.name, record)
+		}
+		data[key] = value
+		if !found || txt.Hdr.Ttl < ttl {
+			ttl = txt.Hdr.Ttl
+		}
+		found = true
+	}
+	if !found {
+		return nil, fmt.Errorf("%s: no TXT records", p.name)
+	}
+	p.mu.Lock()
+	p.ttl = time.Duration(ttl) * time.Second
+	p.mu.Unlock()
+	return data, nil
+}
+
+// Watch queries the records again each time their TTL runs out, calling
+// apply with the first answer and then whenever the answer changes. Failed
+// queries are retried at the next refresh.
+func (p *Provider) Watch(ctx context.Context, apply func(map[string]interface{}) error) error {
+	var last map[string]interface{}
+	for {
+		refreshed := make(chan struct{})
+		timer := p.clock.AfterFunc(p.refresh(), func() { close(refreshed) })
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-refreshed:
+		}
+		data, err := p.Load(ctx)
+		if err != nil || reflect.DeepEqual(data, last) {
+			continue
+		}
+		if apply(data) == nil {
+			last = data
+		}
+	}
+}
+
+// refresh returns how long to wait before the next query.
+func (p *Provider) refresh() time.Duration {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return max(p.ttl, p.minRefresh)
+}
+
+// resolver returns the server to query.
+func (p *Provider) resolver() (string, error) {
+	if p.server != "" {
+		return p.server, nil
+	}
+	conf, err := dns.ClientConfigFromFile("/etc/resolv.conf")
+	if err != nil {
+		return "", err
+	}
+	if len(conf.Servers) == 0 {
+		return "", errors.New("no nameservers in /etc/resolv.conf")
+	}
+	return net.JoinHostPort(conf.Servers[0], conf.Port), nil
+}
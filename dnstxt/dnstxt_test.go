@@ -0,0 +1,96 @@
+package dnstxt
+
+import (
+	"context"
+	"flag"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/andreimerlescu/configurable"
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+)
+
+// zone serves TXT records for config.example.com from a local server.
+type zone struct {
+	mu      sync.Mutex
+	records []string
+	ttl     uint32
+}
+
+func (z *zone) set(ttl uint32, records ...string) {
+	z.mu.Lock()
+	defer z.mu.Unlock()
+	z.ttl, z.records = ttl, records
+}
+
+func (z *zone) ServeDNS(w dns.ResponseWriter, r *dns.Msg) {
+	z.mu.Lock()
+	defer z.mu.Unlock()
+	m := new(dns.Msg)
+	m.SetReply(r)
+	if r.Question[0].Name != "config.example.com." {
+		m.Rcode = dns.RcodeNameError
+	}
+	for _, record := range z.records {
+		if m.Rcode != dns.RcodeSuccess {
+			break
+		}
+		m.Answer = append(m.Answer, &dns.TXT{
+			Hdr: dns.RR_Header{Name: r.Question[0].Name, Rrtype: dns.TypeTXT, Class: dns.ClassINET, Ttl: z.ttl},
+			Txt: []string{record},
+		})
+	}
+	w.WriteMsg(m)
+}
+
+func serve(t *testing.T, z *zone) string {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	srv := &dns.Server{PacketConn: pc, Handler: z}
+	go srv.ActivateAndServe()
+	t.Cleanup(func() { srv.Shutdown() })
+	return pc.LocalAddr().String()
+}
+
+func TestProvider(t *testing.T) {
+	z := &zone{}
+	z.set(300, "log-level=debug", "limits.rate=50")
+	addr := serve(t, z)
+
+	t.Run("test load", func(t *testing.T) {
+		p := New("config.example.com", WithServer(addr))
+		assert.Equal(t, "dns:config.example.com", p.Name())
+		data, err := p.Load(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{"log-level": "debug", "limits.rate": "50"}, data)
+		assert.Equal(t, 300*time.Second, p.refresh())
+
+		_, err = New("missing.example.com", WithServer(addr)).Load(context.Background())
+		assert.ErrorContains(t, err, "NXDOMAIN")
+	})
+
+	t.Run("test watch", func(t *testing.T) {
+		z.set(0, "log-level=info")
+		conf := configurable.New(configurable.WithFlagSet(flag.NewFlagSet(t.Name(), flag.ContinueOnError)))
+		level := conf.NewString("log-level", "warn", "log level")
+		clock := configurable.NewManualClock(time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC))
+		conf.AddProvider(New("config.example.com", WithServer(addr), WithClock(clock)))
+		assert.NoError(t, conf.LoadProviders(context.Background()))
+		assert.Equal(t, "info", conf.View().String("log-level"))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error)
+		go func() { done <- conf.WatchProviders(ctx) }()
+		z.set(0, "log-level=error")
+		assert.Eventually(t, func() bool {
+			clock.Advance(DefaultMinRefresh)
+			return conf.View().String("log-level") == "error"
+		}, 2*time.Second, 10*time.Millisecond, "the refresh waits on the clock")
+		cancel()
+		assert.ErrorIs(t, <-done, context.Canceled)
+		assert.Equal(t, "error", *level)
+	})
+}
@@ -0,0 +1,57 @@
+package configurable
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+)
+
+// Docs renders a Markdown reference for the registered flags, including the
+// long-form help and examples attached with WithHelp and WithExample.
+func (c *Configurable) Docs() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# %s configuration\n", c.fs.Name())
+	c.visitAll(func(f *flag.Flag) {
+		if !c.listed(f.Name) {
+			return
+		}
+		fmt.Fprintf(&sb, "\n## `--%s`\n\n", f.Name)
+		if f.Usage != "" {
+			fmt.Fprintf(&sb, "%s\n\n", f.Usage)
+		}
+		fmt.Fprintf(&sb, "Default: `%s`\n", f.DefValue)
+		m, ok := c.meta[f.Name]
+		if ok && m.unit != "" {
+			fmt.Fprintf(&sb, "\nUnit: %s; values such as `%s` are converted.\n", m.unit, m.unit.example())
+		}
+		if ok && m.bounds != nil {
+			fmt.Fprintf(&sb, "\nRange: `%s` to `%s`\n", formatBound(m.bounds.min), formatBound(m.bounds.max))
+		}
+		if ok && m.text != nil {
+			fmt.Fprintf(&sb, "\nMust be %s.\n", m.text.describe())
+		}
+		if ok && m.short != "" {
+			fmt.Fprintf(&sb, "\nShort form: `-%s`\n", m.short)
+		}
+		if isBoolFlag(f) {
+			fmt.Fprintf(&sb, "\nDisable with `--%s%s`.\n", negationPrefix, f.Name)
+		}
+		if ok && m.deprecated != "" {
+			fmt.Fprintf(&sb, "\n**Deprecated:** %s\n", m.deprecated)
+		}
+		if !ok {
+			return
+		}
+		if help := strings.TrimSpace(m.help); help != "" {
+			fmt.Fprintf(&sb, "\n%s\n", help)
+		}
+		if len(m.examples) > 0 {
+			sb.WriteString("\nExamples:\n\n```\n")
+			for _, example := range m.examples {
+				fmt.Fprintf(&sb, "%s\n", example)
+			}
+			sb.WriteString("```\n")
+		}
+	})
+	return sb.String()
+}
@@ -0,0 +1,51 @@
+package configurable
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// LoadDownwardAPI reads a labels or annotations file mounted by the
+// Kubernetes downward API, in which each line is key="value", into the map
+// flag name. The entries are merged into the flag's value as a document's
+// would be.
+func (c *Configurable) LoadDownwardAPI(name, filename string) error {
+	if _, ok := c.flags[name].(*MapFlag); !ok {
+		return fmt.Errorf("%s is not a map flag", name)
+	}
+	data, err := readFile(filename, c.parseOptions.MaxFileSize)
+	if err != nil {
+		return err
+	}
+	entries, err := parseDownwardAPI(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", filename, err)
+	}
+	return c.setValuesFromMap(SourceFile, filename, map[string]interface{}{name: entries})
+}
+
+// parseDownwardAPI parses key="value" lines, the values quoted as Go
+// strings.
+func parseDownwardAPI(data []byte) (map[string]interface{}, error) {
+	entries := make(map[string]interface{})
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		key, quoted, ok := strings.Cut(line, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("line %d: expected key=\"value\"", n)
+		}
+		value, err := strconv.Unquote(quoted)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s: %w", n, key, err)
+		}
+		entries[key] = value
+	}
+	return entries, scanner.Err()
+}
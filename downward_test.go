@@ -0,0 +1,37 @@
+package configurable
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadDownwardAPI(t *testing.T) {
+	os.Clearenv()
+	dir := t.TempDir()
+
+	t.Run("test labels", func(t *testing.T) {
+		path := filepath.Join(dir, "labels")
+		data := "app=\"api\"\npod-template-hash=\"7c9f\"\n\nteam=\"core \\\"infra\\\"\"\n"
+		assert.NoError(t, os.WriteFile(path, []byte(data), 0644))
+
+		conf := newTestConfigurable(t)
+		labels := conf.NewMap("labels", map[string]string{"env": "prod"}, "pod labels")
+		assert.NoError(t, conf.LoadDownwardAPI("labels", path))
+		assert.Equal(t, map[string]string{"env": "prod", "app": "api", "pod-template-hash": "7c9f", "team": `core "infra"`}, *labels)
+		assert.Equal(t, "file "+path, conf.sources["labels"].String())
+	})
+
+	t.Run("test malformed", func(t *testing.T) {
+		path := filepath.Join(dir, "annotations")
+		assert.NoError(t, os.WriteFile(path, []byte("a=\"1\"\nb=2\n"), 0644))
+
+		conf := newTestConfigurable(t)
+		annotations := conf.NewMap("annotations", nil, "pod annotations")
+		assert.ErrorContains(t, conf.LoadDownwardAPI("annotations", path), "line 2: b: ")
+		assert.Empty(t, *annotations)
+		assert.EqualError(t, conf.LoadDownwardAPI("missing", path), "missing is not a map flag")
+	})
+}
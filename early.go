@@ -0,0 +1,84 @@
+package configurable
+
+import "strings"
+
+// WithEarly marks a flag that Parse reads from the command line before it
+// loads any file or provider, such as one naming the file, a profile or an
+// environment prefix. PreLoad hooks and providers can then depend on it.
+func WithEarly() FlagOption {
+	return func(m *flagMeta) {
+		m.early = true
+	}
+}
+
+// parseEarly parses the early flags in args and returns the arguments for
+// ParseArgs once the files are loaded. Those repeat the early scalar flags,
+// so the command line still overrides a file setting them.
+func (c *Configurable) parseEarly(args []string) ([]string, error) {
+	if !c.hasEarly() {
+		return args, nil
+	}
+	args, err := c.normalizeArgs(args)
+	if err != nil {
+		return nil, c.fail(err)
+	}
+	var early, rest []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if len(arg) < 2 || arg[0] != '-' || arg == "--" {
+			rest = append(rest, args[i:]...)
+			break
+		}
+		name, _, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		f := c.fs.Lookup(name)
+		tokens := args[i : i+1]
+		if f != nil && !hasValue && !isBoolFlag(f) && i+1 < len(args) {
+			tokens = args[i : i+2]
+			i++
+		}
+		if m, ok := c.meta[name]; ok && m.early {
+			early = append(early, tokens...)
+			if c.accumulates(f) {
+				continue
+			}
+		}
+		rest = append(rest, tokens...)
+	}
+	if len(early) == 0 {
+		return rest, nil
+	}
+	return rest, c.parseNormalized(early)
+}
+
+// hasEarly reports whether any flag was registered WithEarly.
+func (c *Configurable) hasEarly() bool {
+	for _, m := range c.meta {
+		if m.early {
+			return true
+		}
+	}
+	return false
+}
+
+// wantsHelp reports whether args ask for help, which Parse gives before it
+// loads anything.
+func (c *Configurable) wantsHelp(args []string) bool {
+	if c.wantsFullHelp(args) {
+		return true
+	}
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if len(arg) < 2 || arg[0] != '-' || arg == "--" {
+			return false
+		}
+		name, _, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		f := c.fs.Lookup(name)
+		if f == nil && (name == "h" || name == "help") {
+			return true
+		}
+		if f != nil && !hasValue && !isBoolFlag(f) {
+			i++
+		}
+	}
+	return false
+}
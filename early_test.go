@@ -0,0 +1,36 @@
+package configurable
+
+import (
+	"flag"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithEarly(t *testing.T) {
+	os.Clearenv()
+	conf := newTestConfigurable(t)
+	profile := conf.NewString("profile", "dev", "profile", WithEarly())
+	tags := conf.NewList("tags", nil, "tags", WithEarly())
+	port := conf.NewInt("port", 8080, "port")
+	var seen []string
+	conf.AddHooks(Hooks{PreLoad: func(source string) error {
+		seen = append(seen, *profile)
+		return nil
+	}})
+	conf.AddProvider(&fakeProvider{name: "remote", data: map[string]interface{}{"port": 9090}})
+	conf.SetArgs([]string{"-port=7070", "-profile", "prod", "-tags", "a", "-tags", "b"})
+	assert.NoError(t, conf.Parse(""))
+	assert.Equal(t, []string{"prod"}, seen)
+	assert.Equal(t, "prod", *profile)
+	assert.Equal(t, []string{"a", "b"}, *tags)
+	assert.Equal(t, 9090, *port)
+}
+
+func TestParseHelpBeforeLoading(t *testing.T) {
+	conf := newTestConfigurable(t)
+	conf.EnableConfigFlag()
+	conf.SetArgs([]string{"--config", "missing.json", "-h"})
+	assert.ErrorIs(t, conf.Parse(""), flag.ErrHelp)
+}
@@ -0,0 +1,174 @@
+package configurable
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// envValue returns the environment variable set for the flag name and the
+// raw value it holds. List flags read a comma-separated list, in which "\,"
+// is a literal comma, or indexed variables (MYAPP_TAGS_0, MYAPP_TAGS_1, ...)
+// when the variable itself is unset. Map flags read k1=v1,k2=v2 pairs, with
+// the same escapes, or a JSON object.
+func (c *Configurable) envValue(name string) (key string, raw interface{}, ok bool) {
+	if key, value, ok := c.lookupFlagEnv(name); ok {
+		switch c.flags[name].(type) {
+		case *bool:
+			if value == "" && c.parseOptions.EnvPresenceBools {
+				return key, "true", true
+			}
+		case *ListFlag, typedList:
+			return key, splitEscaped(value), true
+		case *MapFlag:
+			if m, err := c.parseEnvMap(value); err == nil {
+				c.addPrefixed(name, m)
+				return key, m, true
+			}
+		}
+		return key, value, true
+	}
+	switch c.flags[name].(type) {
+	case *ListFlag, typedList:
+		for _, key := range c.envVars(name) {
+			if items := c.indexedEnv(key); items != nil {
+				return key + "_0", items, true
+			}
+		}
+	case *MapFlag:
+		m := make(map[string]interface{})
+		if c.addPrefixed(name, m) {
+			return c.meta[name].envMapPrefix + "*", m, true
+		}
+	}
+	return "", nil, false
+}
+
+// BindEnv makes the registered flag name read the first of vars that is
+// set, as WithEnv does at registration, such as a new variable name followed
+// by the legacy one it replaces. It fails if another flag reads one of vars.
+func (c *Configurable) BindEnv(name string, vars ...string) error {
+	if _, ok := c.flags[name]; !ok {
+		return fmt.Errorf("no flag named %s", name)
+	}
+	if len(vars) == 0 {
+		return errors.New("no environment variables to bind")
+	}
+	m := c.metaFor(name)
+	bound := m.env
+	m.env = append(slices.Clip(m.env), vars...)
+	if err := c.envCollision(name); err != nil {
+		m.env = bound
+		return err
+	}
+	return nil
+}
+
+// BindEnvPrefixToMap makes every environment variable starting with prefix an
+// entry of the map flag name, keyed by the rest of the variable's name: with
+// prefix "MYAPP_HEADER_", MYAPP_HEADER_X_TRACE=1 sets the entry X_TRACE. The
+// entries are added to those in the flag's own variable, if it is set.
+func (c *Configurable) BindEnvPrefixToMap(name, prefix string) error {
+	if _, ok := c.flags[name].(*MapFlag); !ok {
+		return fmt.Errorf("%s is not a map flag", name)
+	}
+	if prefix == "" {
+		return errors.New("empty env prefix")
+	}
+	c.metaFor(name).envMapPrefix = prefix
+	return nil
+}
+
+// addPrefixed adds to m the variables under the prefix bound to the map flag
+// name, reporting whether there were any.
+func (c *Configurable) addPrefixed(name string, m map[string]interface{}) bool {
+	meta, ok := c.meta[name]
+	if !ok || meta.envMapPrefix == "" {
+		return false
+	}
+	found := false
+	for key, value := range c.environ() {
+		if k, ok := strings.CutPrefix(key, meta.envMapPrefix); ok && k != "" {
+			m[k] = c.parseOptions.EnvTrim.apply(value)
+			found = true
+		}
+	}
+	return found
+}
+
+// environ returns every environment definition visible to lookupEnv, with
+// the same precedence.
+func (c *Configurable) environ() map[string]string {
+	c.envMu.RLock()
+	defer c.envMu.RUnlock()
+	env := make(map[string]string, len(c.envFile)+len(c.env))
+	for k, v := range c.envFile {
+		env[k] = v
+	}
+	for _, kv := range environ() {
+		if k, v, ok := strings.Cut(kv, "="); ok {
+			env[k] = v
+		}
+	}
+	for k, v := range c.env {
+		env[k] = v
+	}
+	return env
+}
+
+// indexedEnv collects key_0, key_1, ... up to the first one that is unset.
+func (c *Configurable) indexedEnv(key string) []string {
+	var items []string
+	for i := 0; ; i++ {
+		value, ok := c.lookupEnv(key + "_" + strconv.Itoa(i))
+		if !ok {
+			return items
+		}
+		items = append(items, c.parseOptions.EnvTrim.apply(value))
+	}
+}
+
+// parseEnvMap parses a map flag's environment value.
+func (c *Configurable) parseEnvMap(value string) (map[string]interface{}, error) {
+	m := make(map[string]interface{})
+	if strings.HasPrefix(strings.TrimSpace(value), "{") {
+		err := unmarshalJSON([]byte(value), &m)
+		return m, err
+	}
+	for _, pair := range splitEscaped(value) {
+		k, v, found := strings.Cut(pair, "=")
+		if !found {
+			return nil, errors.New("invalid map item: " + pair)
+		}
+		m[k] = v
+	}
+	return m, nil
+}
+
+// splitEscaped splits s at commas not preceded by a backslash. A backslash
+// makes the character after it literal.
+func splitEscaped(s string) []string {
+	items := []string{}
+	if s == "" {
+		return items
+	}
+	var item strings.Builder
+	escaped := false
+	for _, r := range s {
+		switch {
+		case escaped:
+			item.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == ',':
+			items = append(items, item.String())
+			item.Reset()
+		default:
+			item.WriteRune(r)
+		}
+	}
+	return append(items, item.String())
+}
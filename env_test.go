@@ -0,0 +1,142 @@
+package configurable
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnvListsAndMaps(t *testing.T) {
+	os.Clearenv()
+
+	t.Run("test escaped lists", func(t *testing.T) {
+		conf := newTestConfigurable(t)
+		conf.NewList("tags", []string{"default"}, "tags")
+		conf.SetEnv("tags", `a\,b,c\\,`)
+		assert.Equal(t, []string{"a,b", `c\`, ""}, *conf.List("tags"))
+		assert.Equal(t, []string{"a,b", `c\`, ""}, *conf.List("tags"), "repeated lookups replace the list")
+	})
+
+	t.Run("test indexed lists", func(t *testing.T) {
+		conf := newTestConfigurable(t)
+		conf.envPrefix = "MYAPP"
+		tags := conf.NewList("tags", nil, "tags")
+		conf.SetEnv("MYAPP_TAGS_0", "a,b")
+		conf.SetEnv("MYAPP_TAGS_1", "c")
+		conf.SetEnv("MYAPP_TAGS_3", "skipped")
+		conf.SetArgs([]string{})
+		assert.NoError(t, conf.Parse(""))
+		assert.Equal(t, []string{"a,b", "c"}, *tags)
+		assert.Equal(t, "env MYAPP_TAGS_0", conf.sources["tags"].String())
+	})
+
+	t.Run("test maps", func(t *testing.T) {
+		conf := newTestConfigurable(t)
+		conf.NewMap("labels", nil, "labels")
+		conf.SetEnv("labels", `team=core,note=a\,b`)
+		assert.Equal(t, map[string]string{"team": "core", "note": "a,b"}, *conf.Map("labels"))
+
+		conf = newTestConfigurable(t)
+		conf.NewMap("labels", nil, "labels")
+		conf.SetEnv("labels", `{"team": "edge", "tier": 1}`)
+		assert.Equal(t, map[string]string{"team": "edge", "tier": "1"}, *conf.Map("labels"))
+	})
+
+	t.Run("test invalid map is ignored", func(t *testing.T) {
+		conf := newTestConfigurable(t)
+		conf.NewMap("labels", map[string]string{"team": "core"}, "labels")
+		conf.SetEnv("labels", `{"team": `)
+		assert.Equal(t, map[string]string{"team": "core"}, *conf.Map("labels"))
+	})
+}
+
+func TestBindEnvPrefixToMap(t *testing.T) {
+	os.Clearenv()
+
+	t.Run("test prefixed variables", func(t *testing.T) {
+		conf := newTestConfigurable(t)
+		conf.NewMap("header", map[string]string{"Accept": "*/*"}, "headers")
+		assert.NoError(t, conf.BindEnvPrefixToMap("header", "MYAPP_HEADER_"))
+		conf.SetEnv("MYAPP_HEADER_X_TRACE", "1")
+		conf.SetEnv("MYAPP_HEADER_AUTHORIZATION", "Bearer t")
+		conf.SetEnv("MYAPP_HEADER_", "ignored")
+		conf.SetEnv("MYAPP_OTHER", "ignored")
+		assert.Equal(t, map[string]string{"X_TRACE": "1", "AUTHORIZATION": "Bearer t"}, *conf.Map("header"))
+		assert.Equal(t, "env MYAPP_HEADER_*", conf.sources["header"].String())
+		assert.Equal(t, []string{"header", "MYAPP_HEADER_*"}, conf.Flags()[0].Env)
+	})
+
+	t.Run("test combined with the flag's variable", func(t *testing.T) {
+		conf := newTestConfigurable(t)
+		conf.NewMap("header", nil, "headers")
+		assert.NoError(t, conf.BindEnvPrefixToMap("header", "H_"))
+		conf.SetEnv("header", "Accept=text/plain,X_TRACE=0")
+		conf.SetEnv("H_X_TRACE", "1")
+		assert.Equal(t, map[string]string{"Accept": "text/plain", "X_TRACE": "1"}, *conf.Map("header"))
+	})
+
+	t.Run("test invalid bindings", func(t *testing.T) {
+		conf := newTestConfigurable(t)
+		conf.NewList("tags", nil, "tags")
+		assert.EqualError(t, conf.BindEnvPrefixToMap("tags", "TAG_"), "tags is not a map flag")
+		assert.Error(t, conf.BindEnvPrefixToMap("missing", "X_"))
+	})
+}
+
+func TestEnvPresenceBools(t *testing.T) {
+	os.Clearenv()
+
+	conf := newTestConfigurable(t)
+	conf.parseOptions.EnvPresenceBools = true
+	debug := conf.NewBool("debug", false, "debug")
+	trace := conf.NewBool("trace", true, "trace")
+	quiet := conf.NewBool("quiet", false, "quiet")
+	name := conf.NewString("name", "app", "name")
+	conf.SetEnv("debug", "")
+	conf.SetEnv("trace", "false")
+	conf.SetEnv("name", "")
+	conf.SetArgs([]string{"-quiet"})
+	assert.NoError(t, conf.Parse(""))
+	assert.True(t, *debug, "an empty variable turns the flag on")
+	assert.False(t, *trace, "a value is parsed as usual")
+	assert.True(t, *quiet, "an unset variable leaves the flag alone")
+	assert.Equal(t, "", *name, "other types are unaffected")
+
+	t.Run("test off by default", func(t *testing.T) {
+		conf := newTestConfigurable(t)
+		debug := conf.NewBool("debug", false, "debug")
+		conf.SetEnv("debug", "")
+		conf.SetArgs([]string{})
+		assert.NoError(t, conf.Parse(""))
+		assert.False(t, *debug)
+	})
+}
+
+func TestBindEnv(t *testing.T) {
+	os.Clearenv()
+
+	conf := newTestConfigurable(t)
+	host := conf.NewString("db-host", "localhost", "database host")
+	port := conf.NewInt("db-port", 5432, "database port", WithEnv("PGPORT"))
+	assert.NoError(t, conf.BindEnv("db-host", "DB_HOST", "DATABASE_HOST"))
+	assert.NoError(t, conf.BindEnv("db-port", "DB_PORT"))
+	conf.SetEnv("db-host", "ignored")
+	conf.SetEnv("DATABASE_HOST", "legacy")
+	conf.SetEnv("DB_PORT", "6432")
+	conf.SetArgs([]string{})
+	assert.NoError(t, conf.Parse(""))
+	assert.Equal(t, "legacy", *host, "the legacy name is read when the new one is unset")
+	assert.Equal(t, 6432, *port)
+
+	conf.SetEnv("DB_HOST", "new")
+	conf.SetEnv("PGPORT", "7432")
+	assert.NoError(t, conf.Parse(""))
+	assert.Equal(t, "new", *host, "the first variable set wins")
+	assert.Equal(t, 7432, *port)
+
+	assert.EqualError(t, conf.BindEnv("db-port", "OTHER", "DB_HOST"), "flag db-port reads environment variable DB_HOST, already read by flag db-host")
+	assert.Equal(t, []string{"PGPORT", "DB_PORT"}, conf.Flags()[1].Env, "a failed bind changes nothing")
+	assert.EqualError(t, conf.BindEnv("missing", "X"), "no flag named missing")
+	assert.Error(t, conf.BindEnv("db-host"))
+}
@@ -0,0 +1,176 @@
+package configurable
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// envFileFlag and envFileVar name where EnableEnvFile looks for env files.
+const (
+	envFileFlag = "env-file"
+	envFileVar  = "ENV_FILE"
+)
+
+// EnableEnvFile registers an --env-file flag. Files named by it, or by the
+// ENV_FILE environment variable, are read by Parse and their definitions
+// become visible to environment lookups, so one image can be parameterized by
+// mounting a file. The process environment and SetEnv take precedence over
+// definitions from files, as with docker-compose's env_file.
+func (c *Configurable) EnableEnvFile() {
+	if _, ok := c.flags[envFileFlag]; ok {
+		return
+	}
+	c.NewList(envFileFlag, []string{}, "file of KEY=VALUE environment definitions (repeatable)")
+}
+
+// loadEnvFiles reads the env files requested through --env-file and ENV_FILE,
+// checking their signatures as LoadFile does.
+func (c *Configurable) loadEnvFiles() error {
+	files, ok := c.flags[envFileFlag].(*ListFlag)
+	if !ok {
+		return nil
+	}
+	names := append([]string{}, *files.values...)
+	if name, ok := c.lookupEnv(envFileVar); ok && name != "" && len(names) == 0 {
+		names = append(names, name)
+	}
+	for _, name := range names {
+		data, err := readFile(name, c.parseOptions.MaxFileSize)
+		if err != nil {
+			return err
+		}
+		if data, err = c.verify(name, data); err != nil {
+			return err
+		}
+		if err := c.addEnvDefs(name, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LoadEnvFile reads a dotenv file of KEY=VALUE definitions, in the syntax
+// EnableEnvFile accepts, and applies them to the flags reading those
+// variables, so MYAPP_PORT=8080 sets port when the env prefix is MYAPP. The
+// definitions stay visible to later environment lookups. As with
+// EnableEnvFile, the process environment and SetEnv take precedence. LoadFile
+// does the same for files with a .env extension.
+func (c *Configurable) LoadEnvFile(filename string) error {
+	return c.loadFileAs(filename, envFormat)
+}
+
+// envFormat is the format name of dotenv files.
+const envFormat = "env"
+
+// loadEnvDefs adds the definitions in data, read from source, and applies
+// the environment.
+func (c *Configurable) loadEnvDefs(source string, data []byte) error {
+	if err := c.addEnvDefs(source, data); err != nil {
+		return err
+	}
+	c.applyEnv()
+	return nil
+}
+
+// addEnvDefs adds the definitions in data, read from source, to those from
+// env files.
+func (c *Configurable) addEnvDefs(source string, data []byte) error {
+	defs, err := parseEnvFile(data, c.lookupEnv)
+	if err != nil {
+		if source == "" {
+			return err
+		}
+		return fmt.Errorf("%s: %w", source, err)
+	}
+	c.envMu.Lock()
+	defer c.envMu.Unlock()
+	if c.envFile == nil {
+		c.envFile = make(map[string]string)
+	}
+	for k, v := range defs {
+		c.envFile[k] = v
+	}
+	return nil
+}
+
+// parseEnvFile parses docker-compose style env file contents: KEY=VALUE lines,
+// an optional "export " prefix, # comments, blank lines, 'single-quoted'
+// literals, "double-quoted" values with \n, \t, \" and \\ escapes, and ${VAR}
+// or $VAR references in unquoted and double-quoted values, resolved against
+// earlier lines and then lookup. Lines with a name but no '=' are ignored.
+func parseEnvFile(data []byte, lookup func(string) (string, bool)) (map[string]string, error) {
+	defs := make(map[string]string)
+	resolve := func(name string) string {
+		if v, ok := defs[name]; ok {
+			return v
+		}
+		v, _ := lookup(name)
+		return v
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+		key, raw, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		key = strings.TrimSpace(key)
+		if key == "" || strings.ContainsAny(key, " \t") {
+			return nil, fmt.Errorf("line %d: invalid variable name %q", lineNo, key)
+		}
+		value, err := parseEnvValue(strings.TrimSpace(raw), resolve)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNo, err)
+		}
+		defs[key] = value
+	}
+	return defs, scanner.Err()
+}
+
+func parseEnvValue(raw string, resolve func(string) string) (string, error) {
+	switch {
+	case strings.HasPrefix(raw, "'"):
+		end := strings.Index(raw[1:], "'")
+		if end < 0 {
+			return "", fmt.Errorf("unterminated single quote")
+		}
+		return raw[1 : end+1], nil
+	case strings.HasPrefix(raw, `"`):
+		var sb strings.Builder
+		for i := 1; i < len(raw); i++ {
+			switch ch := raw[i]; {
+			case ch == '"':
+				return os.Expand(sb.String(), resolve), nil
+			case ch == '\\' && i+1 < len(raw):
+				i++
+				switch raw[i] {
+				case 'n':
+					sb.WriteByte('\n')
+				case 't':
+					sb.WriteByte('\t')
+				case 'r':
+					sb.WriteByte('\r')
+				default:
+					sb.WriteByte(raw[i])
+				}
+			default:
+				sb.WriteByte(ch)
+			}
+		}
+		return "", fmt.Errorf("unterminated double quote")
+	default:
+		if i := strings.Index(raw, " #"); i >= 0 {
+			raw = strings.TrimSpace(raw[:i])
+		}
+		return os.Expand(raw, resolve), nil
+	}
+}
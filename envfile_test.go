@@ -0,0 +1,118 @@
+package configurable
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseEnvFile(t *testing.T) {
+	lookup := func(name string) (string, bool) {
+		if name == "HOME" {
+			return "/home/app", true
+		}
+		return "", false
+	}
+	defs, err := parseEnvFile([]byte(`
+# database
+export DB_HOST=db.internal
+DB_PORT = 5432 # inline comment
+GREETING="hello\n\"world\""
+LITERAL='${not} expanded'
+DATA_DIR=${HOME}/data
+URL=http://$DB_HOST:${DB_PORT}/
+SHELL_ONLY
+EMPTY=
+`), lookup)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"DB_HOST":  "db.internal",
+		"DB_PORT":  "5432",
+		"GREETING": "hello\n\"world\"",
+		"LITERAL":  "${not} expanded",
+		"DATA_DIR": "/home/app/data",
+		"URL":      "http://db.internal:5432/",
+		"EMPTY":    "",
+	}, defs)
+
+	_, err = parseEnvFile([]byte(`A="open`), lookup)
+	assert.EqualError(t, err, "line 1: unterminated double quote")
+	_, err = parseEnvFile([]byte("\nBAD KEY=1"), lookup)
+	assert.EqualError(t, err, `line 2: invalid variable name "BAD KEY"`)
+}
+
+func TestEnvFile(t *testing.T) {
+	os.Clearenv()
+
+	dir := t.TempDir()
+	first := filepath.Join(dir, "first.env")
+	second := filepath.Join(dir, "second.env")
+	assert.NoError(t, os.WriteFile(first, []byte("port=8080\nname=from-file\n"), 0644))
+	assert.NoError(t, os.WriteFile(second, []byte("debug=true\n"), 0644))
+
+	newConf := func(t *testing.T) *Configurable {
+		conf := newTestConfigurable(t)
+		conf.NewInt("port", 80, "port")
+		conf.NewString("name", "", "name")
+		conf.NewBool("debug", false, "debug")
+		conf.EnableEnvFile()
+		return conf
+	}
+
+	t.Run("test --env-file", func(t *testing.T) {
+		conf := newConf(t)
+		conf.SetEnv("name", "explicit")
+		conf.SetArgs([]string{"--env-file", first, "--env-file=" + second})
+		assert.NoError(t, conf.Parse(""))
+		assert.Equal(t, 8080, *conf.Int("port"))
+		assert.Equal(t, "explicit", *conf.String("name"))
+		assert.True(t, *conf.Bool("debug"))
+	})
+
+	t.Run("test ENV_FILE", func(t *testing.T) {
+		conf := newConf(t)
+		conf.SetEnv("ENV_FILE", first)
+		conf.SetArgs([]string{})
+		assert.NoError(t, conf.Parse(""))
+		assert.Equal(t, 8080, *conf.Int("port"))
+	})
+
+	t.Run("test missing file", func(t *testing.T) {
+		conf := newConf(t)
+		conf.SetArgs([]string{"--env-file", filepath.Join(dir, "missing.env")})
+		assert.Error(t, conf.Parse(""))
+	})
+}
+
+func TestLoadEnvFile(t *testing.T) {
+	os.Clearenv()
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, ".env.local")
+	src := "# local overrides\nMYAPP_PORT=8080\nexport MYAPP_NAME='my app'\nMYAPP_SERVER_HOST=\"db.local\" # inline\n"
+	assert.NoError(t, os.WriteFile(file, []byte(src), 0644))
+
+	conf := newTestConfigurable(t)
+	conf.envPrefix = "MYAPP"
+	port := conf.NewInt("port", 80, "port")
+	name := conf.NewString("name", "", "name")
+	host := conf.NewString("server.host", "", "host")
+	conf.SetEnv("MYAPP_NAME", "explicit")
+	assert.NoError(t, conf.LoadEnvFile(file))
+	assert.Equal(t, 8080, *port)
+	assert.Equal(t, "explicit", *name)
+	assert.Equal(t, "db.local", *host)
+	assert.Equal(t, "env MYAPP_PORT", conf.sources["port"].String())
+
+	t.Run("test LoadFile", func(t *testing.T) {
+		file := filepath.Join(dir, ".env")
+		assert.NoError(t, os.WriteFile(file, []byte("port=9090\n"), 0644))
+		conf := newTestConfigurable(t)
+		port := conf.NewInt("port", 80, "port")
+		assert.NoError(t, conf.LoadFile(file))
+		assert.Equal(t, 9090, *port)
+		assert.Error(t, conf.LoadData("env", []byte("port='open\n")))
+	})
+}
@@ -0,0 +1,111 @@
+//go:build !(js && wasm) && !tinygo
+
+// Package etcd is a configurable.Provider reading the keys under a prefix in
+// etcd v3, so a fleet can keep its configuration in etcd without syncing it
+// to disk first.
+//
+// Each key below the prefix sets the flag named by the rest of the key, with
+// slashes read as dots: under the prefix "/myapp/", the key
+// "/myapp/db/host" sets db.host. Values are the text a flag would accept on
+// the command line. Keys that match no flag are reported like unknown keys
+// in a file.
+package etcd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+// Client is the part of *clientv3.Client the Provider uses.
+type Client interface {
+	Get(ctx context.Context, key string, opts ...clientv3.OpOption) (*clientv3.GetResponse, error)
+	Watch(ctx context.Context, key string, opts ...clientv3.OpOption) clientv3.WatchChan
+}
+
+// Provider reads the keys under a prefix.
+type Provider struct {
+	client Client
+	prefix string
+}
+
+// New returns a Provider for the keys under prefix. client decides the
+// endpoints, credentials and timeouts.
+func New(client Client, prefix string) *Provider {
+	return &Provider{client: client, prefix: prefix}
+}
+
+func (p *Provider) Name() string {
+	return "etcd:" + p.prefix
+}
+
+// Load returns the keys under the prefix.
+func (p *Provider) Load(ctx context.Context) (map[string]interface{}, error) {
+	data, _, err := p.get(ctx)
+	return data, err
+}
+
+// Watch applies the keys under the prefix, then the whole document again
+// after every change to them, until ctx is done or the watch fails, as it
+// does when the revision it resumes from has been compacted. A deleted key
+// leaves its flag at the value it had.
+func (p *Provider) Watch(ctx context.Context, apply func(map[string]interface{}) error) error {
+	data, rev, err := p.get(ctx)
+	if err != nil {
+		return err
+	}
+	_ = apply(data)
+	// Without a leader the watch would wait silently through a partition.
+	ctx = clientv3.WithRequireLeader(ctx)
+	for resp := range p.client.Watch(ctx, p.prefix, clientv3.WithPrefix(), clientv3.WithRev(rev+1)) {
+		if err := resp.Err(); err != nil {
+			return fmt.Errorf("watching %s: %w", p.prefix, err)
+		}
+		if len(resp.Events) == 0 {
+			continue
+		}
+		for _, e := range resp.Events {
+			name, ok := p.flagName(string(e.Kv.Key))
+			if !ok {
+				continue
+			}
+			if e.Type == clientv3.EventTypeDelete {
+				delete(data, name)
+			} else {
+				data[name] = string(e.Kv.Value)
+			}
+		}
+		next := make(map[string]interface{}, len(data))
+		for k, v := range data {
+			next[k] = v
+		}
+		_ = apply(next)
+	}
+	return ctx.Err()
+}
+
+// get returns the keys under the prefix and the revision they were read at.
+func (p *Provider) get(ctx context.Context) (map[string]interface{}, int64, error) {
+	resp, err := p.client.Get(ctx, p.prefix, clientv3.WithPrefix())
+	if err != nil {
+		return nil, 0, fmt.Errorf("reading %s: %w", p.prefix, err)
+	}
+	data := make(map[string]interface{}, len(resp.Kvs))
+	for _, kv := range resp.Kvs {
+		if name, ok := p.flagName(string(kv.Key)); ok {
+			data[name] = string(kv.Value)
+		}
+	}
+	return data, resp.Header.GetRevision(), nil
+}
+
+// flagName maps a key to the flag it sets.
+func (p *Provider) flagName(key string) (string, bool) {
+	name := strings.Trim(strings.TrimPrefix(key, p.prefix), "/")
+	if name == "" {
+		return "", false
+	}
+	return strings.ReplaceAll(name, "/", "."), true
+}
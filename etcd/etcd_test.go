@@ -0,0 +1,89 @@
+//go:build !(js && wasm) && !tinygo
+
+package etcd
+
+import (
+	"context"
+	"flag"
+	"testing"
+
+	"github.com/andreimerlescu/configurable"
+	"github.com/stretchr/testify/assert"
+	"go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+// fakeClient serves kvs at revision rev and sends the responses written to
+// watch to the watcher.
+type fakeClient struct {
+	kvs     map[string]string
+	rev     int64
+	watch   chan clientv3.WatchResponse
+	watched int64
+}
+
+func (f *fakeClient) Get(ctx context.Context, key string, opts ...clientv3.OpOption) (*clientv3.GetResponse, error) {
+	resp := &clientv3.GetResponse{Header: &etcdserverpb.ResponseHeader{Revision: f.rev}}
+	for k, v := range f.kvs {
+		resp.Kvs = append(resp.Kvs, &mvccpb.KeyValue{Key: []byte(k), Value: []byte(v)})
+	}
+	return resp, nil
+}
+
+func (f *fakeClient) Watch(ctx context.Context, key string, opts ...clientv3.OpOption) clientv3.WatchChan {
+	op := clientv3.OpGet(key, opts...)
+	f.watched = op.Rev()
+	return f.watch
+}
+
+func put(key, value string) *clientv3.Event {
+	return &clientv3.Event{Type: clientv3.EventTypePut, Kv: &mvccpb.KeyValue{Key: []byte(key), Value: []byte(value)}}
+}
+
+func TestProvider(t *testing.T) {
+	newConf := func(t *testing.T) configurable.IConfigurable {
+		conf := configurable.New(configurable.WithFlagSet(flag.NewFlagSet(t.Name(), flag.ContinueOnError)))
+		conf.NewString("db.host", "localhost", "database host")
+		conf.NewInt("workers", 1, "workers")
+		conf.SetArgs([]string{})
+		return conf
+	}
+
+	t.Run("test load", func(t *testing.T) {
+		client := &fakeClient{kvs: map[string]string{"/myapp/db/host": "db.internal", "/myapp/workers": "8", "/myapp/": "ignored"}, rev: 7}
+		conf := newConf(t)
+		p := New(client, "/myapp/")
+		assert.Equal(t, "etcd:/myapp/", p.Name())
+		conf.AddProvider(p)
+		assert.NoError(t, conf.Parse(""))
+		assert.Equal(t, "db.internal", conf.View().String("db.host"))
+		assert.Equal(t, 8, conf.View().Int("workers"))
+		assert.Equal(t, "remote etcd:/myapp/", conf.Source("workers").String())
+	})
+
+	t.Run("test watch", func(t *testing.T) {
+		client := &fakeClient{kvs: map[string]string{"/myapp/workers": "2"}, rev: 7, watch: make(chan clientv3.WatchResponse)}
+		p := New(client, "/myapp/")
+		docs := make(chan map[string]interface{})
+		done := make(chan error)
+		go func() {
+			done <- p.Watch(context.Background(), func(data map[string]interface{}) error {
+				docs <- data
+				return nil
+			})
+		}()
+		assert.Equal(t, map[string]interface{}{"workers": "2"}, <-docs)
+
+		client.watch <- clientv3.WatchResponse{Events: []*clientv3.Event{
+			put("/myapp/db/host", "db.internal"),
+			{Type: clientv3.EventTypeDelete, Kv: &mvccpb.KeyValue{Key: []byte("/myapp/workers")}},
+		}}
+		assert.Equal(t, map[string]interface{}{"db.host": "db.internal"}, <-docs)
+		assert.Equal(t, int64(8), client.watched, "the watch resumes after the revision read")
+
+		client.watch <- clientv3.WatchResponse{CompactRevision: 5, Canceled: true}
+		assert.ErrorIs(t, <-done, rpctypes.ErrCompacted)
+	})
+}
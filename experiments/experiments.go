@@ -0,0 +1,115 @@
+// Package experiments assigns A/B variants from distributions held in
+// configuration, so traffic splits can be changed by editing config rather
+// than redeploying.
+//
+// An experiment is a map flag named "<prefix>.<experiment>" whose entries are
+// variant weights:
+//
+//	experiments:
+//	  checkout: {a: 50, b: 50}
+//
+// Assignment hashes the experiment name with a stable unit ID (a user or
+// session ID), so a unit keeps its variant for as long as the distribution is
+// unchanged. Distributions are read on every call and pick up reloads.
+package experiments
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/andreimerlescu/configurable"
+)
+
+// Exposure records that a unit was assigned a variant.
+type Exposure struct {
+	Experiment string
+	Variant    string
+	UnitID     string
+}
+
+// Experiments assigns variants for the experiments configured under a prefix.
+type Experiments struct {
+	conf   configurable.IConfigurable
+	prefix string
+
+	mu    sync.RWMutex
+	hooks []func(Exposure)
+}
+
+// New returns Experiments reading distributions from map flags named
+// "<prefix>.<experiment>" in conf.
+func New(conf configurable.IConfigurable, prefix string) *Experiments {
+	return &Experiments{conf: conf, prefix: prefix}
+}
+
+// OnExposure registers fn to be called after every successful assignment, for
+// exposure logging.
+func (e *Experiments) OnExposure(fn func(Exposure)) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.hooks = append(e.hooks, fn)
+}
+
+// Assign returns the variant of experiment for unitID. It fails when the
+// experiment is not configured or its weights are invalid.
+func (e *Experiments) Assign(experiment, unitID string) (string, error) {
+	dist := e.conf.Map(e.prefix + "." + experiment)
+	if dist == nil || len(*dist) == 0 {
+		return "", fmt.Errorf("experiment %s is not configured", experiment)
+	}
+	variant, err := pick(experiment, unitID, *dist)
+	if err != nil {
+		return "", err
+	}
+	exposure := Exposure{Experiment: experiment, Variant: variant, UnitID: unitID}
+	e.mu.RLock()
+	hooks := e.hooks
+	e.mu.RUnlock()
+	for _, hook := range hooks {
+		hook(exposure)
+	}
+	return variant, nil
+}
+
+// buckets is the resolution of the hash-to-weight mapping.
+const buckets = 1_000_000
+
+// pick deterministically maps unitID onto the cumulative weights of dist.
+// Variants are ordered by name so the mapping does not depend on map order.
+func pick(experiment, unitID string, dist map[string]string) (string, error) {
+	variants := make([]string, 0, len(dist))
+	for name := range dist {
+		variants = append(variants, name)
+	}
+	sort.Strings(variants)
+
+	weights := make([]float64, len(variants))
+	total := 0.0
+	for i, name := range variants {
+		w, err := strconv.ParseFloat(dist[name], 64)
+		if err != nil || w < 0 {
+			return "", fmt.Errorf("experiment %s: invalid weight %q for variant %s", experiment, dist[name], name)
+		}
+		weights[i] = w
+		total += w
+	}
+	if total == 0 {
+		return "", fmt.Errorf("experiment %s: weights sum to zero", experiment)
+	}
+
+	h := fnv.New64a()
+	h.Write([]byte(experiment))
+	h.Write([]byte{0})
+	h.Write([]byte(unitID))
+	point := float64(h.Sum64()%buckets) / buckets * total
+	for i, w := range weights {
+		if point < w {
+			return variants[i], nil
+		}
+		point -= w
+	}
+	return variants[len(variants)-1], nil
+}
@@ -0,0 +1,59 @@
+package experiments
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/andreimerlescu/configurable"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAssign(t *testing.T) {
+	conf := configurable.New()
+	conf.NewMap("experiments.checkout", map[string]string{}, "checkout experiment")
+	conf.NewMap("experiments.broken", map[string]string{}, "broken experiment")
+	assert.NoError(t, conf.LoadData("yaml", []byte(`
+experiments:
+  checkout: {a: 50, b: 50}
+  broken: {a: lots}
+`)))
+
+	exp := New(conf, "experiments")
+	var exposures []Exposure
+	exp.OnExposure(func(e Exposure) { exposures = append(exposures, e) })
+
+	t.Run("test deterministic", func(t *testing.T) {
+		first, err := exp.Assign("checkout", "user-1")
+		assert.NoError(t, err)
+		for i := 0; i < 10; i++ {
+			again, _ := exp.Assign("checkout", "user-1")
+			assert.Equal(t, first, again)
+		}
+		assert.Equal(t, Exposure{Experiment: "checkout", Variant: first, UnitID: "user-1"}, exposures[0])
+	})
+
+	t.Run("test distribution", func(t *testing.T) {
+		counts := map[string]int{}
+		for i := 0; i < 10000; i++ {
+			v, err := exp.Assign("checkout", fmt.Sprintf("user-%d", i))
+			assert.NoError(t, err)
+			counts[v]++
+		}
+		assert.InDelta(t, 5000, counts["a"], 300)
+		assert.InDelta(t, 5000, counts["b"], 300)
+	})
+
+	t.Run("test reload changes distribution", func(t *testing.T) {
+		assert.NoError(t, conf.LoadData("json", []byte(`{"experiments": {"checkout": {"a": 0, "b": 100}}}`)))
+		v, err := exp.Assign("checkout", "user-1")
+		assert.NoError(t, err)
+		assert.Equal(t, "b", v)
+	})
+
+	t.Run("test misconfiguration", func(t *testing.T) {
+		_, err := exp.Assign("missing", "user-1")
+		assert.EqualError(t, err, "experiment missing is not configured")
+		_, err = exp.Assign("broken", "user-1")
+		assert.EqualError(t, err, `experiment broken: invalid weight "lots" for variant a`)
+	})
+}
@@ -0,0 +1,428 @@
+package configurable
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// SetInFile sets the flag name to value in the configuration file filename,
+// creating the file if needed, and leaves its other keys as they are: JSON
+// keys keep their order, and YAML and INI documents keep their comments too.
+// Unlike WriteFile, values from flags, the environment and other sources are
+// not written. value is checked like a value read from a file, so a change
+// that the next load would reject is refused. The current values are not
+// changed. Signed files, flags registered WithApproval and edits in read-only
+// mode are refused. The edit holds the lock EditFile takes.
+func (c *Configurable) SetInFile(filename, name string, value interface{}) error {
+	if err := c.checkFileEdit("set", name); err != nil {
+		return err
+	}
+	if err := c.checkRaw(name, value); err != nil {
+		return err
+	}
+	v, err := c.convert(name, value)
+	if err != nil {
+		return err
+	}
+	if err := c.checkEntries(name, v); err != nil {
+		return err
+	}
+	return c.editDocument(filename, name, v, true)
+}
+
+// UnsetInFile removes the flag name from the configuration file filename, so
+// it falls back to the other sources or its default. Removing a key the file
+// does not have is not an error. It is refused as SetInFile is.
+func (c *Configurable) UnsetInFile(filename, name string) error {
+	if err := c.checkFileEdit("unset", name); err != nil {
+		return err
+	}
+	return c.editDocument(filename, name, nil, false)
+}
+
+// checkFileEdit refuses the edit op to files for name in read-only mode, or if
+// name is not a flag or needs approval.
+func (c *Configurable) checkFileEdit(op, name string) error {
+	if c.IsReadOnly() {
+		return &ReadOnlyError{Op: op, Name: name}
+	}
+	if _, ok := c.flags[name]; !ok {
+		return fmt.Errorf("no flag named %s", name)
+	}
+	if c.NeedsApproval(name) {
+		return fmt.Errorf("%w: %s", ErrApprovalRequired, name)
+	}
+	return nil
+}
+
+// editDocument sets the flag name to value in filename, or removes it if set
+// is false, under the file lock. A missing file is edited as an empty
+// document. A signed file is verified and then refused, as the edit would
+// break its signature.
+func (c *Configurable) editDocument(filename, name string, value interface{}, set bool) error {
+	format := strings.TrimPrefix(strings.ToLower(filepath.Ext(filename)), ".")
+	unlock, err := lockFile(filename)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	data, err := readFile(filename, c.parseOptions.MaxFileSize)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	if err := c.checkUnsigned(filename, data); err != nil {
+		return err
+	}
+	var out []byte
+	switch format {
+	case "json":
+		out, err = editJSON(data, name, formatValue(value), set)
+	case "yaml", "yml":
+		out, err = editYAML(data, name, formatValue(value), set)
+	case "toml":
+		out, err = editTOML(data, name, formatValue(value), set)
+	case "ini":
+		out, err = editINI(data, c.flags, name, value, set)
+	default:
+		err = errors.New("unsupported file extension")
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", filename, err)
+	}
+	return writeFile(filename, out, c.writeMode())
+}
+
+// editJSON edits a JSON document, writing its objects' keys in the order
+// they had. New keys come last.
+func editJSON(data []byte, name string, value interface{}, set bool) ([]byte, error) {
+	if len(bytes.TrimSpace(data)) == 0 {
+		data = []byte("{}")
+	}
+	doc, err := decodeJSON(data)
+	if err != nil {
+		return nil, err
+	}
+	order := make(map[string][]string)
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if err := jsonKeyOrder(dec, "", order); err != nil {
+		return nil, err
+	}
+	if set {
+		setPath(doc, name, value)
+	} else {
+		deletePath(doc, name)
+	}
+	var buf bytes.Buffer
+	if err := encodeOrdered(&buf, doc, "", order); err != nil {
+		return nil, err
+	}
+	var out bytes.Buffer
+	if err := json.Indent(&out, buf.Bytes(), "", "  "); err != nil {
+		return nil, err
+	}
+	out.WriteByte('\n')
+	return out.Bytes(), nil
+}
+
+// jsonKeyOrder records the keys of each object in the value dec reads next,
+// in document order, by the path of the object.
+func jsonKeyOrder(dec *json.Decoder, path string, order map[string][]string) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	switch tok {
+	case json.Delim('{'):
+		var keys []string
+		for dec.More() {
+			tok, err := dec.Token()
+			if err != nil {
+				return err
+			}
+			key := tok.(string)
+			keys = append(keys, key)
+			if err := jsonKeyOrder(dec, path+"\x00"+key, order); err != nil {
+				return err
+			}
+		}
+		order[path] = keys
+	case json.Delim('['):
+		for i := 0; dec.More(); i++ {
+			if err := jsonKeyOrder(dec, path+"\x00"+strconv.Itoa(i), order); err != nil {
+				return err
+			}
+		}
+	default:
+		return nil
+	}
+	_, err = dec.Token()
+	return err
+}
+
+// encodeOrdered writes v as compact JSON, with the keys of each object in
+// the order recorded for its path and then sorted.
+func encodeOrdered(buf *bytes.Buffer, v interface{}, path string, order map[string][]string) error {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(val))
+		for _, key := range order[path] {
+			if _, ok := val[key]; ok {
+				keys = append(keys, key)
+			}
+		}
+		var added []string
+		for key := range val {
+			if !slices.Contains(order[path], key) {
+				added = append(added, key)
+			}
+		}
+		sort.Strings(added)
+		buf.WriteByte('{')
+		for i, key := range append(keys, added...) {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			encoded, _ := json.Marshal(key)
+			buf.Write(encoded)
+			buf.WriteByte(':')
+			if err := encodeOrdered(buf, val[key], path+"\x00"+key, order); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	case []interface{}:
+		buf.WriteByte('[')
+		for i, item := range val {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := encodeOrdered(buf, item, path+"\x00"+strconv.Itoa(i), order); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	default:
+		encoded, err := json.Marshal(val)
+		if err != nil {
+			return err
+		}
+		buf.Write(encoded)
+	}
+	return nil
+}
+
+// editYAML edits a YAML document through its node tree, so its comments,
+// key order and styles survive. The indentation is kept too.
+func editYAML(data []byte, name string, value interface{}, set bool) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if doc.Kind == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, errors.New("document is not a mapping")
+	}
+	if set {
+		var node yaml.Node
+		if err := node.Encode(value); err != nil {
+			return nil, err
+		}
+		setNode(root, name, &node)
+	} else {
+		deleteNode(root, name)
+	}
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(yamlIndent(data))
+	if err := enc.Encode(&doc); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// yamlIndent returns the indentation of the first indented line of data, or
+// the yaml.v3 default of 4.
+func yamlIndent(data []byte) int {
+	for _, line := range strings.Split(string(data), "\n") {
+		trimmed := strings.TrimLeft(line, " ")
+		if n := len(line) - len(trimmed); n > 0 && trimmed != "" && !strings.HasPrefix(trimmed, "#") && !strings.HasPrefix(trimmed, "-") {
+			return max(n, 2)
+		}
+	}
+	return 4
+}
+
+// mappingValue returns the value of key in the mapping node m, or nil.
+func mappingValue(m *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			return m.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// setNode is setPath for the mapping node m. A value replaced keeps its
+// comments.
+func setNode(m *yaml.Node, name string, value *yaml.Node) {
+	if old := mappingValue(m, name); old != nil {
+		value.HeadComment, value.LineComment, value.FootComment = old.HeadComment, old.LineComment, old.FootComment
+		*old = *value
+		return
+	}
+	for i := strings.IndexByte(name, '.'); i > 0; i = nextDot(name, i) {
+		if nested := mappingValue(m, name[:i]); nested != nil && nested.Kind == yaml.MappingNode {
+			setNode(nested, name[i+1:], value)
+			return
+		}
+	}
+	key := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: name}
+	head, rest, ok := strings.Cut(name, ".")
+	if ok && mappingValue(m, head) == nil {
+		nested := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		setNode(nested, rest, value)
+		key.Value, value = head, nested
+	}
+	m.Content = append(m.Content, key, value)
+}
+
+// deleteNode is deletePath for the mapping node m.
+func deleteNode(m *yaml.Node, name string) {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == name {
+			m.Content = slices.Delete(m.Content, i, i+2)
+			return
+		}
+	}
+	for i := strings.IndexByte(name, '.'); i > 0; i = nextDot(name, i) {
+		if nested := mappingValue(m, name[:i]); nested != nil && nested.Kind == yaml.MappingNode {
+			deleteNode(nested, name[i+1:])
+			if len(nested.Content) == 0 {
+				deleteNode(m, name[:i])
+			}
+			return
+		}
+	}
+}
+
+// editTOML edits a TOML document. It is written again from its values, so
+// unlike the other formats it loses its comments and key order.
+func editTOML(data []byte, name string, value interface{}, set bool) ([]byte, error) {
+	doc, err := decodeTOML(data)
+	if err != nil {
+		return nil, err
+	}
+	if doc == nil {
+		doc = make(map[string]interface{})
+	}
+	if set {
+		setPath(doc, name, value)
+	} else {
+		deletePath(doc, name)
+	}
+	return toml.Marshal(doc)
+}
+
+// editINI edits an INI document in place, keeping its comments.
+func editINI(data []byte, flags map[string]interface{}, name string, value interface{}, set bool) ([]byte, error) {
+	_, cfg, err := decodeINI(data, flags)
+	if err != nil {
+		return nil, err
+	}
+	section, key := iniKey(cfg, name)
+	section.DeleteKey(key + "[]")
+	if set {
+		section.Key(key).SetValue(iniText(value))
+	} else {
+		section.DeleteKey(key)
+	}
+	var buf bytes.Buffer
+	if _, err := cfg.WriteTo(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// iniText renders value as an INI value that decodeINI reads back.
+func iniText(value interface{}) string {
+	switch v := value.(type) {
+	case []string:
+		return strings.Join(v, ",")
+	case []int, []float64, []bool:
+		return strings.Trim(strings.Join(strings.Fields(fmt.Sprint(v)), ","), "[]")
+	case map[string]string:
+		pairs := make([]string, 0, len(v))
+		for k, val := range v {
+			pairs = append(pairs, k+"="+val)
+		}
+		sort.Strings(pairs)
+		return strings.Join(pairs, ",")
+	}
+	return fmt.Sprint(formatValue(value))
+}
+
+// setPath stores value under name in doc, inside the nested mappings the
+// document already has for name's dotted prefix, or in new ones.
+func setPath(doc map[string]interface{}, name string, value interface{}) {
+	if _, ok := doc[name]; ok {
+		doc[name] = value
+		return
+	}
+	for i := strings.IndexByte(name, '.'); i > 0; i = nextDot(name, i) {
+		if nested, ok := doc[name[:i]].(map[string]interface{}); ok {
+			setPath(nested, name[i+1:], value)
+			return
+		}
+	}
+	head, rest, nested := strings.Cut(name, ".")
+	if !nested || doc[head] != nil {
+		doc[name] = value
+		return
+	}
+	m := make(map[string]interface{})
+	setPath(m, rest, value)
+	doc[head] = m
+}
+
+// deletePath removes name from doc, and any mapping left empty by doing so.
+func deletePath(doc map[string]interface{}, name string) {
+	if _, ok := doc[name]; ok {
+		delete(doc, name)
+		return
+	}
+	for i := strings.IndexByte(name, '.'); i > 0; i = nextDot(name, i) {
+		if nested, ok := doc[name[:i]].(map[string]interface{}); ok {
+			deletePath(nested, name[i+1:])
+			if len(nested) == 0 {
+				delete(doc, name[:i])
+			}
+			return
+		}
+	}
+}
+
+// nextDot returns the index of the first '.' in name after i, or -1.
+func nextDot(name string, i int) int {
+	j := strings.IndexByte(name[i+1:], '.')
+	if j < 0 {
+		return -1
+	}
+	return i + 1 + j
+}
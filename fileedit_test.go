@@ -0,0 +1,127 @@
+package configurable
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetInFile(t *testing.T) {
+	os.Clearenv()
+	dir := t.TempDir()
+
+	newConf := func(t *testing.T) *Configurable {
+		conf := newTestConfigurable(t)
+		conf.NewString("name", "", "name")
+		conf.NewInt("server.port", 80, "port")
+		conf.NewList("tags", nil, "tags")
+		conf.NewBoundedInt("workers", 1, 1, 8, "workers")
+		return conf
+	}
+
+	for _, ext := range []string{".json", ".yaml", ".toml", ".ini"} {
+		t.Run("test "+ext, func(t *testing.T) {
+			file := filepath.Join(dir, "app"+ext)
+			conf := newConf(t)
+			conf.SetEnv("name", "from-env")
+			assert.NoError(t, conf.SetInFile(file, "server.port", "8080"))
+			assert.NoError(t, conf.SetInFile(file, "tags", []string{"a", "b"}))
+			assert.NoError(t, conf.SetInFile(file, "workers", 4))
+			assert.NoError(t, conf.UnsetInFile(file, "workers"))
+			assert.Equal(t, 80, *conf.Int("server.port"), "current values are unchanged")
+
+			assert.ErrorContains(t, conf.SetInFile(file, "server.port", "eighty"), "eighty")
+			assert.Error(t, conf.SetInFile(file, "workers", 9))
+			assert.EqualError(t, conf.SetInFile(file, "prot", 1), "no flag named prot")
+
+			reload := newConf(t)
+			assert.NoError(t, reload.LoadFile(file))
+			assert.Equal(t, 8080, *reload.Int("server.port"))
+			assert.Equal(t, []string{"a", "b"}, *reload.List("tags"))
+			assert.Equal(t, 1, *reload.Int("workers"))
+			assert.Equal(t, "", *reload.String("name"), "other sources are not written")
+		})
+	}
+
+	t.Run("test keeps other keys and nesting", func(t *testing.T) {
+		file := filepath.Join(dir, "nested.yaml")
+		assert.NoError(t, os.WriteFile(file, []byte("name: api\nserver:\n  port: 80\n"), 0644))
+		conf := newConf(t)
+		assert.NoError(t, conf.SetInFile(file, "server.port", 9090))
+		data, err := os.ReadFile(file)
+		assert.NoError(t, err)
+		assert.Equal(t, "name: api\nserver:\n  port: 9090\n", string(data))
+
+		assert.NoError(t, conf.UnsetInFile(file, "server.port"))
+		data, err = os.ReadFile(file)
+		assert.NoError(t, err)
+		assert.Equal(t, "name: api\n", string(data))
+	})
+
+	t.Run("test keeps YAML comments", func(t *testing.T) {
+		file := filepath.Join(dir, "comments.yaml")
+		src := "# Service settings.\nname: api # public name\nserver:\n  # Behind the proxy.\n  port: 80\n"
+		assert.NoError(t, os.WriteFile(file, []byte(src), 0644))
+		conf := newConf(t)
+		assert.NoError(t, conf.SetInFile(file, "server.port", 9090))
+		assert.NoError(t, conf.SetInFile(file, "workers", 2))
+		data, err := os.ReadFile(file)
+		assert.NoError(t, err)
+		assert.Equal(t, "# Service settings.\nname: api # public name\nserver:\n  # Behind the proxy.\n  port: 9090\nworkers: 2\n", string(data))
+	})
+
+	t.Run("test keeps JSON key order", func(t *testing.T) {
+		file := filepath.Join(dir, "order.json")
+		assert.NoError(t, os.WriteFile(file, []byte(`{"server": {"port": 80, "host": "a"}, "name": "api", "id": 9007199254740993}`), 0644))
+		conf := newConf(t)
+		assert.NoError(t, conf.SetInFile(file, "server.port", 9090))
+		assert.NoError(t, conf.SetInFile(file, "workers", 2))
+		data, err := os.ReadFile(file)
+		assert.NoError(t, err)
+		assert.Equal(t, "{\n  \"server\": {\n    \"port\": 9090,\n    \"host\": \"a\"\n  },\n  \"name\": \"api\",\n  \"id\": 9007199254740993,\n  \"workers\": 2\n}\n", string(data))
+	})
+
+	t.Run("test approval", func(t *testing.T) {
+		file := filepath.Join(dir, "approval.yaml")
+		conf := newConf(t)
+		conf.RequireApproval("workers")
+		assert.ErrorIs(t, conf.SetInFile(file, "workers", 2), ErrApprovalRequired)
+		assert.ErrorIs(t, conf.UnsetInFile(file, "workers"), ErrApprovalRequired)
+		assert.NoFileExists(t, file)
+	})
+
+	t.Run("test read-only", func(t *testing.T) {
+		file := filepath.Join(dir, "read-only.yaml")
+		conf := newConf(t)
+		conf.ReadOnly(true)
+		var readOnly *ReadOnlyError
+		assert.ErrorAs(t, conf.SetInFile(file, "workers", 2), &readOnly)
+		assert.Equal(t, &ReadOnlyError{Op: "set", Name: "workers"}, readOnly)
+		assert.ErrorIs(t, conf.UnsetInFile(file, "workers"), ErrReadOnly)
+		assert.NoFileExists(t, file)
+	})
+
+	t.Run("test signed files", func(t *testing.T) {
+		pub, priv, _ := ed25519.GenerateKey(nil)
+		file := filepath.Join(dir, "signed.json")
+		signed := SignConfig([]byte(`{"name": "api"}`), priv)
+		assert.NoError(t, os.WriteFile(file, signed, 0644))
+
+		conf := newConf(t)
+		assert.ErrorIs(t, conf.SetInFile(file, "name", "web"), ErrSignature)
+		conf.SetTrustedKeys(pub)
+		assert.ErrorContains(t, conf.SetInFile(file, "name", "web"), "cannot be edited")
+		tampered := bytes.Replace(signed, []byte("api"), []byte("web"), 1)
+		assert.NoError(t, os.WriteFile(file, tampered, 0644))
+		assert.ErrorContains(t, conf.UnsetInFile(file, "name"), "does not match a trusted key", "the signature is verified first")
+		assert.ErrorContains(t, conf.SetInFile(filepath.Join(dir, "unsigned.json"), "name", "web"), "not signed")
+
+		data, err := os.ReadFile(file)
+		assert.NoError(t, err)
+		assert.Equal(t, tampered, data)
+	})
+}
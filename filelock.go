@@ -0,0 +1,31 @@
+package configurable
+
+import (
+	"errors"
+	"io/fs"
+)
+
+// lockSuffix names the file next to a configuration file that writers lock.
+// The configuration file itself is replaced on every write, so a lock on it
+// would not outlive the write.
+const lockSuffix = ".lock"
+
+// EditFile changes filename under an exclusive lock shared with WriteFile
+// and with other processes doing the same, such as an agent and a CLI: it
+// loads the file, if it exists, calls edit, which typically calls Set, and
+// writes the result back. Concurrent edits apply one after the other, so
+// none loses another's change. The file is not written if edit fails.
+func (c *Configurable) EditFile(filename string, edit func() error) error {
+	unlock, err := lockFile(filename)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	if err := c.LoadFile(filename); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	if err := edit(); err != nil {
+		return err
+	}
+	return c.writeFile(filename)
+}
@@ -0,0 +1,31 @@
+//go:build (darwin || dragonfly || freebsd || linux || netbsd || openbsd) && !tinygo
+
+package configurable
+
+import (
+	"os"
+	"syscall"
+)
+
+// lockFile takes an exclusive advisory lock for filename, waiting for other
+// processes holding it, and returns a function releasing it.
+func lockFile(filename string) (func(), error) {
+	f, err := os.OpenFile(filename+lockSuffix, os.O_RDWR|os.O_CREATE, privateFileMode)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		err = syscall.Flock(int(f.Fd()), syscall.LOCK_EX)
+		if err != syscall.EINTR {
+			break
+		}
+	}
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return func() {
+		syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+		f.Close()
+	}, nil
+}
@@ -0,0 +1,9 @@
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd || windows) || tinygo
+
+package configurable
+
+// lockFile does nothing where advisory locks are unavailable; writes are
+// still atomic.
+func lockFile(filename string) (func(), error) {
+	return func() {}, nil
+}
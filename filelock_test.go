@@ -0,0 +1,50 @@
+package configurable
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEditFile(t *testing.T) {
+	os.Clearenv()
+	file := filepath.Join(t.TempDir(), "app.json")
+
+	const writers = 8
+	var wg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// Each writer stands for a separate process with its own view
+			// of the file.
+			conf := newTestConfigurable(t)
+			count := conf.NewInt("count", 0, "count")
+			assert.NoError(t, conf.EditFile(file, func() error {
+				return conf.Set("count", *count+1)
+			}))
+		}()
+	}
+	wg.Wait()
+
+	conf := newTestConfigurable(t)
+	count := conf.NewInt("count", 0, "count")
+	assert.NoError(t, conf.LoadFile(file))
+	assert.Equal(t, writers, *count, "no edit was lost")
+
+	t.Run("test failed edit", func(t *testing.T) {
+		conf := newTestConfigurable(t)
+		count := conf.NewInt("count", 0, "count")
+		err := conf.EditFile(file, func() error {
+			*count = 0
+			return errors.New("abandoned")
+		})
+		assert.EqualError(t, err, "abandoned")
+		assert.NoError(t, conf.LoadFile(file))
+		assert.Equal(t, writers, *count)
+	})
+}
@@ -0,0 +1,28 @@
+//go:build windows && !tinygo
+
+package configurable
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// lockFile takes an exclusive advisory lock for filename, waiting for other
+// processes holding it, and returns a function releasing it.
+func lockFile(filename string) (func(), error) {
+	f, err := os.OpenFile(filename+lockSuffix, os.O_RDWR|os.O_CREATE, privateFileMode)
+	if err != nil {
+		return nil, err
+	}
+	h := windows.Handle(f.Fd())
+	ol := new(windows.Overlapped)
+	if err := windows.LockFileEx(h, windows.LOCKFILE_EXCLUSIVE_LOCK, 0, 1, 0, ol); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return func() {
+		windows.UnlockFileEx(h, 0, 1, 0, ol)
+		f.Close()
+	}, nil
+}
@@ -0,0 +1,145 @@
+package configurable
+
+import (
+	"encoding/json"
+	"flag"
+	"time"
+)
+
+// FlagInfo describes a registered flag, for tooling such as documentation
+// generators, admin UIs and exporters.
+type FlagInfo struct {
+	Name string `json:"name"`
+	// Type is one of int, int64, float64, string, bool, duration, list, map,
+	// text and json.
+	Type    string      `json:"type"`
+	Default string      `json:"default"`
+	Value   interface{} `json:"value,omitempty"`
+	Usage   string      `json:"usage,omitempty"`
+	// Source is where the current value came from, such as "default",
+	// "flag" or "env PORT".
+	Source string `json:"source"`
+
+	Help       string   `json:"help,omitempty"`
+	Examples   []string `json:"examples,omitempty"`
+	Env        []string `json:"env,omitempty"`
+	Short      string   `json:"short,omitempty"`
+	Group      string   `json:"group,omitempty"`
+	Deprecated string   `json:"deprecated,omitempty"`
+	Required   bool     `json:"required,omitempty"`
+	Secret     bool     `json:"secret,omitempty"`
+	Hidden     bool     `json:"hidden,omitempty"`
+	Unit       string   `json:"unit,omitempty"`
+	// Min and Max are the bounds of NewBoundedInt and NewBoundedFloat64.
+	Min *float64 `json:"min,omitempty"`
+	Max *float64 `json:"max,omitempty"`
+}
+
+// Flags describes every registered flag, in registration order. Value holds secrets in the clear;
+// check Secret before displaying it.
+func (c *Configurable) Flags() []FlagInfo {
+	var infos []FlagInfo
+	values := c.current().values
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	c.visitAll(func(f *flag.Flag) {
+		ptr, ok := c.flags[f.Name]
+		if !ok {
+			return
+		}
+		info := FlagInfo{
+			Name:    f.Name,
+			Type:    typeName(ptr),
+			Default: f.DefValue,
+			Value:   formatValue(values[f.Name]),
+			Usage:   f.Usage,
+			Source:  SourceDefault.String(),
+		}
+		if s, ok := c.sources[f.Name]; ok {
+			info.Source = s.String()
+		}
+		if m, ok := c.meta[f.Name]; ok {
+			info.Help = m.help
+			info.Examples = m.examples
+			info.Env = m.env
+			info.Short = m.short
+			info.Group = m.group
+			info.Deprecated = m.deprecated
+			info.Required = m.required
+			info.Secret = m.secret
+			info.Hidden = m.hidden
+			info.Unit = string(m.unit)
+			if m.bounds != nil {
+				info.Min, info.Max = &m.bounds.min, &m.bounds.max
+			}
+		}
+		if len(info.Env) == 0 {
+			info.Env = []string{c.envName(f.Name)}
+		}
+		if m, ok := c.meta[f.Name]; ok && m.envMapPrefix != "" {
+			info.Env = append(append([]string{}, info.Env...), m.envMapPrefix+"*")
+		}
+		infos = append(infos, info)
+	})
+	return infos
+}
+
+// Manifest renders Flags as JSON, leaving out the values of secrets.
+func (c *Configurable) Manifest() ([]byte, error) {
+	infos := c.Flags()
+	for i := range infos {
+		if infos[i].Secret {
+			infos[i].Value = nil
+		}
+	}
+	data, err := json.MarshalIndent(infos, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+func typeName(ptr interface{}) string {
+	switch v := ptr.(type) {
+	case *int:
+		return "int"
+	case *int64:
+		return "int64"
+	case *float64:
+		return "float64"
+	case *string:
+		return "string"
+	case *bool:
+		return "bool"
+	case *time.Duration:
+		return "duration"
+	case *ListFlag:
+		return "list"
+	case typedList:
+		return "[]" + v.itemKind()
+	case *MapFlag:
+		return "map"
+	case *TextFlag:
+		return "text"
+	case *JSONFlag:
+		return "json"
+	}
+	return "unknown"
+}
+
+// visitAll calls fn for each registered flag in registration order, then for
+// any other flags on the FlagSet in lexical order. Short aliases are skipped.
+func (c *Configurable) visitAll(fn func(*flag.Flag)) {
+	for _, name := range c.order {
+		if f := c.fs.Lookup(name); f != nil {
+			fn(f)
+		}
+	}
+	c.fs.VisitAll(func(f *flag.Flag) {
+		_, registered := c.flags[f.Name]
+		_, alias := c.aliases[f.Name]
+		if !registered && !alias {
+			fn(f)
+		}
+	})
+}
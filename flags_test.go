@@ -0,0 +1,48 @@
+package configurable
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFlags(t *testing.T) {
+	os.Clearenv()
+
+	conf := newTestConfigurable(t)
+	conf.NewBoundedInt("port", 80, 1, 65535, "listen port", WithShort("p"))
+	conf.NewDuration("timeout", time.Second, "timeout", WithEnv("TIMEOUT"))
+	conf.NewString("token", "", "api token", WithSecret(), WithRequired())
+	conf.SetArgs([]string{"-p", "8080", "-token", "s3cret"})
+	conf.SetEnv("TIMEOUT", "5s")
+	assert.NoError(t, conf.Parse(""))
+
+	t.Run("test flags", func(t *testing.T) {
+		one, max := 1.0, 65535.0
+		infos := conf.Flags()
+		assert.Len(t, infos, 3)
+		assert.Equal(t, FlagInfo{
+			Name: "port", Type: "int", Default: "80", Value: 8080, Usage: "listen port", Source: "flag",
+			Env: []string{"port"}, Short: "p", Min: &one, Max: &max,
+		}, infos[0])
+		assert.Equal(t, "5s", infos[1].Value)
+		assert.Equal(t, "duration", infos[1].Type)
+		assert.Equal(t, "env TIMEOUT", infos[1].Source)
+		assert.True(t, infos[2].Secret)
+		assert.True(t, infos[2].Required)
+	})
+
+	t.Run("test manifest", func(t *testing.T) {
+		data, err := conf.Manifest()
+		assert.NoError(t, err)
+		assert.NotContains(t, string(data), "s3cret")
+		var infos []map[string]interface{}
+		assert.NoError(t, json.Unmarshal(data, &infos))
+		assert.Equal(t, 65535.0, infos[0]["max"])
+		assert.Equal(t, "token", infos[2]["name"])
+		assert.NotContains(t, infos[2], "value")
+	})
+}
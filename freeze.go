@@ -0,0 +1,231 @@
+package configurable
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrFrozen is matched by errors.Is for every *FrozenError.
+var ErrFrozen = errors.New("configuration is frozen")
+
+// FrozenError is returned for a change made after Parse during a freeze
+// window.
+type FrozenError struct {
+	// Window is the freeze window in effect, as it was declared.
+	Window string
+	// Source is where the change came from, such as "set".
+	Source string
+}
+
+func (e *FrozenError) Error() string {
+	return ErrFrozen.Error() + " (" + e.Window + "): change from " + e.Source + " rejected"
+}
+
+func (e *FrozenError) Is(target error) bool {
+	return target == ErrFrozen
+}
+
+// freezeOverride lifts freezes until a time, on someone's authority.
+type freezeOverride struct {
+	actor  string
+	reason string
+	until  time.Time
+}
+
+// freezeWindow is a parsed freeze window: either the absolute span from
+// start to end, or the daily span from the start to the end time of day on
+// the given weekdays.
+type freezeWindow struct {
+	start, end time.Time
+
+	days     [7]bool
+	from, to time.Duration
+	loc      *time.Location
+}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// parseFreezeWindow parses a window in one of two forms:
+//
+//	2026-12-20T00:00:00Z/2027-01-04T00:00:00Z
+//	Mon-Fri 09:30-16:00 America/New_York
+//
+// In the second, the days and the time zone are optional, defaulting to
+// every day and UTC, days may also be listed as Sat,Sun, and a span ending
+// before it starts runs past midnight.
+func parseFreezeWindow(spec string) (freezeWindow, error) {
+	var w freezeWindow
+	if from, to, ok := strings.Cut(spec, "/"); ok {
+		start, err := time.Parse(time.RFC3339, strings.TrimSpace(from))
+		if err == nil {
+			end, err := time.Parse(time.RFC3339, strings.TrimSpace(to))
+			if err != nil {
+				return w, fmt.Errorf("freeze window %q: %w", spec, err)
+			}
+			if !end.After(start) {
+				return w, fmt.Errorf("freeze window %q ends before it starts", spec)
+			}
+			w.start, w.end = start, end
+			return w, nil
+		}
+	}
+	fields := strings.Fields(spec)
+	if len(fields) == 0 {
+		return w, fmt.Errorf("freeze window %q is empty", spec)
+	}
+	w.loc = time.UTC
+	if c := fields[0][0]; c < '0' || c > '9' {
+		if err := w.parseDays(fields[0]); err != nil {
+			return w, fmt.Errorf("freeze window %q: %w", spec, err)
+		}
+		fields = fields[1:]
+	} else {
+		w.days = [7]bool{true, true, true, true, true, true, true}
+	}
+	if len(fields) == 0 || len(fields) > 2 {
+		return w, fmt.Errorf("freeze window %q is not DAYS HH:MM-HH:MM ZONE", spec)
+	}
+	if len(fields) == 2 {
+		loc, err := time.LoadLocation(fields[1])
+		if err != nil {
+			return w, fmt.Errorf("freeze window %q: %w", spec, err)
+		}
+		w.loc = loc
+	}
+	from, to, ok := strings.Cut(fields[0], "-")
+	var err error
+	if w.from, err = parseTimeOfDay(from); err != nil || !ok {
+		return w, fmt.Errorf("freeze window %q has an invalid span %q", spec, fields[0])
+	}
+	if w.to, err = parseTimeOfDay(to); err != nil || w.from == w.to {
+		return w, fmt.Errorf("freeze window %q has an invalid span %q", spec, fields[0])
+	}
+	return w, nil
+}
+
+// parseDays sets the days listed as Mon-Fri, Sat,Sun or a single day.
+func (w *freezeWindow) parseDays(s string) error {
+	for _, part := range strings.Split(s, ",") {
+		from, to, isRange := strings.Cut(strings.ToLower(part), "-")
+		first, ok := weekdays[from]
+		if !ok {
+			return fmt.Errorf("unknown day %q", from)
+		}
+		last := first
+		if isRange {
+			if last, ok = weekdays[to]; !ok {
+				return fmt.Errorf("unknown day %q", to)
+			}
+		}
+		for d := first; ; d = (d + 1) % 7 {
+			w.days[d] = true
+			if d == last {
+				break
+			}
+		}
+	}
+	return nil
+}
+
+func parseTimeOfDay(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// contains reports whether t falls in the window.
+func (w freezeWindow) contains(t time.Time) bool {
+	if w.loc == nil {
+		return !t.Before(w.start) && t.Before(w.end)
+	}
+	t = t.In(w.loc)
+	tod := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second
+	if w.from < w.to {
+		return w.days[t.Weekday()] && tod >= w.from && tod < w.to
+	}
+	// The span runs past midnight, so it belongs to the day it started.
+	yesterday := (t.Weekday() + 6) % 7
+	return w.days[t.Weekday()] && tod >= w.from || w.days[yesterday] && tod < w.to
+}
+
+// NewFreezeWindows registers a list flag of freeze windows, during which
+// changes after Parse, from reloads, providers, Set and Approve alike, are
+// rejected with a *FrozenError. Like any flag the windows can come from a
+// file or the environment; each is either an absolute span such as
+// "2026-12-20T00:00:00Z/2027-01-04T00:00:00Z" or a weekly one such as
+// "Mon-Fri 09:30-16:00 America/New_York". The windows in effect decide, so a
+// change cannot lift the freeze that blocks it. It panics if a default
+// window is invalid.
+func (c *Configurable) NewFreezeWindows(name string, windows []string, usage string, opts ...FlagOption) *[]string {
+	for _, spec := range windows {
+		if _, err := parseFreezeWindow(spec); err != nil {
+			panic("configurable: " + err.Error())
+		}
+	}
+	opts = append(opts, WithValidator(func(v interface{}) error {
+		for _, spec := range v.([]string) {
+			if _, err := parseFreezeWindow(spec); err != nil {
+				return err
+			}
+		}
+		return nil
+	}))
+	c.freezeFlag = name
+	return c.NewList(name, windows, usage, opts...)
+}
+
+// Frozen returns the freeze window in effect now, if any. An override does
+// not end it.
+func (c *Configurable) Frozen() (window string, frozen bool) {
+	return c.frozenAt(c.current(), c.clock().Now())
+}
+
+func (c *Configurable) frozenAt(s *snapshot, now time.Time) (string, bool) {
+	if c.freezeFlag == "" {
+		return "", false
+	}
+	specs, _ := s.values[c.freezeFlag].([]string)
+	for _, spec := range specs {
+		if w, err := parseFreezeWindow(spec); err == nil && w.contains(now) {
+			return spec, true
+		}
+	}
+	return "", false
+}
+
+// OverrideFreeze lets changes through freeze windows for d, as the escape
+// hatch for emergencies. The override and every change it lets through are
+// logged at warning level with actor and reason, for the audit trail.
+func (c *Configurable) OverrideFreeze(actor, reason string, d time.Duration) {
+	until := c.clock().Now().Add(d)
+	c.mu.Lock()
+	c.freezeOverride = &freezeOverride{actor: actor, reason: reason, until: until}
+	c.mu.Unlock()
+	c.logger().Warn("configurable: freeze overridden", "actor", actor, "reason", reason, "until", until)
+}
+
+// checkFreeze rejects d if a freeze window is in effect and not overridden.
+// The caller holds c.mu.
+func (c *Configurable) checkFreeze(d Diff) error {
+	now := c.clock().Now()
+	window, frozen := c.frozenAt(c.current(), now)
+	if !frozen {
+		return nil
+	}
+	if o := c.freezeOverride; o != nil && now.Before(o.until) {
+		names := make([]string, len(d.Changes))
+		for i, change := range d.Changes {
+			names[i] = change.Name
+		}
+		c.logger().Warn("configurable: change applied during freeze", "window", window, "source", d.Source, "flags", names, "actor", o.actor, "reason", o.reason)
+		return nil
+	}
+	return &FrozenError{Window: window, Source: d.Source}
+}
@@ -0,0 +1,116 @@
+package configurable
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFreezeWindows(t *testing.T) {
+	now := time.Now().UTC()
+	active := now.Add(-time.Hour).Format(time.RFC3339) + "/" + now.Add(time.Hour).Format(time.RFC3339)
+	past := now.Add(-2*time.Hour).Format(time.RFC3339) + "/" + now.Add(-time.Hour).Format(time.RFC3339)
+
+	newConf := func(t *testing.T, windows ...string) *Configurable {
+		conf := newTestConfigurable(t)
+		conf.NewFreezeWindows("freeze", windows, "freeze windows")
+		conf.NewInt("workers", 4, "workers")
+		conf.SetArgs([]string{})
+		assert.NoError(t, conf.Parse(""))
+		return conf
+	}
+
+	t.Run("test frozen", func(t *testing.T) {
+		conf := newConf(t, past, active)
+		window, frozen := conf.Frozen()
+		assert.True(t, frozen)
+		assert.Equal(t, active, window)
+
+		err := conf.Set("workers", 8)
+		assert.ErrorIs(t, err, ErrFrozen)
+		assert.EqualError(t, err, "configuration is frozen ("+active+"): change from set rejected")
+		assert.ErrorIs(t, conf.LoadData("json", []byte(`{"workers": 8}`)), ErrFrozen)
+		assert.ErrorIs(t, conf.Set("freeze", []string{}), ErrFrozen)
+		assert.Equal(t, 4, *conf.Int("workers"))
+
+		// A reload that changes nothing is not a change.
+		assert.NoError(t, conf.LoadData("json", []byte(`{"workers": 4}`)))
+	})
+
+	t.Run("test not frozen", func(t *testing.T) {
+		conf := newConf(t, past)
+		_, frozen := conf.Frozen()
+		assert.False(t, frozen)
+		assert.NoError(t, conf.Set("workers", 8))
+		assert.NoError(t, conf.Set("freeze", []string{active}))
+		assert.ErrorIs(t, conf.Set("workers", 9), ErrFrozen)
+	})
+
+	t.Run("test startup is not frozen", func(t *testing.T) {
+		conf := newTestConfigurable(t)
+		conf.NewFreezeWindows("freeze", []string{active}, "freeze windows")
+		conf.NewInt("workers", 4, "workers")
+		conf.SetArgs([]string{"-workers", "8"})
+		assert.NoError(t, conf.Parse(""))
+		assert.Equal(t, 8, *conf.Int("workers"))
+	})
+
+	t.Run("test override", func(t *testing.T) {
+		conf := newConf(t, active)
+		var buf bytes.Buffer
+		conf.SetLogger(slog.New(slog.NewTextHandler(&buf, nil)))
+		conf.OverrideFreeze("alice", "INC-42 rollback", time.Minute)
+		assert.NoError(t, conf.Set("workers", 8))
+		assert.Equal(t, 8, *conf.Int("workers"))
+		assert.Contains(t, buf.String(), "freeze overridden")
+		assert.Contains(t, buf.String(), `msg="configurable: change applied during freeze" window=`+active+` source=set flags=[workers] actor=alice reason="INC-42 rollback"`)
+
+		conf.OverrideFreeze("alice", "done", 0)
+		assert.ErrorIs(t, conf.Set("workers", 9), ErrFrozen)
+	})
+
+	t.Run("test invalid windows", func(t *testing.T) {
+		conf := newConf(t)
+		assert.ErrorContains(t, conf.Set("freeze", []string{"Someday 09:00-17:00"}), `unknown day "someday"`)
+		assert.Error(t, conf.Set("freeze", []string{"Mon-Fri 9am-5pm"}))
+		assert.Error(t, conf.Set("freeze", []string{"2026-01-02T00:00:00Z/2026-01-01T00:00:00Z"}))
+		assert.Panics(t, func() {
+			newTestConfigurable(t).NewFreezeWindows("freeze", []string{"09:00-09:00"}, "freeze windows")
+		})
+	})
+}
+
+func TestFreezeWindowContains(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("no time zone database")
+	}
+	at := func(s string) time.Time {
+		tm, err := time.ParseInLocation("2006-01-02 15:04", s, ny)
+		assert.NoError(t, err)
+		return tm
+	}
+	tests := []struct {
+		spec string
+		at   string
+		want bool
+	}{
+		{"Mon-Fri 09:30-16:00 America/New_York", "2026-10-16 10:00", true}, // Friday
+		{"Mon-Fri 09:30-16:00 America/New_York", "2026-10-16 16:00", false},
+		{"Mon-Fri 09:30-16:00 America/New_York", "2026-10-17 10:00", false}, // Saturday
+		{"Sat,Sun 00:00-06:00 America/New_York", "2026-10-18 05:59", true},
+		{"Fri-Mon 12:00-13:00 America/New_York", "2026-10-19 12:30", true}, // Monday
+		{"Fri-Mon 12:00-13:00 America/New_York", "2026-10-20 12:30", false},
+		{"Fri 22:00-02:00 America/New_York", "2026-10-17 01:00", true}, // Saturday, from Friday
+		{"Fri 22:00-02:00 America/New_York", "2026-10-16 01:00", false},
+		{"14:00-15:00", "2026-10-16 10:30", true}, // 14:30 UTC
+	}
+	for _, tt := range tests {
+		w, err := parseFreezeWindow(tt.spec)
+		assert.NoError(t, err)
+		assert.Equal(t, tt.want, w.contains(at(tt.at)), "%s at %s", tt.spec, tt.at)
+	}
+}
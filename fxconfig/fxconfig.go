@@ -0,0 +1,113 @@
+// Package fxconfig makes a Configurable part of a dependency injection
+// graph built with fx or dig.
+//
+// Module provides the Configurable to an fx application, parsing it when it
+// is first needed and tying its watches and Close to the application's
+// lifecycle.
+// Struct is a constructor for a typed configuration struct, filled with
+// Unmarshal, that fx.Provide and dig's Provide both accept, as does wire
+// once it is instantiated in a named function.
+package fxconfig
+
+import (
+	"context"
+
+	"github.com/andreimerlescu/configurable"
+	"go.uber.org/fx"
+)
+
+// Option configures Module.
+type Option func(*module)
+
+type module struct {
+	watchFile      bool
+	watchProviders bool
+}
+
+// WatchFile reloads the configuration file while the application runs, as
+// Watch does. It does nothing without a filename.
+func WatchFile() Option {
+	return func(m *module) {
+		m.watchFile = true
+	}
+}
+
+// WatchProviders applies the changes delivered by watching providers while
+// the application runs, as WatchProviders does.
+func WatchProviders() Option {
+	return func(m *module) {
+		m.watchProviders = true
+	}
+}
+
+// Module provides conf as a configurable.IConfigurable, parsed with
+// filename by the constructor, so a parse error fails the application
+// before it starts. The watches chosen by opts start with the application,
+// and conf is closed when it stops.
+func Module(conf configurable.IConfigurable, filename string, opts ...Option) fx.Option {
+	var m module
+	for _, opt := range opts {
+		opt(&m)
+	}
+	return fx.Module("configurable", fx.Provide(func(lc fx.Lifecycle) (configurable.IConfigurable, error) {
+		if err := conf.Parse(filename); err != nil {
+			return nil, err
+		}
+		lc.Append(m.hook(conf, filename))
+		return conf, nil
+	}))
+}
+
+// hook starts and stops the watches of conf.
+func (m module) hook(conf configurable.IConfigurable, filename string) fx.Hook {
+	var stopFile func()
+	var cancel context.CancelFunc
+	done := make(chan struct{})
+	return fx.Hook{
+		OnStart: func(context.Context) error {
+			if m.watchFile && filename != "" {
+				stop, err := conf.Watch(filename)
+				if err != nil {
+					return err
+				}
+				stopFile = stop
+			}
+			if !m.watchProviders {
+				close(done)
+				return nil
+			}
+			// The start context ends once the application has started, so
+			// the watch gets its own.
+			var ctx context.Context
+			ctx, cancel = context.WithCancel(context.Background())
+			go func() {
+				defer close(done)
+				_ = conf.WatchProviders(ctx)
+			}()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			if stopFile != nil {
+				stopFile()
+			}
+			if cancel != nil {
+				cancel()
+				select {
+				case <-done:
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+			return conf.Close()
+		},
+	}
+}
+
+// Struct fills a T, a struct type, with conf.Unmarshal, so
+// fx.Provide(fxconfig.Struct[Config]) makes Config available to every
+// constructor that needs it.
+func Struct[T any](conf configurable.IConfigurable) (T, error) {
+	var v T
+	err := conf.Unmarshal(&v)
+	return v, err
+}
@@ -0,0 +1,68 @@
+package fxconfig
+
+import (
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/andreimerlescu/configurable"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/fx"
+	"go.uber.org/goleak"
+)
+
+type serverConfig struct {
+	Addr    string        `config:"addr"`
+	Timeout time.Duration `config:"timeout"`
+}
+
+func newConf(t *testing.T) configurable.IConfigurable {
+	conf := configurable.New(configurable.WithFlagSet(flag.NewFlagSet(t.Name(), flag.ContinueOnError)))
+	conf.NewString("addr", ":8080", "listen address")
+	conf.NewDuration("timeout", time.Second, "timeout")
+	conf.SetArgs([]string{})
+	return conf
+}
+
+func TestModule(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	assert.NoError(t, os.WriteFile(path, []byte(`{"addr": ":9090"}`), 0o644))
+
+	t.Run("test provide", func(t *testing.T) {
+		var cfg serverConfig
+		var conf configurable.IConfigurable
+		app := fx.New(
+			fx.NopLogger,
+			Module(newConf(t), path),
+			fx.Provide(Struct[serverConfig]),
+			fx.Populate(&cfg, &conf),
+		)
+		assert.NoError(t, app.Err())
+		assert.Equal(t, serverConfig{Addr: ":9090", Timeout: time.Second}, cfg)
+		assert.Equal(t, ":9090", conf.View().String("addr"))
+	})
+
+	t.Run("test parse error", func(t *testing.T) {
+		bad := filepath.Join(t.TempDir(), "bad.json")
+		assert.NoError(t, os.WriteFile(bad, []byte(`{`), 0o644))
+		app := fx.New(fx.NopLogger, Module(newConf(t), bad), fx.Invoke(func(configurable.IConfigurable) {}))
+		assert.Error(t, app.Err())
+	})
+
+	t.Run("test lifecycle", func(t *testing.T) {
+		defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+		var conf configurable.IConfigurable
+		app := fx.New(fx.NopLogger, Module(newConf(t), path, WatchFile(), WatchProviders()), fx.Populate(&conf))
+		assert.NoError(t, app.Start(context.Background()))
+		assert.NoError(t, os.WriteFile(path, []byte(`{"addr": ":7070"}`), 0o644))
+		assert.Eventually(t, func() bool { return conf.View().String("addr") == ":7070" }, 2*time.Second, 10*time.Millisecond)
+		assert.NoError(t, app.Stop(context.Background()))
+
+		assert.NoError(t, os.WriteFile(path, []byte(`{"addr": ":6060"}`), 0o644))
+		time.Sleep(300 * time.Millisecond)
+		assert.Equal(t, ":7070", conf.View().String("addr"), "the watch stops with the application")
+	})
+}
@@ -0,0 +1,49 @@
+package configurable
+
+// Values are published in generations. Writers (Parse, loads and env
+// lookups) mutate the flag storage with c.mu held and then publish a copy of
+// every value as a new generation. Views share that copy, so reading one
+// never blocks on or observes a load in progress, and a load that fails
+// publishes nothing, leaving the previous generation in place. Values
+// written directly through the flag storage pointers are published by the
+// next Set or load.
+
+// current returns the newest generation.
+func (c *Configurable) current() *snapshot {
+	if s := c.generation.Load(); s != nil {
+		return s
+	}
+	return &snapshot{values: map[string]interface{}{}, fs: c.fs}
+}
+
+// publish makes the values now in flag storage the newest generation. The
+// caller holds c.mu.
+func (c *Configurable) publish() *snapshot {
+	next := &snapshot{values: c.values(), fs: c.fs}
+	if prev := c.generation.Load(); prev != nil {
+		next.id = prev.id + 1
+	}
+	c.generation.Store(next)
+	return next
+}
+
+// update runs fn, which mutates flag storage, excluding other writers. When
+// fn succeeds the result is published and change functions are notified;
+// when it fails the current generation stays as it was.
+func (c *Configurable) update(fn func() error) error {
+	c.mu.Lock()
+	before := c.current()
+	if err := fn(); err != nil {
+		c.mu.Unlock()
+		return err
+	}
+	after := c.publish()
+	events := c.changeEvents(before, after)
+	parsed := c.parsed
+	c.mu.Unlock()
+	c.notifyChanges(after, events)
+	if parsed {
+		c.saveLastKnownGood()
+	}
+	return nil
+}
@@ -0,0 +1,165 @@
+package configurable
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerations(t *testing.T) {
+	newConf := func(t *testing.T) *Configurable {
+		conf := newTestConfigurable(t)
+		conf.NewInt("min", 1, "min")
+		conf.NewInt("max", 2, "max")
+		conf.NewList("tags", []string{"a"}, "tags")
+		return conf
+	}
+
+	t.Run("test failed load publishes nothing", func(t *testing.T) {
+		conf := newConf(t)
+		view := conf.View()
+		err := conf.LoadData("json", []byte(`{"min": 5, "max": "many"}`))
+		assert.Error(t, err)
+		assert.Same(t, view, conf.View())
+		assert.Equal(t, 1, *conf.Int("min"))
+	})
+
+	t.Run("test loads publish a new generation", func(t *testing.T) {
+		conf := newConf(t)
+		first := conf.current()
+		assert.NoError(t, conf.LoadData("json", []byte(`{"min": 5, "max": 10}`)))
+		second := conf.current()
+		assert.Equal(t, first.id+1, second.id)
+		assert.Equal(t, 1, first.Int("min"))
+		assert.Equal(t, 5, second.Int("min"))
+	})
+
+	t.Run("test parsing publishes a new generation", func(t *testing.T) {
+		conf := newConf(t)
+		view := conf.View()
+		assert.NoError(t, conf.ParseArgs([]string{"-min", "0"}))
+		assert.Equal(t, 1, view.Int("min"))
+		assert.Equal(t, 0, conf.View().Int("min"))
+		assert.Equal(t, view.(*snapshot).id+1, conf.current().id)
+	})
+
+	t.Run("test direct writes are published by the next write", func(t *testing.T) {
+		conf := newConf(t)
+		var changed []string
+		conf.OnChange(func(_ View, names []string) { changed = names })
+		view := conf.View()
+		*conf.flags["max"].(*int) = 3
+		assert.Same(t, view, conf.View(), "reading publishes nothing")
+		assert.Equal(t, 2, conf.View().Int("max"))
+		assert.NoError(t, conf.Set("min", 2))
+		assert.Equal(t, 3, conf.View().Int("max"))
+		assert.Equal(t, []string{"max", "min"}, changed)
+	})
+
+	t.Run("test views are not shared mutably", func(t *testing.T) {
+		conf := newConf(t)
+		tags := conf.View().List("tags")
+		tags[0] = "changed"
+		assert.Equal(t, []string{"a"}, conf.View().List("tags"))
+	})
+
+	t.Run("test readers see whole generations", func(t *testing.T) {
+		conf := newConf(t)
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				data := fmt.Sprintf(`{"min": %d, "max": %d}`, i, i+1)
+				assert.NoError(t, conf.LoadData("json", []byte(data)))
+			}
+		}()
+		for i := 0; i < 200; i++ {
+			v := conf.View()
+			assert.Equal(t, v.Int("min")+1, v.Int("max"))
+		}
+		wg.Wait()
+	})
+}
+
+func TestConcurrentUse(t *testing.T) {
+	conf := newTestConfigurable(t)
+	conf.NewInt("workers", 1, "workers")
+	conf.NewString("mode", "a", "mode")
+	conf.NewList("tags", []string{"a"}, "tags")
+	conf.SetArgs([]string{})
+	assert.NoError(t, conf.Parse(""))
+
+	var wg sync.WaitGroup
+	wg.Add(3)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			assert.NoError(t, conf.LoadData("json", []byte(fmt.Sprintf(`{"workers": %d, "unknown": 1}`, i))))
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			assert.NoError(t, conf.Set("mode", fmt.Sprint(i)))
+			conf.SetEnv("tags", fmt.Sprintf("a,%d", i))
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			conf.Int("workers")
+			conf.String("mode")
+			conf.List("tags")
+			v := conf.View()
+			assert.GreaterOrEqual(t, v.Int("workers"), 0)
+			assert.NotEmpty(t, v.String("mode"))
+			assert.Equal(t, "a", v.List("tags")[0])
+			conf.Source("workers")
+			conf.Flags()
+			conf.Problems()
+			conf.Health()
+			_ = conf.Banner(BannerOptions{})
+			_, _ = conf.Manifest()
+			_, _ = conf.Dump("json")
+			_, _ = conf.Dump("ini")
+		}
+	}()
+	wg.Wait()
+}
+
+func TestConcurrentSetup(t *testing.T) {
+	conf := newTestConfigurable(t)
+	conf.NewInt("workers", 1, "workers")
+	conf.SetArgs([]string{})
+	assert.NoError(t, conf.Parse(""))
+
+	var wg sync.WaitGroup
+	wg.Add(3)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			conf.NewInt(fmt.Sprintf("plugin-%d", i), i, "plugin")
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			assert.NoError(t, conf.LoadData("json", []byte(fmt.Sprintf(`{"workers": %d}`, i))))
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			conf.ReadOnly(i%2 == 0)
+			err := conf.Set("workers", i)
+			if err != nil {
+				assert.ErrorIs(t, err, ErrReadOnly)
+			}
+		}
+	}()
+	wg.Wait()
+	assert.Equal(t, 99, conf.View().Int("plugin-99"))
+}
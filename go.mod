@@ -1,14 +1,22 @@
 module github.com/andreimerlescu/configurable
 
-go 1.23
+go 1.23.0
 
 require (
+	github.com/fsnotify/fsnotify v1.8.0
 	github.com/go-ini/ini v1.67.0
-	github.com/stretchr/testify v1.8.4
+	github.com/pelletier/go-toml/v2 v2.2.3
+	github.com/stretchr/testify v1.10.0
+	go.uber.org/goleak v1.3.0
+	golang.org/x/crypto v0.41.0
+	golang.org/x/sys v0.35.0
+	golang.org/x/term v0.34.0
 	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/kr/pretty v0.3.1 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
+	gopkg.in/check.v1 v1.0.0-20190902080502-41f04d3bba15 // indirect
 )
@@ -0,0 +1,6 @@
+// Package configpb holds the protobuf messages and gRPC stubs of the
+// configuration service the grpcconfig provider talks to. Servers implement
+// ConfigServiceServer.
+package configpb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative config.proto
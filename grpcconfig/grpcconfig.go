@@ -0,0 +1,141 @@
+// Package grpcconfig is a configurable.Provider for a central configuration
+// service speaking the ConfigService protocol in configpb.
+//
+// Values travel typed: an int stays an int64, a duration a
+// google.protobuf.Duration, a list a StringList, so nothing is parsed from
+// strings on the way. As a configurable.Watcher the provider streams new
+// versions with WatchConfig, reopening the stream when it breaks.
+package grpcconfig
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/andreimerlescu/configurable"
+	"github.com/andreimerlescu/configurable/grpcconfig/configpb"
+	"google.golang.org/grpc"
+)
+
+// DefaultRetryInterval is how long Watch waits before reopening a broken
+// stream.
+const DefaultRetryInterval = 5 * time.Second
+
+// Provider loads the configuration of one service.
+type Provider struct {
+	client  configpb.ConfigServiceClient
+	service string
+	retry   time.Duration
+	clock   configurable.Clock
+}
+
+// Option configures a Provider.
+type Option func(*Provider)
+
+// WithRetryInterval sets how long Watch waits before reopening a broken
+// stream. The default is DefaultRetryInterval.
+func WithRetryInterval(d time.Duration) Option {
+	return func(p *Provider) {
+		p.retry = d
+	}
+}
+
+// WithClock sets the clock Watch waits on before reopening a stream, so
+// tests can drive it with a configurable.ManualClock. The default is the
+// system clock.
+func WithClock(clock configurable.Clock) Option {
+	return func(p *Provider) {
+		p.clock = clock
+	}
+}
+
+// New returns a Provider for the configuration of service. conn decides the
+// server, credentials and interceptors.
+func New(conn grpc.ClientConnInterface, service string, opts ...Option) *Provider {
+	p := &Provider{client: configpb.NewConfigServiceClient(conn), service: service, retry: DefaultRetryInterval, clock: configurable.SystemClock()}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+func (p *Provider) Name() string {
+	return "grpc:" + p.service
+}
+
+// Load returns the current configuration.
+func (p *Provider) Load(ctx context.Context) (map[string]interface{}, error) {
+	cfg, err := p.client.GetConfig(ctx, &configpb.GetConfigRequest{Service: p.service})
+	if err != nil {
+		return nil, err
+	}
+	return Values(cfg)
+}
+
+// Watch applies each version the server streams until ctx is done. A broken
+// stream is reopened after the retry interval, asking only for versions newer
+// than the last one applied.
+func (p *Provider) Watch(ctx context.Context, apply func(map[string]interface{}) error) error {
+	var version string
+	for {
+		stream, err := p.client.WatchConfig(ctx, &configpb.WatchConfigRequest{Service: p.service, Version: version})
+		for err == nil {
+			var cfg *configpb.Config
+			if cfg, err = stream.Recv(); err != nil {
+				break
+			}
+			if values, err := Values(cfg); err == nil && apply(values) == nil {
+				version = cfg.GetVersion()
+			}
+		}
+		if err := p.wait(ctx); err != nil {
+			return err
+		}
+	}
+}
+
+// wait waits the retry interval, or until ctx is done.
+func (p *Provider) wait(ctx context.Context) error {
+	waited := make(chan struct{})
+	timer := p.clock.AfterFunc(p.retry, func() { close(waited) })
+	select {
+	case <-ctx.Done():
+		timer.Stop()
+		return ctx.Err()
+	case <-waited:
+		return nil
+	}
+}
+
+// Values converts cfg into the values Configurable accepts from a document.
+func Values(cfg *configpb.Config) (map[string]interface{}, error) {
+	values := make(map[string]interface{}, len(cfg.GetValues()))
+	for name, v := range cfg.GetValues() {
+		switch kind := v.GetKind().(type) {
+		case *configpb.Value_IntValue:
+			values[name] = kind.IntValue
+		case *configpb.Value_FloatValue:
+			values[name] = kind.FloatValue
+		case *configpb.Value_StringValue:
+			values[name] = kind.StringValue
+		case *configpb.Value_BoolValue:
+			values[name] = kind.BoolValue
+		case *configpb.Value_DurationValue:
+			if err := kind.DurationValue.CheckValid(); err != nil {
+				return nil, fmt.Errorf("value %s: %w", name, err)
+			}
+			values[name] = kind.DurationValue.AsDuration()
+		case *configpb.Value_ListValue:
+			values[name] = append([]string{}, kind.ListValue.GetValues()...)
+		case *configpb.Value_MapValue:
+			m := make(map[string]interface{}, len(kind.MapValue.GetValues()))
+			for key, item := range kind.MapValue.GetValues() {
+				m[key] = item
+			}
+			values[name] = m
+		default:
+			return nil, fmt.Errorf("value %s has no kind", name)
+		}
+	}
+	return values, nil
+}
@@ -0,0 +1,153 @@
+package grpcconfig
+
+import (
+	"context"
+	"flag"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/andreimerlescu/configurable"
+	"github.com/andreimerlescu/configurable/grpcconfig/configpb"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// server serves config and streams each Config sent on updates, ending the
+// stream when it receives nil.
+type server struct {
+	configpb.UnimplementedConfigServiceServer
+	config  *configpb.Config
+	updates chan *configpb.Config
+
+	mu       sync.Mutex
+	versions []string
+}
+
+func (s *server) GetConfig(ctx context.Context, req *configpb.GetConfigRequest) (*configpb.Config, error) {
+	return s.config, nil
+}
+
+func (s *server) WatchConfig(req *configpb.WatchConfigRequest, stream configpb.ConfigService_WatchConfigServer) error {
+	s.mu.Lock()
+	s.versions = append(s.versions, req.GetVersion())
+	s.mu.Unlock()
+	for {
+		select {
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		case cfg := <-s.updates:
+			if cfg == nil {
+				return nil
+			}
+			if err := stream.Send(cfg); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (s *server) watchedFrom() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string{}, s.versions...)
+}
+
+func dial(t *testing.T, srv *server) *grpc.ClientConn {
+	lis := bufconn.Listen(1 << 20)
+	s := grpc.NewServer()
+	configpb.RegisterConfigServiceServer(s, srv)
+	go s.Serve(lis)
+	t.Cleanup(s.Stop)
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	assert.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func str(s string) *configpb.Value {
+	return &configpb.Value{Kind: &configpb.Value_StringValue{StringValue: s}}
+}
+
+func TestProvider(t *testing.T) {
+	t.Run("test typed values", func(t *testing.T) {
+		srv := &server{config: &configpb.Config{Version: "7", Values: map[string]*configpb.Value{
+			"workers":     {Kind: &configpb.Value_IntValue{IntValue: 8}},
+			"quota":       {Kind: &configpb.Value_IntValue{IntValue: 9007199254740993}},
+			"ratio":       {Kind: &configpb.Value_FloatValue{FloatValue: 0.25}},
+			"debug":       {Kind: &configpb.Value_BoolValue{BoolValue: true}},
+			"timeout":     {Kind: &configpb.Value_DurationValue{DurationValue: durationpb.New(1500 * time.Millisecond)}},
+			"hosts":       {Kind: &configpb.Value_ListValue{ListValue: &configpb.StringList{Values: []string{"a", "b"}}}},
+			"ports":       {Kind: &configpb.Value_ListValue{ListValue: &configpb.StringList{Values: []string{"80", "443"}}}},
+			"labels":      {Kind: &configpb.Value_MapValue{MapValue: &configpb.StringMap{Values: map[string]string{"team": "core"}}}},
+			"limits.name": str("edge"),
+		}}}
+		conf := configurable.New(configurable.WithFlagSet(flag.NewFlagSet(t.Name(), flag.ContinueOnError)))
+		workers := conf.NewInt("workers", 1, "workers")
+		quota := conf.NewInt64("quota", 0, "quota")
+		ratio := conf.NewFloat64("ratio", 0, "ratio")
+		debug := conf.NewBool("debug", false, "debug")
+		timeout := conf.NewDuration("timeout", time.Second, "timeout")
+		hosts := conf.NewList("hosts", nil, "hosts")
+		ports := conf.NewIntSlice("ports", nil, "ports")
+		labels := conf.NewMap("labels", nil, "labels")
+		name := conf.NewString("limits.name", "", "name")
+
+		p := New(dial(t, srv), "billing")
+		assert.Equal(t, "grpc:billing", p.Name())
+		conf.AddProvider(p)
+		conf.SetArgs([]string{})
+		assert.NoError(t, conf.Parse(""))
+		assert.Equal(t, 8, *workers)
+		assert.Equal(t, int64(9007199254740993), *quota)
+		assert.Equal(t, 0.25, *ratio)
+		assert.True(t, *debug)
+		assert.Equal(t, 1500*time.Millisecond, *timeout)
+		assert.Equal(t, []string{"a", "b"}, *hosts)
+		assert.Equal(t, []int{80, 443}, *ports)
+		assert.Equal(t, map[string]string{"team": "core"}, *labels)
+		assert.Equal(t, "edge", *name)
+	})
+
+	t.Run("test invalid values", func(t *testing.T) {
+		_, err := Values(&configpb.Config{Values: map[string]*configpb.Value{"x": {}}})
+		assert.ErrorContains(t, err, "value x has no kind")
+		_, err = Values(&configpb.Config{Values: map[string]*configpb.Value{
+			"x": {Kind: &configpb.Value_DurationValue{DurationValue: &durationpb.Duration{Seconds: 1, Nanos: -1}}},
+		}})
+		assert.ErrorContains(t, err, "value x")
+	})
+
+	t.Run("test watch", func(t *testing.T) {
+		srv := &server{config: &configpb.Config{Version: "1"}, updates: make(chan *configpb.Config)}
+		conf := configurable.New(configurable.WithFlagSet(flag.NewFlagSet(t.Name(), flag.ContinueOnError)))
+		conf.NewString("mode", "a", "mode")
+		clock := configurable.NewManualClock(time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC))
+		conf.AddProvider(New(dial(t, srv), "billing", WithClock(clock)))
+		conf.SetArgs([]string{})
+		assert.NoError(t, conf.Parse(""))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go conf.WatchProviders(ctx)
+		srv.updates <- &configpb.Config{Version: "2", Values: map[string]*configpb.Value{"mode": str("b")}}
+		assert.Eventually(t, func() bool { return conf.View().String("mode") == "b" }, time.Second, time.Millisecond)
+
+		// The stream breaks and is reopened from the last version applied.
+		srv.updates <- nil
+		go func() {
+			srv.updates <- &configpb.Config{Version: "3", Values: map[string]*configpb.Value{"mode": str("c")}}
+		}()
+		assert.Eventually(t, func() bool {
+			clock.Advance(DefaultRetryInterval)
+			return conf.View().String("mode") == "c"
+		}, time.Second, time.Millisecond, "the retry waits on the clock")
+		assert.Equal(t, []string{"", "2"}, srv.watchedFrom())
+	})
+}
@@ -0,0 +1,70 @@
+package grpcconfig
+
+import (
+	"context"
+
+	"github.com/andreimerlescu/configurable/grpcconfig/configpb"
+	"google.golang.org/grpc"
+)
+
+// Subscription is a Provider that watches with StreamConfig, the versioned
+// subscribe and acknowledge flow of the xDS protocols, for service meshes
+// whose control plane tracks the version each instance runs.
+//
+// Every version pushed is answered: with an ACK carrying it once applied,
+// or with a NACK carrying the version still in effect and the reason the
+// push was rejected, such as a failed validation.
+type Subscription struct {
+	*Provider
+	node string
+}
+
+// NewSubscription returns a Subscription to the configuration of service
+// for the instance node.
+func NewSubscription(conn grpc.ClientConnInterface, service, node string, opts ...Option) *Subscription {
+	return &Subscription{Provider: New(conn, service, opts...), node: node}
+}
+
+// Watch subscribes and applies each version the server pushes until ctx is
+// done. A broken stream is reopened after the retry interval, subscribing
+// with the version in effect.
+func (s *Subscription) Watch(ctx context.Context, apply func(map[string]interface{}) error) error {
+	var version string
+	for {
+		s.stream(ctx, &version, apply)
+		if err := s.wait(ctx); err != nil {
+			return err
+		}
+	}
+}
+
+// stream runs one subscription until it breaks, keeping version current.
+func (s *Subscription) stream(ctx context.Context, version *string, apply func(map[string]interface{}) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := s.client.StreamConfig(ctx)
+	if err != nil {
+		return err
+	}
+	req := &configpb.SubscribeRequest{Service: s.service, Node: s.node, Version: *version}
+	for {
+		if err := stream.Send(req); err != nil {
+			return err
+		}
+		resp, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+		req = &configpb.SubscribeRequest{Service: s.service, Node: s.node, ResponseNonce: resp.GetNonce()}
+		values, err := Values(resp.GetConfig())
+		if err == nil {
+			err = apply(values)
+		}
+		if err != nil {
+			req.ErrorDetail = err.Error()
+		} else {
+			*version = resp.GetConfig().GetVersion()
+		}
+		req.Version = *version
+	}
+}
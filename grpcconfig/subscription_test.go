@@ -0,0 +1,126 @@
+package grpcconfig
+
+import (
+	"context"
+	"flag"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/andreimerlescu/configurable"
+	"github.com/andreimerlescu/configurable/grpcconfig/configpb"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// controlPlane pushes each response sent on pushes, ending the stream when
+// it receives nil, and reports every request on requests.
+type controlPlane struct {
+	configpb.UnimplementedConfigServiceServer
+	pushes   chan *configpb.ConfigResponse
+	requests chan *configpb.SubscribeRequest
+}
+
+func (s *controlPlane) GetConfig(ctx context.Context, req *configpb.GetConfigRequest) (*configpb.Config, error) {
+	return &configpb.Config{}, nil
+}
+
+func (s *controlPlane) StreamConfig(stream configpb.ConfigService_StreamConfigServer) error {
+	go func() {
+		for {
+			req, err := stream.Recv()
+			if err != nil {
+				return
+			}
+			s.requests <- req
+		}
+	}()
+	for {
+		select {
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		case resp := <-s.pushes:
+			if resp == nil {
+				return nil
+			}
+			if err := stream.Send(resp); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func push(version, nonce, workers string) *configpb.ConfigResponse {
+	return &configpb.ConfigResponse{Nonce: nonce, Config: &configpb.Config{Version: version, Values: map[string]*configpb.Value{"workers": str(workers)}}}
+}
+
+func TestSubscription(t *testing.T) {
+	cp := &controlPlane{pushes: make(chan *configpb.ConfigResponse), requests: make(chan *configpb.SubscribeRequest, 10)}
+	lis := bufconn.Listen(1 << 20)
+	s := grpc.NewServer()
+	configpb.RegisterConfigServiceServer(s, cp)
+	go s.Serve(lis)
+	defer s.Stop()
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	conf := configurable.New(configurable.WithFlagSet(flag.NewFlagSet(t.Name(), flag.ContinueOnError)))
+	conf.NewInt("workers", 1, "workers")
+	clock := configurable.NewManualClock(time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC))
+	conf.AddProvider(NewSubscription(conn, "billing", "pod-1", WithClock(clock)))
+	conf.SetArgs([]string{})
+	assert.NoError(t, conf.Parse(""))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go conf.WatchProviders(ctx)
+
+	receive := func() *configpb.SubscribeRequest {
+		select {
+		case req := <-cp.requests:
+			return req
+		case <-time.After(time.Second):
+			t.Fatal("no request")
+			return nil
+		}
+	}
+
+	req := receive()
+	assert.Equal(t, "billing", req.GetService())
+	assert.Equal(t, "pod-1", req.GetNode())
+	assert.Empty(t, req.GetVersion())
+	assert.Empty(t, req.GetResponseNonce())
+
+	cp.pushes <- push("v1", "n1", "4")
+	req = receive()
+	assert.Equal(t, "v1", req.GetVersion())
+	assert.Equal(t, "n1", req.GetResponseNonce())
+	assert.Empty(t, req.GetErrorDetail())
+	assert.Equal(t, 4, conf.View().Int("workers"))
+
+	cp.pushes <- push("v2", "n2", "many")
+	req = receive()
+	assert.Equal(t, "v1", req.GetVersion())
+	assert.Equal(t, "n2", req.GetResponseNonce())
+	assert.Contains(t, req.GetErrorDetail(), "workers")
+	assert.Equal(t, 4, conf.View().Int("workers"))
+
+	// The stream breaks and the client subscribes again from v1.
+	cp.pushes <- nil
+	assert.Eventually(t, func() bool {
+		clock.Advance(DefaultRetryInterval)
+		select {
+		case req = <-cp.requests:
+			return true
+		default:
+			return false
+		}
+	}, time.Second, time.Millisecond, "the retry waits on the clock")
+	assert.Equal(t, "v1", req.GetVersion())
+	assert.Empty(t, req.GetResponseNonce())
+}
@@ -0,0 +1,91 @@
+package configurable
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrLimitExceeded is wrapped by errors reporting that a source broke one of
+// the size limits in ParseOptions.
+var ErrLimitExceeded = errors.New("configuration limit exceeded")
+
+// checkLength enforces ParseOptions.MaxValueLength on one value of name.
+func (c *Configurable) checkLength(name, value string) error {
+	if max := c.parseOptions.MaxValueLength; max > 0 && len(value) > max {
+		return fmt.Errorf("value for %s is longer than %d bytes: %w", name, max, ErrLimitExceeded)
+	}
+	return nil
+}
+
+// checkCount enforces ParseOptions.MaxEntries on a list or map of n entries.
+func (c *Configurable) checkCount(name string, n int) error {
+	if max := c.parseOptions.MaxEntries; max > 0 && n > max {
+		return fmt.Errorf("%s has more than %d entries: %w", name, max, ErrLimitExceeded)
+	}
+	return nil
+}
+
+// checkRaw enforces the limits on a raw value from a document before it is
+// converted.
+func (c *Configurable) checkRaw(name string, raw interface{}) error {
+	switch v := raw.(type) {
+	case string:
+		if err := c.checkLength(name, v); err != nil {
+			return err
+		}
+		switch c.flags[name].(type) {
+		case *ListFlag, typedList, *MapFlag:
+			return c.checkCount(name, strings.Count(v, ",")+1)
+		}
+	case []string:
+		if err := c.checkCount(name, len(v)); err != nil {
+			return err
+		}
+		for _, item := range v {
+			if err := c.checkLength(name, item); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		if err := c.checkCount(name, len(v)); err != nil {
+			return err
+		}
+		for _, item := range v {
+			if err := c.checkRaw(name, item); err != nil {
+				return err
+			}
+		}
+	case map[string]interface{}:
+		if err := c.checkCount(name, len(v)); err != nil {
+			return err
+		}
+		for key, item := range v {
+			if err := c.checkLength(name, key); err != nil {
+				return err
+			}
+			if err := c.checkRaw(name, item); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// checkEntries enforces ParseOptions.MaxEntries on value, the accumulated
+// value of a list or map flag.
+func (c *Configurable) checkEntries(name string, value interface{}) error {
+	switch v := value.(type) {
+	case []string:
+		return c.checkCount(name, len(v))
+	case []int:
+		return c.checkCount(name, len(v))
+	case []float64:
+		return c.checkCount(name, len(v))
+	case []bool:
+		return c.checkCount(name, len(v))
+	case map[string]string:
+		return c.checkCount(name, len(v))
+	}
+	return nil
+}
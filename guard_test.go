@@ -0,0 +1,65 @@
+package configurable
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimits(t *testing.T) {
+	os.Clearenv()
+
+	newConf := func(t *testing.T) *Configurable {
+		conf := newTestConfigurable(t)
+		conf.NewString("name", "", "name")
+		conf.NewList("tags", []string{}, "tags")
+		conf.NewMap("labels", map[string]string{}, "labels")
+		conf.SetParseOptions(ParseOptions{MaxValueLength: 8, MaxEntries: 2, MaxFileSize: 64})
+		conf.SetOutput(&bytes.Buffer{})
+		return conf
+	}
+
+	t.Run("test value length on the command line", func(t *testing.T) {
+		conf := newConf(t)
+		assert.NoError(t, conf.ParseArgs([]string{"-name", "12345678"}))
+		err := newConf(t).ParseArgs([]string{"-name=123456789"})
+		assert.ErrorIs(t, err, ErrLimitExceeded)
+		assert.EqualError(t, err, "value for name is longer than 8 bytes: configuration limit exceeded")
+	})
+
+	t.Run("test entries on the command line", func(t *testing.T) {
+		conf := newConf(t)
+		assert.NoError(t, conf.ParseArgs([]string{"-tags", "a", "-tags", "b"}))
+		err := newConf(t).ParseArgs([]string{"-tags", "a,b", "-tags", "c"})
+		assert.EqualError(t, err, "tags has more than 2 entries: configuration limit exceeded")
+	})
+
+	t.Run("test limits in documents", func(t *testing.T) {
+		assert.NoError(t, newConf(t).LoadData("json", []byte(`{"tags": ["a", "b"]}`)))
+		assert.ErrorIs(t, newConf(t).LoadData("json", []byte(`{"tags": ["a", "b", "c"]}`)), ErrLimitExceeded)
+		assert.ErrorIs(t, newConf(t).LoadData("json", []byte(`{"labels": {"a": "123456789"}}`)), ErrLimitExceeded)
+		assert.ErrorIs(t, newConf(t).LoadData("yaml", []byte("name: "+strings.Repeat("x", 80))), ErrLimitExceeded)
+	})
+
+	t.Run("test file size", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config.json")
+		assert.NoError(t, os.WriteFile(path, []byte(`{"name": "`+strings.Repeat("x", 64)+`"}`), 0644))
+		err := newConf(t).LoadFile(path)
+		assert.ErrorIs(t, err, ErrLimitExceeded)
+		assert.Contains(t, err.Error(), "larger than 64 bytes")
+	})
+
+	t.Run("test oversized env values are skipped", func(t *testing.T) {
+		conf := newConf(t)
+		conf.SetEnv("name", "123456789")
+		conf.SetEnv("tags", "a,b,c")
+		conf.SetArgs([]string{})
+		assert.NoError(t, conf.Parse(""))
+		assert.Equal(t, "", *conf.String("name"))
+		assert.Empty(t, *conf.List("tags"))
+	})
+}
@@ -0,0 +1,116 @@
+package configurable
+
+import (
+	"time"
+)
+
+// HealthStatus is the state of the configuration sources, for readiness
+// probes.
+type HealthStatus struct {
+	// Healthy is true once Parse has succeeded and every source is healthy.
+	Healthy bool           `json:"healthy"`
+	Sources []SourceHealth `json:"sources"`
+}
+
+// SourceHealth is the state of one config file or provider.
+type SourceHealth struct {
+	// Source is the file or provider, such as "file config.yaml" or
+	// "remote consul".
+	Source string `json:"source"`
+	// Healthy is true when the source has loaded, its last load or update
+	// succeeded, it is not stale and its watch, if any, has not failed.
+	Healthy bool `json:"healthy"`
+	// LastLoad is when the source last loaded or delivered an update.
+	LastLoad time.Time `json:"lastLoad,omitempty"`
+	// Error is why the last load or update failed.
+	Error string `json:"error,omitempty"`
+	// Cached is set while a provider's cached copy stands in for it.
+	Cached bool `json:"cached,omitempty"`
+	// Stale is set when a provider with WithMaxAge has not loaded within it.
+	Stale    bool `json:"stale,omitempty"`
+	Watching bool `json:"watching,omitempty"`
+	// WatchError is why the provider's watch stopped.
+	WatchError string `json:"watchError,omitempty"`
+}
+
+// WithMaxAge makes Health report the provider as stale when it has not
+// loaded or delivered an update for longer than d.
+func WithMaxAge(d time.Duration) SourceOption {
+	return func(s *remoteSource) {
+		s.maxAge = d
+	}
+}
+
+type sourceHealth struct {
+	SourceHealth
+	maxAge time.Duration
+}
+
+// Health reports the state of every config file and provider loaded so far.
+func (c *Configurable) Health() HealthStatus {
+	c.mu.Lock()
+	parsed := c.parsed
+	c.mu.Unlock()
+	c.healthMu.Lock()
+	defer c.healthMu.Unlock()
+	status := HealthStatus{Healthy: parsed}
+	now := c.clock().Now()
+	for _, name := range c.healthOrder {
+		h := c.health[name]
+		s := h.SourceHealth
+		s.Stale = h.maxAge > 0 && !s.LastLoad.IsZero() && now.Sub(s.LastLoad) > h.maxAge
+		s.Healthy = !s.LastLoad.IsZero() && s.Error == "" && !s.Stale && s.WatchError == ""
+		status.Healthy = status.Healthy && s.Healthy
+		status.Sources = append(status.Sources, s)
+	}
+	return status
+}
+
+// sourceHealth returns the health record of source, creating it on first
+// use.
+func (c *Configurable) sourceHealth(source string) *sourceHealth {
+	if c.health == nil {
+		c.health = make(map[string]*sourceHealth)
+	}
+	h, ok := c.health[source]
+	if !ok {
+		h = &sourceHealth{SourceHealth: SourceHealth{Source: source}}
+		c.health[source] = h
+		c.healthOrder = append(c.healthOrder, source)
+	}
+	return h
+}
+
+// recordLoad records the outcome of loading source. cached is set when a
+// cached copy was applied instead.
+func (c *Configurable) recordLoad(source string, err error, cached bool) {
+	c.healthMu.Lock()
+	defer c.healthMu.Unlock()
+	h := c.sourceHealth(source)
+	h.Cached = cached
+	if err != nil {
+		h.Error = err.Error()
+		return
+	}
+	h.Error = ""
+	h.LastLoad = c.clock().Now()
+}
+
+// recordWatch records that the watch of source started, or stopped with err.
+func (c *Configurable) recordWatch(source string, watching bool, err error) {
+	c.healthMu.Lock()
+	defer c.healthMu.Unlock()
+	h := c.sourceHealth(source)
+	h.Watching = watching
+	h.WatchError = ""
+	if err != nil {
+		h.WatchError = err.Error()
+	}
+}
+
+// registerSource lists source in Health before it first loads.
+func (c *Configurable) registerSource(source string, maxAge time.Duration) {
+	c.healthMu.Lock()
+	defer c.healthMu.Unlock()
+	c.sourceHealth(source).maxAge = maxAge
+}
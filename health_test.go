@@ -0,0 +1,91 @@
+package configurable
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// failingWatcher is a provider whose watch fails with err.
+type failingWatcher struct {
+	fakeProvider
+	watchErr error
+}
+
+func (p *failingWatcher) Watch(ctx context.Context, apply func(map[string]interface{}) error) error {
+	return p.watchErr
+}
+
+func TestHealth(t *testing.T) {
+	os.Clearenv()
+
+	t.Run("test healthy", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config.json")
+		assert.NoError(t, os.WriteFile(path, []byte(`{"port": 8080}`), 0o644))
+		conf := newTestConfigurable(t)
+		conf.NewInt("port", 80, "port")
+		conf.AddProvider(&fakeProvider{name: "consul", data: map[string]interface{}{}}, WithMaxAge(time.Hour))
+		assert.False(t, conf.Health().Healthy)
+
+		conf.SetArgs([]string{})
+		assert.NoError(t, conf.Parse(path))
+		h := conf.Health()
+		assert.True(t, h.Healthy)
+		assert.Len(t, h.Sources, 2)
+		assert.Equal(t, "remote consul", h.Sources[0].Source)
+		assert.Equal(t, "file "+path, h.Sources[1].Source)
+		assert.False(t, h.Sources[1].LastLoad.IsZero())
+
+		conf.healthMu.Lock()
+		conf.health["remote consul"].LastLoad = time.Now().Add(-2 * time.Hour)
+		conf.healthMu.Unlock()
+		h = conf.Health()
+		assert.False(t, h.Healthy)
+		assert.True(t, h.Sources[0].Stale)
+	})
+
+	t.Run("test provider down", func(t *testing.T) {
+		conf := newTestConfigurable(t)
+		conf.NewInt("port", 80, "port")
+		conf.AddProvider(&fakeProvider{name: "consul", err: errors.New("connection refused")}, WithFailurePolicy(UseDefaults))
+		conf.SetArgs([]string{})
+		assert.NoError(t, conf.Parse(""))
+		h := conf.Health()
+		assert.False(t, h.Healthy)
+		assert.Equal(t, "connection refused", h.Sources[0].Error)
+	})
+
+	t.Run("test watch failed", func(t *testing.T) {
+		conf := newTestConfigurable(t)
+		conf.AddProvider(&failingWatcher{fakeProvider: fakeProvider{name: "push", data: map[string]interface{}{}}, watchErr: errors.New("stream reset")})
+		conf.SetArgs([]string{})
+		assert.NoError(t, conf.Parse(""))
+		assert.True(t, conf.Health().Healthy)
+
+		assert.EqualError(t, conf.WatchProviders(context.Background()), "stream reset")
+		h := conf.Health()
+		assert.False(t, h.Healthy)
+		assert.False(t, h.Sources[0].Watching)
+		assert.Equal(t, "watch stopped: stream reset", h.Sources[0].WatchError)
+	})
+
+	t.Run("test watch cancelled", func(t *testing.T) {
+		conf := newTestConfigurable(t)
+		p := &pushProvider{fakeProvider: fakeProvider{name: "push", data: map[string]interface{}{}}, docs: make(chan map[string]interface{}), results: make(chan error)}
+		conf.AddProvider(p)
+		conf.SetArgs([]string{})
+		assert.NoError(t, conf.Parse(""))
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error)
+		go func() { done <- conf.WatchProviders(ctx) }()
+		assert.Eventually(t, func() bool { return conf.Health().Sources[0].Watching }, time.Second, time.Millisecond)
+		cancel()
+		<-done
+		assert.True(t, conf.Health().Healthy)
+	})
+}
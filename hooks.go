@@ -0,0 +1,88 @@
+package configurable
+
+import "fmt"
+
+// Hooks are called around configuration loads and changes, so applications
+// can prepare for new values, such as by pausing traffic, and catch up after
+// them, such as by flushing caches or re-dialing connections. Any of the
+// functions may be nil.
+type Hooks struct {
+	// PreLoad is called before a source, such as "file config.yaml" or
+	// "remote consul", is read. Returning an error abandons the load, which
+	// fails with that error.
+	PreLoad func(source string) error
+	// PostLoad is called once the source has been read and applied, with the
+	// error the load failed with, if any.
+	PostLoad func(source string, err error)
+	// PreApply is called with a change that has passed every check, just
+	// before it is applied. Returning an error rejects the change. It runs
+	// while writers are excluded, so it must not change configuration.
+	PreApply func(change Diff) error
+	// PostApply is called once the change has been applied.
+	PostApply func(change Diff)
+}
+
+// AddHooks registers h. Hooks run in the order they were added. The apply
+// hooks are called for changes made by LoadFile, LoadData, a provider or Set
+// that alter at least one value.
+func (c *Configurable) AddHooks(h Hooks) {
+	c.hooks = append(c.hooks, h)
+}
+
+// loading runs load between the PreLoad and PostLoad hooks.
+func (c *Configurable) loading(source string, load func() error) error {
+	for _, h := range c.hooks {
+		if h.PreLoad == nil {
+			continue
+		}
+		if err := h.PreLoad(source); err != nil {
+			err = fmt.Errorf("load of %s abandoned: %w", source, err)
+			c.postLoad(source, err)
+			return err
+		}
+	}
+	err := load()
+	c.postLoad(source, err)
+	return err
+}
+
+func (c *Configurable) postLoad(source string, err error) {
+	for _, h := range c.hooks {
+		if h.PostLoad != nil {
+			h.PostLoad(source, err)
+		}
+	}
+}
+
+// preApply runs the PreApply hooks on the change from the current values to
+// staged, returning the change for postApply. The caller holds c.mu.
+func (c *Configurable) preApply(source ValueSource, staged map[string]interface{}) (Diff, error) {
+	if len(c.hooks) == 0 {
+		return Diff{}, nil
+	}
+	d := c.diff(source, staged)
+	if len(d.Changes) == 0 {
+		return d, nil
+	}
+	for _, h := range c.hooks {
+		if h.PreApply == nil {
+			continue
+		}
+		if err := h.PreApply(d); err != nil {
+			return d, fmt.Errorf("change from %s rejected by hook: %w", d.Source, err)
+		}
+	}
+	return d, nil
+}
+
+// postApply runs the PostApply hooks on a change returned by preApply.
+func (c *Configurable) postApply(d Diff) {
+	if len(d.Changes) == 0 {
+		return
+	}
+	for _, h := range c.hooks {
+		if h.PostApply != nil {
+			h.PostApply(d)
+		}
+	}
+}
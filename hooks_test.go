@@ -0,0 +1,84 @@
+package configurable
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHooks(t *testing.T) {
+	os.Clearenv()
+
+	t.Run("test order", func(t *testing.T) {
+		conf := newTestConfigurable(t)
+		port := conf.NewInt("port", 80, "port")
+		var events []string
+		conf.AddHooks(Hooks{
+			PreLoad:  func(source string) error { events = append(events, "pre-load "+source); return nil },
+			PostLoad: func(source string, err error) { events = append(events, "post-load "+source) },
+			PreApply: func(d Diff) error {
+				events = append(events, "pre-apply "+d.Source)
+				assert.Equal(t, 80, *port)
+				return nil
+			},
+			PostApply: func(d Diff) {
+				events = append(events, "post-apply "+d.Source)
+				assert.Equal(t, []Change{{Name: "port", Old: 80, New: 8080}}, d.Changes)
+			},
+		})
+		conf.OnChange(func(View, []string) { events = append(events, "change") })
+
+		path := filepath.Join(t.TempDir(), "config.json")
+		assert.NoError(t, os.WriteFile(path, []byte(`{"port": 8080}`), 0600))
+		assert.NoError(t, conf.LoadFile(path))
+		assert.Equal(t, []string{
+			"pre-load file " + path,
+			"pre-apply file " + path,
+			"change",
+			"post-apply file " + path,
+			"post-load file " + path,
+		}, events)
+
+		// A load that changes nothing does not apply.
+		events = nil
+		assert.NoError(t, conf.LoadFile(path))
+		assert.Equal(t, []string{"pre-load file " + path, "post-load file " + path}, events)
+	})
+
+	t.Run("test rejections", func(t *testing.T) {
+		conf := newTestConfigurable(t)
+		port := conf.NewInt("port", 80, "port")
+		errBusy := errors.New("draining")
+		var loadErr error
+		conf.AddHooks(Hooks{
+			PreLoad:  func(source string) error { return errBusy },
+			PostLoad: func(source string, err error) { loadErr = err },
+		})
+		err := conf.LoadData("json", []byte(`{"port": 8080}`))
+		assert.ErrorIs(t, err, errBusy)
+		assert.Equal(t, err, loadErr)
+		assert.Equal(t, 80, *port)
+
+		conf = newTestConfigurable(t)
+		port = conf.NewInt("port", 80, "port")
+		conf.AddHooks(Hooks{PreApply: func(Diff) error { return errBusy }})
+		assert.ErrorIs(t, conf.Set("port", 8080), errBusy)
+		assert.ErrorIs(t, conf.LoadData("json", []byte(`{"port": 8080}`)), errBusy)
+		assert.Equal(t, 80, *port)
+	})
+
+	t.Run("test provider", func(t *testing.T) {
+		conf := newTestConfigurable(t)
+		conf.NewInt("port", 80, "port")
+		conf.AddProvider(&fakeProvider{name: "consul", data: map[string]interface{}{"port": 8080}})
+		var sources []string
+		conf.AddHooks(Hooks{PostApply: func(d Diff) { sources = append(sources, d.Source) }})
+		assert.NoError(t, conf.LoadProviders(context.Background()))
+		assert.NoError(t, conf.Set("port", 9090))
+		assert.Equal(t, []string{"remote consul", "set"}, sources)
+	})
+}
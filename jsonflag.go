@@ -0,0 +1,86 @@
+package configurable
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// JSONFlag is a flag holding a small structured value as a JSON document.
+// The command line and environment give it inline JSON; files may also nest
+// the value as an object or list.
+type JSONFlag struct {
+	value *json.RawMessage
+}
+
+func (j *JSONFlag) String() string {
+	if j == nil || j.value == nil {
+		return ""
+	}
+	return string(*j.value)
+}
+
+func (j *JSONFlag) Set(value string) error {
+	doc, err := compactJSON([]byte(value))
+	if err != nil {
+		return err
+	}
+	*j.value = doc
+	return nil
+}
+
+// set stores raw, which is inline JSON if it is a string and is otherwise
+// a value decoded from a document.
+func (j *JSONFlag) set(raw interface{}) error {
+	if s, ok := raw.(string); ok {
+		return j.Set(s)
+	}
+	if doc, ok := raw.(json.RawMessage); ok {
+		return j.Set(string(doc))
+	}
+	b, err := json.Marshal(raw)
+	if err != nil {
+		return fmt.Errorf("cannot convert %v to JSON: %w", raw, err)
+	}
+	return j.Set(string(b))
+}
+
+// compactJSON validates doc and strips its insignificant whitespace, so
+// equal documents compare equal.
+func compactJSON(doc []byte) (json.RawMessage, error) {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, doc); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// NewJSON registers a flag holding a JSON document, for small structured
+// values such as a retry policy that do not deserve a flag per field. Decode
+// it with json.Unmarshal. A string from a file is read as inline JSON too,
+// so a JSON string must be quoted twice.
+func (c *Configurable) NewJSON(name string, value json.RawMessage, usage string, opts ...FlagOption) *json.RawMessage {
+	if len(value) == 0 {
+		value = json.RawMessage("null")
+	}
+	doc, err := compactJSON(value)
+	if err != nil {
+		panic(fmt.Sprintf("configurable: NewJSON on %s with a default that is %v", name, err))
+	}
+	j := &JSONFlag{value: &doc}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.fs.Var(j, name, usage)
+	c.flags[name] = j
+	c.annotate(name, opts)
+	return j.value
+}
+
+// JSON returns the value of the JSON flag name, or nil if it is not one.
+func (c *Configurable) JSON(name string) *json.RawMessage {
+	c.checkAndSetFromEnv(name)
+	if ptr, ok := c.flags[name].(*JSONFlag); ok {
+		return ptr.value
+	}
+	return nil
+}
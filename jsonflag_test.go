@@ -0,0 +1,66 @@
+package configurable
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJSONFlag(t *testing.T) {
+	os.Clearenv()
+
+	type retryPolicy struct {
+		Max     int    `json:"max"`
+		Backoff string `json:"backoff"`
+	}
+	decode := func(doc *json.RawMessage) retryPolicy {
+		var p retryPolicy
+		assert.NoError(t, json.Unmarshal(*doc, &p))
+		return p
+	}
+
+	t.Run("test sources", func(t *testing.T) {
+		conf := newTestConfigurable(t)
+		retry := conf.NewJSON("retry", json.RawMessage(`{"max": 3, "backoff": "1s"}`), "retry policy")
+		assert.Equal(t, `{"max":3,"backoff":"1s"}`, string(*retry))
+
+		conf.SetArgs([]string{"-retry", `{"max": 5}`})
+		assert.NoError(t, conf.Parse(""))
+		assert.Equal(t, retryPolicy{Max: 5}, decode(retry))
+
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		assert.NoError(t, os.WriteFile(path, []byte("retry:\n  max: 7\n  backoff: 2s\n"), 0600))
+		assert.NoError(t, conf.LoadFile(path))
+		assert.Equal(t, retryPolicy{Max: 5}, decode(retry), "the command line outranks files")
+		conf.SetPrecedence(SourceEnv, SourceFile)
+		assert.NoError(t, conf.LoadFile(path))
+		assert.Equal(t, retryPolicy{Max: 7, Backoff: "2s"}, decode(retry))
+
+		conf.SetEnv("retry", `{"max": 9}`)
+		assert.Equal(t, retryPolicy{Max: 9}, decode(conf.JSON("retry")))
+
+		assert.Error(t, conf.LoadData("json", []byte(`{"retry": "{not json"}`)))
+		assert.Equal(t, retryPolicy{Max: 9}, decode(retry))
+	})
+
+	t.Run("test output", func(t *testing.T) {
+		conf := newTestConfigurable(t)
+		conf.NewJSON("retry", json.RawMessage(`{"max": 3}`), "retry policy")
+		conf.NewJSON("empty", nil, "nothing")
+		conf.SetArgs([]string{})
+		assert.NoError(t, conf.Parse(""))
+		view, _ := conf.View().Lookup("retry")
+		assert.Equal(t, json.RawMessage(`{"max":3}`), view)
+
+		out, err := conf.Dump("yaml")
+		assert.NoError(t, err)
+		assert.Contains(t, string(out), "retry:\n    max: 3")
+		out, err = conf.Dump("json")
+		assert.NoError(t, err)
+		assert.Contains(t, string(out), `"empty": null`)
+		assert.Panics(t, func() { conf.NewJSON("bad", json.RawMessage(`{`), "") })
+	})
+}
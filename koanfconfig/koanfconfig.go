@@ -0,0 +1,132 @@
+// Package koanfconfig bridges a Configurable and knadh/koanf, so koanf's
+// providers and parsers can feed a Configurable and a Configurable can be
+// loaded into koanf.
+package koanfconfig
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/andreimerlescu/configurable"
+	"github.com/knadh/koanf/v2"
+)
+
+// Provider is a configurable.Provider reading a koanf provider.
+type Provider struct {
+	name   string
+	p      koanf.Provider
+	parser koanf.Parser
+}
+
+// watchable is implemented by koanf providers that watch their source, such
+// as the file provider.
+type watchable interface {
+	Watch(cb func(event interface{}, err error)) error
+}
+
+// WatchProvider is a Provider whose koanf provider watches its source. It is
+// a configurable.Watcher.
+type WatchProvider struct {
+	*Provider
+}
+
+// New returns a configurable.Provider named name that loads p, decoding
+// what it reads with parser, or with p's own Read if parser is nil. When p
+// can watch its source, the result is a *WatchProvider; otherwise it is a
+// *Provider.
+func New(name string, p koanf.Provider, parser koanf.Parser) configurable.Provider {
+	provider := &Provider{name: name, p: p, parser: parser}
+	if _, ok := p.(watchable); ok {
+		return &WatchProvider{Provider: provider}
+	}
+	return provider
+}
+
+func (p *Provider) Name() string {
+	return p.name
+}
+
+func (p *Provider) Load(ctx context.Context) (map[string]interface{}, error) {
+	if p.parser == nil {
+		return p.p.Read()
+	}
+	data, err := p.p.ReadBytes()
+	if err != nil {
+		return nil, err
+	}
+	return p.parser.Unmarshal(data)
+}
+
+// Watch reloads the provider whenever its koanf provider reports a change,
+// until ctx is done or the watch fails.
+func (p *WatchProvider) Watch(ctx context.Context, apply func(map[string]interface{}) error) error {
+	failed := make(chan error, 1)
+	err := p.p.(watchable).Watch(func(event interface{}, err error) {
+		if err == nil {
+			var data map[string]interface{}
+			if data, err = p.Load(ctx); err == nil {
+				// Rejected updates are reported by the Configurable.
+				_ = apply(data)
+				return
+			}
+		}
+		select {
+		case failed <- err:
+		default:
+		}
+	})
+	if err != nil {
+		return err
+	}
+	if u, ok := p.p.(interface{ Unwatch() error }); ok {
+		defer u.Unwatch()
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case err := <-failed:
+		return err
+	}
+}
+
+// Source is a koanf.Provider reading a Configurable's current values.
+type Source struct {
+	conf configurable.IConfigurable
+}
+
+// NewSource returns a koanf.Provider for conf. Load it with a nil parser, or
+// with a JSON parser.
+func NewSource(conf configurable.IConfigurable) *Source {
+	return &Source{conf: conf}
+}
+
+// ReadBytes returns the values as JSON, as Dump does.
+func (s *Source) ReadBytes() ([]byte, error) {
+	return s.conf.Dump("json")
+}
+
+// Read returns the values nested by the dots in flag names, as koanf
+// expects.
+func (s *Source) Read() (map[string]interface{}, error) {
+	view := s.conf.View()
+	out := make(map[string]interface{})
+	for _, name := range view.Names() {
+		value, _ := view.Lookup(name)
+		keys := strings.Split(name, ".")
+		m := out
+		for _, key := range keys[:len(keys)-1] {
+			next, ok := m[key].(map[string]interface{})
+			if !ok {
+				if _, taken := m[key]; taken {
+					return nil, errors.New("koanfconfig: flag " + name + " is nested under another flag")
+				}
+				next = make(map[string]interface{})
+				m[key] = next
+			}
+			m = next
+		}
+		m[keys[len(keys)-1]] = value
+	}
+	return out, nil
+}
@@ -0,0 +1,94 @@
+package koanfconfig
+
+import (
+	"context"
+	"flag"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/andreimerlescu/configurable"
+	"github.com/knadh/koanf/parsers/json"
+	"github.com/knadh/koanf/providers/rawbytes"
+	"github.com/knadh/koanf/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func newConf(t *testing.T) configurable.IConfigurable {
+	return configurable.New(configurable.WithFlagSet(flag.NewFlagSet(t.Name(), flag.ContinueOnError)))
+}
+
+// watched is a koanf provider whose source changes on demand.
+type watched struct {
+	data map[string]interface{}
+	cb   func(event interface{}, err error)
+	set  chan struct{}
+}
+
+func (w *watched) ReadBytes() ([]byte, error)            { return nil, nil }
+func (w *watched) Read() (map[string]interface{}, error) { return w.data, nil }
+
+func (w *watched) Watch(cb func(event interface{}, err error)) error {
+	w.cb = cb
+	close(w.set)
+	return nil
+}
+
+func TestProvider(t *testing.T) {
+	os.Clearenv()
+
+	t.Run("test parser", func(t *testing.T) {
+		conf := newConf(t)
+		port := conf.NewInt("server.port", 80, "port")
+		p := New("raw", rawbytes.Provider([]byte(`{"server": {"port": 8080}}`)), json.Parser())
+		_, watching := p.(configurable.Watcher)
+		assert.False(t, watching)
+		conf.AddProvider(p)
+		conf.SetArgs([]string{})
+		assert.NoError(t, conf.Parse(""))
+		assert.Equal(t, 8080, *port)
+	})
+
+	t.Run("test watch", func(t *testing.T) {
+		conf := newConf(t)
+		conf.NewInt("port", 80, "port")
+		w := &watched{data: map[string]interface{}{"port": 8080}, set: make(chan struct{})}
+		p := New("watched", w, nil)
+		conf.AddProvider(p)
+		conf.SetArgs([]string{})
+		assert.NoError(t, conf.Parse(""))
+		assert.Equal(t, 8080, conf.View().Int("port"))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error)
+		go func() { done <- conf.WatchProviders(ctx) }()
+		<-w.set
+		w.data = map[string]interface{}{"port": 9090}
+		w.cb(nil, nil)
+		assert.Equal(t, 9090, conf.View().Int("port"))
+		cancel()
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("watch did not stop")
+		}
+	})
+}
+
+func TestSource(t *testing.T) {
+	os.Clearenv()
+	conf := newConf(t)
+	conf.NewInt("server.port", 80, "port")
+	conf.NewList("hosts", []string{"a"}, "hosts")
+	conf.SetArgs([]string{"-server.port", "8080"})
+	assert.NoError(t, conf.Parse(""))
+
+	k := koanf.New(".")
+	assert.NoError(t, k.Load(NewSource(conf), nil))
+	assert.Equal(t, 8080, k.Int("server.port"))
+	assert.Equal(t, []string{"a"}, k.Strings("hosts"))
+
+	k = koanf.New(".")
+	assert.NoError(t, k.Load(NewSource(conf), json.Parser()))
+	assert.Equal(t, 8080, k.Int("server.port"))
+}
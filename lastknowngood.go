@@ -0,0 +1,84 @@
+package configurable
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"time"
+)
+
+// lastKnownGoodFile is the name of the last-known-good file in the cache
+// directory.
+const lastKnownGoodFile = "last-known-good.json"
+
+// lastKnownGood is the document persisted after each successful load.
+type lastKnownGood struct {
+	Saved  time.Time              `json:"saved"`
+	Values map[string]interface{} `json:"values"`
+}
+
+// saveLastKnownGood persists the current values to the cache directory, if
+// one is set. Failing to do so is logged but never fails a load.
+func (c *Configurable) saveLastKnownGood() {
+	if c.cacheDir == "" {
+		return
+	}
+	data, err := json.Marshal(lastKnownGood{Saved: c.clock().Now().UTC(), Values: c.exportValues()})
+	if err == nil {
+		err = makeDir(c.cacheDir, cacheDirMode)
+	}
+	if err == nil {
+		err = writeFile(filepath.Join(c.cacheDir, lastKnownGoodFile), data, privateFileMode)
+	}
+	if err != nil {
+		c.logger().Warn("configurable: cannot save last-known-good configuration", "error", err)
+	}
+}
+
+// LoadLastKnownGood applies the values persisted by the last successful Parse
+// or reload, so a process can start when every source is down. The values
+// replace the current ones, and a warning giving their age is logged.
+func (c *Configurable) LoadLastKnownGood() error {
+	if c.cacheDir == "" {
+		return errors.New("no cache directory set")
+	}
+	path := filepath.Join(c.cacheDir, lastKnownGoodFile)
+	data, err := readFile(path, c.parseOptions.MaxFileSize)
+	if err != nil {
+		return err
+	}
+	var saved lastKnownGood
+	if err := unmarshalJSON(data, &saved); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	values := make(map[string]interface{}, len(saved.Values))
+	for name, raw := range saved.Values {
+		if _, ok := c.flags[name]; !ok {
+			continue
+		}
+		value, err := c.convert(name, raw)
+		if err != nil {
+			return fmt.Errorf("%s: error setting key %s: %w", path, name, err)
+		}
+		values[name] = value
+	}
+	err = c.update(func() error {
+		for name, value := range values {
+			assign(c.flags[name], value)
+			c.sources[name] = ValueSource{Kind: SourceFile, Name: path}
+		}
+		r := c.report.source(SourceFile, path)
+		for name := range values {
+			r.Keys = appendUnique(r.Keys, name)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	c.invalidateTenants()
+	c.logger().Warn("configurable: using last-known-good configuration",
+		"saved", saved.Saved, "age", c.clock().Now().Sub(saved.Saved).Round(time.Second))
+	return nil
+}
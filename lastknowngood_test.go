@@ -0,0 +1,62 @@
+package configurable
+
+import (
+	"bytes"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLastKnownGood(t *testing.T) {
+	os.Clearenv()
+	dir := t.TempDir()
+
+	newConf := func(t *testing.T, logs *bytes.Buffer) *Configurable {
+		conf := newTestConfigurable(t)
+		conf.NewInt("port", 80, "port")
+		conf.NewDuration("timeout", time.Second, "timeout")
+		conf.NewList("tags", []string{"a"}, "tags")
+		conf.SetCacheDir(dir)
+		conf.SetLogger(slog.New(slog.NewTextHandler(logs, nil)))
+		return conf
+	}
+
+	t.Run("test without a saved copy", func(t *testing.T) {
+		conf := newConf(t, &bytes.Buffer{})
+		conf.SetCacheDir(t.TempDir())
+		assert.Error(t, conf.LoadLastKnownGood())
+	})
+
+	t.Run("test saved after parse and restored", func(t *testing.T) {
+		conf := newConf(t, &bytes.Buffer{})
+		conf.SetArgs([]string{"-port", "8080", "-timeout", "5s", "-tags", "b"})
+		assert.NoError(t, conf.Parse(""))
+		assert.FileExists(t, dir+"/"+lastKnownGoodFile)
+		info, err := os.Stat(dir + "/" + lastKnownGoodFile)
+		assert.NoError(t, err)
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+		var logs bytes.Buffer
+		conf = newConf(t, &logs)
+		assert.NoError(t, conf.LoadLastKnownGood())
+		assert.Equal(t, 8080, *conf.Int("port"))
+		assert.Equal(t, 5*time.Second, *conf.Duration("timeout"))
+		assert.Equal(t, []string{"a", "b"}, *conf.List("tags"))
+		assert.Contains(t, logs.String(), "level=WARN msg=\"configurable: using last-known-good configuration\"")
+		assert.Contains(t, logs.String(), "age=")
+	})
+
+	t.Run("test saved after reload", func(t *testing.T) {
+		conf := newConf(t, &bytes.Buffer{})
+		conf.SetArgs([]string{})
+		assert.NoError(t, conf.Parse(""))
+		assert.NoError(t, conf.LoadData("json", []byte(`{"port": 9090}`)))
+
+		conf = newConf(t, &bytes.Buffer{})
+		assert.NoError(t, conf.LoadLastKnownGood())
+		assert.Equal(t, 9090, *conf.Int("port"))
+	})
+}
@@ -0,0 +1,90 @@
+package configurable
+
+import (
+	"errors"
+	"sort"
+)
+
+// ErrNotLeader is matched by errors.Is for every *NotLeaderError.
+var ErrNotLeader = errors.New("only the leader may change this flag")
+
+// NotLeaderError is returned by Set for a flag marked WithLeaderOnly when
+// this instance is not the leader.
+type NotLeaderError struct {
+	Name string
+}
+
+func (e *NotLeaderError) Error() string {
+	return ErrNotLeader.Error() + ": " + e.Name
+}
+
+func (e *NotLeaderError) Is(target error) bool {
+	return target == ErrNotLeader
+}
+
+// Leadership tells whether this instance leads its cluster, as decided by
+// the application's own election, such as a Raft node or a lease.
+type Leadership interface {
+	IsLeader() bool
+}
+
+// LeadershipFunc adapts a function to Leadership.
+type LeadershipFunc func() bool
+
+func (f LeadershipFunc) IsLeader() bool {
+	return f()
+}
+
+// WithLeaderOnly lets only the leader change the flag at runtime, for
+// settings such as compaction schedules that must not diverge between
+// instances. Followers leave such changes from files and providers to the
+// application's replication, which applies them with ApplyReplicated, and
+// refuse them from Set. It takes effect once SetLeadership is called; startup
+// values are not gated.
+func WithLeaderOnly() FlagOption {
+	return func(m *flagMeta) {
+		m.leaderOnly = true
+	}
+}
+
+// SetLeadership sets how Configurable learns whether it runs on the leader.
+// Until it is called every instance is treated as the leader.
+func (c *Configurable) SetLeadership(l Leadership) {
+	c.leadership = l
+}
+
+// ApplyReplicated applies values replicated from the leader, including
+// leader-only flags. Like LoadData it is vetted by the validators and
+// policies, and it is recorded as SourceReplicated.
+func (c *Configurable) ApplyReplicated(data map[string]interface{}) error {
+	return c.setValuesFromMap(SourceReplicated, "", data)
+}
+
+// heldForLeader reports whether a runtime change to name from kind must be
+// left to the leader.
+func (c *Configurable) heldForLeader(kind SourceKind, name string) bool {
+	if !c.parsed || c.leadership == nil || kind == SourceReplicated {
+		return false
+	}
+	m, ok := c.meta[name]
+	return ok && m.leaderOnly && !c.leadership.IsLeader()
+}
+
+// holdForLeader removes from names the flags a follower leaves to the leader,
+// logging them.
+func (c *Configurable) holdForLeader(kind SourceKind, source string, names []string) []string {
+	kept := names[:0]
+	var held []string
+	for _, name := range names {
+		if c.heldForLeader(kind, name) {
+			held = append(held, name)
+		} else {
+			kept = append(kept, name)
+		}
+	}
+	if len(held) > 0 {
+		sort.Strings(held)
+		c.logger().Info("configurable: leader-only flags left to replication", "source", ValueSource{Kind: kind, Name: source}.String(), "flags", held)
+	}
+	return kept
+}
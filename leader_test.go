@@ -0,0 +1,61 @@
+package configurable
+
+import (
+	"os"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLeaderOnly(t *testing.T) {
+	os.Clearenv()
+
+	newConf := func(t *testing.T, leader *atomic.Bool) *Configurable {
+		conf := newTestConfigurable(t)
+		conf.NewString("compaction", "daily", "compaction schedule", WithLeaderOnly())
+		conf.NewInt("workers", 1, "workers")
+		conf.SetLeadership(LeadershipFunc(leader.Load))
+		conf.SetArgs([]string{})
+		assert.NoError(t, conf.Parse(""))
+		return conf
+	}
+
+	t.Run("test leader applies", func(t *testing.T) {
+		var leader atomic.Bool
+		leader.Store(true)
+		conf := newConf(t, &leader)
+		assert.NoError(t, conf.LoadData("json", []byte(`{"compaction": "hourly"}`)))
+		assert.NoError(t, conf.Set("workers", 4))
+		assert.Equal(t, "hourly", conf.View().String("compaction"))
+	})
+
+	t.Run("test follower holds", func(t *testing.T) {
+		var leader atomic.Bool
+		conf := newConf(t, &leader)
+		assert.NoError(t, conf.LoadData("json", []byte(`{"compaction": "hourly", "workers": 4}`)))
+		assert.Equal(t, "daily", conf.View().String("compaction"))
+		assert.Equal(t, 4, conf.View().Int("workers"))
+
+		err := conf.Set("compaction", "hourly")
+		assert.ErrorIs(t, err, ErrNotLeader)
+		assert.EqualError(t, err, "only the leader may change this flag: compaction")
+		assert.NoError(t, conf.Set("workers", 8))
+
+		assert.NoError(t, conf.ApplyReplicated(map[string]interface{}{"compaction": "hourly"}))
+		assert.Equal(t, "hourly", conf.View().String("compaction"))
+		assert.Equal(t, "replicated", conf.sources["compaction"].String())
+
+		leader.Store(true)
+		assert.NoError(t, conf.Set("compaction", "weekly"))
+		assert.Equal(t, "weekly", conf.View().String("compaction"))
+	})
+
+	t.Run("test startup not gated", func(t *testing.T) {
+		conf := newTestConfigurable(t)
+		conf.NewString("compaction", "daily", "compaction schedule", WithLeaderOnly())
+		conf.SetLeadership(LeadershipFunc(func() bool { return false }))
+		assert.NoError(t, conf.LoadData("json", []byte(`{"compaction": "hourly"}`)))
+		assert.Equal(t, "hourly", conf.View().String("compaction"))
+	})
+}
@@ -0,0 +1,106 @@
+// Package limits exposes numeric limits and toggles from configuration
+// through lock-free lookups for hot paths.
+//
+// Every int, int64, float64 and bool flag named "<prefix>.<name>" becomes a
+// limit called <name>. The whole set is replaced atomically whenever the
+// configuration changes, so readers never observe a half-applied reload and
+// never touch the Configurable itself.
+package limits
+
+import (
+	"strings"
+	"sync/atomic"
+
+	"github.com/andreimerlescu/configurable"
+)
+
+// Registry holds the current limits.
+type Registry struct {
+	prefix string
+	values atomic.Pointer[map[string]float64]
+}
+
+// New returns a Registry of the limits under prefix in conf, kept up to date
+// through conf.OnChange.
+func New(conf configurable.IConfigurable, prefix string) *Registry {
+	r := &Registry{prefix: prefix + "."}
+	r.update(conf.View())
+	conf.OnChange(func(v configurable.View, _ []string) {
+		r.update(v)
+	})
+	return r
+}
+
+func (r *Registry) update(v configurable.View) {
+	values := make(map[string]float64)
+	for _, name := range v.Names() {
+		if !strings.HasPrefix(name, r.prefix) {
+			continue
+		}
+		key := strings.TrimPrefix(name, r.prefix)
+		value, _ := v.Lookup(name)
+		switch n := value.(type) {
+		case int:
+			values[key] = float64(n)
+		case int64:
+			values[key] = float64(n)
+		case float64:
+			values[key] = n
+		case bool:
+			if n {
+				values[key] = 1
+			} else {
+				values[key] = 0
+			}
+		}
+	}
+	r.values.Store(&values)
+}
+
+// Get returns the limit called name, or 0 if there is none.
+func (r *Registry) Get(name string) float64 {
+	return (*r.values.Load())[name]
+}
+
+// Lookup is Get reporting whether the limit exists.
+func (r *Registry) Lookup(name string) (float64, bool) {
+	v, ok := (*r.values.Load())[name]
+	return v, ok
+}
+
+// Int returns the limit called name truncated to an int64.
+func (r *Registry) Int(name string) int64 {
+	return int64(r.Get(name))
+}
+
+// Enabled reports whether the toggle called name is on.
+func (r *Registry) Enabled(name string) bool {
+	return r.Get(name) != 0
+}
+
+var defaultRegistry atomic.Pointer[Registry]
+
+// Init installs the package-level registry used by Get, Int and Enabled.
+func Init(conf configurable.IConfigurable, prefix string) *Registry {
+	r := New(conf, prefix)
+	defaultRegistry.Store(r)
+	return r
+}
+
+// Get returns a limit from the registry installed by Init, or 0 before Init.
+func Get(name string) float64 {
+	if r := defaultRegistry.Load(); r != nil {
+		return r.Get(name)
+	}
+	return 0
+}
+
+// Int returns a limit from the registry installed by Init as an int64.
+func Int(name string) int64 {
+	return int64(Get(name))
+}
+
+// Enabled reports whether a toggle in the registry installed by Init is on.
+func Enabled(name string) bool {
+	return Get(name) != 0
+}
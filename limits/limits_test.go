@@ -0,0 +1,33 @@
+package limits
+
+import (
+	"testing"
+
+	"github.com/andreimerlescu/configurable"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry(t *testing.T) {
+	assert.Zero(t, Get("max-uploads"))
+
+	conf := configurable.New()
+	conf.NewInt("limits.max-uploads", 10, "maximum concurrent uploads")
+	conf.NewFloat64("limits.error-budget", 0.5, "error budget")
+	conf.NewBool("limits.uploads-enabled", true, "accept uploads")
+	conf.NewString("limits.note", "ignored", "not numeric")
+	conf.NewInt("port", 80, "outside the prefix")
+
+	r := Init(conf, "limits")
+	assert.Equal(t, 10.0, Get("max-uploads"))
+	assert.Equal(t, int64(10), Int("max-uploads"))
+	assert.Equal(t, 0.5, r.Get("error-budget"))
+	assert.True(t, Enabled("uploads-enabled"))
+	_, ok := r.Lookup("note")
+	assert.False(t, ok)
+	_, ok = r.Lookup("port")
+	assert.False(t, ok)
+
+	assert.NoError(t, conf.LoadData("yaml", []byte("limits:\n  max-uploads: 25\n  uploads-enabled: false\n")))
+	assert.Equal(t, 25.0, Get("max-uploads"))
+	assert.False(t, Enabled("uploads-enabled"))
+}
@@ -0,0 +1,17 @@
+package configurable
+
+import "log/slog"
+
+// SetLogger sets where the package reports events worth an operator's
+// attention, such as a provider falling back to cached values. The default
+// is slog.Default().
+func (c *Configurable) SetLogger(logger *slog.Logger) {
+	c.log = logger
+}
+
+func (c *Configurable) logger() *slog.Logger {
+	if c.log == nil {
+		return slog.Default()
+	}
+	return c.log
+}
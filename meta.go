@@ -0,0 +1,161 @@
+package configurable
+
+import "time"
+
+// FlagOption attaches metadata to a flag when it is registered.
+type FlagOption func(*flagMeta)
+
+// flagMeta holds everything known about a flag beyond what flag.Flag records.
+type flagMeta struct {
+	examples []string
+	help     string
+	repeat   *RepeatPolicy
+	secret   bool
+
+	bounds *bounds
+	text   *textRules
+
+	env        []string
+	required   bool
+	approval   bool
+	stagger    time.Duration
+	canary     bool
+	validators []func(interface{}) error
+	hidden     bool
+	deprecated string
+	short      string
+	group      string
+	// envMapPrefix is the prefix bound by BindEnvPrefixToMap.
+	envMapPrefix string
+	leaderOnly   bool
+	early        bool
+	path         bool
+	unit         Unit
+}
+
+// WithExample adds an example invocation, such as "--listen :8080", shown by
+// the extended help and in generated docs. It may be given more than once.
+func WithExample(example string) FlagOption {
+	return func(m *flagMeta) {
+		m.examples = append(m.examples, example)
+	}
+}
+
+// WithHelp sets long-form help shown by the extended help and in generated
+// docs. The usage string stays the one-line summary.
+func WithHelp(text string) FlagOption {
+	return func(m *flagMeta) {
+		m.help = text
+	}
+}
+
+// WithRepeatPolicy overrides ParseOptions.RepeatPolicy for one flag.
+func WithRepeatPolicy(policy RepeatPolicy) FlagOption {
+	return func(m *flagMeta) {
+		m.repeat = &policy
+	}
+}
+
+// WithSecret marks a flag as holding a secret, so its value is redacted from
+// human-facing output such as Banner.
+func WithSecret() FlagOption {
+	return func(m *flagMeta) {
+		m.secret = true
+	}
+}
+
+// WithEnv makes the flag read the first of vars that is set, instead of the
+// environment variable derived from its name.
+func WithEnv(vars ...string) FlagOption {
+	return func(m *flagMeta) {
+		m.env = append(m.env, vars...)
+	}
+}
+
+// WithRequired makes Parse fail unless a flag, environment variable, file or
+// provider sets the flag.
+func WithRequired() FlagOption {
+	return func(m *flagMeta) {
+		m.required = true
+	}
+}
+
+// WithValidator adds a check of the flag's value, which fn receives as the
+// flag's Go type. Validators run with the functions given to ValidateWith.
+func WithValidator(fn func(v interface{}) error) FlagOption {
+	return func(m *flagMeta) {
+		m.validators = append(m.validators, fn)
+	}
+}
+
+// WithHidden leaves the flag out of Usage and Docs. It can still be set.
+func WithHidden() FlagOption {
+	return func(m *flagMeta) {
+		m.hidden = true
+	}
+}
+
+// WithDeprecated marks the flag as deprecated. Usage shows message, and Parse
+// logs a warning when any source sets the flag.
+func WithDeprecated(message string) FlagOption {
+	return func(m *flagMeta) {
+		m.deprecated = message
+	}
+}
+
+// WithShort registers name, usually a single letter, as another name for the
+// flag on the command line.
+func WithShort(name string) FlagOption {
+	return func(m *flagMeta) {
+		m.short = name
+	}
+}
+
+// WithGroup puts the flag under a heading in Usage. Flags without a group
+// are listed first.
+func WithGroup(group string) FlagOption {
+	return func(m *flagMeta) {
+		m.group = group
+	}
+}
+
+// annotate applies opts to the flag name just registered and publishes it.
+// The caller holds c.mu.
+func (c *Configurable) annotate(name string, opts []FlagOption) {
+	if _, registered := c.meta[name]; !registered {
+		c.order = append(c.order, name)
+	}
+	m := c.metaFor(name)
+	for _, opt := range opts {
+		opt(m)
+	}
+	c.checkEnvCollision(name)
+	if m.constrained() {
+		c.guard(name)
+	}
+	if m.unit != "" {
+		c.measure(name, m.unit)
+	}
+	if m.path {
+		c.locate(name)
+	}
+	if m.short != "" {
+		f := c.fs.Lookup(name)
+		c.fs.Var(f.Value, m.short, f.Usage)
+		if c.aliases == nil {
+			c.aliases = make(map[string]string)
+		}
+		c.aliases[m.short] = name
+	}
+	c.publish()
+}
+
+// metaFor returns the metadata for name, creating it on first use.
+func (c *Configurable) metaFor(name string) *flagMeta {
+	m, ok := c.meta[name]
+	if !ok {
+		m = &flagMeta{}
+		c.meta[name] = m
+	}
+	return m
+}
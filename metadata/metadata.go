@@ -0,0 +1,197 @@
+// Package metadata reads facts about the host from cloud instance metadata
+// services and makes them available to configuration as ${meta.<key>}.
+//
+// Every source reports the same keys where the platform has them: cloud,
+// region, zone and instance-id. A value such as
+//
+//	bucket: "logs-${meta.region}"
+//
+// then resolves on whichever cloud the process runs, without a script
+// querying the metadata service at startup.
+package metadata
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/andreimerlescu/configurable"
+)
+
+// Namespace is the variable namespace Bind sets.
+const Namespace = "meta"
+
+// Source is an instance metadata service.
+type Source interface {
+	// Name identifies the source in errors.
+	Name() string
+	// Fetch returns the host's metadata, or an error if the service is not
+	// reachable, as it is not on other platforms.
+	Fetch(ctx context.Context) (map[string]string, error)
+}
+
+// defaultTimeout bounds each request when the client has no timeout, so
+// probing a platform the process is not on fails quickly.
+const defaultTimeout = 2 * time.Second
+
+// Load returns the metadata from the first of sources that answers. With no
+// sources it tries EC2, GCE and Azure.
+func Load(ctx context.Context, sources ...Source) (map[string]string, error) {
+	if len(sources) == 0 {
+		sources = []Source{&EC2{}, &GCE{}, &Azure{}}
+	}
+	var errs []error
+	for _, s := range sources {
+		values, err := s.Fetch(ctx)
+		if err == nil {
+			return values, nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", s.Name(), err))
+	}
+	return nil, fmt.Errorf("no instance metadata available: %w", errors.Join(errs...))
+}
+
+// Bind loads the host's metadata and sets it as the "meta" variables of conf.
+func Bind(ctx context.Context, conf configurable.IConfigurable, sources ...Source) error {
+	values, err := Load(ctx, sources...)
+	if err != nil {
+		return err
+	}
+	conf.SetVariables(Namespace, values)
+	return nil
+}
+
+// EC2 reads the AWS EC2 instance metadata service using IMDSv2 session
+// tokens.
+type EC2 struct {
+	// Endpoint defaults to http://169.254.169.254.
+	Endpoint string
+	// Client defaults to an http.Client with a short timeout.
+	Client *http.Client
+}
+
+func (s *EC2) Name() string {
+	return "ec2"
+}
+
+func (s *EC2) Fetch(ctx context.Context) (map[string]string, error) {
+	base := endpoint(s.Endpoint, "http://169.254.169.254")
+	token, err := get(ctx, s.Client, http.MethodPut, base+"/latest/api/token", http.Header{
+		"X-Aws-Ec2-Metadata-Token-Ttl-Seconds": {"60"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	header := http.Header{"X-Aws-Ec2-Metadata-Token": {token}}
+	values := map[string]string{"cloud": "aws"}
+	for key, path := range map[string]string{
+		"region":      "placement/region",
+		"zone":        "placement/availability-zone",
+		"instance-id": "instance-id",
+	} {
+		value, err := get(ctx, s.Client, http.MethodGet, base+"/latest/meta-data/"+path, header)
+		if err != nil {
+			return nil, err
+		}
+		values[key] = value
+	}
+	return values, nil
+}
+
+// GCE reads the Google Compute Engine metadata server.
+type GCE struct {
+	// Endpoint defaults to http://metadata.google.internal.
+	Endpoint string
+	// Client defaults to an http.Client with a short timeout.
+	Client *http.Client
+}
+
+func (s *GCE) Name() string {
+	return "gce"
+}
+
+func (s *GCE) Fetch(ctx context.Context) (map[string]string, error) {
+	base := endpoint(s.Endpoint, "http://metadata.google.internal") + "/computeMetadata/v1/instance/"
+	header := http.Header{"Metadata-Flavor": {"Google"}}
+	id, err := get(ctx, s.Client, http.MethodGet, base+"id", header)
+	if err != nil {
+		return nil, err
+	}
+	// The zone is given as projects/<number>/zones/<zone>.
+	zone, err := get(ctx, s.Client, http.MethodGet, base+"zone", header)
+	if err != nil {
+		return nil, err
+	}
+	zone = zone[strings.LastIndex(zone, "/")+1:]
+	region := zone
+	if i := strings.LastIndex(zone, "-"); i > 0 {
+		region = zone[:i]
+	}
+	return map[string]string{"cloud": "gcp", "region": region, "zone": zone, "instance-id": id}, nil
+}
+
+// Azure reads the Azure Instance Metadata Service.
+type Azure struct {
+	// Endpoint defaults to http://169.254.169.254.
+	Endpoint string
+	// Client defaults to an http.Client with a short timeout.
+	Client *http.Client
+}
+
+func (s *Azure) Name() string {
+	return "azure"
+}
+
+func (s *Azure) Fetch(ctx context.Context) (map[string]string, error) {
+	url := endpoint(s.Endpoint, "http://169.254.169.254") + "/metadata/instance/compute?api-version=2021-02-01"
+	body, err := get(ctx, s.Client, http.MethodGet, url, http.Header{"Metadata": {"true"}})
+	if err != nil {
+		return nil, err
+	}
+	var compute struct {
+		Location string `json:"location"`
+		Zone     string `json:"zone"`
+		VMID     string `json:"vmId"`
+	}
+	if err := json.Unmarshal([]byte(body), &compute); err != nil {
+		return nil, err
+	}
+	return map[string]string{"cloud": "azure", "region": compute.Location, "zone": compute.Zone, "instance-id": compute.VMID}, nil
+}
+
+func endpoint(configured, fallback string) string {
+	if configured == "" {
+		return fallback
+	}
+	return strings.TrimSuffix(configured, "/")
+}
+
+// get performs a metadata request and returns the response body.
+func get(ctx context.Context, client *http.Client, method, url string, header http.Header) (string, error) {
+	if client == nil {
+		client = &http.Client{Timeout: defaultTimeout}
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header = header
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%s %s: %s", method, url, resp.Status)
+	}
+	return strings.TrimSpace(string(body)), nil
+}
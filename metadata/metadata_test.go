@@ -0,0 +1,81 @@
+package metadata
+
+import (
+	"context"
+	"flag"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/andreimerlescu/configurable"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSources(t *testing.T) {
+	t.Run("test EC2", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/latest/api/token" {
+				assert.Equal(t, http.MethodPut, r.Method)
+				w.Write([]byte("tok"))
+				return
+			}
+			if r.Header.Get("X-Aws-Ec2-Metadata-Token") != "tok" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Write([]byte(map[string]string{
+				"/latest/meta-data/placement/region":            "us-east-1",
+				"/latest/meta-data/placement/availability-zone": "us-east-1b",
+				"/latest/meta-data/instance-id":                 "i-0abc",
+			}[r.URL.Path]))
+		}))
+		defer srv.Close()
+		values, err := (&EC2{Endpoint: srv.URL}).Fetch(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]string{"cloud": "aws", "region": "us-east-1", "zone": "us-east-1b", "instance-id": "i-0abc"}, values)
+	})
+
+	t.Run("test GCE", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "Google", r.Header.Get("Metadata-Flavor"))
+			w.Write([]byte(map[string]string{
+				"/computeMetadata/v1/instance/id":   "4242",
+				"/computeMetadata/v1/instance/zone": "projects/123/zones/europe-west1-c",
+			}[r.URL.Path]))
+		}))
+		defer srv.Close()
+		values, err := (&GCE{Endpoint: srv.URL}).Fetch(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]string{"cloud": "gcp", "region": "europe-west1", "zone": "europe-west1-c", "instance-id": "4242"}, values)
+	})
+
+	t.Run("test Azure", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "true", r.Header.Get("Metadata"))
+			w.Write([]byte(`{"location": "westeurope", "zone": "2", "vmId": "vm-1"}`))
+		}))
+		defer srv.Close()
+		values, err := (&Azure{Endpoint: srv.URL}).Fetch(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]string{"cloud": "azure", "region": "westeurope", "zone": "2", "instance-id": "vm-1"}, values)
+	})
+}
+
+func TestBind(t *testing.T) {
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer down.Close()
+	azure := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"location": "westeurope", "zone": "1", "vmId": "vm-1"}`))
+	}))
+	defer azure.Close()
+
+	conf := configurable.New(configurable.WithFlagSet(flag.NewFlagSet(t.Name(), flag.ContinueOnError)))
+	bucket := conf.NewString("bucket", "logs-${meta.region}", "bucket")
+	assert.NoError(t, Bind(context.Background(), conf, &EC2{Endpoint: down.URL}, &Azure{Endpoint: azure.URL}))
+	assert.Equal(t, "logs-westeurope", *bucket)
+
+	_, err := Load(context.Background(), &GCE{Endpoint: down.URL})
+	assert.ErrorContains(t, err, "gce: ")
+}
@@ -0,0 +1,140 @@
+// Package mqtt is a configurable.Provider receiving configuration documents
+// pushed to an MQTT topic, so a device fleet gets changes without polling.
+//
+// Each message on the topic is a JSON document of the shape LoadFile
+// accepts. Publish documents with the retained flag set: the broker then
+// hands the latest one to every device as it subscribes, which is what Load
+// waits for at startup. An empty retained message (the way a retained
+// message is cleared) is ignored.
+package mqtt
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	paho "github.com/eclipse/paho.mqtt.golang"
+)
+
+// DefaultRetainedWait is how long Load waits for the retained document.
+const DefaultRetainedWait = 5 * time.Second
+
+// Provider subscribes to a topic for configuration documents.
+type Provider struct {
+	client       paho.Client
+	topic        string
+	qos          byte
+	retainedWait time.Duration
+}
+
+// Option configures a Provider.
+type Option func(*Provider)
+
+// WithQoS sets the quality of service of the subscription: 0, 1 (the
+// default) or 2.
+func WithQoS(qos byte) Option {
+	return func(p *Provider) {
+		p.qos = qos
+	}
+}
+
+// WithRetainedWait sets how long Load waits for the retained document. The
+// default is DefaultRetainedWait.
+func WithRetainedWait(d time.Duration) Option {
+	return func(p *Provider) {
+		p.retainedWait = d
+	}
+}
+
+// New returns a Provider for topic. client must be connected; its options
+// decide the broker, credentials and reconnection behavior.
+func New(client paho.Client, topic string, opts ...Option) *Provider {
+	p := &Provider{client: client, topic: topic, qos: 1, retainedWait: DefaultRetainedWait}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+func (p *Provider) Name() string {
+	return "mqtt:" + p.topic
+}
+
+// Load returns the document retained on the topic.
+func (p *Provider) Load(ctx context.Context) (map[string]interface{}, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.retainedWait)
+	defer cancel()
+	docs, err := p.subscribe(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer p.client.Unsubscribe(p.topic)
+	select {
+	case <-ctx.Done():
+		return nil, fmt.Errorf("%s: no retained document: %w", p.topic, ctx.Err())
+	case payload := <-docs:
+		return decode(payload)
+	}
+}
+
+// Watch applies each document published to the topic, starting with the
+// retained one, until ctx is done. Documents that are not valid JSON are
+// skipped.
+func (p *Provider) Watch(ctx context.Context, apply func(map[string]interface{}) error) error {
+	docs, err := p.subscribe(ctx)
+	if err != nil {
+		return err
+	}
+	defer p.client.Unsubscribe(p.topic)
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case payload := <-docs:
+			if data, err := decode(payload); err == nil {
+				_ = apply(data)
+			}
+		}
+	}
+}
+
+// subscribe subscribes to the topic, returning a channel of the non-empty
+// payloads received until ctx is done.
+func (p *Provider) subscribe(ctx context.Context) (<-chan []byte, error) {
+	docs := make(chan []byte, 1)
+	token := p.client.Subscribe(p.topic, p.qos, func(_ paho.Client, msg paho.Message) {
+		if len(msg.Payload()) == 0 {
+			return
+		}
+		select {
+		case docs <- msg.Payload():
+		case <-ctx.Done():
+		}
+	})
+	select {
+	case <-token.Done():
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if err := token.Error(); err != nil {
+		return nil, fmt.Errorf("subscribing to %s: %w", p.topic, err)
+	}
+	return docs, nil
+}
+
+// decode parses a JSON document, keeping numbers exact.
+func decode(payload []byte) (map[string]interface{}, error) {
+	var data map[string]interface{}
+	dec := json.NewDecoder(bytes.NewReader(payload))
+	dec.UseNumber()
+	if err := dec.Decode(&data); err != nil {
+		return nil, err
+	}
+	if data == nil {
+		return nil, errors.New("document is not a JSON object")
+	}
+	return data, nil
+}
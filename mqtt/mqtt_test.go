@@ -0,0 +1,110 @@
+package mqtt
+
+import (
+	"context"
+	"flag"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/andreimerlescu/configurable"
+	paho "github.com/eclipse/paho.mqtt.golang"
+	"github.com/stretchr/testify/assert"
+)
+
+// broker is a paho.Client delivering retained and published messages to
+// one subscriber, as a broker would.
+type broker struct {
+	paho.Client
+	mu       sync.Mutex
+	retained []byte
+	handler  paho.MessageHandler
+	qos      byte
+}
+
+func (b *broker) Subscribe(topic string, qos byte, callback paho.MessageHandler) paho.Token {
+	b.mu.Lock()
+	b.handler, b.qos = callback, qos
+	retained := b.retained
+	b.mu.Unlock()
+	if retained != nil {
+		go callback(b, &message{payload: retained, retained: true})
+	}
+	return done{}
+}
+
+func (b *broker) Unsubscribe(topics ...string) paho.Token {
+	b.mu.Lock()
+	b.handler = nil
+	b.mu.Unlock()
+	return done{}
+}
+
+func (b *broker) publish(payload string) {
+	b.mu.Lock()
+	handler := b.handler
+	b.mu.Unlock()
+	if handler != nil {
+		handler(b, &message{payload: []byte(payload)})
+	}
+}
+
+func (b *broker) subscribed() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.handler != nil
+}
+
+type message struct {
+	paho.Message
+	payload  []byte
+	retained bool
+}
+
+func (m *message) Payload() []byte { return m.payload }
+func (m *message) Retained() bool  { return m.retained }
+
+type done struct{ paho.Token }
+
+func (done) Done() <-chan struct{} {
+	c := make(chan struct{})
+	close(c)
+	return c
+}
+
+func (done) Error() error { return nil }
+
+func TestProvider(t *testing.T) {
+	t.Run("test retained document", func(t *testing.T) {
+		b := &broker{retained: []byte(`{"interval": "30s", "fleet": {"id": 9007199254740993}}`)}
+		p := New(b, "devices/config", WithQoS(2))
+		assert.Equal(t, "mqtt:devices/config", p.Name())
+		data, err := p.Load(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, "30s", data["interval"])
+		assert.Equal(t, byte(2), b.qos)
+		assert.False(t, b.subscribed())
+
+		_, err = New(&broker{}, "devices/config", WithRetainedWait(10*time.Millisecond)).Load(context.Background())
+		assert.ErrorContains(t, err, "no retained document")
+	})
+
+	t.Run("test pushed documents", func(t *testing.T) {
+		b := &broker{retained: []byte(`{"interval": "30s"}`)}
+		conf := configurable.New(configurable.WithFlagSet(flag.NewFlagSet(t.Name(), flag.ContinueOnError)))
+		interval := conf.NewDuration("interval", time.Minute, "report interval")
+		conf.AddProvider(New(b, "devices/config"))
+		conf.SetArgs([]string{})
+		assert.NoError(t, conf.Parse(""))
+		assert.Equal(t, 30*time.Second, *interval)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go conf.WatchProviders(ctx)
+		assert.Eventually(t, b.subscribed, time.Second, time.Millisecond)
+		b.publish("")
+		b.publish("not json")
+		b.publish(`{"interval": "5s"}`)
+		assert.Eventually(t, func() bool { return conf.View().Duration("interval") == 5*time.Second }, time.Second, time.Millisecond)
+	})
+}
@@ -0,0 +1,149 @@
+package configurable
+
+import (
+	"flag"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+)
+
+// Option configures a Configurable when it is created by New. Each option
+// has the same effect as the corresponding setter.
+type Option func(*Configurable)
+
+// WithFlagSet registers flags on fs instead of flag.CommandLine.
+func WithFlagSet(fs *flag.FlagSet) Option {
+	return func(c *Configurable) {
+		c.fs = fs
+	}
+}
+
+// WithErrorHandling sets how Parse reacts to errors. Unless WithFlagSet is
+// also given, flags are then registered on a new FlagSet named after the
+// program rather than on flag.CommandLine.
+func WithErrorHandling(handling flag.ErrorHandling) Option {
+	return func(c *Configurable) {
+		c.errorHandling = &handling
+	}
+}
+
+// WithArgs is SetArgs.
+func WithArgs(args []string) Option {
+	return func(c *Configurable) {
+		c.args = args
+	}
+}
+
+// WithEnvPrefix is SetEnvPrefix.
+func WithEnvPrefix(prefix string) Option {
+	return func(c *Configurable) {
+		c.SetEnvPrefix(prefix)
+	}
+}
+
+// WithEnvDelimiter is SetEnvDelimiter.
+func WithEnvDelimiter(delimiter string) Option {
+	return func(c *Configurable) {
+		c.SetEnvDelimiter(delimiter)
+	}
+}
+
+// WithLogger is SetLogger.
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *Configurable) {
+		c.log = logger
+	}
+}
+
+// WithParseOptions is SetParseOptions.
+func WithParseOptions(opts ParseOptions) Option {
+	return func(c *Configurable) {
+		c.parseOptions = opts
+	}
+}
+
+// WithStrict sets ParseOptions.Strict, making files and providers that
+// contain keys matching no flag fail to load.
+func WithStrict() Option {
+	return func(c *Configurable) {
+		c.parseOptions.Strict = true
+	}
+}
+
+// WithRetainUnknown sets ParseOptions.RetainUnknown, keeping keys that
+// match no flag for UnboundKeys.
+func WithRetainUnknown() Option {
+	return func(c *Configurable) {
+		c.parseOptions.RetainUnknown = true
+	}
+}
+
+// WithLenientBools sets ParseOptions.LenientBools, so bool flags accept
+// yes/no, on/off and 1/0 from files and the environment.
+func WithLenientBools() Option {
+	return func(c *Configurable) {
+		c.parseOptions.LenientBools = true
+	}
+}
+
+// WithEnvPresenceBools sets ParseOptions.EnvPresenceBools, so an empty
+// environment variable turns its bool flag on.
+func WithEnvPresenceBools() Option {
+	return func(c *Configurable) {
+		c.parseOptions.EnvPresenceBools = true
+	}
+}
+
+// WithEnvTrim sets ParseOptions.EnvTrim, for orchestrators that inject
+// environment values with stray whitespace or quotes.
+func WithEnvTrim(trim EnvTrim) Option {
+	return func(c *Configurable) {
+		c.parseOptions.EnvTrim = trim
+	}
+}
+
+// WithOutput is SetOutput.
+func WithOutput(w io.Writer) Option {
+	return func(c *Configurable) {
+		c.output = w
+	}
+}
+
+// WithCacheDir is SetCacheDir.
+func WithCacheDir(dir string) Option {
+	return func(c *Configurable) {
+		c.cacheDir = dir
+	}
+}
+
+// WithFileMode is SetFileMode.
+func WithFileMode(mode fs.FileMode) Option {
+	return func(c *Configurable) {
+		c.fileMode = mode
+	}
+}
+
+// WithReadOnly is ReadOnly(true).
+func WithReadOnly() Option {
+	return func(c *Configurable) {
+		c.readOnly = true
+	}
+}
+
+// applyOptions runs opts and settles the FlagSet they chose.
+func (c *Configurable) applyOptions(opts []Option) {
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.errorHandling != nil {
+		if c.fs == flag.CommandLine {
+			c.fs = flag.NewFlagSet(os.Args[0], *c.errorHandling)
+		} else {
+			c.fs.Init(c.fs.Name(), *c.errorHandling)
+		}
+	}
+	if c.output != nil {
+		c.SetOutput(c.output)
+	}
+}
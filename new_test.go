@@ -0,0 +1,149 @@
+package configurable
+
+import (
+	"bytes"
+	"flag"
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOptions(t *testing.T) {
+	os.Clearenv()
+
+	t.Run("test flag set and args", func(t *testing.T) {
+		fs := flag.NewFlagSet(t.Name(), flag.ContinueOnError)
+		conf := New(WithFlagSet(fs), WithArgs([]string{"-port", "8080"}))
+		conf.NewInt("port", 80, "port")
+		assert.NoError(t, conf.Parse(""))
+		assert.Equal(t, 8080, *conf.Int("port"))
+		assert.NotNil(t, fs.Lookup("port"))
+	})
+
+	t.Run("test error handling", func(t *testing.T) {
+		var buf bytes.Buffer
+		conf := New(WithErrorHandling(flag.ContinueOnError), WithOutput(&buf)).(*Configurable)
+		assert.NotSame(t, flag.CommandLine, conf.fs)
+		conf.NewInt("port", 80, "port")
+		assert.ErrorContains(t, conf.ParseArgs([]string{"-port", "x"}), "invalid value")
+		assert.Contains(t, buf.String(), "Usage of")
+	})
+
+	t.Run("test env prefix", func(t *testing.T) {
+		conf := New(WithFlagSet(flag.NewFlagSet(t.Name(), flag.ContinueOnError)), WithEnvPrefix("MYAPP"), WithArgs([]string{}))
+		conf.NewString("db-host", "localhost", "database host")
+		conf.NewInt("db.port", 5432, "database port")
+		conf.SetEnv("db-host", "ignored")
+		conf.SetEnv("MYAPP_DB_HOST", "db1")
+		conf.SetEnv("MYAPP_DB_PORT", "6432")
+		report, err := conf.ParseReport("")
+		assert.NoError(t, err)
+		assert.Equal(t, "db1", *conf.String("db-host"))
+		assert.Equal(t, 6432, *conf.Int("db.port"))
+		assert.Equal(t, "config: 2 from env (MYAPP_DB_HOST, MYAPP_DB_PORT)", report.String())
+	})
+
+	t.Run("test env delimiter", func(t *testing.T) {
+		conf := New(WithFlagSet(flag.NewFlagSet(t.Name(), flag.ContinueOnError)), WithEnvPrefix("MYAPP"), WithEnvDelimiter("__"), WithArgs([]string{}))
+		conf.NewInt("server.port", 80, "port")
+		conf.NewInt("server-port", 81, "legacy port")
+		conf.SetEnv("MYAPP_SERVER__PORT", "8080")
+		conf.SetEnv("MYAPP_SERVER_PORT", "8081")
+		assert.NoError(t, conf.Parse(""))
+		assert.Equal(t, 8080, *conf.Int("server.port"))
+		assert.Equal(t, 8081, *conf.Int("server-port"))
+	})
+
+	t.Run("test env collision", func(t *testing.T) {
+		conf := New(WithFlagSet(flag.NewFlagSet(t.Name(), flag.ContinueOnError)), WithEnvPrefix("MYAPP"))
+		conf.NewInt("server.port", 80, "port")
+		assert.PanicsWithValue(t, "configurable: flag server-port reads environment variable MYAPP_SERVER_PORT, already read by flag server.port", func() {
+			conf.NewInt("server-port", 81, "legacy port")
+		})
+		assert.Panics(t, func() {
+			conf.NewString("listen", "", "address", WithEnv("MYAPP_SERVER_PORT"))
+		})
+	})
+
+	t.Run("test set env prefix", func(t *testing.T) {
+		conf := New(WithFlagSet(flag.NewFlagSet(t.Name(), flag.ContinueOnError)), WithArgs([]string{}))
+		conf.NewString("db-host", "localhost", "database host")
+		conf.NewInt("server.port", 80, "port")
+		conf.SetEnvPrefix("MYAPP")
+		conf.SetEnv("MYAPP_DB_HOST", "db1")
+		conf.SetEnv("MYAPP_SERVER_PORT", "8080")
+		assert.NoError(t, conf.Parse(""))
+		assert.Equal(t, "db1", *conf.String("db-host"))
+		assert.Equal(t, 8080, *conf.Int("server.port"))
+
+		conf.SetEnvDelimiter("__")
+		conf.NewInt("server_port", 82, "other port")
+		assert.PanicsWithValue(t, "configurable: flag server.port reads environment variable MYAPP_SERVER_PORT, already read by flag server_port", func() {
+			conf.SetEnvDelimiter("_")
+		})
+	})
+
+	t.Run("test lenient bools", func(t *testing.T) {
+		strict := New(WithFlagSet(flag.NewFlagSet(t.Name(), flag.ContinueOnError)))
+		strict.NewBool("debug", false, "debug")
+		assert.Error(t, strict.LoadData("yaml", []byte("debug: yes\n")))
+
+		conf := New(WithFlagSet(flag.NewFlagSet(t.Name(), flag.ContinueOnError)), WithLenientBools(), WithArgs([]string{}))
+		debug := conf.NewBool("debug", false, "debug")
+		tls := conf.NewBool("tls", true, "tls")
+		metrics := conf.NewBool("metrics", false, "metrics")
+		assert.NoError(t, conf.LoadData("yaml", []byte("debug: yes\ntls: 0\n")))
+		assert.True(t, *debug)
+		assert.False(t, *tls)
+		assert.NoError(t, conf.LoadData("json", []byte(`{"debug": "OFF", "tls": 1}`)))
+		assert.False(t, *debug)
+		assert.True(t, *tls)
+		assert.Error(t, conf.LoadData("json", []byte(`{"debug": 2}`)))
+
+		conf.SetEnv("metrics", "on")
+		assert.NoError(t, conf.Parse(""))
+		assert.True(t, *metrics)
+	})
+
+	t.Run("test env trim", func(t *testing.T) {
+		conf := New(WithFlagSet(flag.NewFlagSet(t.Name(), flag.ContinueOnError)), WithEnvTrim(TrimSpace|TrimQuotes), WithArgs([]string{}))
+		port := conf.NewInt("port", 80, "port")
+		name := conf.NewString("name", "", "name")
+		quote := conf.NewString("quote", "", "quote")
+		conf.SetEnv("port", " 8080\n")
+		conf.SetEnv("name", "\"api server\"\n")
+		conf.SetEnv("quote", `'it"`)
+		assert.NoError(t, conf.Parse(""))
+		assert.Equal(t, 8080, *port)
+		assert.Equal(t, "api server", *name)
+		assert.Equal(t, `'it"`, *quote)
+
+		untrimmed := New(WithFlagSet(flag.NewFlagSet(t.Name(), flag.ContinueOnError)), WithArgs([]string{}))
+		port = untrimmed.NewInt("port", 80, "port")
+		untrimmed.SetEnv("port", " 8080\n")
+		assert.NoError(t, untrimmed.Parse(""))
+		assert.Equal(t, 80, *port)
+	})
+
+	t.Run("test strict", func(t *testing.T) {
+		conf := New(WithFlagSet(flag.NewFlagSet(t.Name(), flag.ContinueOnError)), WithStrict())
+		conf.NewInt("port", 80, "port")
+		err := conf.LoadData("yaml", []byte("port: 8080\nlegacy: 1\nold:\n  key: 2\n"))
+		var unknown *UnknownKeysError
+		assert.ErrorAs(t, err, &unknown)
+		assert.EqualError(t, err, "file: unknown keys: legacy, old.key")
+		assert.Equal(t, 80, *conf.Int("port"))
+	})
+
+	t.Run("test setters", func(t *testing.T) {
+		logger := slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))
+		conf := New(WithLogger(logger), WithCacheDir("/tmp/cache"), WithReadOnly(),
+			WithParseOptions(ParseOptions{AllowAbbreviations: true})).(*Configurable)
+		assert.Same(t, logger, conf.logger())
+		assert.Equal(t, "/tmp/cache", conf.cacheDir)
+		assert.True(t, conf.IsReadOnly())
+		assert.True(t, conf.parseOptions.AllowAbbreviations)
+	})
+}
@@ -0,0 +1,101 @@
+package configurable
+
+import "strings"
+
+// ParseOptions tunes how Parse interprets its inputs.
+type ParseOptions struct {
+	// AllowAbbreviations accepts any unambiguous prefix of a flag name, so
+	// --verb selects --verbose. Ambiguous prefixes fail with an
+	// *AmbiguousFlagError.
+	AllowAbbreviations bool
+
+	// RepeatPolicy decides what happens when a scalar flag appears more than
+	// once on the command line. Flags registered WithRepeatPolicy override it.
+	RepeatPolicy RepeatPolicy
+
+	// MaxValueLength caps the length in bytes of any single value, list item
+	// or map value. Zero means no limit.
+	MaxValueLength int
+
+	// MaxEntries caps the number of items in a list flag and entries in a
+	// map flag. Zero means no limit.
+	MaxEntries int
+
+	// MaxFileSize caps the size in bytes of a configuration file or document.
+	// Zero means no limit.
+	MaxFileSize int64
+
+	// Strict makes files, documents and providers fail to load with an
+	// *UnknownKeysError when they contain keys matching no flag, instead of
+	// skipping those keys.
+	Strict bool
+
+	// RetainUnknown keeps the keys matching no flag, with their values, for
+	// UnboundKeys, so a file written for a newer release can be shipped
+	// ahead of the binary and the keys it does not understand yet listed.
+	RetainUnknown bool
+
+	// LenientBools accepts yes/no, on/off, y/n and the numbers 1 and 0 for
+	// bool flags set from files, documents, providers and the environment,
+	// as written by YAML 1.1 tools and other configuration systems.
+	LenientBools bool
+
+	// EnvPresenceBools turns a bool flag on when its environment variable is
+	// set but empty, as with DEBUG= in a container spec. A variable with a
+	// value is parsed as usual, so DEBUG=false still turns the flag off, and
+	// an unset variable leaves the flag alone.
+	EnvPresenceBools bool
+
+	// EnvTrim cleans up values read from the environment before they are
+	// parsed. The zero value uses them as they are.
+	EnvTrim EnvTrim
+
+	// FileRetry retries reading a configuration file that failed for a
+	// reason other than being missing, unreadable or too large, such as a
+	// transient NFS error. The zero value makes a single attempt.
+	FileRetry RetryPolicy
+}
+
+// EnvTrim selects how values read from the environment are cleaned up. The
+// constants combine with |.
+type EnvTrim int
+
+const (
+	// TrimSpace removes leading and trailing whitespace, including the
+	// trailing newline of values injected from files.
+	TrimSpace EnvTrim = 1 << iota
+	// TrimQuotes removes one pair of matching single or double quotes
+	// around the value, after TrimSpace.
+	TrimQuotes
+)
+
+// apply returns value cleaned up as t selects.
+func (t EnvTrim) apply(value string) string {
+	if t&TrimSpace != 0 {
+		value = strings.TrimSpace(value)
+	}
+	if t&TrimQuotes != 0 && len(value) >= 2 {
+		if q := value[0]; (q == '"' || q == '\'') && value[len(value)-1] == q {
+			value = value[1 : len(value)-1]
+		}
+	}
+	return value
+}
+
+// RepeatPolicy is how repeated occurrences of a scalar flag are resolved.
+// List and map flags always accumulate.
+type RepeatPolicy int
+
+const (
+	// RepeatLastWins keeps the last occurrence, like the flag package.
+	RepeatLastWins RepeatPolicy = iota
+	// RepeatFirstWins keeps the first occurrence and ignores the rest.
+	RepeatFirstWins
+	// RepeatError fails Parse with a *RepeatedFlagError.
+	RepeatError
+)
+
+// SetParseOptions replaces the options used by Parse and ParseArgs.
+func (c *Configurable) SetParseOptions(opts ParseOptions) {
+	c.parseOptions = opts
+}
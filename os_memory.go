@@ -0,0 +1,46 @@
+//go:build (js && wasm) || tinygo
+
+package configurable
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+)
+
+// Browser and TinyGo builds have no usable filesystem or process environment,
+// so the package operates purely in memory: load documents with LoadData and
+// supply environment values with SetEnv.
+
+func readFile(filename string, limit int64) ([]byte, error) {
+	return nil, fmt.Errorf("reading %s: %w", filename, errors.ErrUnsupported)
+}
+
+func lookupEnv(key string) (string, bool) {
+	return "", false
+}
+
+func environ() []string {
+	return nil
+}
+
+func executableDir() (string, error) {
+	return "", fmt.Errorf("locating the executable: %w", errors.ErrUnsupported)
+}
+
+func userDir(kind userDirKind) (string, error) {
+	return "", fmt.Errorf("locating user directories: %w", errors.ErrUnsupported)
+}
+
+func makeDir(dir string, perm fs.FileMode) error {
+	return fmt.Errorf("creating %s: %w", dir, errors.ErrUnsupported)
+}
+
+func writeFile(filename string, data []byte, perm fs.FileMode) error {
+	return fmt.Errorf("writing %s: %w", filename, errors.ErrUnsupported)
+}
+
+func terminalWidth(w io.Writer) (int, bool) {
+	return 0, false
+}
@@ -0,0 +1,130 @@
+//go:build !(js && wasm) && !tinygo
+
+package configurable
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"golang.org/x/term"
+)
+
+// The functions in this file are the only places the package touches the host
+// filesystem and process environment; in-memory builds replace them.
+
+// readFile reads filename, failing with ErrLimitExceeded rather than reading
+// more than limit bytes when limit is positive.
+func readFile(filename string, limit int64) ([]byte, error) {
+	if limit <= 0 {
+		return os.ReadFile(filename)
+	}
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	data, err := io.ReadAll(io.LimitReader(f, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("%s is larger than %d bytes: %w", filename, limit, ErrLimitExceeded)
+	}
+	return data, nil
+}
+
+func lookupEnv(key string) (string, bool) {
+	return os.LookupEnv(key)
+}
+
+func environ() []string {
+	return os.Environ()
+}
+
+// executableDir returns the directory holding the running executable.
+func executableDir() (string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Dir(exe), nil
+}
+
+// userDir returns the per-user base directory of the given kind.
+func userDir(kind userDirKind) (string, error) {
+	switch kind {
+	case configDir:
+		return os.UserConfigDir()
+	case cacheDir:
+		return os.UserCacheDir()
+	}
+	switch runtime.GOOS {
+	case "windows":
+		if dir := os.Getenv("LocalAppData"); dir != "" {
+			return dir, nil
+		}
+		return "", errors.New("%LocalAppData% is not defined")
+	case "darwin", "ios":
+		return os.UserConfigDir()
+	}
+	if dir := os.Getenv("XDG_DATA_HOME"); filepath.IsAbs(dir) {
+		return dir, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".local", "share"), nil
+}
+
+func makeDir(dir string, perm fs.FileMode) error {
+	return os.MkdirAll(dir, perm)
+}
+
+// writeFile replaces filename with data atomically: readers see the old file
+// or the new one, never a partial write. The file gets exactly perm,
+// whatever the umask.
+func writeFile(filename string, data []byte, perm fs.FileMode) (err error) {
+	f, err := os.CreateTemp(filepath.Dir(filename), "."+filepath.Base(filename)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			f.Close()
+			os.Remove(f.Name())
+		}
+	}()
+	if err = f.Chmod(perm); err != nil {
+		return err
+	}
+	if _, err = f.Write(data); err != nil {
+		return err
+	}
+	if err = f.Sync(); err != nil {
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), filename)
+}
+
+// terminalWidth reports whether w is an interactive terminal and, if so, its
+// width in columns (zero when the size cannot be determined).
+func terminalWidth(w io.Writer) (int, bool) {
+	f, ok := w.(*os.File)
+	if !ok || !term.IsTerminal(int(f.Fd())) {
+		return 0, false
+	}
+	width, _, err := term.GetSize(int(f.Fd()))
+	if err != nil {
+		return 0, true
+	}
+	return width, true
+}
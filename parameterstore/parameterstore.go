@@ -0,0 +1,63 @@
+// Package parameterstore is a configurable.Provider reading the parameters
+// under a path in AWS Systems Manager Parameter Store, in place of a
+// bootstrap script exporting them to the environment.
+//
+// Each parameter below the path sets the flag named by the rest of its name,
+// with slashes read as dots: under the path "/myapp/prod", the parameter
+// "/myapp/prod/db/host" sets db.host. SecureString parameters are decrypted,
+// and a StringList sets a slice flag from its comma-separated values.
+// Parameters that match no flag are reported like unknown keys in a file.
+package parameterstore
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+)
+
+// Client is the part of *ssm.Client the Provider uses.
+type Client = ssm.GetParametersByPathAPIClient
+
+// Provider reads the parameters under a path.
+type Provider struct {
+	client Client
+	path   string
+}
+
+// New returns a Provider for the parameters under path. client decides the
+// region, credentials and retries; its credentials need ssm:GetParametersByPath
+// on the path and kms:Decrypt on the keys of its SecureString parameters.
+func New(client Client, path string) *Provider {
+	return &Provider{client: client, path: "/" + strings.Trim(path, "/")}
+}
+
+func (p *Provider) Name() string {
+	return "ssm:" + p.path
+}
+
+// Load returns the parameters under the path, at every depth.
+func (p *Provider) Load(ctx context.Context) (map[string]interface{}, error) {
+	pages := ssm.NewGetParametersByPathPaginator(p.client, &ssm.GetParametersByPathInput{
+		Path:           aws.String(p.path),
+		Recursive:      aws.Bool(true),
+		WithDecryption: aws.Bool(true),
+	})
+	data := make(map[string]interface{})
+	for pages.HasMorePages() {
+		page, err := pages.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", p.path, err)
+		}
+		for _, param := range page.Parameters {
+			name := strings.Trim(strings.TrimPrefix(aws.ToString(param.Name), p.path), "/")
+			if name == "" {
+				continue
+			}
+			data[strings.ReplaceAll(name, "/", ".")] = aws.ToString(param.Value)
+		}
+	}
+	return data, nil
+}
@@ -0,0 +1,78 @@
+package parameterstore
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"strconv"
+	"testing"
+
+	"github.com/andreimerlescu/configurable"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/aws/aws-sdk-go-v2/service/ssm/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeClient serves params one page at a time, as already decrypted.
+type fakeClient struct {
+	params []types.Parameter
+	inputs []*ssm.GetParametersByPathInput
+	err    error
+}
+
+func (f *fakeClient) GetParametersByPath(ctx context.Context, in *ssm.GetParametersByPathInput, _ ...func(*ssm.Options)) (*ssm.GetParametersByPathOutput, error) {
+	f.inputs = append(f.inputs, in)
+	if f.err != nil {
+		return nil, f.err
+	}
+	i := 0
+	if in.NextToken != nil {
+		i, _ = strconv.Atoi(*in.NextToken)
+	}
+	out := &ssm.GetParametersByPathOutput{Parameters: f.params[i : i+1]}
+	if i+1 < len(f.params) {
+		out.NextToken = aws.String(strconv.Itoa(i + 1))
+	}
+	return out, nil
+}
+
+func param(name, value string, kind types.ParameterType) types.Parameter {
+	return types.Parameter{Name: aws.String(name), Value: aws.String(value), Type: kind}
+}
+
+func TestProvider(t *testing.T) {
+	t.Run("test load", func(t *testing.T) {
+		client := &fakeClient{params: []types.Parameter{
+			param("/myapp/prod/db/host", "db.internal", types.ParameterTypeString),
+			param("/myapp/prod/db/password", "hunter2", types.ParameterTypeSecureString),
+			param("/myapp/prod/regions", "us-east-1,eu-west-1", types.ParameterTypeStringList),
+		}}
+		conf := configurable.New(configurable.WithFlagSet(flag.NewFlagSet(t.Name(), flag.ContinueOnError)))
+		conf.NewString("db.host", "localhost", "database host")
+		conf.NewString("db.password", "", "database password", configurable.WithSecret())
+		conf.NewList("regions", nil, "regions")
+		conf.SetArgs([]string{})
+		p := New(client, "myapp/prod/")
+		assert.Equal(t, "ssm:/myapp/prod", p.Name())
+		conf.AddProvider(p)
+		assert.NoError(t, conf.Parse(""))
+
+		assert.Equal(t, "db.internal", conf.View().String("db.host"))
+		assert.Equal(t, "hunter2", conf.View().String("db.password"))
+		assert.Equal(t, []string{"us-east-1", "eu-west-1"}, *conf.List("regions"))
+		assert.Equal(t, "remote ssm:/myapp/prod", conf.Source("db.host").String())
+
+		assert.Len(t, client.inputs, 3, "every page is read")
+		assert.Equal(t, "/myapp/prod", aws.ToString(client.inputs[0].Path))
+		assert.True(t, aws.ToBool(client.inputs[0].Recursive))
+		assert.True(t, aws.ToBool(client.inputs[0].WithDecryption))
+	})
+
+	t.Run("test error", func(t *testing.T) {
+		errDenied := errors.New("AccessDeniedException")
+		_, err := New(&fakeClient{err: errDenied}, "/myapp").Load(context.Background())
+		assert.ErrorIs(t, err, errDenied)
+		assert.ErrorContains(t, err, "reading /myapp")
+	})
+}
@@ -0,0 +1,150 @@
+package configurable
+
+import (
+	"flag"
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// windowsPaths enables %VAR% expansion in paths. It is a variable so tests
+// can exercise it on any platform.
+var windowsPaths = runtime.GOOS == "windows"
+
+// WithPath marks a string flag as a filesystem path. On Windows, references
+// such as %APPDATA% in its values are expanded from the environment, and in
+// portable mode a relative path is resolved against the executable's
+// directory. The default value is treated the same way.
+func WithPath() FlagOption {
+	return func(m *flagMeta) {
+		m.path = true
+	}
+}
+
+// WithPortable enables portable mode, for builds shipped as a zip that run
+// from wherever they are unpacked: relative paths in flags marked WithPath,
+// and relative configuration files given to Parse and LoadFile, are resolved
+// against the executable's directory rather than the working directory.
+func WithPortable() Option {
+	return func(c *Configurable) {
+		c.portable = true
+	}
+}
+
+// resolvePath expands and, in portable mode, anchors the path p.
+func (c *Configurable) resolvePath(p string) string {
+	if windowsPaths {
+		p = c.expandWindows(p)
+	}
+	if !c.portable || p == "" || filepath.IsAbs(p) {
+		return p
+	}
+	dir, err := executableDir()
+	if err != nil {
+		return p
+	}
+	return filepath.Join(dir, p)
+}
+
+// expandWindows replaces %NAME% references in s with environment values, as
+// ExpandEnvironmentStrings does: names are matched case-insensitively and
+// undefined references are left as they are.
+func (c *Configurable) expandWindows(s string) string {
+	var sb strings.Builder
+	for {
+		start := strings.IndexByte(s, '%')
+		if start < 0 {
+			break
+		}
+		end := strings.IndexByte(s[start+1:], '%')
+		if end < 0 {
+			break
+		}
+		end += start + 1
+		sb.WriteString(s[:start])
+		value, ok := c.lookupWindowsEnv(s[start+1 : end])
+		if !ok || start+1 == end {
+			// Not a reference; the closing % may open the next one.
+			sb.WriteString(s[start:end])
+			s = s[end:]
+			continue
+		}
+		sb.WriteString(value)
+		s = s[end+1:]
+	}
+	sb.WriteString(s)
+	return sb.String()
+}
+
+// lookupWindowsEnv looks name up the way Windows does, ignoring case.
+func (c *Configurable) lookupWindowsEnv(name string) (string, bool) {
+	if value, ok := c.lookupEnv(name); ok {
+		return value, true
+	}
+	c.envMu.RLock()
+	defer c.envMu.RUnlock()
+	for key, value := range c.env {
+		if strings.EqualFold(key, name) {
+			return value, true
+		}
+	}
+	for _, kv := range environ() {
+		if key, value, ok := strings.Cut(kv, "="); ok && strings.EqualFold(key, name) {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+// withPath resolves raw, if it is a string for a flag marked WithPath.
+func (c *Configurable) withPath(name string, raw interface{}) interface{} {
+	s, ok := raw.(string)
+	if m, declared := c.meta[name]; ok && declared && m.path {
+		return c.resolvePath(s)
+	}
+	return raw
+}
+
+// pathValue resolves paths given for a flag on the command line.
+type pathValue struct {
+	flag.Value
+	c *Configurable
+}
+
+func (v *pathValue) Set(s string) error {
+	return v.Value.Set(v.c.resolvePath(s))
+}
+
+func (v *pathValue) String() string {
+	// The flag package calls String on a zero pathValue.
+	if v.Value == nil {
+		return ""
+	}
+	return v.Value.String()
+}
+
+func (v *pathValue) Get() interface{} {
+	if g, ok := v.Value.(flag.Getter); ok {
+		return g.Get()
+	}
+	return v.Value.String()
+}
+
+// locate resolves the default of name and installs a pathValue in front of
+// its flag.Value. It panics if the flag is not a string flag.
+func (c *Configurable) locate(name string) {
+	ptr, ok := c.flags[name].(*string)
+	if !ok {
+		panic(fmt.Sprintf("configurable: WithPath on %s, which is not a string flag", name))
+	}
+	*ptr = c.resolvePath(*ptr)
+	f := c.fs.Lookup(name)
+	if f == nil {
+		return
+	}
+	if _, ok := f.Value.(*pathValue); ok {
+		return
+	}
+	f.Value = &pathValue{Value: f.Value, c: c}
+}
@@ -0,0 +1,65 @@
+package configurable
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithPath(t *testing.T) {
+	os.Clearenv()
+	windowsPaths = true
+	t.Cleanup(func() { windowsPaths = false })
+
+	t.Run("test expansion", func(t *testing.T) {
+		conf := newTestConfigurable(t)
+		conf.SetEnv("APPDATA", `C:\Users\me\AppData\Roaming`)
+		assert.Equal(t, `C:\Users\me\AppData\Roaming\app`, conf.expandWindows(`%appdata%\app`))
+		assert.Equal(t, `%MISSING%\app`, conf.expandWindows(`%MISSING%\app`))
+		assert.Equal(t, `100%%`, conf.expandWindows(`100%%`))
+		assert.Equal(t, `50% of %`, conf.expandWindows(`50% of %`))
+	})
+
+	t.Run("test sources", func(t *testing.T) {
+		conf := newTestConfigurable(t)
+		conf.SetEnv("APPDATA", `C:\Roaming`)
+		data := conf.NewString("data", `%APPDATA%\app`, "data directory", WithPath())
+		cache := conf.NewString("cache", "", "cache directory", WithPath())
+		logs := conf.NewString("logs", "", "log directory", WithPath())
+		assert.Equal(t, `C:\Roaming\app`, *data)
+
+		conf.SetArgs([]string{`-cache=%APPDATA%\cache`})
+		assert.NoError(t, conf.LoadData("json", []byte(`{"logs": "%APPDATA%\\logs"}`)))
+		assert.NoError(t, conf.Parse(""))
+		assert.Equal(t, `C:\Roaming\cache`, *cache)
+		assert.Equal(t, `C:\Roaming\logs`, *logs)
+	})
+
+	t.Run("test not a string", func(t *testing.T) {
+		conf := newTestConfigurable(t)
+		assert.Panics(t, func() { conf.NewInt("port", 0, "port", WithPath()) })
+	})
+}
+
+func TestWithPortable(t *testing.T) {
+	os.Clearenv()
+	exe, err := os.Executable()
+	assert.NoError(t, err)
+	dir := filepath.Dir(exe)
+
+	conf := newTestConfigurable(t)
+	WithPortable()(conf)
+	conf.SetArgs([]string{"-state", "state"})
+	data := conf.NewString("data", "data", "data directory", WithPath())
+	state := conf.NewString("state", "", "state directory", WithPath())
+	abs := conf.NewString("abs", "/var/lib/app", "absolute directory", WithPath())
+	name := conf.NewString("name", "app", "name")
+	assert.NoError(t, conf.Parse(""))
+	assert.Equal(t, filepath.Join(dir, "data"), *data)
+	assert.Equal(t, filepath.Join(dir, "state"), *state)
+	assert.Equal(t, "/var/lib/app", *abs)
+	assert.Equal(t, "app", *name)
+	assert.ErrorContains(t, conf.LoadFile("missing.json"), filepath.Join(dir, "missing.json"))
+}
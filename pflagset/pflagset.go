@@ -0,0 +1,81 @@
+// Package pflagset exposes a Configurable's flags as a pflag.FlagSet, for
+// programs and libraries built on spf13/pflag.
+//
+// The pflag set parses GNU-style arguments, with the flags' WithShort names
+// as shorthands, but does not change any value itself. It records what it
+// was given, in the standard flag syntax, for the Configurable to parse:
+//
+//	fs := pflagset.New(config)
+//	if err := fs.Parse(os.Args[1:]); err != nil {
+//		log.Fatal(err)
+//	}
+//	config.SetArgs(fs.ConfigArgs())
+//	err := config.Parse(file)
+//
+// so the values are checked and attributed exactly as with ParseArgs.
+package pflagset
+
+import (
+	"flag"
+
+	"github.com/andreimerlescu/configurable"
+	"github.com/spf13/pflag"
+)
+
+// FlagSet is a pflag.FlagSet holding a Configurable's flags.
+type FlagSet struct {
+	*pflag.FlagSet
+	args []string
+}
+
+// New returns a FlagSet with every flag conf has registered.
+func New(conf configurable.IConfigurable) *FlagSet {
+	fs := conf.FlagSet()
+	s := &FlagSet{FlagSet: pflag.NewFlagSet(fs.Name(), pflag.ContinueOnError)}
+	for _, info := range conf.Flags() {
+		f := fs.Lookup(info.Name)
+		if f == nil {
+			continue
+		}
+		pf := &pflag.Flag{
+			Name:      info.Name,
+			Shorthand: info.Short,
+			Usage:     f.Usage,
+			Value:     &value{Value: f.Value, name: info.Name, typ: info.Type, s: s},
+			DefValue:  f.DefValue,
+			Hidden:    info.Hidden,
+		}
+		if b, ok := f.Value.(interface{ IsBoolFlag() bool }); ok && b.IsBoolFlag() {
+			pf.NoOptDefVal = "true"
+		}
+		s.AddFlag(pf)
+	}
+	return s
+}
+
+// ConfigArgs returns the flags given to Parse as arguments for the
+// Configurable's SetArgs or ParseArgs, followed by the positional arguments.
+func (s *FlagSet) ConfigArgs() []string {
+	args := append([]string(nil), s.args...)
+	if rest := s.Args(); len(rest) > 0 {
+		args = append(append(args, "--"), rest...)
+	}
+	return args
+}
+
+// value records the arguments of one flag while showing the flag's value.
+type value struct {
+	flag.Value
+	name string
+	typ  string
+	s    *FlagSet
+}
+
+func (v *value) Set(s string) error {
+	v.s.args = append(v.s.args, "-"+v.name+"="+s)
+	return nil
+}
+
+func (v *value) Type() string {
+	return v.typ
+}
@@ -0,0 +1,41 @@
+package pflagset
+
+import (
+	"flag"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andreimerlescu/configurable"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFlagSet(t *testing.T) {
+	os.Clearenv()
+	conf := configurable.New(configurable.WithFlagSet(flag.NewFlagSet(t.Name(), flag.ContinueOnError)))
+	port := conf.NewInt("port", 80, "port to listen on", configurable.WithShort("p"))
+	verbose := conf.NewBool("verbose", false, "log more")
+	timeout := conf.NewDuration("timeout", time.Second, "timeout")
+	hosts := conf.NewList("hosts", nil, "hosts")
+	conf.NewString("internal", "", "internal", configurable.WithHidden())
+
+	fs := New(conf)
+	assert.Nil(t, fs.Lookup("p"), "short names are shorthands")
+	assert.Equal(t, "p", fs.Lookup("port").Shorthand)
+	assert.True(t, fs.Lookup("internal").Hidden)
+	assert.Contains(t, fs.FlagUsages(), "-p, --port int")
+	assert.False(t, strings.Contains(fs.FlagUsages(), "internal"))
+
+	assert.NoError(t, fs.Parse([]string{"-p", "8080", "--verbose", "--timeout=5s", "--hosts", "a", "--hosts", "b", "serve"}))
+	assert.Equal(t, 80, *port, "pflag does not set values itself")
+	assert.Equal(t, []string{"-port=8080", "-verbose=true", "-timeout=5s", "-hosts=a", "-hosts=b", "--", "serve"}, fs.ConfigArgs())
+
+	conf.SetArgs(fs.ConfigArgs())
+	assert.NoError(t, conf.Parse(""))
+	assert.Equal(t, 8080, *port)
+	assert.True(t, *verbose)
+	assert.Equal(t, 5*time.Second, *timeout)
+	assert.Equal(t, []string{"a", "b"}, *hosts)
+	assert.Equal(t, []string{"serve"}, conf.FlagSet().Args())
+}
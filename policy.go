@@ -0,0 +1,90 @@
+package configurable
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// PolicyFunc decides whether a pending change may be applied. Returning an
+// error rejects the whole change.
+type PolicyFunc func(change Diff) error
+
+// Diff is a change about to be applied by a reload or remote update.
+type Diff struct {
+	// Source is where the change comes from, such as "file config.yaml" or
+	// "remote consul".
+	Source string
+	// Changes lists the flags whose values would change, sorted by name.
+	Changes []Change
+	// Before and After are the configuration without and with the change.
+	Before View
+	After  View
+}
+
+// Change is the old and new value of one flag in a Diff.
+type Change struct {
+	Name string
+	Old  interface{}
+	New  interface{}
+}
+
+// Lookup returns the change to name, if the Diff changes it.
+func (d Diff) Lookup(name string) (Change, bool) {
+	for _, change := range d.Changes {
+		if change.Name == name {
+			return change, true
+		}
+	}
+	return Change{}, false
+}
+
+// AddPolicy registers fn to vet every change made after Parse succeeds, by
+// LoadFile, LoadData or a provider, before it is applied. Changes that leave
+// every value as it was are not vetted.
+func (c *Configurable) AddPolicy(fn PolicyFunc) {
+	c.policies = append(c.policies, fn)
+}
+
+// diff compares the current generation with staged values.
+func (c *Configurable) diff(source ValueSource, staged map[string]interface{}) Diff {
+	before := c.current()
+	d := Diff{
+		Source: source.String(),
+		Before: before,
+		After:  &snapshot{values: staged, fs: c.fs},
+	}
+	for name, value := range staged {
+		if old := before.values[name]; !reflect.DeepEqual(old, value) {
+			d.Changes = append(d.Changes, Change{Name: name, Old: old, New: value})
+		}
+	}
+	sort.Slice(d.Changes, func(i, j int) bool {
+		return d.Changes[i].Name < d.Changes[j].Name
+	})
+	return d
+}
+
+// admit runs the validators, freeze windows and policies against staged
+// values proposed by source after Parse has succeeded.
+func (c *Configurable) admit(source ValueSource, staged map[string]interface{}) error {
+	if err := c.validate(staged); err != nil {
+		return err
+	}
+	if len(c.policies) == 0 && c.freezeFlag == "" {
+		return nil
+	}
+	d := c.diff(source, staged)
+	if len(d.Changes) == 0 {
+		return nil
+	}
+	if err := c.checkFreeze(d); err != nil {
+		return err
+	}
+	for _, fn := range c.policies {
+		if err := fn(d); err != nil {
+			return fmt.Errorf("change from %s rejected by policy: %w", d.Source, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package configurable
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPolicies(t *testing.T) {
+	os.Clearenv()
+
+	newConf := func(t *testing.T) *Configurable {
+		conf := newTestConfigurable(t)
+		conf.NewString("env", "prod", "environment")
+		conf.NewFloat64("sampling-rate", 0.01, "trace sampling rate")
+		conf.NewInt("port", 80, "port")
+		conf.AddPolicy(func(d Diff) error {
+			change, ok := d.Lookup("sampling-rate")
+			if ok && d.After.String("env") == "prod" && change.New.(float64) > 0.1 {
+				return errors.New("sampling rate may not exceed 10% in prod")
+			}
+			return nil
+		})
+		conf.SetArgs([]string{})
+		return conf
+	}
+
+	t.Run("test startup is not vetted", func(t *testing.T) {
+		conf := newConf(t)
+		assert.NoError(t, conf.LoadData("json", []byte(`{"sampling-rate": 0.5}`)))
+		assert.Equal(t, 0.5, *conf.Float64("sampling-rate"))
+	})
+
+	t.Run("test reload rejected", func(t *testing.T) {
+		conf := newConf(t)
+		assert.NoError(t, conf.Parse(""))
+		err := conf.LoadData("json", []byte(`{"sampling-rate": 0.5, "port": 8080}`))
+		assert.EqualError(t, err, "change from file rejected by policy: sampling rate may not exceed 10% in prod")
+		assert.Equal(t, 0.01, *conf.Float64("sampling-rate"))
+		assert.Equal(t, 80, *conf.Int("port"))
+
+		assert.NoError(t, conf.LoadData("json", []byte(`{"env": "staging", "sampling-rate": 0.5}`)))
+		assert.Equal(t, 0.5, *conf.Float64("sampling-rate"))
+	})
+
+	t.Run("test remote update rejected", func(t *testing.T) {
+		conf := newConf(t)
+		p := &fakeProvider{name: "consul", data: map[string]interface{}{"sampling-rate": 0.05}}
+		conf.AddProvider(p)
+		assert.NoError(t, conf.Parse(""))
+		p.data = map[string]interface{}{"sampling-rate": 0.2}
+		err := conf.LoadProviders(context.Background())
+		assert.ErrorContains(t, err, "change from remote consul rejected by policy")
+	})
+
+	t.Run("test diff", func(t *testing.T) {
+		conf := newConf(t)
+		assert.NoError(t, conf.Parse(""))
+		var got Diff
+		conf.AddPolicy(func(d Diff) error {
+			got = d
+			return nil
+		})
+		assert.NoError(t, conf.LoadData("json", []byte(`{"port": 8080, "env": "prod"}`)))
+		assert.Equal(t, []Change{{Name: "port", Old: 80, New: 8080}}, got.Changes)
+		assert.Equal(t, 80, got.Before.Int("port"))
+		assert.Equal(t, 8080, got.After.Int("port"))
+	})
+}
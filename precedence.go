@@ -0,0 +1,74 @@
+package configurable
+
+import (
+	"fmt"
+	"slices"
+)
+
+// defaultPrecedence is the order Parse applies sources in, highest first.
+var defaultPrecedence = []SourceKind{SourceEnv, SourceRemote, SourceFlag, SourceFile, SourceDefault}
+
+// SetPrecedence sets which source wins when several set a flag, highest
+// first, as in SetPrecedence(SourceFlag, SourceEnv, SourceFile,
+// SourceDefault). Kinds left out rank below those given, in their default
+// order, which is env, remote, flag, file and default. Parse applies the
+// sources from lowest to highest, and a later load leaves alone every flag
+// that a higher source has set, so reloading a file does not undo a
+// command-line flag. Set and ApplyReplicated are runtime changes that always
+// apply and that any later load may replace; passing them panics.
+func (c *Configurable) SetPrecedence(kinds ...SourceKind) {
+	seen := make(map[SourceKind]bool)
+	for _, kind := range kinds {
+		if kind == SourceSet || kind == SourceReplicated || !slices.Contains(defaultPrecedence, kind) {
+			panic(fmt.Sprintf("configurable: SetPrecedence with %s, which is not a source that can be ranked", kind))
+		}
+		if seen[kind] {
+			panic(fmt.Sprintf("configurable: SetPrecedence with %s twice", kind))
+		}
+		seen[kind] = true
+	}
+	precedence := append([]SourceKind(nil), kinds...)
+	for _, kind := range defaultPrecedence {
+		if !seen[kind] {
+			precedence = append(precedence, kind)
+		}
+	}
+	c.precedence = precedence
+}
+
+// WithPrecedence is SetPrecedence.
+func WithPrecedence(kinds ...SourceKind) Option {
+	return func(c *Configurable) {
+		c.SetPrecedence(kinds...)
+	}
+}
+
+// rank returns the position of kind in the precedence, 0 being the highest,
+// or -1 for runtime changes, which are not ranked.
+func (c *Configurable) rank(kind SourceKind) int {
+	precedence := c.precedence
+	if precedence == nil {
+		precedence = defaultPrecedence
+	}
+	return slices.Index(precedence, kind)
+}
+
+// outranked reports whether the source of the value name holds ranks above
+// kind, so a value from kind must not replace it. The caller holds c.mu.
+func (c *Configurable) outranked(name string, kind SourceKind) bool {
+	current, ok := c.sources[name]
+	if !ok {
+		return false
+	}
+	have, incoming := c.rank(current.Kind), c.rank(kind)
+	return have >= 0 && incoming >= 0 && have < incoming
+}
+
+// parseOrder returns the sources Parse applies, lowest precedence first.
+func (c *Configurable) parseOrder() []SourceKind {
+	order := []SourceKind{SourceFile, SourceFlag, SourceRemote, SourceEnv}
+	slices.SortFunc(order, func(a, b SourceKind) int {
+		return c.rank(b) - c.rank(a)
+	})
+	return order
+}
@@ -0,0 +1,97 @@
+package configurable
+
+import (
+	"fmt"
+	"time"
+)
+
+// ProblemKind classifies a Problem.
+type ProblemKind int
+
+const (
+	// UnknownKey is a key in a file or provider that matches no flag.
+	UnknownKey ProblemKind = iota
+	// DeprecatedFlag is a deprecated flag that a source set.
+	DeprecatedFlag
+	// InvalidEnv is an environment variable whose value was rejected, leaving
+	// the flag's value as it was.
+	InvalidEnv
+	// ProviderUnavailable is a provider that failed to load and was replaced
+	// by its cached copy or skipped, as its FailurePolicy says.
+	ProviderUnavailable
+	// RejectedUpdate is a watched provider or file update that failed
+	// validation or a policy.
+	RejectedUpdate
+	// BreakerOpen is a provider whose circuit breaker opened.
+	BreakerOpen
+)
+
+func (k ProblemKind) String() string {
+	switch k {
+	case UnknownKey:
+		return "unknown-key"
+	case DeprecatedFlag:
+		return "deprecated-flag"
+	case InvalidEnv:
+		return "invalid-env"
+	case ProviderUnavailable:
+		return "provider-unavailable"
+	case RejectedUpdate:
+		return "rejected-update"
+	case BreakerOpen:
+		return "breaker-open"
+	default:
+		return fmt.Sprintf("ProblemKind(%d)", int(k))
+	}
+}
+
+func (k ProblemKind) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+// Problem is an issue that did not stop configuration from loading, but that
+// an operator may want to know about.
+type Problem struct {
+	Kind ProblemKind `json:"kind"`
+	// Name is the flag, key or provider concerned.
+	Name string `json:"name"`
+	// Source is where the problem came from, such as "file config.yaml" or
+	// "env PORT".
+	Source  string    `json:"source,omitempty"`
+	Message string    `json:"message"`
+	Time    time.Time `json:"time"`
+}
+
+func (p Problem) String() string {
+	if p.Source == "" {
+		return fmt.Sprintf("%s %s: %s", p.Kind, p.Name, p.Message)
+	}
+	return fmt.Sprintf("%s %s from %s: %s", p.Kind, p.Name, p.Source, p.Message)
+}
+
+// maxProblems bounds the problems kept; older ones are dropped first.
+const maxProblems = 100
+
+// Problems returns the non-fatal issues met while loading configuration,
+// oldest first, so applications can report them from health endpoints. A
+// problem that recurs is kept once, at its first occurrence.
+func (c *Configurable) Problems() []Problem {
+	c.problemsMu.Lock()
+	defer c.problemsMu.Unlock()
+	return append([]Problem(nil), c.problems...)
+}
+
+// problem records a Problem unless the same one is already recorded.
+func (c *Configurable) problem(kind ProblemKind, name, source, message string) {
+	c.problemsMu.Lock()
+	defer c.problemsMu.Unlock()
+	for _, p := range c.problems {
+		if p.Kind == kind && p.Name == name && p.Source == source && p.Message == message {
+			return
+		}
+	}
+	if len(c.problems) == maxProblems {
+		c.problems = c.problems[1:]
+	}
+	c.problems = append(c.problems, Problem{Kind: kind, Name: name, Source: source, Message: message, Time: c.clock().Now()})
+}
@@ -0,0 +1,53 @@
+package configurable
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProblems(t *testing.T) {
+	os.Clearenv()
+
+	t.Run("test problems", func(t *testing.T) {
+		conf := newTestConfigurable(t)
+		conf.NewInt("port", 80, "port")
+		conf.NewString("addr", "", "address", WithDeprecated("use listen"))
+		conf.AddProvider(&fakeProvider{name: "consul", err: assert.AnError}, WithFailurePolicy(UseDefaults))
+		conf.SetEnv("port", "eighty")
+		conf.SetArgs([]string{"-addr", ":80"})
+		assert.Empty(t, conf.Problems())
+
+		assert.NoError(t, conf.LoadData("json", []byte(`{"port": 8080, "legacy": true}`)))
+		assert.NoError(t, conf.Parse(""))
+		assert.Equal(t, 8080, *conf.Int("port"))
+		assert.Equal(t, 8080, *conf.Int("port"))
+
+		var kinds []string
+		for _, p := range conf.Problems() {
+			kinds = append(kinds, p.String())
+		}
+		assert.Equal(t, []string{
+			"unknown-key legacy from file: matches no flag",
+			"provider-unavailable consul: skipped: " + assert.AnError.Error(),
+			`invalid-env port from env port: strconv.Atoi: parsing "eighty": invalid syntax`,
+			"deprecated-flag addr from flag: use listen",
+		}, kinds)
+
+		data, err := json.Marshal(conf.Problems()[0])
+		assert.NoError(t, err)
+		assert.Contains(t, string(data), `"kind":"unknown-key"`)
+	})
+
+	t.Run("test bounded", func(t *testing.T) {
+		conf := newTestConfigurable(t)
+		for i := 0; i < maxProblems+5; i++ {
+			conf.problem(UnknownKey, string(rune('a'+i%26))+string(rune('0'+i/26)), "", "matches no flag")
+		}
+		problems := conf.Problems()
+		assert.Len(t, problems, maxProblems)
+		assert.Equal(t, "f0", problems[0].Name)
+	})
+}
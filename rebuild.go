@@ -0,0 +1,38 @@
+package configurable
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Rebuild builds a value, such as a database pool or HTTP client, from the
+// configuration, and builds it again whenever a load or Set changes any of
+// keys, so the returned pointer always holds a value made from the newest
+// settings. A load changing several keys rebuilds once. The first build's
+// error is returned; a later build that fails leaves the previous value in
+// place, so build should report its own errors. Retiring a replaced value,
+// such as closing the old pool, is up to build or the caller.
+func Rebuild[T any](c IConfigurable, keys []string, build func(View) (T, error)) (*atomic.Pointer[T], error) {
+	var ptr atomic.Pointer[T]
+	v, err := build(c.View())
+	if err != nil {
+		return nil, err
+	}
+	ptr.Store(&v)
+	var mu sync.Mutex
+	var built uint64
+	for _, key := range keys {
+		c.OnKeyChange(key, func(e ChangeEvent) {
+			mu.Lock()
+			defer mu.Unlock()
+			if e.Batch <= built {
+				return
+			}
+			built = e.Batch
+			if v, err := build(c.View()); err == nil {
+				ptr.Store(&v)
+			}
+		})
+	}
+	return &ptr, nil
+}
@@ -0,0 +1,49 @@
+package configurable
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testClient struct {
+	addr string
+}
+
+func TestRebuild(t *testing.T) {
+	os.Clearenv()
+
+	conf := newTestConfigurable(t)
+	conf.NewString("host", "localhost", "host")
+	conf.NewInt("port", 80, "port")
+	conf.NewInt("workers", 4, "workers")
+
+	builds := 0
+	client, err := Rebuild(conf, []string{"host", "port"}, func(v View) (*testClient, error) {
+		builds++
+		if v.Int("port") == 0 {
+			return nil, errors.New("no port")
+		}
+		return &testClient{addr: fmt.Sprintf("%s:%d", v.String("host"), v.Int("port"))}, nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "localhost:80", (*client.Load()).addr)
+
+	assert.NoError(t, conf.LoadData("json", []byte(`{"host": "db", "port": 5432}`)))
+	assert.Equal(t, "db:5432", (*client.Load()).addr)
+	assert.Equal(t, 2, builds)
+
+	assert.NoError(t, conf.Set("workers", 8))
+	assert.Equal(t, 2, builds)
+
+	assert.NoError(t, conf.Set("port", 0))
+	assert.Equal(t, "db:5432", (*client.Load()).addr, "failed build keeps the old client")
+
+	_, err = Rebuild(conf, []string{"port"}, func(v View) (*testClient, error) {
+		return nil, errors.New("no port")
+	})
+	assert.Error(t, err)
+}
@@ -0,0 +1,277 @@
+package configurable
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Provider fetches configuration from a remote store such as Consul or etcd.
+type Provider interface {
+	// Name identifies the provider in reports, errors and cache file names.
+	Name() string
+	// Load returns the provider's current document, with nested mappings
+	// for dotted flag names as in a config file.
+	Load(ctx context.Context) (map[string]interface{}, error)
+}
+
+// Watcher is implemented by providers that deliver changes as they happen
+// rather than waiting for LoadProviders.
+type Watcher interface {
+	// Watch blocks until ctx is done or the watch fails, calling apply with
+	// each new document. An error from apply means that document was
+	// rejected; the watch carries on.
+	Watch(ctx context.Context, apply func(map[string]interface{}) error) error
+}
+
+// FailurePolicy decides what happens when a provider cannot be loaded.
+type FailurePolicy int
+
+const (
+	// FailStartup returns the provider's error from Parse.
+	FailStartup FailurePolicy = iota
+	// UseCached applies the copy saved in the cache directory by the last
+	// successful load, failing only if there is none.
+	UseCached
+	// UseDefaults skips the provider, leaving values as the other sources
+	// set them.
+	UseDefaults
+)
+
+// SourceOption configures a provider added with AddProvider.
+type SourceOption func(*remoteSource)
+
+// WithFailurePolicy sets what happens when the provider is unavailable. The
+// default is FailStartup.
+func WithFailurePolicy(policy FailurePolicy) SourceOption {
+	return func(s *remoteSource) {
+		s.policy = policy
+	}
+}
+
+type remoteSource struct {
+	provider Provider
+	policy   FailurePolicy
+	maxAge   time.Duration
+	retry    RetryPolicy
+	breaker  *breaker
+	debounce time.Duration
+}
+
+// AddProvider adds a remote source. Parse loads providers in the order they
+// were added, after the config file and before the environment.
+func (c *Configurable) AddProvider(p Provider, opts ...SourceOption) {
+	s := &remoteSource{provider: p}
+	for _, opt := range opts {
+		opt(s)
+	}
+	c.providers = append(c.providers, s)
+	c.registerSource(remoteName(p.Name()), s.maxAge)
+}
+
+// remoteName is how the provider name is reported as a source.
+func remoteName(name string) string {
+	return ValueSource{Kind: SourceRemote, Name: name}.String()
+}
+
+// SetCacheDir sets where each provider's last successfully loaded document
+// is kept, for providers using UseCached.
+func (c *Configurable) SetCacheDir(dir string) {
+	c.cacheDir = dir
+}
+
+// LoadProviders loads every provider again, applying their failure
+// policies. Parse calls it; call it again to refresh remote values.
+func (c *Configurable) LoadProviders(ctx context.Context) error {
+	for _, s := range c.providers {
+		err := c.loading(remoteName(s.provider.Name()), func() error {
+			return c.loadProvider(ctx, s)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WatchProviders runs the Watch method of every provider that is a Watcher,
+// applying the documents they deliver as LoadProviders would, until ctx is
+// done, Close is called or one of the watches returns. It returns that
+// watch's error, and nil at once if no provider is a Watcher.
+func (c *Configurable) WatchProviders(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	finished := make(chan struct{})
+	remove, err := c.onClose(func() error {
+		cancel()
+		<-finished
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	defer remove()
+	defer close(finished)
+	errs := make(chan error, len(c.providers))
+	watching := 0
+	for _, s := range c.providers {
+		w, ok := s.provider.(Watcher)
+		if !ok {
+			continue
+		}
+		watching++
+		name := s.provider.Name()
+		go func() {
+			c.recordWatch(remoteName(name), true, nil)
+			apply := func(data map[string]interface{}) error {
+				return c.loading(remoteName(name), func() error {
+					return c.applyWatched(ctx, s, data)
+				})
+			}
+			if s.debounce > 0 {
+				apply = c.debounceApply(ctx, s.debounce, apply)
+			}
+			err := w.Watch(ctx, apply)
+			// A watch stopped because ctx is done, by the caller or by
+			// another watch failing, has not failed itself.
+			if ctx.Err() != nil {
+				c.recordWatch(remoteName(name), false, nil)
+			} else {
+				c.recordWatch(remoteName(name), false, fmt.Errorf("watch stopped: %w", err))
+			}
+			errs <- err
+		}()
+	}
+	var first error
+	for i := 0; i < watching; i++ {
+		if err := <-errs; first == nil {
+			first = err
+			cancel()
+		}
+	}
+	return first
+}
+
+// applyWatched applies a document delivered by the watch of s, holding the
+// flags registered WithCanary and delaying those registered WithStagger.
+func (c *Configurable) applyWatched(ctx context.Context, s *remoteSource, data map[string]interface{}) error {
+	name := s.provider.Name()
+	if !s.breaker.allow(c.clock().Now()) {
+		c.recordLoad(remoteName(name), ErrBreakerOpen, false)
+		return ErrBreakerOpen
+	}
+	if reason := s.breaker.admit(c.clock().Now(), data); reason != "" {
+		c.tripped(s, reason)
+		c.recordLoad(remoteName(name), ErrBreakerOpen, false)
+		return ErrBreakerOpen
+	}
+	if err := c.setValuesFromMap(SourceRemote, name, c.stagger(ctx, s, c.holdCanary(name, data))); err != nil {
+		c.logger().Warn("configurable: provider update rejected", "provider", name, "error", err)
+		c.problem(RejectedUpdate, name, "", err.Error())
+		c.recordLoad(remoteName(name), err, false)
+		c.tripped(s, s.breaker.record(c.clock().Now(), nil, err))
+		return err
+	}
+	c.writeCache(name, data)
+	c.recordLoad(remoteName(name), nil, false)
+	s.breaker.record(c.clock().Now(), data, nil)
+	return nil
+}
+
+func (c *Configurable) loadProvider(ctx context.Context, s *remoteSource) error {
+	name := s.provider.Name()
+	if !s.breaker.allow(c.clock().Now()) {
+		// The provider's last values stay in place until the breaker closes.
+		c.recordLoad(remoteName(name), ErrBreakerOpen, false)
+		return nil
+	}
+	var data map[string]interface{}
+	err := s.retry.do(ctx, c.clock(), anyError, c.retrying(remoteName(name)), func() (err error) {
+		data, err = s.provider.Load(ctx)
+		return err
+	})
+	if err == nil {
+		if reason := s.breaker.admit(c.clock().Now(), data); reason != "" {
+			c.tripped(s, reason)
+			c.recordLoad(remoteName(name), ErrBreakerOpen, false)
+			return nil
+		}
+		if err := c.setValuesFromMap(SourceRemote, name, data); err != nil {
+			c.recordLoad(remoteName(name), err, false)
+			c.tripped(s, s.breaker.record(c.clock().Now(), nil, err))
+			return fmt.Errorf("provider %s: %w", name, err)
+		}
+		c.writeCache(name, data)
+		c.recordLoad(remoteName(name), nil, false)
+		s.breaker.record(c.clock().Now(), data, nil)
+		return nil
+	}
+	c.recordLoad(remoteName(name), err, false)
+	c.tripped(s, s.breaker.record(c.clock().Now(), nil, err))
+	switch s.policy {
+	case UseCached:
+		cached, cacheErr := c.readCache(name)
+		if cacheErr != nil {
+			return fmt.Errorf("provider %s: %w; no cached copy: %v", name, err, cacheErr)
+		}
+		if err := c.setValuesFromMap(SourceRemote, name, cached); err != nil {
+			return fmt.Errorf("provider %s: cached copy: %w", name, err)
+		}
+		c.recordLoad(remoteName(name), err, true)
+		c.logger().Warn("configurable: provider unavailable, using cached copy", "provider", name, "error", err)
+		c.problem(ProviderUnavailable, name, "", "using cached copy: "+err.Error())
+		return nil
+	case UseDefaults:
+		c.logger().Warn("configurable: provider unavailable, skipping it", "provider", name, "error", err)
+		c.problem(ProviderUnavailable, name, "", "skipped: "+err.Error())
+		return nil
+	}
+	return fmt.Errorf("provider %s: %w", name, err)
+}
+
+// cachePath returns the cache file for the provider name, or "" when no
+// cache directory is set.
+func (c *Configurable) cachePath(name string) string {
+	if c.cacheDir == "" {
+		return ""
+	}
+	safe := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '.':
+			return r
+		}
+		return '_'
+	}, name)
+	return filepath.Join(c.cacheDir, safe+".json")
+}
+
+// writeCache saves data for the provider name. The cache is best effort: a
+// failure to write it never fails a load.
+func (c *Configurable) writeCache(name string, data map[string]interface{}) {
+	path := c.cachePath(name)
+	if path == "" {
+		return
+	}
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return
+	}
+	if makeDir(c.cacheDir, cacheDirMode) == nil {
+		_ = writeFile(path, encoded, privateFileMode)
+	}
+}
+
+func (c *Configurable) readCache(name string) (map[string]interface{}, error) {
+	path := c.cachePath(name)
+	if path == "" {
+		return nil, fmt.Errorf("no cache directory set")
+	}
+	data, err := readFile(path, c.parseOptions.MaxFileSize)
+	if err != nil {
+		return nil, err
+	}
+	return decodeJSON(data)
+}
@@ -0,0 +1,140 @@
+package configurable
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeProvider serves data, or err when it is set.
+type fakeProvider struct {
+	name string
+	data map[string]interface{}
+	err  error
+}
+
+func (p *fakeProvider) Name() string { return p.name }
+
+func (p *fakeProvider) Load(ctx context.Context) (map[string]interface{}, error) {
+	return p.data, p.err
+}
+
+func TestProviders(t *testing.T) {
+	os.Clearenv()
+	errDown := errors.New("connection refused")
+
+	newConf := func(t *testing.T, p Provider, opts ...SourceOption) *Configurable {
+		conf := newTestConfigurable(t)
+		conf.NewInt("port", 80, "port")
+		conf.NewString("db.host", "localhost", "database host")
+		conf.SetArgs([]string{})
+		conf.SetLogger(slog.New(slog.NewTextHandler(io.Discard, nil)))
+		conf.AddProvider(p, opts...)
+		return conf
+	}
+
+	t.Run("test values are applied", func(t *testing.T) {
+		p := &fakeProvider{name: "consul", data: map[string]interface{}{"port": 8080, "db": map[string]interface{}{"host": "db1"}}}
+		conf := newConf(t, p)
+		report, err := conf.ParseReport("")
+		assert.NoError(t, err)
+		assert.Equal(t, 8080, *conf.Int("port"))
+		assert.Equal(t, "db1", *conf.String("db.host"))
+		assert.Equal(t, "config: 2 from consul", report.String())
+	})
+
+	t.Run("test fail startup", func(t *testing.T) {
+		conf := newConf(t, &fakeProvider{name: "consul", err: errDown})
+		err := conf.Parse("")
+		assert.ErrorIs(t, err, errDown)
+		assert.EqualError(t, err, "provider consul: connection refused")
+	})
+
+	t.Run("test use defaults", func(t *testing.T) {
+		conf := newConf(t, &fakeProvider{name: "consul", err: errDown}, WithFailurePolicy(UseDefaults))
+		assert.NoError(t, conf.Parse(""))
+		assert.Equal(t, 80, *conf.Int("port"))
+	})
+
+	t.Run("test use cached", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "cache")
+		p := &fakeProvider{name: "consul:app/config", data: map[string]interface{}{"port": 8080}}
+		conf := newConf(t, p, WithFailurePolicy(UseCached))
+		conf.SetCacheDir(dir)
+		assert.NoError(t, conf.Parse(""))
+		assert.FileExists(t, filepath.Join(dir, "consul_app_config.json"))
+
+		p.err = errDown
+		conf = newConf(t, p, WithFailurePolicy(UseCached))
+		conf.SetCacheDir(dir)
+		assert.NoError(t, conf.Parse(""))
+		assert.Equal(t, 8080, *conf.Int("port"))
+	})
+
+	t.Run("test use cached without a cache", func(t *testing.T) {
+		conf := newConf(t, &fakeProvider{name: "consul", err: errDown}, WithFailurePolicy(UseCached))
+		conf.SetCacheDir(t.TempDir())
+		err := conf.Parse("")
+		assert.ErrorIs(t, err, errDown)
+		assert.Contains(t, err.Error(), "no cached copy")
+	})
+}
+
+// pushProvider delivers each document sent on docs to Watch, recording what
+// apply returned.
+type pushProvider struct {
+	fakeProvider
+	docs    chan map[string]interface{}
+	results chan error
+}
+
+func (p *pushProvider) Watch(ctx context.Context, apply func(map[string]interface{}) error) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case doc := <-p.docs:
+			p.results <- apply(doc)
+		}
+	}
+}
+
+func TestWatchProviders(t *testing.T) {
+	os.Clearenv()
+
+	t.Run("test no watchers", func(t *testing.T) {
+		conf := newTestConfigurable(t)
+		conf.AddProvider(&fakeProvider{name: "static"})
+		assert.NoError(t, conf.WatchProviders(context.Background()))
+	})
+
+	t.Run("test pushed documents", func(t *testing.T) {
+		conf := newTestConfigurable(t)
+		conf.SetLogger(slog.New(slog.NewTextHandler(io.Discard, nil)))
+		conf.NewBoundedInt("port", 80, 1, 65535, "port")
+		p := &pushProvider{fakeProvider: fakeProvider{name: "push"}, docs: make(chan map[string]interface{}), results: make(chan error)}
+		conf.AddProvider(p)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error)
+		go func() { done <- conf.WatchProviders(ctx) }()
+
+		p.docs <- map[string]interface{}{"port": 8080}
+		assert.NoError(t, <-p.results)
+		assert.Equal(t, 8080, conf.View().Int("port"))
+		assert.Equal(t, "remote push", conf.sources["port"].String())
+
+		p.docs <- map[string]interface{}{"port": 0}
+		assert.Error(t, <-p.results)
+		assert.Equal(t, 8080, conf.View().Int("port"))
+
+		cancel()
+		assert.ErrorIs(t, <-done, context.Canceled)
+	})
+}
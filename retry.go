@@ -0,0 +1,108 @@
+package configurable
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"math/rand/v2"
+	"time"
+)
+
+// RetryPolicy retries a failed load with exponential backoff. The zero value
+// makes a single attempt.
+type RetryPolicy struct {
+	// MaxAttempts is the most attempts made, including the first. Zero means
+	// no limit when MaxElapsed is set, and a single attempt otherwise.
+	MaxAttempts int
+	// InitialInterval is the wait before the first retry. The default is
+	// 100ms.
+	InitialInterval time.Duration
+	// MaxInterval caps the wait between attempts. The default is 10s.
+	MaxInterval time.Duration
+	// Multiplier grows the wait after each retry. The default is 2.
+	Multiplier float64
+	// Jitter randomizes each wait by up to this fraction of it, from 0 to 1,
+	// so that instances starting together do not retry in lockstep.
+	Jitter float64
+	// MaxElapsed stops retrying once this much time has passed since the
+	// first attempt. Zero means no limit.
+	MaxElapsed time.Duration
+}
+
+// WithRetry retries the provider's Load as policy says before its
+// FailurePolicy applies.
+func WithRetry(policy RetryPolicy) SourceOption {
+	return func(s *remoteSource) {
+		s.retry = policy
+	}
+}
+
+// retries reports whether the policy allows more than one attempt.
+func (p RetryPolicy) retries() bool {
+	return p.MaxAttempts > 1 || p.MaxElapsed > 0
+}
+
+// do calls fn until it succeeds, returns an error that retryable rejects, or
+// the policy or ctx ends the retries. It returns the last error. onRetry is
+// called with each error that will be retried.
+func (p RetryPolicy) do(ctx context.Context, clock Clock, retryable func(error) bool, onRetry func(error, time.Duration), fn func() error) error {
+	interval := p.InitialInterval
+	if interval <= 0 {
+		interval = 100 * time.Millisecond
+	}
+	maxInterval := p.MaxInterval
+	if maxInterval <= 0 {
+		maxInterval = 10 * time.Second
+	}
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 2
+	}
+	start := clock.Now()
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || !p.retries() || !retryable(err) {
+			return err
+		}
+		if p.MaxAttempts > 0 && attempt >= p.MaxAttempts {
+			return err
+		}
+		wait := interval
+		if p.Jitter > 0 {
+			wait += time.Duration((rand.Float64()*2 - 1) * p.Jitter * float64(wait))
+		}
+		if p.MaxElapsed > 0 && clock.Now().Sub(start)+wait > p.MaxElapsed {
+			return err
+		}
+		if onRetry != nil {
+			onRetry(err, wait)
+		}
+		waited := make(chan struct{})
+		timer := clock.AfterFunc(wait, func() { close(waited) })
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-waited:
+		}
+		interval = min(time.Duration(float64(interval)*multiplier), maxInterval)
+	}
+}
+
+// transientFileError reports whether reading a file failed in a way that a
+// retry may fix, such as an NFS timeout, rather than because the file is
+// missing, unreadable or too large.
+func transientFileError(err error) bool {
+	return !errors.Is(err, fs.ErrNotExist) && !errors.Is(err, fs.ErrPermission) && !errors.Is(err, ErrLimitExceeded)
+}
+
+func anyError(error) bool {
+	return true
+}
+
+// retrying returns the function logging each retry of source.
+func (c *Configurable) retrying(source string) func(error, time.Duration) {
+	return func(err error, wait time.Duration) {
+		c.logger().Warn("configurable: load failed, retrying", "source", source, "error", err, "wait", wait)
+	}
+}
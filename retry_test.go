@@ -0,0 +1,100 @@
+package configurable
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// flakyProvider fails until it has been loaded failures+1 times.
+type flakyProvider struct {
+	fakeProvider
+	failures int
+	loads    int
+}
+
+func (p *flakyProvider) Load(ctx context.Context) (map[string]interface{}, error) {
+	p.loads++
+	if p.loads <= p.failures {
+		return nil, errors.New("connection refused")
+	}
+	return p.data, nil
+}
+
+func TestRetry(t *testing.T) {
+	os.Clearenv()
+
+	t.Run("test policy", func(t *testing.T) {
+		errDown := errors.New("down")
+		var waits []time.Duration
+		calls := 0
+		p := RetryPolicy{MaxAttempts: 4, InitialInterval: time.Millisecond, MaxInterval: 3 * time.Millisecond}
+		err := p.do(context.Background(), realClock{}, anyError, func(_ error, wait time.Duration) { waits = append(waits, wait) }, func() error {
+			calls++
+			return errDown
+		})
+		assert.ErrorIs(t, err, errDown)
+		assert.Equal(t, 4, calls)
+		assert.Equal(t, []time.Duration{time.Millisecond, 2 * time.Millisecond, 3 * time.Millisecond}, waits)
+
+		calls = 0
+		assert.ErrorIs(t, RetryPolicy{}.do(context.Background(), realClock{}, anyError, nil, func() error { calls++; return errDown }), errDown)
+		assert.Equal(t, 1, calls)
+
+		calls = 0
+		err = p.do(context.Background(), realClock{}, transientFileError, nil, func() error { calls++; return fs.ErrNotExist })
+		assert.ErrorIs(t, err, fs.ErrNotExist)
+		assert.Equal(t, 1, calls)
+
+		calls = 0
+		p = RetryPolicy{InitialInterval: 10 * time.Millisecond, MaxElapsed: 25 * time.Millisecond}
+		assert.Error(t, p.do(context.Background(), realClock{}, anyError, nil, func() error { calls++; return errDown }))
+		assert.Equal(t, 2, calls)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		calls = 0
+		p = RetryPolicy{MaxAttempts: 3, InitialInterval: time.Hour}
+		assert.Error(t, p.do(ctx, realClock{}, anyError, nil, func() error { calls++; return errDown }))
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("test jitter", func(t *testing.T) {
+		p := RetryPolicy{MaxAttempts: 50, InitialInterval: 100 * time.Microsecond, MaxInterval: 100 * time.Microsecond, Jitter: 0.5}
+		varied := false
+		_ = p.do(context.Background(), realClock{}, anyError, func(_ error, wait time.Duration) {
+			assert.GreaterOrEqual(t, wait, 50*time.Microsecond)
+			assert.LessOrEqual(t, wait, 150*time.Microsecond)
+			varied = varied || wait != 100*time.Microsecond
+		}, func() error { return errors.New("down") })
+		assert.True(t, varied)
+	})
+
+	t.Run("test provider", func(t *testing.T) {
+		conf := newTestConfigurable(t)
+		port := conf.NewInt("port", 80, "port")
+		p := &flakyProvider{fakeProvider: fakeProvider{name: "consul", data: map[string]interface{}{"port": 8080}}, failures: 2}
+		conf.AddProvider(p, WithRetry(RetryPolicy{MaxAttempts: 3, InitialInterval: time.Millisecond}))
+		conf.SetArgs([]string{})
+		assert.NoError(t, conf.Parse(""))
+		assert.Equal(t, 3, p.loads)
+		assert.Equal(t, 8080, *port)
+	})
+
+	t.Run("test file", func(t *testing.T) {
+		conf := newTestConfigurable(t)
+		conf.SetParseOptions(ParseOptions{FileRetry: RetryPolicy{MaxAttempts: 3, InitialInterval: time.Millisecond}})
+		conf.SetArgs([]string{})
+		// Reading a directory fails in a way that is retried.
+		start := time.Now()
+		assert.Error(t, conf.Parse(t.TempDir()))
+		assert.GreaterOrEqual(t, time.Since(start), 3*time.Millisecond)
+		assert.ErrorIs(t, conf.Parse(filepath.Join(t.TempDir(), "missing.json")), fs.ErrNotExist)
+	})
+}
@@ -0,0 +1,104 @@
+package configurable
+
+import (
+	"encoding/json"
+	"flag"
+	"time"
+)
+
+// jsonSchemaDialect is the JSON Schema version Schema declares.
+const jsonSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// Schema renders a JSON Schema describing a configuration file for the
+// registered flags, with their types, defaults and constraints. Editors can
+// use it to validate and complete config files.
+func (c *Configurable) Schema() ([]byte, error) {
+	properties := make(map[string]interface{})
+	c.fs.VisitAll(func(f *flag.Flag) {
+		if _, ok := c.flags[f.Name]; ok {
+			properties[f.Name] = c.propertySchema(f)
+		}
+	})
+	schema := map[string]interface{}{
+		"$schema":    jsonSchemaDialect,
+		"title":      c.fs.Name(),
+		"type":       "object",
+		"properties": properties,
+	}
+	data, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+func (c *Configurable) propertySchema(f *flag.Flag) map[string]interface{} {
+	p := make(map[string]interface{})
+	switch v := c.flags[f.Name].(type) {
+	case *int, *int64:
+		p["type"] = "integer"
+	case *float64:
+		p["type"] = "number"
+	case *bool:
+		p["type"] = "boolean"
+	case *time.Duration:
+		p["type"] = "string"
+		p["pattern"] = durationPattern
+	case *ListFlag:
+		p["type"] = "array"
+		p["items"] = map[string]interface{}{"type": "string"}
+	case typedList:
+		p["type"] = "array"
+		p["items"] = map[string]interface{}{"type": map[string]string{"int": "integer", "float64": "number", "bool": "boolean"}[v.itemKind()]}
+	case *MapFlag:
+		p["type"] = "object"
+		p["additionalProperties"] = map[string]interface{}{"type": "string"}
+	case *JSONFlag:
+		// Any JSON value is accepted.
+	default:
+		p["type"] = "string"
+	}
+	if f.Usage != "" {
+		p["description"] = f.Usage
+	}
+	if def, err := c.convert(f.Name, f.DefValue); err == nil {
+		p["default"] = formatValue(def)
+	}
+	if m, ok := c.meta[f.Name]; ok {
+		if m.bounds != nil {
+			p["minimum"] = m.bounds.min
+			p["maximum"] = m.bounds.max
+		}
+		if r := m.text; r != nil {
+			if r.minLength > 0 {
+				p["minLength"] = r.minLength
+			}
+			if r.maxLength > 0 {
+				p["maxLength"] = r.maxLength
+			}
+			switch patterns := r.patterns(); len(patterns) {
+			case 0:
+			case 1:
+				p["pattern"] = patterns[0]
+			default:
+				all := make([]interface{}, len(patterns))
+				for i, pattern := range patterns {
+					all[i] = map[string]interface{}{"pattern": pattern}
+				}
+				p["allOf"] = all
+			}
+		}
+		if m.secret {
+			p["writeOnly"] = true
+		}
+		if m.unit != "" {
+			// Values may also be written with a suffix.
+			p["type"] = []string{p["type"].(string), "string"}
+			p["x-unit"] = string(m.unit)
+		}
+	}
+	return p
+}
+
+// durationPattern matches the strings time.ParseDuration accepts.
+const durationPattern = `^[-+]?(0|([0-9]*(\.[0-9]*)?(ns|us|µs|ms|s|m|h))+)$`
@@ -0,0 +1,112 @@
+package configurable
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// ErrReadOnly is matched by errors.Is for every *ReadOnlyError.
+var ErrReadOnly = errors.New("configuration is read-only")
+
+// ReadOnlyError is returned by operations that would mutate configuration at
+// runtime while read-only mode is on.
+type ReadOnlyError struct {
+	// Op is the refused operation, such as "set".
+	Op   string
+	Name string
+}
+
+func (e *ReadOnlyError) Error() string {
+	msg := ErrReadOnly.Error() + ": cannot " + e.Op
+	if e.Name != "" {
+		msg += " " + e.Name
+	}
+	return msg
+}
+
+func (e *ReadOnlyError) Is(target error) bool {
+	return target == ErrReadOnly
+}
+
+// readOnlyVar turns read-only mode on from the environment, so a deployment
+// can enforce it without code changes.
+const readOnlyVar = "CONFIG_READ_ONLY"
+
+// ReadOnly turns read-only mode on or off. In read-only mode runtime
+// mutation, such as Set, fails with a *ReadOnlyError; Parse and loads are
+// unaffected. Setting CONFIG_READ_ONLY to a true value also turns it on.
+func (c *Configurable) ReadOnly(enabled bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.readOnly = enabled
+}
+
+// IsReadOnly reports whether read-only mode is on.
+func (c *Configurable) IsReadOnly() bool {
+	c.mu.RLock()
+	readOnly := c.readOnly
+	c.mu.RUnlock()
+	if readOnly {
+		return true
+	}
+	v, _ := c.lookupEnv(readOnlyVar)
+	on, _ := strconv.ParseBool(v)
+	return on
+}
+
+// Set changes a flag at runtime. value may be the flag's Go type or anything
+// a config file could hold, such as "5s" for a duration; list and map values
+// replace the current ones. Once Parse has succeeded, the change is checked by
+// the validators and policies first. Changes to flags registered
+// WithApproval fail with ErrApprovalRequired; stage them with Propose.
+func (c *Configurable) Set(name string, value interface{}) error {
+	if c.IsReadOnly() {
+		return &ReadOnlyError{Op: "set", Name: name}
+	}
+	if c.NeedsApproval(name) {
+		return fmt.Errorf("%w: %s; stage the change with Propose", ErrApprovalRequired, name)
+	}
+	return c.set(name, value)
+}
+
+// set is Set without the read-only and approval gates.
+func (c *Configurable) set(name string, value interface{}) error {
+	if err := c.checkRaw(name, value); err != nil {
+		return err
+	}
+	v, err := c.convert(name, value)
+	if err != nil {
+		return err
+	}
+	if err := c.checkEntries(name, v); err != nil {
+		return err
+	}
+	var applied Diff
+	err = c.update(func() error {
+		source := ValueSource{Kind: SourceSet}
+		if c.heldForLeader(SourceSet, name) {
+			return &NotLeaderError{Name: name}
+		}
+		staged := c.values()
+		staged[name] = v
+		if c.parsed {
+			if err := c.admit(source, staged); err != nil {
+				return err
+			}
+		}
+		var err error
+		if applied, err = c.preApply(source, staged); err != nil {
+			return err
+		}
+		assign(c.flags[name], v)
+		c.sources[name] = source
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	c.invalidateTenants()
+	c.postApply(applied)
+	return nil
+}
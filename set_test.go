@@ -0,0 +1,78 @@
+package configurable
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSet(t *testing.T) {
+	os.Clearenv()
+
+	newConf := func(t *testing.T) *Configurable {
+		conf := newTestConfigurable(t)
+		conf.NewInt("port", 80, "port")
+		conf.NewDuration("timeout", time.Second, "timeout")
+		conf.NewList("tags", []string{"a"}, "tags")
+		return conf
+	}
+
+	t.Run("test set", func(t *testing.T) {
+		conf := newConf(t)
+		var changed []string
+		conf.OnChange(func(v View, names []string) { changed = names })
+		assert.NoError(t, conf.Set("port", 8080))
+		assert.NoError(t, conf.Set("timeout", "5s"))
+		assert.NoError(t, conf.Set("tags", []string{"b", "c"}))
+		assert.Equal(t, 8080, *conf.Int("port"))
+		assert.Equal(t, 5*time.Second, conf.View().Duration("timeout"))
+		assert.Equal(t, []string{"b", "c"}, *conf.List("tags"))
+		assert.Equal(t, []string{"tags"}, changed)
+		assert.Equal(t, "set", conf.sources["port"].String())
+	})
+
+	t.Run("test invalid values", func(t *testing.T) {
+		conf := newConf(t)
+		assert.EqualError(t, conf.Set("missing", 1), "unknown flag missing")
+		assert.Error(t, conf.Set("port", "eighty"))
+		assert.Equal(t, 80, *conf.Int("port"))
+	})
+
+	t.Run("test validators run after parse", func(t *testing.T) {
+		conf := newConf(t)
+		conf.ValidateWith(func(v View) error {
+			if v.Int("port") < 1024 && v.Int("port") != 80 {
+				return errors.New("privileged port")
+			}
+			return nil
+		})
+		conf.SetArgs([]string{})
+		assert.NoError(t, conf.Parse(""))
+		assert.EqualError(t, conf.Set("port", 22), "privileged port")
+		assert.Equal(t, 80, *conf.Int("port"))
+	})
+
+	t.Run("test read-only", func(t *testing.T) {
+		conf := newConf(t)
+		conf.ReadOnly(true)
+		err := conf.Set("port", 8080)
+		var readOnly *ReadOnlyError
+		assert.ErrorAs(t, err, &readOnly)
+		assert.ErrorIs(t, err, ErrReadOnly)
+		assert.EqualError(t, err, "configuration is read-only: cannot set port")
+		assert.Equal(t, 80, *conf.Int("port"))
+
+		conf.ReadOnly(false)
+		assert.NoError(t, conf.Set("port", 8080))
+	})
+
+	t.Run("test read-only from env", func(t *testing.T) {
+		conf := newConf(t)
+		conf.SetEnv(readOnlyVar, "true")
+		assert.True(t, conf.IsReadOnly())
+		assert.ErrorIs(t, conf.Set("port", 8080), ErrReadOnly)
+	})
+}
@@ -0,0 +1,192 @@
+package configurable
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+// ErrSignature is wrapped by the errors LoadFile returns when trusted keys
+// are set and a file's signature is missing or invalid.
+var ErrSignature = errors.New("config signature verification failed")
+
+const (
+	signatureBegin = "# -----BEGIN CONFIG SIGNATURE-----"
+	signatureEnd   = "# -----END CONFIG SIGNATURE-----"
+)
+
+// SetTrustedKeys makes LoadFile refuse any config file that is not signed by
+// one of keys. A file is signed by an embedded signature block (see
+// SignConfig), or by a detached minisign signature in <file>.minisig or raw
+// ed25519 signature in <file>.sig. Calling it with no keys turns
+// verification off.
+func (c *Configurable) SetTrustedKeys(keys ...ed25519.PublicKey) {
+	c.trustedKeys = keys
+}
+
+// SignConfig appends a signature block to a config file's contents. The block
+// is made of '#' comments, which LoadFile strips before decoding, so signed
+// YAML, INI and JSON files stay loadable.
+func SignConfig(data []byte, key ed25519.PrivateKey) []byte {
+	var b bytes.Buffer
+	b.Write(data)
+	if len(data) > 0 && data[len(data)-1] != '\n' {
+		b.WriteByte('\n')
+	}
+	signed := b.Len()
+	sig := ed25519.Sign(key, b.Bytes()[:signed])
+	fmt.Fprintf(&b, "%s\n# %s\n%s\n", signatureBegin, base64.StdEncoding.EncodeToString(sig), signatureEnd)
+	return b.Bytes()
+}
+
+// ParseMinisignPublicKey decodes a minisign public key, given either as the
+// base64 line or as the whole .pub file.
+func ParseMinisignPublicKey(text string) (ed25519.PublicKey, error) {
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(lines[len(lines)-1]))
+	if err != nil {
+		return nil, fmt.Errorf("minisign public key: %w", err)
+	}
+	if len(raw) != 2+8+ed25519.PublicKeySize || string(raw[:2]) != "Ed" {
+		return nil, errors.New("minisign public key: unsupported format")
+	}
+	return ed25519.PublicKey(raw[10:]), nil
+}
+
+// splitSignature separates an embedded signature block from the content it
+// signs. sig is nil when data has no block.
+func splitSignature(data []byte) (content, sig []byte, err error) {
+	i := bytes.Index(data, []byte(signatureBegin+"\n"))
+	if i < 0 || (i > 0 && data[i-1] != '\n') {
+		return data, nil, nil
+	}
+	block := strings.TrimSpace(string(data[i+len(signatureBegin):]))
+	encoded, ok := strings.CutSuffix(block, signatureEnd)
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: unterminated signature block", ErrSignature)
+	}
+	encoded = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(encoded), "#"))
+	sig, err = base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrSignature, err)
+	}
+	return data[:i], sig, nil
+}
+
+// verify checks the signature of filename, whose contents are data, and
+// returns the contents without any embedded signature block.
+func (c *Configurable) verify(filename string, data []byte) ([]byte, error) {
+	content, sig, err := splitSignature(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	if len(c.trustedKeys) == 0 {
+		return content, nil
+	}
+	if sig != nil {
+		if !c.trusted(content, sig) {
+			return nil, fmt.Errorf("%s: %w: signature does not match a trusted key", filename, ErrSignature)
+		}
+		return content, nil
+	}
+	if minisig, err := readFile(filename+".minisig", 0); err == nil {
+		if err := c.verifyMinisign(content, minisig); err != nil {
+			return nil, fmt.Errorf("%s: %w", filename, err)
+		}
+		return content, nil
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	detached, err := readFile(filename+".sig", 0)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%s: %w: file is not signed", filename, ErrSignature)
+	} else if err != nil {
+		return nil, err
+	}
+	if len(detached) != ed25519.SignatureSize {
+		if detached, err = base64.StdEncoding.DecodeString(strings.TrimSpace(string(detached))); err != nil {
+			return nil, fmt.Errorf("%s.sig: %w: %v", filename, ErrSignature, err)
+		}
+	}
+	if !c.trusted(content, detached) {
+		return nil, fmt.Errorf("%s: %w: signature does not match a trusted key", filename, ErrSignature)
+	}
+	return content, nil
+}
+
+// checkUnsigned refuses to edit filename, whose contents are data, if it is
+// signed or trusted keys are set. The signature is verified first, so a
+// tampered file is reported as such; a valid one is refused all the same,
+// since only the holder of the private key can sign the edited file.
+func (c *Configurable) checkUnsigned(filename string, data []byte) error {
+	if _, err := c.verify(filename, data); err != nil {
+		return err
+	}
+	_, sig, _ := splitSignature(data)
+	if sig == nil && len(c.trustedKeys) == 0 && !exists(filename+".minisig") && !exists(filename+".sig") {
+		return nil
+	}
+	return fmt.Errorf("%s: %w: a signed file cannot be edited without breaking its signature", filename, ErrSignature)
+}
+
+// exists reports whether filename can be read.
+func exists(filename string) bool {
+	_, err := readFile(filename, 0)
+	return err == nil
+}
+
+func (c *Configurable) trusted(message, sig []byte) bool {
+	for _, key := range c.trustedKeys {
+		if len(key) == ed25519.PublicKeySize && ed25519.Verify(key, message, sig) {
+			return true
+		}
+	}
+	return false
+}
+
+// verifyMinisign checks a minisign signature file: the signature of the
+// content (of its BLAKE2b-512 hash for the default prehashed "ED" algorithm)
+// and the global signature covering the trusted comment.
+func (c *Configurable) verifyMinisign(content, minisig []byte) error {
+	lines := strings.Split(strings.TrimSpace(string(minisig)), "\n")
+	if len(lines) != 4 {
+		return fmt.Errorf("%w: malformed minisign signature", ErrSignature)
+	}
+	blob, err := base64.StdEncoding.DecodeString(strings.TrimSpace(lines[1]))
+	if err != nil || len(blob) != 2+8+ed25519.SignatureSize {
+		return fmt.Errorf("%w: malformed minisign signature", ErrSignature)
+	}
+	comment, ok := strings.CutPrefix(strings.TrimRight(lines[2], "\r"), "trusted comment: ")
+	if !ok {
+		return fmt.Errorf("%w: malformed minisign trusted comment", ErrSignature)
+	}
+	global, err := base64.StdEncoding.DecodeString(strings.TrimSpace(lines[3]))
+	if err != nil {
+		return fmt.Errorf("%w: malformed minisign global signature", ErrSignature)
+	}
+	message := content
+	switch string(blob[:2]) {
+	case "Ed":
+	case "ED":
+		sum := blake2b.Sum512(content)
+		message = sum[:]
+	default:
+		return fmt.Errorf("%w: unsupported minisign algorithm %q", ErrSignature, blob[:2])
+	}
+	sig := blob[10:]
+	for _, key := range c.trustedKeys {
+		if len(key) == ed25519.PublicKeySize && ed25519.Verify(key, message, sig) {
+			if !ed25519.Verify(key, append(append([]byte{}, sig...), comment...), global) {
+				return fmt.Errorf("%w: trusted comment has been altered", ErrSignature)
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: signature does not match a trusted key", ErrSignature)
+}
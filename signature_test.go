@@ -0,0 +1,138 @@
+package configurable
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/blake2b"
+)
+
+// minisign produces a minisign public key and signature file the way the
+// minisign tool does.
+func minisign(priv ed25519.PrivateKey, data []byte, prehash bool) (pub, sig string) {
+	keyID := []byte("12345678")
+	pubRaw := append(append([]byte("Ed"), keyID...), priv.Public().(ed25519.PublicKey)...)
+	alg, message := "Ed", data
+	if prehash {
+		sum := blake2b.Sum512(data)
+		alg, message = "ED", sum[:]
+	}
+	signature := ed25519.Sign(priv, message)
+	comment := "timestamp:1700000000"
+	global := ed25519.Sign(priv, append(append([]byte{}, signature...), comment...))
+	pub = "untrusted comment: minisign public key\n" + base64.StdEncoding.EncodeToString(pubRaw) + "\n"
+	sig = fmt.Sprintf("untrusted comment: signature\n%s\ntrusted comment: %s\n%s\n",
+		base64.StdEncoding.EncodeToString(append(append([]byte(alg), keyID...), signature...)),
+		comment, base64.StdEncoding.EncodeToString(global))
+	return pub, sig
+}
+
+func TestSignedConfigs(t *testing.T) {
+	os.Clearenv()
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	_, other, _ := ed25519.GenerateKey(nil)
+	data := []byte("port: 8080\n")
+
+	newConf := func(t *testing.T) *Configurable {
+		conf := newTestConfigurable(t)
+		conf.NewInt("port", 80, "port")
+		conf.SetTrustedKeys(pub)
+		return conf
+	}
+	write := func(t *testing.T, files map[string][]byte) string {
+		dir := t.TempDir()
+		for name, content := range files {
+			assert.NoError(t, os.WriteFile(filepath.Join(dir, name), content, 0644))
+		}
+		return filepath.Join(dir, "config.yaml")
+	}
+
+	t.Run("test embedded signature", func(t *testing.T) {
+		conf := newConf(t)
+		assert.NoError(t, conf.LoadFile(write(t, map[string][]byte{"config.yaml": SignConfig(data, priv)})))
+		assert.Equal(t, 8080, *conf.Int("port"))
+
+		tampered := SignConfig(data, priv)
+		tampered[7] = '9'
+		err := newConf(t).LoadFile(write(t, map[string][]byte{"config.yaml": tampered}))
+		assert.ErrorIs(t, err, ErrSignature)
+		assert.ErrorContains(t, err, "signature does not match a trusted key")
+
+		err = newConf(t).LoadFile(write(t, map[string][]byte{"config.yaml": SignConfig(data, other)}))
+		assert.ErrorIs(t, err, ErrSignature)
+	})
+
+	t.Run("test embedded signature in json without keys", func(t *testing.T) {
+		conf := newTestConfigurable(t)
+		conf.NewInt("port", 80, "port")
+		dir := t.TempDir()
+		path := filepath.Join(dir, "config.json")
+		assert.NoError(t, os.WriteFile(path, SignConfig([]byte(`{"port": 8080}`), priv), 0644))
+		assert.NoError(t, conf.LoadFile(path))
+		assert.Equal(t, 8080, *conf.Int("port"))
+	})
+
+	t.Run("test unsigned", func(t *testing.T) {
+		err := newConf(t).LoadFile(write(t, map[string][]byte{"config.yaml": data}))
+		assert.ErrorIs(t, err, ErrSignature)
+		assert.ErrorContains(t, err, "file is not signed")
+	})
+
+	t.Run("test detached ed25519", func(t *testing.T) {
+		sig := ed25519.Sign(priv, data)
+		conf := newConf(t)
+		assert.NoError(t, conf.LoadFile(write(t, map[string][]byte{"config.yaml": data, "config.yaml.sig": sig})))
+		assert.Equal(t, 8080, *conf.Int("port"))
+
+		encoded := []byte(base64.StdEncoding.EncodeToString(sig) + "\n")
+		assert.NoError(t, newConf(t).LoadFile(write(t, map[string][]byte{"config.yaml": data, "config.yaml.sig": encoded})))
+	})
+
+	t.Run("test minisign", func(t *testing.T) {
+		for _, prehash := range []bool{false, true} {
+			pubText, sig := minisign(priv, data, prehash)
+			key, err := ParseMinisignPublicKey(pubText)
+			assert.NoError(t, err)
+			conf := newTestConfigurable(t)
+			conf.NewInt("port", 80, "port")
+			conf.SetTrustedKeys(key)
+			assert.NoError(t, conf.LoadFile(write(t, map[string][]byte{"config.yaml": data, "config.yaml.minisig": []byte(sig)})))
+			assert.Equal(t, 8080, *conf.Int("port"))
+
+			err = conf.LoadFile(write(t, map[string][]byte{"config.yaml": []byte("port: 1\n"), "config.yaml.minisig": []byte(sig)}))
+			assert.ErrorIs(t, err, ErrSignature)
+		}
+	})
+
+	t.Run("test env and tenant files", func(t *testing.T) {
+		dir := t.TempDir()
+		env := filepath.Join(dir, "app.env")
+		assert.NoError(t, os.WriteFile(env, []byte("port=8080\n"), 0644))
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, "acme.yaml"), data, 0644))
+
+		conf := newConf(t)
+		conf.EnableEnvFile()
+		conf.SetArgs([]string{"--env-file", env})
+		assert.ErrorIs(t, conf.Parse(""), ErrSignature)
+		conf.SetTenantLoader(conf.TenantFiles(filepath.Join(dir, "%s.yaml")))
+		_, err := conf.LoadTenant("acme")
+		assert.ErrorIs(t, err, ErrSignature)
+
+		assert.NoError(t, os.WriteFile(env+".sig", ed25519.Sign(priv, []byte("port=8080\n")), 0644))
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, "acme.yaml.sig"), ed25519.Sign(priv, data), 0644))
+		conf = newConf(t)
+		conf.EnableEnvFile()
+		conf.SetArgs([]string{"--env-file", env})
+		assert.NoError(t, conf.Parse(""))
+		assert.Equal(t, 8080, *conf.Int("port"))
+		conf.SetTenantLoader(conf.TenantFiles(filepath.Join(dir, "%s.yaml")))
+		acme, err := conf.LoadTenant("acme")
+		assert.NoError(t, err)
+		assert.Equal(t, 8080, acme.Int("port"))
+	})
+}
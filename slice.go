@@ -0,0 +1,138 @@
+package configurable
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SliceFlag is a list flag whose items are typed. On the command line it
+// takes comma-separated items and accumulates like a ListFlag; documents may
+// give it a sequence of numbers or bools.
+type SliceFlag[T int | float64 | bool] struct {
+	values *[]T
+	kind   string
+	from   func(raw interface{}, lenient bool) (T, error)
+}
+
+// typedList is implemented by every SliceFlag, whatever its item type, so
+// the loaders can handle them without a case per type.
+type typedList interface {
+	itemKind() string
+	value() interface{}
+	assign(value interface{})
+	empty() typedList
+	add(raw interface{}, lenient bool) error
+}
+
+func (s *SliceFlag[T]) String() string {
+	if s.values == nil {
+		return ""
+	}
+	items := make([]string, len(*s.values))
+	for i, v := range *s.values {
+		items[i] = fmt.Sprint(v)
+	}
+	return strings.Join(items, ",")
+}
+
+func (s *SliceFlag[T]) Set(value string) error {
+	return s.add(value, false)
+}
+
+func (s *SliceFlag[T]) itemKind() string {
+	return s.kind
+}
+
+func (s *SliceFlag[T]) value() interface{} {
+	return append([]T{}, *s.values...)
+}
+
+func (s *SliceFlag[T]) assign(value interface{}) {
+	*s.values = append([]T{}, value.([]T)...)
+}
+
+func (s *SliceFlag[T]) empty() typedList {
+	return &SliceFlag[T]{values: &[]T{}, kind: s.kind, from: s.from}
+}
+
+// add appends the items of raw: a comma-separated string, or a sequence from
+// a document. Errors name the position of the item that failed.
+func (s *SliceFlag[T]) add(raw interface{}, lenient bool) error {
+	var items []interface{}
+	switch v := raw.(type) {
+	case []T:
+		*s.values = append(*s.values, v...)
+		return nil
+	case []interface{}:
+		items = v
+	case []string:
+		for _, item := range v {
+			items = append(items, item)
+		}
+	case string:
+		if v == "" {
+			return nil
+		}
+		for _, item := range strings.Split(v, ",") {
+			items = append(items, strings.TrimSpace(item))
+		}
+	default:
+		return fmt.Errorf("cannot convert %v to []%s", raw, s.kind)
+	}
+	converted := make([]T, len(items))
+	for i, item := range items {
+		value, err := s.from(item, lenient)
+		if err != nil {
+			return fmt.Errorf("item %d: %w", i, err)
+		}
+		converted[i] = value
+	}
+	*s.values = append(*s.values, converted...)
+	return nil
+}
+
+func (c *Configurable) NewIntSlice(name string, value []int, usage string, opts ...FlagOption) *[]int {
+	return newSlice(c, name, value, usage, opts, "int", func(raw interface{}, _ bool) (int, error) {
+		return toInt(raw)
+	})
+}
+
+func (c *Configurable) IntSlice(name string) *[]int {
+	return slice[int](c, name)
+}
+
+func (c *Configurable) NewFloat64Slice(name string, value []float64, usage string, opts ...FlagOption) *[]float64 {
+	return newSlice(c, name, value, usage, opts, "float64", func(raw interface{}, _ bool) (float64, error) {
+		return toFloat64(raw)
+	})
+}
+
+func (c *Configurable) Float64Slice(name string) *[]float64 {
+	return slice[float64](c, name)
+}
+
+func (c *Configurable) NewBoolSlice(name string, value []bool, usage string, opts ...FlagOption) *[]bool {
+	return newSlice(c, name, value, usage, opts, "bool", toBool)
+}
+
+func (c *Configurable) BoolSlice(name string) *[]bool {
+	return slice[bool](c, name)
+}
+
+func newSlice[T int | float64 | bool](c *Configurable, name string, value []T, usage string, opts []FlagOption, kind string, from func(interface{}, bool) (T, error)) *[]T {
+	s := &SliceFlag[T]{values: &value, kind: kind, from: from}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.fs.Var(s, name, usage)
+	c.flags[name] = s
+	c.annotate(name, opts)
+	return s.values
+}
+
+func slice[T int | float64 | bool](c *Configurable, name string) *[]T {
+	c.checkAndSetFromEnv(name)
+	if ptr, ok := c.flags[name].(*SliceFlag[T]); ok {
+		return ptr.values
+	}
+	return nil
+}
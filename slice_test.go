@@ -0,0 +1,63 @@
+package configurable
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSliceFlags(t *testing.T) {
+	os.Clearenv()
+
+	t.Run("test YAML and JSON sequences", func(t *testing.T) {
+		conf := newTestConfigurable(t)
+		ports := conf.NewIntSlice("ports", nil, "ports")
+		weights := conf.NewFloat64Slice("weights", nil, "weights")
+		flags := conf.NewBoolSlice("flags", nil, "flags")
+		assert.NoError(t, conf.LoadData("yaml", []byte("ports: [80, 443]\nweights: [0.5, 1]\nflags: [true, false]\n")))
+		assert.Equal(t, []int{80, 443}, *ports)
+		assert.Equal(t, []float64{0.5, 1}, *weights)
+		assert.Equal(t, []bool{true, false}, *flags)
+
+		conf = newTestConfigurable(t)
+		ports = conf.NewIntSlice("ports", nil, "ports")
+		assert.NoError(t, conf.LoadData("json", []byte(`{"ports": [8080, 9090]}`)))
+		assert.Equal(t, []int{8080, 9090}, *ports)
+		assert.Equal(t, []int{8080, 9090}, conf.View().(*snapshot).values["ports"])
+	})
+
+	t.Run("test item errors", func(t *testing.T) {
+		conf := newTestConfigurable(t)
+		ports := conf.NewIntSlice("ports", []int{80}, "ports")
+		err := conf.LoadData("yaml", []byte("ports: [80, 443, http]\n"))
+		assert.ErrorContains(t, err, "error setting key ports: item 2: ")
+		assert.Equal(t, []int{80}, *ports)
+	})
+
+	t.Run("test command line and env", func(t *testing.T) {
+		conf := newTestConfigurable(t)
+		ports := conf.NewIntSlice("ports", nil, "ports")
+		assert.NoError(t, conf.ParseArgs([]string{"-ports", "80,443", "-ports", "8080"}))
+		assert.Equal(t, []int{80, 443, 8080}, *ports)
+		assert.Error(t, conf.ParseArgs([]string{"-ports", "eighty"}))
+
+		conf = newTestConfigurable(t)
+		conf.NewBoolSlice("flags", nil, "flags")
+		conf.SetEnv("flags", "true,false")
+		assert.Equal(t, []bool{true, false}, *conf.BoolSlice("flags"))
+	})
+
+	t.Run("test dump and schema", func(t *testing.T) {
+		conf := newTestConfigurable(t)
+		conf.NewIntSlice("ports", []int{80, 443}, "ports")
+		data, err := conf.Dump("yaml")
+		assert.NoError(t, err)
+		assert.Equal(t, "ports:\n    - 80\n    - 443\n", string(data))
+		schema, err := conf.Schema()
+		assert.NoError(t, err)
+		assert.Contains(t, string(schema), `"items": {`)
+		assert.Contains(t, string(schema), `"type": "integer"`)
+		assert.Equal(t, "[]int", conf.Flags()[0].Type)
+	})
+}
@@ -0,0 +1,192 @@
+package configurable
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// SourceKind identifies where a configuration value came from.
+type SourceKind int
+
+const (
+	SourceDefault SourceKind = iota
+	SourceFlag
+	SourceEnv
+	SourceFile
+	SourceRemote
+	SourceSet
+	// SourceReplicated is a value applied by ApplyReplicated.
+	SourceReplicated
+)
+
+func (k SourceKind) String() string {
+	switch k {
+	case SourceDefault:
+		return "default"
+	case SourceFlag:
+		return "flag"
+	case SourceEnv:
+		return "env"
+	case SourceFile:
+		return "file"
+	case SourceRemote:
+		return "remote"
+	case SourceSet:
+		return "set"
+	case SourceReplicated:
+		return "replicated"
+	default:
+		return fmt.Sprintf("SourceKind(%d)", int(k))
+	}
+}
+
+// ValueSource is where the value of a flag came from.
+type ValueSource struct {
+	Kind SourceKind
+	// Name is the file path for SourceFile, the variable for SourceEnv and
+	// the provider for SourceRemote; empty for the others and for in-memory
+	// documents.
+	Name string
+}
+
+func (s ValueSource) String() string {
+	if s.Name == "" {
+		return s.Kind.String()
+	}
+	return s.Kind.String() + " " + s.Name
+}
+
+// Source returns where the value of the flag name came from, so "where did
+// this setting come from?" has an answer in production: the default, the
+// command line, an environment variable, a file, a provider or Set. Like
+// the getters, it returns the zero ValueSource if name is not a flag.
+func (c *Configurable) Source(name string) ValueSource {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if s, ok := c.sources[name]; ok {
+		return s
+	}
+	return ValueSource{Kind: SourceDefault}
+}
+
+// UnknownKeysError is returned, in strict mode, by loads whose source has keys
+// that match no registered flag.
+type UnknownKeysError struct {
+	Source string
+	Keys   []string
+}
+
+func (e *UnknownKeysError) Error() string {
+	return e.Source + ": unknown keys: " + strings.Join(e.Keys, ", ")
+}
+
+// Report summarizes what the sources consulted by Parse contributed, so
+// startup logs can include a one-line configuration summary.
+type Report struct {
+	Sources []SourceReport
+}
+
+// SourceReport is what one source contributed to a Report.
+type SourceReport struct {
+	Kind SourceKind
+	// Name is the file path for SourceFile and the provider name for
+	// SourceRemote; empty for in-memory documents, flags and the environment.
+	Name string
+	// Keys lists the flags this source set.
+	Keys []string
+	// Unknown lists keys the source offered that match no registered flag.
+	Unknown []string
+	// Vars lists the environment variables honored, for SourceEnv.
+	Vars []string
+}
+
+// ParseReport is Parse returning a Report of what was applied.
+func (c *Configurable) ParseReport(filename string) (Report, error) {
+	c.report = &Report{}
+	err := c.Parse(filename)
+	return *c.report, err
+}
+
+// Files lists the configuration files that were loaded, in order.
+func (r Report) Files() []string {
+	var files []string
+	for _, s := range r.Sources {
+		if s.Kind == SourceFile && s.Name != "" {
+			files = append(files, s.Name)
+		}
+	}
+	return files
+}
+
+// String renders the report on one line, for example:
+//
+//	config: 3 from app.yaml, 1 from flags, 1 from env (PORT); 1 unknown key skipped (legacy)
+func (r Report) String() string {
+	var parts, unknown []string
+	for _, s := range r.Sources {
+		switch s.Kind {
+		case SourceFile, SourceRemote:
+			name := s.Name
+			if name == "" {
+				name = "data"
+			}
+			parts = append(parts, fmt.Sprintf("%d from %s", len(s.Keys), name))
+			unknown = append(unknown, s.Unknown...)
+		case SourceFlag:
+			parts = append(parts, fmt.Sprintf("%d from flags", len(s.Keys)))
+		case SourceEnv:
+			parts = append(parts, fmt.Sprintf("%d from env (%s)", len(s.Keys), strings.Join(s.Vars, ", ")))
+		}
+	}
+	if len(parts) == 0 {
+		parts = append(parts, "defaults only")
+	}
+	line := "config: " + strings.Join(parts, ", ")
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		noun := "keys"
+		if len(unknown) == 1 {
+			noun = "key"
+		}
+		line += fmt.Sprintf("; %d unknown %s skipped (%s)", len(unknown), noun, strings.Join(unknown, ", "))
+	}
+	return line
+}
+
+// source returns the entry for kind and name, appending it on first use.
+func (r *Report) source(kind SourceKind, name string) *SourceReport {
+	for i := range r.Sources {
+		if r.Sources[i].Kind == kind && r.Sources[i].Name == name {
+			return &r.Sources[i]
+		}
+	}
+	r.Sources = append(r.Sources, SourceReport{Kind: kind, Name: name})
+	return &r.Sources[len(r.Sources)-1]
+}
+
+// applyEnv applies the environment to every registered flag.
+func (c *Configurable) applyEnv() {
+	names := make([]string, 0, len(c.flags))
+	for name := range c.flags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	_ = c.update(func() error {
+		for _, name := range names {
+			c.setFromEnv(name)
+		}
+		return nil
+	})
+}
+
+// appendUnique appends the items of add not already in list.
+func appendUnique(list []string, add ...string) []string {
+	for _, s := range add {
+		if !slices.Contains(list, s) {
+			list = append(list, s)
+		}
+	}
+	return list
+}
@@ -0,0 +1,141 @@
+package configurable
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseReport(t *testing.T) {
+	os.Clearenv()
+
+	file := filepath.Join(t.TempDir(), "app.yaml")
+	assert.NoError(t, os.WriteFile(file, []byte("name: api\nworkers: 4\nlegacy: true\n"), 0644))
+
+	conf := newTestConfigurable(t)
+	conf.NewString("name", "", "name")
+	conf.NewInt("workers", 1, "workers")
+	conf.NewInt("port", 80, "port")
+	conf.NewBool("debug", false, "debug")
+	conf.SetArgs([]string{"-debug"})
+	conf.SetEnv("port", "9090")
+
+	report, err := conf.ParseReport(file)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{file}, report.Files())
+	assert.Len(t, report.Sources, 3)
+	assert.ElementsMatch(t, []string{"name", "workers"}, report.Sources[0].Keys)
+	assert.Equal(t, []string{"legacy"}, report.Sources[0].Unknown)
+	assert.Equal(t, SourceReport{Kind: SourceFlag, Keys: []string{"debug"}}, report.Sources[1])
+	assert.Equal(t, []string{"port"}, report.Sources[2].Vars)
+	assert.Equal(t, "config: 2 from "+file+", 1 from flags, 1 from env (port); 1 unknown key skipped (legacy)", report.String())
+	assert.Equal(t, 9090, *conf.Int("port"))
+}
+
+func TestReportString(t *testing.T) {
+	assert.Equal(t, "config: defaults only", Report{}.String())
+	assert.Equal(t, "config: 2 from data", Report{Sources: []SourceReport{{Kind: SourceFile, Keys: []string{"a", "b"}}}}.String())
+	assert.Equal(t, "env", SourceEnv.String())
+}
+
+func TestPrecedence(t *testing.T) {
+	os.Clearenv()
+
+	newConf := func(t *testing.T) *Configurable {
+		conf := newTestConfigurable(t)
+		conf.NewString("host", "localhost", "host")
+		conf.NewInt("port", 80, "port")
+		conf.NewString("mode", "dev", "mode")
+		conf.NewList("tags", []string{"a"}, "tags")
+		conf.SetEnv("host", "env.example")
+		conf.SetEnv("port", "8000")
+		conf.SetArgs([]string{"-host", "flag.example", "-mode", "prod"})
+		return conf
+	}
+	path := filepath.Join(t.TempDir(), "config.json")
+	assert.NoError(t, os.WriteFile(path, []byte(`{"host": "file.example", "port": 9000, "mode": "staging", "tags": ["b"]}`), 0o644))
+
+	t.Run("test default", func(t *testing.T) {
+		conf := newConf(t)
+		assert.NoError(t, conf.Parse(path))
+		assert.Equal(t, "env.example", *conf.String("host"))
+		assert.Equal(t, 8000, *conf.Int("port"))
+		assert.Equal(t, "prod", *conf.String("mode"))
+		assert.Equal(t, []string{"a", "b"}, *conf.List("tags"))
+
+		// A reload is ranked too: the file cannot undo the command line.
+		assert.NoError(t, os.WriteFile(path, []byte(`{"mode": "test", "tags": ["c"]}`), 0o644))
+		assert.NoError(t, conf.LoadFile(path))
+		assert.Equal(t, "prod", *conf.String("mode"))
+		assert.Equal(t, []string{"a", "b", "c"}, *conf.List("tags"))
+		assert.Error(t, conf.LoadData("json", []byte(`{"port": "many"}`)), "outranked values are still checked")
+
+		// Set is not ranked, and a later load replaces it.
+		assert.NoError(t, conf.Set("mode", "maintenance"))
+		assert.Equal(t, "maintenance", *conf.String("mode"))
+		assert.NoError(t, conf.LoadData("json", []byte(`{"mode": "test"}`)))
+		assert.Equal(t, "test", *conf.String("mode"))
+	})
+
+	t.Run("test flags first", func(t *testing.T) {
+		conf := newConf(t)
+		conf.SetPrecedence(SourceFlag, SourceEnv, SourceFile, SourceDefault)
+		assert.NoError(t, conf.Parse(path))
+		assert.Equal(t, "flag.example", *conf.String("host"))
+		assert.Equal(t, 8000, *conf.Int("port"))
+		assert.Equal(t, "prod", *conf.String("mode"))
+		assert.Equal(t, "flag", conf.sources["host"].String())
+
+		// Neither lookups nor later arguments of a lower rank replace it.
+		conf.SetEnv("mode", "env")
+		assert.Equal(t, "prod", *conf.String("mode"))
+		assert.NoError(t, conf.ParseArgs([]string{"-port", "7000"}))
+		assert.Equal(t, 7000, *conf.Int("port"))
+	})
+
+	t.Run("test file first", func(t *testing.T) {
+		conf := newConf(t)
+		conf.SetPrecedence(SourceFile)
+		assert.NoError(t, os.WriteFile(path, []byte(`{"host": "file.example", "port": 9000}`), 0o644))
+		assert.NoError(t, conf.Parse(path))
+		assert.Equal(t, "file.example", *conf.String("host"))
+		assert.Equal(t, 9000, *conf.Int("port"))
+		assert.Equal(t, "prod", *conf.String("mode"))
+		assert.NoError(t, conf.ParseArgs([]string{"-host", "again.example"}))
+		assert.Equal(t, "file.example", *conf.String("host"))
+	})
+
+	t.Run("test invalid", func(t *testing.T) {
+		conf := newConf(t)
+		assert.PanicsWithValue(t, "configurable: SetPrecedence with set, which is not a source that can be ranked", func() {
+			conf.SetPrecedence(SourceSet)
+		})
+		assert.Panics(t, func() { conf.SetPrecedence(SourceFlag, SourceFlag) })
+	})
+}
+
+func TestSource(t *testing.T) {
+	os.Clearenv()
+	conf := newTestConfigurable(t)
+	conf.NewString("host", "localhost", "host")
+	conf.NewInt("port", 80, "port")
+	conf.NewString("mode", "dev", "mode")
+	conf.NewInt("workers", 4, "workers")
+	conf.NewBool("debug", false, "debug")
+	conf.SetEnv("port", "8000")
+	conf.SetArgs([]string{"-mode", "prod"})
+	path := filepath.Join(t.TempDir(), "config.json")
+	assert.NoError(t, os.WriteFile(path, []byte(`{"host": "file.example"}`), 0o644))
+	assert.NoError(t, conf.Parse(path))
+	assert.NoError(t, conf.Set("workers", 8))
+
+	assert.Equal(t, ValueSource{Kind: SourceFile, Name: path}, conf.Source("host"))
+	assert.Equal(t, ValueSource{Kind: SourceEnv, Name: "port"}, conf.Source("port"))
+	assert.Equal(t, ValueSource{Kind: SourceFlag}, conf.Source("mode"))
+	assert.Equal(t, ValueSource{Kind: SourceSet}, conf.Source("workers"))
+	assert.Equal(t, ValueSource{Kind: SourceDefault}, conf.Source("debug"))
+	assert.Equal(t, "env port", conf.Source("port").String())
+	assert.Zero(t, conf.Source("missing"))
+}
@@ -0,0 +1,137 @@
+package configurable
+
+import (
+	"context"
+	"hash/fnv"
+	"os"
+	"sync"
+	"time"
+)
+
+// staggered holds the changes to staggered flags that are waiting for their
+// delay, one per flag.
+type staggered struct {
+	mu     sync.Mutex
+	timers map[string]Timer
+}
+
+// WithStagger delays changes to the flag that a watched provider delivers
+// by up to window, so that a fleet does not flip a behavior at the same
+// instant. The delay is derived from a hash of the instance ID and the flag
+// name: it differs between instances and flags but is the same every time,
+// so an instance is always early or always late for a given flag. A newer
+// change replaces one still waiting. Loads at startup, files and Set are not
+// delayed.
+func WithStagger(window time.Duration) FlagOption {
+	return func(m *flagMeta) {
+		m.stagger = window
+	}
+}
+
+// SetInstanceID sets the ID that staggered delays and the canary bucket are
+// derived from. The default is the host name.
+func (c *Configurable) SetInstanceID(id string) {
+	c.instanceID = id
+}
+
+// WithInstanceID is SetInstanceID.
+func WithInstanceID(id string) Option {
+	return func(c *Configurable) {
+		c.SetInstanceID(id)
+	}
+}
+
+// instance returns the instance ID, or the host name if none was set.
+func (c *Configurable) instance() string {
+	if c.instanceID != "" {
+		return c.instanceID
+	}
+	host, _ := os.Hostname()
+	return host
+}
+
+// staggerDelay returns how long this instance waits before applying a
+// change to name.
+func (c *Configurable) staggerDelay(name string) time.Duration {
+	m, ok := c.meta[name]
+	if !ok || m.stagger <= 0 {
+		return 0
+	}
+	h := fnv.New64a()
+	h.Write([]byte(c.instance()))
+	h.Write([]byte{0})
+	h.Write([]byte(name))
+	return time.Duration(h.Sum64() % uint64(m.stagger))
+}
+
+// stagger takes the staggered flags out of a document delivered by the watch
+// of s, scheduling each to be applied after its delay, and returns the rest
+// to apply now.
+func (c *Configurable) stagger(ctx context.Context, s *remoteSource, data map[string]interface{}) map[string]interface{} {
+	known := make(map[string]interface{})
+	var unknown []string
+	c.flatten("", data, known, &unknown)
+	now := make(map[string]interface{}, len(data))
+	delayed := false
+	for name, value := range known {
+		delay := c.staggerDelay(name)
+		if delay == 0 {
+			now[name] = value
+			continue
+		}
+		delayed = true
+		c.delay(ctx, s, name, value, delay)
+	}
+	if !delayed {
+		return data
+	}
+	// Unknown keys stay in the document, to be reported as usual.
+	for _, key := range unknown {
+		now[key] = nil
+	}
+	return now
+}
+
+// stop drops every change still waiting.
+func (s *staggered) stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, t := range s.timers {
+		t.Stop()
+	}
+	s.timers = nil
+}
+
+// delay applies value to name after delay, replacing a change to name that
+// is still waiting.
+func (c *Configurable) delay(ctx context.Context, s *remoteSource, name string, value interface{}, delay time.Duration) {
+	c.staggered.mu.Lock()
+	defer c.staggered.mu.Unlock()
+	if c.staggered.timers == nil {
+		c.staggered.timers = make(map[string]Timer)
+	}
+	if t, ok := c.staggered.timers[name]; ok {
+		t.Stop()
+	}
+	var t Timer
+	t = c.clock().AfterFunc(delay, func() {
+		c.staggered.mu.Lock()
+		current := c.staggered.timers[name] == t
+		if current {
+			delete(c.staggered.timers, name)
+		}
+		c.staggered.mu.Unlock()
+		if !current || ctx.Err() != nil {
+			return
+		}
+		provider := s.provider.Name()
+		err := c.loading(remoteName(provider), func() error {
+			return c.setValuesFromMap(SourceRemote, provider, map[string]interface{}{name: value})
+		})
+		if err != nil {
+			c.logger().Warn("configurable: provider update rejected", "provider", provider, "error", err)
+			c.problem(RejectedUpdate, provider, "", err.Error())
+		}
+	})
+	c.staggered.timers[name] = t
+}
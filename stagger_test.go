@@ -0,0 +1,67 @@
+package configurable
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStagger(t *testing.T) {
+	os.Clearenv()
+
+	t.Run("test delay", func(t *testing.T) {
+		conf := newTestConfigurable(t)
+		conf.NewBool("new-checkout", false, "new checkout", WithStagger(time.Minute))
+		conf.NewInt("port", 80, "port")
+		assert.Zero(t, conf.staggerDelay("port"))
+
+		delays := make(map[time.Duration]bool)
+		for i := 0; i < 20; i++ {
+			conf.SetInstanceID(fmt.Sprintf("web-%d", i))
+			d := conf.staggerDelay("new-checkout")
+			assert.Equal(t, d, conf.staggerDelay("new-checkout"), "the delay is deterministic")
+			assert.True(t, d >= 0 && d < time.Minute)
+			delays[d] = true
+		}
+		assert.Greater(t, len(delays), 15, "instances are spread across the window")
+	})
+
+	t.Run("test watched changes", func(t *testing.T) {
+		conf := newTestConfigurable(t)
+		conf.NewString("mode", "a", "mode", WithStagger(200*time.Millisecond))
+		conf.NewInt("port", 80, "port")
+		// An instance whose delay is long enough to observe.
+		for i := 0; conf.staggerDelay("mode") < 20*time.Millisecond; i++ {
+			conf.SetInstanceID(fmt.Sprintf("web-%d", i))
+		}
+		conf.SetArgs([]string{})
+		assert.NoError(t, conf.Parse(""))
+		p := &pushProvider{fakeProvider: fakeProvider{name: "push"}, docs: make(chan map[string]interface{}), results: make(chan error)}
+		conf.AddProvider(p)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go conf.WatchProviders(ctx)
+
+		p.docs <- map[string]interface{}{"mode": "b", "port": 8080}
+		assert.NoError(t, <-p.results)
+		assert.Equal(t, 8080, conf.View().Int("port"))
+		assert.Equal(t, "a", conf.View().String("mode"))
+
+		p.docs <- map[string]interface{}{"mode": "c"}
+		assert.NoError(t, <-p.results)
+		assert.Eventually(t, func() bool { return conf.View().String("mode") == "c" }, time.Second, 5*time.Millisecond)
+		time.Sleep(250 * time.Millisecond)
+		assert.Equal(t, "c", conf.View().String("mode"), "the newer change replaces the waiting one")
+		assert.Equal(t, "remote push", conf.sources["mode"].String())
+
+		p.docs <- map[string]interface{}{"mode": "d"}
+		assert.NoError(t, <-p.results)
+		cancel()
+		time.Sleep(250 * time.Millisecond)
+		assert.Equal(t, "c", conf.View().String("mode"), "a stopped watch drops waiting changes")
+	})
+}
@@ -0,0 +1,109 @@
+package configurable
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"path/filepath"
+	"strings"
+)
+
+// TenantLoader fetches the overrides for one tenant as a document of the same
+// shape LoadFile accepts: nested mappings are flattened into dotted names. A
+// nil map means the tenant has no overrides.
+type TenantLoader func(id string) (map[string]interface{}, error)
+
+// TenantFiles returns a TenantLoader reading one file per tenant. pattern
+// contains a single %s replaced by the tenant ID, for example
+// "/etc/app/tenants/%s.yaml". A missing file means no overrides. The files
+// are held to the same size limit and trusted keys as LoadFile.
+func (c *Configurable) TenantFiles(pattern string) TenantLoader {
+	return func(id string) (map[string]interface{}, error) {
+		if id == "" || id == "." || id == ".." || strings.ContainsAny(id, `/\`) {
+			return nil, fmt.Errorf("invalid tenant id %q", id)
+		}
+		filename := fmt.Sprintf(pattern, id)
+		data, err := readFile(filename, c.parseOptions.MaxFileSize)
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if data, err = c.verify(filename, data); err != nil {
+			return nil, err
+		}
+		values, _, err := decode(filepath.Ext(filename), data, nil)
+		return values, err
+	}
+}
+
+// SetTenantLoader sets where Tenant finds per-tenant overrides and drops any
+// cached tenant views.
+func (c *Configurable) SetTenantLoader(loader TenantLoader) {
+	c.tenantMu.Lock()
+	defer c.tenantMu.Unlock()
+	c.tenantLoader = loader
+	c.tenants = nil
+}
+
+// Tenant returns the global configuration with the overrides for tenant id
+// laid on top. Views are cached until InvalidateTenant is called or the global
+// configuration is reloaded. If the overrides cannot be loaded the global
+// configuration is returned; use LoadTenant to see the error.
+func (c *Configurable) Tenant(id string) View {
+	v, err := c.LoadTenant(id)
+	if err != nil {
+		return c.View()
+	}
+	return v
+}
+
+// LoadTenant is Tenant reporting failures to load or apply the overrides.
+func (c *Configurable) LoadTenant(id string) (View, error) {
+	c.tenantMu.Lock()
+	defer c.tenantMu.Unlock()
+	if v, ok := c.tenants[id]; ok {
+		return v, nil
+	}
+	base := &snapshot{values: maps.Clone(c.current().values), fs: c.fs}
+	if c.tenantLoader == nil {
+		return base, nil
+	}
+	data, err := c.tenantLoader(id)
+	if err != nil {
+		return nil, fmt.Errorf("tenant %s: %w", id, err)
+	}
+	known := make(map[string]interface{})
+	var unknown []string
+	c.flatten("", data, known, &unknown)
+	for name, raw := range known {
+		value, err := c.convert(name, raw)
+		if err != nil {
+			return nil, fmt.Errorf("tenant %s: error setting key %s: %w", id, name, err)
+		}
+		base.values[name] = value
+	}
+	if c.tenants == nil {
+		c.tenants = make(map[string]*snapshot)
+	}
+	c.tenants[id] = base
+	return base, nil
+}
+
+// InvalidateTenant drops the cached view for tenant id so the next call to
+// Tenant reloads its overrides.
+func (c *Configurable) InvalidateTenant(id string) {
+	c.tenantMu.Lock()
+	defer c.tenantMu.Unlock()
+	delete(c.tenants, id)
+}
+
+// invalidateTenants drops every cached tenant view; each was built on top of
+// global values that have just changed.
+func (c *Configurable) invalidateTenants() {
+	c.tenantMu.Lock()
+	defer c.tenantMu.Unlock()
+	c.tenants = nil
+}
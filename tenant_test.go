@@ -0,0 +1,83 @@
+package configurable
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTenant(t *testing.T) {
+	os.Clearenv()
+
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "acme.yaml"), []byte("limits:\n  uploads: 50\nfeatures: [beta]\n"), 0644))
+
+	newConf := func(t *testing.T) *Configurable {
+		conf := newTestConfigurable(t)
+		conf.NewInt("limits.uploads", 10, "uploads")
+		conf.NewList("features", []string{"core"}, "features")
+		conf.NewString("region", "us", "region")
+		conf.SetTenantLoader(conf.TenantFiles(filepath.Join(dir, "%s.yaml")))
+		return conf
+	}
+
+	t.Run("test overlay", func(t *testing.T) {
+		conf := newConf(t)
+		acme := conf.Tenant("acme")
+		assert.Equal(t, 50, acme.Int("limits.uploads"))
+		assert.Equal(t, []string{"beta"}, acme.List("features"))
+		assert.Equal(t, "us", acme.String("region"))
+		assert.Equal(t, 10, *conf.Int("limits.uploads"))
+	})
+
+	t.Run("test tenant without file sees global", func(t *testing.T) {
+		conf := newConf(t)
+		assert.Equal(t, 10, conf.Tenant("globex").Int("limits.uploads"))
+	})
+
+	t.Run("test size limit", func(t *testing.T) {
+		conf := newConf(t)
+		conf.SetParseOptions(ParseOptions{MaxFileSize: 8})
+		_, err := conf.LoadTenant("acme")
+		assert.ErrorIs(t, err, ErrLimitExceeded)
+	})
+
+	t.Run("test invalid tenant id", func(t *testing.T) {
+		conf := newConf(t)
+		_, err := conf.LoadTenant("../acme")
+		assert.Error(t, err)
+		assert.Equal(t, 10, conf.Tenant("../acme").Int("limits.uploads"))
+	})
+
+	t.Run("test caching and invalidation", func(t *testing.T) {
+		conf := newConf(t)
+		calls := 0
+		conf.SetTenantLoader(func(id string) (map[string]interface{}, error) {
+			calls++
+			return map[string]interface{}{"region": "eu"}, nil
+		})
+		assert.Equal(t, "eu", conf.Tenant("acme").String("region"))
+		conf.Tenant("acme")
+		assert.Equal(t, 1, calls)
+
+		conf.InvalidateTenant("acme")
+		conf.Tenant("acme")
+		assert.Equal(t, 2, calls)
+
+		assert.NoError(t, conf.LoadData("json", []byte(`{"limits": {"uploads": 20}}`)))
+		assert.Equal(t, 20, conf.Tenant("acme").Int("limits.uploads"))
+		assert.Equal(t, 3, calls)
+	})
+
+	t.Run("test loader error", func(t *testing.T) {
+		conf := newConf(t)
+		conf.SetTenantLoader(func(id string) (map[string]interface{}, error) {
+			return nil, errors.New("unreachable")
+		})
+		_, err := conf.LoadTenant("acme")
+		assert.EqualError(t, err, "tenant acme: unreachable")
+	})
+}
@@ -0,0 +1,55 @@
+package configurable
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+)
+
+// TextFlag is a flag whose value is a custom type implementing
+// encoding.TextUnmarshaler and encoding.TextMarshaler, such as net/netip.Addr
+// or log/slog.Level. Views hold its text form.
+type TextFlag struct {
+	p encoding.TextUnmarshaler
+}
+
+func (t *TextFlag) String() string {
+	if t == nil || t.p == nil {
+		return ""
+	}
+	return t.text()
+}
+
+func (t *TextFlag) Set(value string) error {
+	return t.p.UnmarshalText([]byte(value))
+}
+
+// text returns the value's text form.
+func (t *TextFlag) text() string {
+	b, err := t.p.(encoding.TextMarshaler).MarshalText()
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
+
+// empty returns a TextFlag holding a new value of the same type.
+func (t *TextFlag) empty() *TextFlag {
+	return &TextFlag{p: reflect.New(reflect.TypeOf(t.p).Elem()).Interface().(encoding.TextUnmarshaler)}
+}
+
+// NewTextVar registers a flag stored in p, whose current value is the
+// default, like flag.TextVar. p must be a pointer that also implements
+// encoding.TextMarshaler. Every source gives the value as text, which is
+// converted with UnmarshalText.
+func (c *Configurable) NewTextVar(name string, p encoding.TextUnmarshaler, usage string, opts ...FlagOption) {
+	if _, ok := p.(encoding.TextMarshaler); !ok || reflect.TypeOf(p).Kind() != reflect.Pointer {
+		panic(fmt.Sprintf("configurable: NewTextVar on %s with %T, which is not a pointer to an encoding.TextMarshaler", name, p))
+	}
+	t := &TextFlag{p: p}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.fs.Var(t, name, usage)
+	c.flags[name] = t
+	c.annotate(name, opts)
+}
@@ -0,0 +1,50 @@
+package configurable
+
+import (
+	"log/slog"
+	"net/netip"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTextVar(t *testing.T) {
+	os.Clearenv()
+
+	conf := newTestConfigurable(t)
+	addr := netip.MustParseAddr("127.0.0.1")
+	conf.NewTextVar("bind", &addr, "address to bind")
+	var level slog.Level
+	conf.NewTextVar("level", &level, "log level")
+	assert.Equal(t, "127.0.0.1", conf.View().String("bind"))
+
+	conf.SetEnv("level", "warn")
+	conf.SetArgs([]string{"-bind", "10.0.0.1"})
+	assert.NoError(t, conf.Parse(""))
+	assert.Equal(t, netip.MustParseAddr("10.0.0.1"), addr)
+	assert.Equal(t, slog.LevelWarn, level)
+
+	assert.NoError(t, conf.LoadData("json", []byte(`{"bind": "::1", "level": "DEBUG"}`)))
+	assert.Equal(t, netip.MustParseAddr("10.0.0.1"), addr, "the command line outranks documents")
+
+	conf.SetPrecedence(SourceFile)
+	assert.NoError(t, conf.LoadData("json", []byte(`{"bind": "::1", "level": "DEBUG"}`)))
+	assert.Equal(t, netip.IPv6Loopback(), addr)
+	assert.Equal(t, slog.LevelDebug, level)
+	assert.Equal(t, "DEBUG", conf.View().String("level"))
+
+	assert.Error(t, conf.LoadData("json", []byte(`{"bind": "not-an-ip", "level": "ERROR"}`)))
+	assert.Equal(t, slog.LevelDebug, level, "a rejected document changes nothing")
+
+	assert.NoError(t, conf.Set("bind", "192.168.1.1"))
+	assert.Equal(t, netip.MustParseAddr("192.168.1.1"), addr)
+	assert.Contains(t, conf.Usage(), "(default: 127.0.0.1)")
+
+	assert.Panics(t, func() { conf.NewTextVar("plain", &textOnly{}, "") })
+}
+
+// textOnly can be read from text but not written to it.
+type textOnly struct{}
+
+func (*textOnly) UnmarshalText([]byte) error { return nil }
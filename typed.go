@@ -0,0 +1,90 @@
+package configurable
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// Get returns the current value of the flag name as a T. T may be the type
+// the flag holds or any type Unmarshal would convert it to, such as int32
+// for an int flag or a struct for a JSON flag. It fails if there is no such
+// flag or its value cannot be a T, rather than returning a zero value.
+func Get[T any](c IConfigurable, name string) (T, error) {
+	var out T
+	v, ok := c.View().Lookup(name)
+	if !ok {
+		return out, fmt.Errorf("no flag named %s", name)
+	}
+	if err := setField(reflect.ValueOf(&out).Elem(), v); err != nil {
+		return out, fmt.Errorf("flag %s: %w", name, err)
+	}
+	return out, nil
+}
+
+// Define registers a flag holding a T, with the constructor for T's type, and
+// returns its storage. T may be int, int64, float64, string, bool,
+// time.Duration, []string, []int, []float64, []bool, map[string]string,
+// json.RawMessage, or a type whose pointer implements encoding.TextMarshaler
+// and encoding.TextUnmarshaler. Any other T panics, since it is a programming
+// error.
+func Define[T any](c IConfigurable, name string, def T, usage string, opts ...FlagOption) *T {
+	var p interface{}
+	switch v := any(def).(type) {
+	case int:
+		p = c.NewInt(name, v, usage, opts...)
+	case int64:
+		p = c.NewInt64(name, v, usage, opts...)
+	case float64:
+		p = c.NewFloat64(name, v, usage, opts...)
+	case string:
+		p = c.NewString(name, v, usage, opts...)
+	case bool:
+		p = c.NewBool(name, v, usage, opts...)
+	case time.Duration:
+		p = c.NewDuration(name, v, usage, opts...)
+	case []string:
+		p = c.NewList(name, v, usage, opts...)
+	case []int:
+		p = c.NewIntSlice(name, v, usage, opts...)
+	case []float64:
+		p = c.NewFloat64Slice(name, v, usage, opts...)
+	case []bool:
+		p = c.NewBoolSlice(name, v, usage, opts...)
+	case map[string]string:
+		p = c.NewMap(name, v, usage, opts...)
+	case json.RawMessage:
+		p = c.NewJSON(name, v, usage, opts...)
+	default:
+		ptr := &def
+		text, ok := any(ptr).(encoding.TextUnmarshaler)
+		if _, marshals := any(ptr).(encoding.TextMarshaler); !ok || !marshals {
+			panic(fmt.Sprintf("configurable: Define[%T] on %s, which no flag type holds", def, name))
+		}
+		c.NewTextVar(name, text, usage, opts...)
+		return ptr
+	}
+	return p.(*T)
+}
+
+// Check adapts fn, which validates a T, for WithValidator and ValidateFlag,
+// converting the flag's value as Get does:
+//
+//	conf.NewInt("port", 8080, "port", configurable.WithValidator(configurable.Check(func(port int) error { ... })))
+func Check[T any](fn func(T) error) func(interface{}) error {
+	return func(v interface{}) error {
+		var out T
+		if err := setField(reflect.ValueOf(&out).Elem(), v); err != nil {
+			return err
+		}
+		return fn(out)
+	}
+}
+
+// ValidateFlag adds fn to the validators of the registered flag name, as
+// WithValidator(Check(fn)) does at registration.
+func ValidateFlag[T any](c IConfigurable, name string, fn func(T) error) {
+	c.AddValidator(name, Check(fn))
+}
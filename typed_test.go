@@ -0,0 +1,73 @@
+package configurable
+
+import (
+	"errors"
+	"net/netip"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetAndDefine(t *testing.T) {
+	os.Clearenv()
+
+	conf := newTestConfigurable(t)
+	port := Define(conf, "port", 8080, "port")
+	timeout := Define(conf, "timeout", 5*time.Second, "timeout")
+	tags := Define(conf, "tags", []string{"a"}, "tags")
+	addr := Define(conf, "addr", netip.MustParseAddr("127.0.0.1"), "address")
+	conf.SetArgs([]string{"-port", "9090", "-addr", "10.0.0.1", "-tags", "b"})
+	assert.NoError(t, conf.Parse(""))
+	assert.Equal(t, 9090, *port)
+	assert.Equal(t, 5*time.Second, *timeout)
+	assert.Equal(t, []string{"a", "b"}, *tags)
+	assert.Equal(t, netip.MustParseAddr("10.0.0.1"), *addr)
+
+	p, err := Get[int](conf, "port")
+	assert.NoError(t, err)
+	assert.Equal(t, 9090, p)
+
+	p16, err := Get[uint16](conf, "port")
+	assert.NoError(t, err)
+	assert.Equal(t, uint16(9090), p16)
+
+	a, err := Get[netip.Addr](conf, "addr")
+	assert.NoError(t, err)
+	assert.Equal(t, *addr, a)
+
+	_, err = Get[string](conf, "port")
+	assert.EqualError(t, err, "flag port: cannot store int in string")
+	_, err = Get[int](conf, "prot")
+	assert.EqualError(t, err, "no flag named prot")
+
+	assert.Panics(t, func() { Define(conf, "ch", make(chan int), "channel") })
+}
+
+func TestValidateFlag(t *testing.T) {
+	conf := newTestConfigurable(t)
+	conf.NewInt("port", 8080, "port")
+	conf.NewString("host", "localhost", "host", WithValidator(Check(func(host string) error {
+		if host == "" {
+			return errors.New("must not be empty")
+		}
+		return nil
+	})))
+	ValidateFlag(conf, "port", func(port uint16) error {
+		if port < 1024 {
+			return errors.New("must not be a privileged port")
+		}
+		return nil
+	})
+	conf.SetArgs([]string{"-port", "80", "-host", ""})
+	assert.EqualError(t, conf.Parse(""), "host: must not be empty\nport: must not be a privileged port")
+
+	conf = newTestConfigurable(t)
+	conf.NewInt("port", 8080, "port")
+	ValidateFlag(conf, "port", func(port string) error { return nil })
+	conf.SetArgs([]string{})
+	assert.EqualError(t, conf.Parse(""), "port: cannot store int in string")
+
+	assert.Panics(t, func() { ValidateFlag(conf, "prot", func(int) error { return nil }) })
+}
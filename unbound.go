@@ -0,0 +1,50 @@
+package configurable
+
+import (
+	"sort"
+)
+
+// UnboundKey is a key a source offered that matches no flag, kept when
+// ParseOptions.RetainUnknown is set. Its value is not kept, as it may be a
+// secret meant for a newer release.
+type UnboundKey struct {
+	Name string `json:"name"`
+	// Source is where the key came from, such as "file config.yaml".
+	Source string `json:"source"`
+}
+
+// UnboundKeys lists the keys loaded since RetainUnknown was set that match
+// no flag, sorted by name, with the source of the latest load to offer each.
+// A key stops being listed once a flag of its name is registered, or once
+// its source loads again without it.
+func (c *Configurable) UnboundKeys() []UnboundKey {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	keys := make([]UnboundKey, 0, len(c.unbound))
+	for name, key := range c.unbound {
+		if _, ok := c.flags[name]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].Name < keys[j].Name })
+	return keys
+}
+
+// retainUnbound replaces the unknown keys kept from source with those of its
+// latest load. The caller holds c.mu.
+func (c *Configurable) retainUnbound(source string, unknown []string) {
+	for name, key := range c.unbound {
+		if key.Source == source {
+			delete(c.unbound, name)
+		}
+	}
+	if len(unknown) == 0 {
+		return
+	}
+	if c.unbound == nil {
+		c.unbound = make(map[string]UnboundKey)
+	}
+	for _, name := range unknown {
+		c.unbound[name] = UnboundKey{Name: name, Source: source}
+	}
+}
@@ -0,0 +1,64 @@
+package configurable
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnboundKeys(t *testing.T) {
+	os.Clearenv()
+
+	t.Run("test retained", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config.json")
+		assert.NoError(t, os.WriteFile(path, []byte(`{"port": 8080, "search": {"engine": "v2", "shards": "auto"}}`), 0o644))
+		conf := newTestConfigurable(t)
+		conf.parseOptions.RetainUnknown = true
+		conf.NewInt("port", 80, "port")
+		conf.SetArgs([]string{})
+		assert.NoError(t, conf.Parse(path))
+		assert.NoError(t, conf.LoadData("json", []byte(`{"search": {"engine": "v3"}}`)))
+
+		assert.Equal(t, 8080, conf.View().Int("port"))
+		assert.Equal(t, []UnboundKey{
+			{Name: "search.engine", Source: "file"},
+			{Name: "search.shards", Source: "file " + path},
+		}, conf.UnboundKeys())
+		assert.Len(t, conf.Problems(), 3, "unknown keys are still reported")
+
+		conf.NewString("search.engine", "v1", "search engine")
+		assert.Equal(t, []string{"search.shards"}, unboundNames(conf.UnboundKeys()))
+	})
+
+	t.Run("test pruned on reload", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config.json")
+		assert.NoError(t, os.WriteFile(path, []byte(`{"legacy": 1, "search": {"engine": "v2"}}`), 0o644))
+		conf := newTestConfigurable(t)
+		conf.parseOptions.RetainUnknown = true
+		conf.NewInt("port", 80, "port")
+		assert.NoError(t, conf.LoadFile(path))
+		assert.NoError(t, conf.LoadData("json", []byte(`{"other": true}`)))
+		assert.Equal(t, []string{"legacy", "other", "search.engine"}, unboundNames(conf.UnboundKeys()))
+
+		assert.NoError(t, os.WriteFile(path, []byte(`{"search": {"engine": "v3"}}`), 0o644))
+		assert.NoError(t, conf.LoadFile(path))
+		assert.Equal(t, []string{"other", "search.engine"}, unboundNames(conf.UnboundKeys()), "keys the source dropped are dropped")
+	})
+
+	t.Run("test dropped by default", func(t *testing.T) {
+		conf := newTestConfigurable(t)
+		conf.NewInt("port", 80, "port")
+		assert.NoError(t, conf.LoadData("json", []byte(`{"legacy": true}`)))
+		assert.Empty(t, conf.UnboundKeys())
+	})
+}
+
+func unboundNames(keys []UnboundKey) []string {
+	var names []string
+	for _, key := range keys {
+		names = append(names, key.Name)
+	}
+	return names
+}
@@ -0,0 +1,214 @@
+package configurable
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Unit is the unit of a numeric flag, declared WithUnit.
+type Unit string
+
+const (
+	Seconds      Unit = "seconds"
+	Milliseconds Unit = "milliseconds"
+	Bytes        Unit = "bytes"
+	Percent      Unit = "percent"
+)
+
+// WithUnit declares the unit of an int, int64 or float64 flag. Usage, Docs
+// and Schema show it, and every source may also give the value with a
+// suffix, which is converted to the unit: a duration such as "1m30s" for
+// Seconds and Milliseconds, a size such as "512KiB" or "10MB" for Bytes, and
+// "50%" for Percent. A value that does not fit the unit is rejected, as is a
+// fraction for an integer flag.
+func WithUnit(unit Unit) FlagOption {
+	return func(m *flagMeta) {
+		m.unit = unit
+	}
+}
+
+// sizes maps the suffixes accepted for Bytes to their multipliers.
+var sizes = map[string]float64{
+	"b":   1,
+	"kb":  1e3,
+	"mb":  1e6,
+	"gb":  1e9,
+	"tb":  1e12,
+	"kib": 1 << 10,
+	"mib": 1 << 20,
+	"gib": 1 << 30,
+	"tib": 1 << 40,
+}
+
+// example is a suffixed value of the unit, for help text.
+func (u Unit) example() string {
+	switch u {
+	case Seconds, Milliseconds:
+		return "1m30s"
+	case Bytes:
+		return "512KiB"
+	case Percent:
+		return "50%"
+	}
+	return ""
+}
+
+// binarySizes and decimalSizes are the units sizes are written with, largest
+// first.
+var (
+	binarySizes  = []string{"TiB", "GiB", "MiB", "KiB"}
+	decimalSizes = []string{"TB", "GB", "MB", "KB"}
+)
+
+// formatSize writes n bytes in the largest unit that divides it, preferring
+// binary units, so 1073741824 reads "1GiB". Sizes that no unit divides are
+// not formatted.
+func formatSize(n float64) (string, bool) {
+	if n <= 0 || n != math.Trunc(n) {
+		return "", false
+	}
+	for _, suffix := range append(binarySizes, decimalSizes...) {
+		if size := sizes[strings.ToLower(suffix)]; math.Mod(n, size) == 0 {
+			return strconv.FormatFloat(n/size, 'f', -1, 64) + suffix, true
+		}
+	}
+	return "", false
+}
+
+// size returns v, the value of the flag name, written with its unit if name
+// is registered WithUnit(Bytes).
+func (c *Configurable) size(name string, v interface{}) (string, bool) {
+	if m, ok := c.meta[name]; !ok || m.unit != Bytes {
+		return "", false
+	}
+	switch n := v.(type) {
+	case int:
+		return formatSize(float64(n))
+	case int64:
+		return formatSize(float64(n))
+	case float64:
+		return formatSize(n)
+	}
+	return "", false
+}
+
+// parse converts s, if it is written with a suffix, to a plain number in
+// the unit. Plain numbers are returned unchanged.
+func (u Unit) parse(s string, whole bool) (string, error) {
+	s = strings.TrimSpace(s)
+	if _, err := strconv.ParseFloat(s, 64); err == nil {
+		return s, nil
+	}
+	var n float64
+	var err error
+	switch u {
+	case Seconds, Milliseconds:
+		var d time.Duration
+		if d, err = time.ParseDuration(s); err == nil {
+			n = d.Seconds()
+			if u == Milliseconds {
+				n = float64(d) / float64(time.Millisecond)
+			}
+		}
+	case Bytes:
+		i := strings.IndexFunc(s, func(r rune) bool { return (r < '0' || r > '9') && r != '.' })
+		multiplier, ok := 0.0, false
+		if i > 0 {
+			multiplier, ok = sizes[strings.ToLower(strings.TrimSpace(s[i:]))]
+		}
+		if !ok {
+			err = strconv.ErrSyntax
+			break
+		}
+		if n, err = strconv.ParseFloat(s[:i], 64); err == nil {
+			n *= multiplier
+		}
+	case Percent:
+		if !strings.HasSuffix(s, "%") {
+			err = strconv.ErrSyntax
+			break
+		}
+		n, err = strconv.ParseFloat(strings.TrimSpace(strings.TrimSuffix(s, "%")), 64)
+	default:
+		err = strconv.ErrSyntax
+	}
+	if err != nil {
+		return "", fmt.Errorf("%q is not a number of %s", s, u)
+	}
+	if whole && n != math.Trunc(n) {
+		return "", fmt.Errorf("%q is not a whole number of %s", s, u)
+	}
+	return strconv.FormatFloat(n, 'f', -1, 64), nil
+}
+
+// withUnit converts raw, if it is a string given with a suffix, to a plain
+// number in the unit of the flag name.
+func (c *Configurable) withUnit(name string, raw interface{}) (interface{}, error) {
+	s, ok := raw.(string)
+	m, declared := c.meta[name]
+	if !ok || !declared || m.unit == "" {
+		return raw, nil
+	}
+	return m.unit.parse(s, isWhole(c.flags[name]))
+}
+
+func isWhole(ptr interface{}) bool {
+	switch ptr.(type) {
+	case *int, *int64:
+		return true
+	}
+	return false
+}
+
+// unitValue accepts suffixed values for a flag with a unit on the command
+// line.
+type unitValue struct {
+	flag.Value
+	unit  Unit
+	whole bool
+}
+
+func (v *unitValue) Set(s string) error {
+	n, err := v.unit.parse(s, v.whole)
+	if err != nil {
+		return err
+	}
+	return v.Value.Set(n)
+}
+
+func (v *unitValue) String() string {
+	// The flag package calls String on a zero unitValue.
+	if v.Value == nil {
+		return ""
+	}
+	return v.Value.String()
+}
+
+func (v *unitValue) Get() interface{} {
+	if g, ok := v.Value.(flag.Getter); ok {
+		return g.Get()
+	}
+	return v.Value.String()
+}
+
+// measure installs a unitValue in front of name's flag.Value. It panics if
+// the flag is not numeric, since a unit on it is a programming error.
+func (c *Configurable) measure(name string, unit Unit) {
+	switch c.flags[name].(type) {
+	case *int, *int64, *float64:
+	default:
+		panic(fmt.Sprintf("configurable: WithUnit on %s, which is not an int, int64 or float64 flag", name))
+	}
+	f := c.fs.Lookup(name)
+	if f == nil {
+		return
+	}
+	if _, ok := f.Value.(*unitValue); ok {
+		return
+	}
+	f.Value = &unitValue{Value: f.Value, unit: unit, whole: isWhole(c.flags[name])}
+}
@@ -0,0 +1,94 @@
+package configurable
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnits(t *testing.T) {
+	os.Clearenv()
+
+	t.Run("test parse", func(t *testing.T) {
+		for _, tc := range []struct {
+			unit  Unit
+			in    string
+			whole bool
+			out   string
+			err   string
+		}{
+			{unit: Seconds, in: "30", out: "30"},
+			{unit: Seconds, in: "1m30s", out: "90"},
+			{unit: Seconds, in: "500ms", out: "0.5"},
+			{unit: Seconds, in: "500ms", whole: true, err: `"500ms" is not a whole number of seconds`},
+			{unit: Seconds, in: "10MB", err: `"10MB" is not a number of seconds`},
+			{unit: Milliseconds, in: "1.5s", out: "1500"},
+			{unit: Bytes, in: "512KiB", out: "524288"},
+			{unit: Bytes, in: "10 MB", out: "10000000"},
+			{unit: Bytes, in: "1.5gib", out: "1610612736"},
+			{unit: Bytes, in: "10kg", err: `"10kg" is not a number of bytes`},
+			{unit: Bytes, in: "MB", err: `"MB" is not a number of bytes`},
+			{unit: Percent, in: "50%", out: "50"},
+			{unit: Percent, in: "12.5 %", out: "12.5"},
+			{unit: Percent, in: "50s", err: `"50s" is not a number of percent`},
+		} {
+			out, err := tc.unit.parse(tc.in, tc.whole)
+			if tc.err != "" {
+				assert.EqualError(t, err, tc.err, tc.in)
+				continue
+			}
+			assert.NoError(t, err, tc.in)
+			assert.Equal(t, tc.out, out, tc.in)
+		}
+	})
+
+	t.Run("test sources", func(t *testing.T) {
+		conf := newTestConfigurable(t)
+		timeout := conf.NewInt("timeout", 30, "request timeout", WithUnit(Seconds))
+		cache := conf.NewInt64("cache", 0, "cache size", WithUnit(Bytes))
+		conf.NewFloat64("sample", 1, "sampling rate", WithUnit(Percent))
+		conf.SetArgs([]string{"-timeout=2m"})
+		conf.SetEnv("sample", "2.5%")
+		assert.NoError(t, conf.Parse(""))
+		assert.NoError(t, conf.LoadData("json", []byte(`{"cache": "64MiB"}`)))
+		assert.Equal(t, 120, *timeout)
+		assert.Equal(t, int64(64<<20), *cache)
+		assert.Equal(t, 2.5, *conf.Float64("sample"))
+
+		assert.NoError(t, conf.Set("timeout", "45s"))
+		assert.Equal(t, 45, conf.View().Int("timeout"))
+		assert.EqualError(t, conf.Set("timeout", "1.5s"), `"1.5s" is not a whole number of seconds`)
+		assert.ErrorContains(t, conf.LoadData("json", []byte(`{"cache": "lots"}`)), `"lots" is not a number of bytes`)
+
+		conf = newTestConfigurable(t)
+		conf.NewInt("timeout", 30, "request timeout", WithUnit(Seconds))
+		conf.SetArgs([]string{"-timeout=250ms"})
+		assert.ErrorContains(t, conf.Parse(""), `"250ms" is not a whole number of seconds`)
+	})
+
+	t.Run("test help and schema", func(t *testing.T) {
+		conf := newTestConfigurable(t)
+		conf.NewInt("timeout", 30, "request timeout", WithUnit(Seconds))
+		assert.Contains(t, conf.Usage(), "request timeout (default: 30 seconds)")
+		assert.Contains(t, conf.Docs(), "Unit: seconds; values such as `1m30s` are converted.")
+		assert.Equal(t, "seconds", conf.Flags()[0].Unit)
+
+		data, err := conf.Schema()
+		assert.NoError(t, err)
+		var schema struct {
+			Properties map[string]map[string]interface{} `json:"properties"`
+		}
+		assert.NoError(t, json.Unmarshal(data, &schema))
+		assert.Equal(t, []interface{}{"integer", "string"}, schema.Properties["timeout"]["type"])
+		assert.Equal(t, "seconds", schema.Properties["timeout"]["x-unit"])
+	})
+
+	t.Run("test non-numeric flag", func(t *testing.T) {
+		conf := newTestConfigurable(t)
+		assert.PanicsWithValue(t, "configurable: WithUnit on name, which is not an int, int64 or float64 flag", func() {
+			conf.NewString("name", "", "name", WithUnit(Bytes))
+		})
+	})
+}
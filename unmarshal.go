@@ -0,0 +1,198 @@
+package configurable
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// Unmarshal copies the current values into target, a pointer to a struct.
+// Fields tagged `config:"name"` receive the value of the flag name, converted
+// to the field's type: numbers to any numeric kind they fit, lists to slices
+// of any such element, maps to maps, text to fields implementing
+// encoding.TextUnmarshaler and JSON flags to any type json.Unmarshal fills.
+// A tagged struct field is filled from the flags under its name, so a field
+// tagged "server" holding a field tagged "port" reads server.port. Embedded
+// structs are filled as if their fields were the parent's. Untagged fields
+// and fields tagged "-" are left alone; a tag naming no flag is an error.
+//
+// The values come from one View, so they are consistent even while a load
+// is in progress.
+func (c *Configurable) Unmarshal(target interface{}) error {
+	rv := reflect.ValueOf(target)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("cannot unmarshal into %T: not a pointer to a struct", target)
+	}
+	return unmarshalStruct(c.current(), "", rv.Elem())
+}
+
+var (
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	durationType        = reflect.TypeOf(time.Duration(0))
+)
+
+// unmarshalStruct fills the tagged fields of rv from s, reading the flags
+// under prefix.
+func unmarshalStruct(s *snapshot, prefix string, rv reflect.Value) error {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		tag, tagged := field.Tag.Lookup("config")
+		if tag == "-" {
+			continue
+		}
+		fv := rv.Field(i)
+		if !tagged {
+			if field.Anonymous && field.Type.Kind() == reflect.Struct {
+				if err := unmarshalStruct(s, prefix, fv); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		name := prefix + tag
+		if value, ok := s.values[name]; ok {
+			if err := setField(fv, value); err != nil {
+				return fmt.Errorf("field %s from flag %s: %w", field.Name, name, err)
+			}
+			continue
+		}
+		if fv.Kind() == reflect.Struct && !isLeaf(fv.Type()) {
+			if err := unmarshalStruct(s, name+".", fv); err != nil {
+				return err
+			}
+			continue
+		}
+		if fv.Kind() == reflect.Pointer && fv.Type().Elem().Kind() == reflect.Struct && !isLeaf(fv.Type().Elem()) {
+			if fv.IsNil() {
+				fv.Set(reflect.New(fv.Type().Elem()))
+			}
+			if err := unmarshalStruct(s, name+".", fv.Elem()); err != nil {
+				return err
+			}
+			continue
+		}
+		return fmt.Errorf("field %s: no flag named %s", field.Name, name)
+	}
+	return nil
+}
+
+// isLeaf reports whether a struct type is filled from one value rather than
+// field by field.
+func isLeaf(t reflect.Type) bool {
+	return reflect.PointerTo(t).Implements(textUnmarshalerType)
+}
+
+// setField stores value, as held by a View, in fv.
+func setField(fv reflect.Value, value interface{}) error {
+	if fv.Kind() == reflect.Pointer {
+		if fv.IsNil() {
+			fv.Set(reflect.New(fv.Type().Elem()))
+		}
+		return setField(fv.Elem(), value)
+	}
+	if fv.CanAddr() && fv.Addr().Type().Implements(textUnmarshalerType) {
+		if s, ok := value.(string); ok {
+			return fv.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+		}
+	}
+	if doc, ok := value.(json.RawMessage); ok && fv.Type() != reflect.TypeOf(doc) {
+		return json.Unmarshal(doc, fv.Addr().Interface())
+	}
+	return convertInto(fv, reflect.ValueOf(value))
+}
+
+// convertInto stores v in fv, converting between numeric kinds and element
+// by element for slices and maps. Slices and maps are copied, since View
+// values are shared.
+func convertInto(fv, v reflect.Value) error {
+	switch {
+	case fv.Kind() == reflect.Slice && v.Kind() == reflect.Slice:
+		out := reflect.MakeSlice(fv.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			if err := setField(out.Index(i), v.Index(i).Interface()); err != nil {
+				return fmt.Errorf("item %d: %w", i, err)
+			}
+		}
+		fv.Set(out)
+		return nil
+	case fv.Kind() == reflect.Map && v.Kind() == reflect.Map:
+		out := reflect.MakeMapWithSize(fv.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			key := reflect.New(fv.Type().Key()).Elem()
+			if err := setField(key, iter.Key().Interface()); err != nil {
+				return fmt.Errorf("key %v: %w", iter.Key(), err)
+			}
+			elem := reflect.New(fv.Type().Elem()).Elem()
+			if err := setField(elem, iter.Value().Interface()); err != nil {
+				return fmt.Errorf("key %v: %w", iter.Key(), err)
+			}
+			out.SetMapIndex(key, elem)
+		}
+		fv.Set(out)
+		return nil
+	case v.Type().AssignableTo(fv.Type()):
+		fv.Set(v)
+		return nil
+	case isNumber(v.Kind()) && isNumber(fv.Kind()) && fv.Type() != durationType:
+		return convertNumber(fv, v)
+	}
+	return fmt.Errorf("cannot store %s in %s", v.Type(), fv.Type())
+}
+
+func isNumber(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// convertNumber stores the number v in fv, failing rather than overflowing
+// or dropping a fraction.
+func convertNumber(fv, v reflect.Value) error {
+	var f float64
+	switch {
+	case v.CanInt():
+		f = float64(v.Int())
+	case v.CanUint():
+		f = float64(v.Uint())
+	default:
+		f = v.Float()
+	}
+	overflow := fmt.Errorf("%v does not fit in %s", v, fv.Type())
+	switch {
+	case fv.CanInt():
+		if v.CanFloat() && f != float64(int64(f)) || v.CanUint() && v.Uint() > 1<<63-1 {
+			return overflow
+		}
+		n := v.Convert(reflect.TypeOf(int64(0))).Int()
+		if fv.OverflowInt(n) {
+			return overflow
+		}
+		fv.SetInt(n)
+	case fv.CanUint():
+		if v.CanInt() && v.Int() < 0 || v.CanFloat() && (f < 0 || f != float64(uint64(f))) {
+			return overflow
+		}
+		n := v.Convert(reflect.TypeOf(uint64(0))).Uint()
+		if fv.OverflowUint(n) {
+			return overflow
+		}
+		fv.SetUint(n)
+	default:
+		if fv.OverflowFloat(f) {
+			return overflow
+		}
+		fv.SetFloat(f)
+	}
+	return nil
+}
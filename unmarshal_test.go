@@ -0,0 +1,98 @@
+package configurable
+
+import (
+	"encoding/json"
+	"net/netip"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnmarshal(t *testing.T) {
+	os.Clearenv()
+
+	type Limits struct {
+		Burst int `config:"burst"`
+	}
+	type Server struct {
+		Host    string        `config:"host"`
+		Port    uint16        `config:"port"`
+		Timeout time.Duration `config:"timeout"`
+		Limits  *Limits       `config:"limits"`
+	}
+	type Common struct {
+		Debug bool `config:"debug"`
+	}
+	type Retry struct {
+		Attempts int `json:"attempts"`
+	}
+	type Config struct {
+		Common
+		Name     string            `config:"name"`
+		Ratio    float32           `config:"ratio"`
+		Tags     []string          `config:"tags"`
+		Ports    []int64           `config:"ports"`
+		Labels   map[string]string `config:"labels"`
+		Addr     netip.Addr        `config:"addr"`
+		Retry    Retry             `config:"retry"`
+		Server   Server            `config:"server"`
+		Ignored  string            `config:"-"`
+		Untagged string
+	}
+
+	conf := newTestConfigurable(t)
+	conf.NewString("name", "api", "name")
+	conf.NewBool("debug", true, "debug")
+	conf.NewFloat64("ratio", 0.5, "ratio")
+	conf.NewList("tags", []string{"a", "b"}, "tags")
+	conf.NewIntSlice("ports", []int{80, 443}, "ports")
+	conf.NewMap("labels", map[string]string{"team": "core"}, "labels")
+	addr := netip.MustParseAddr("10.0.0.1")
+	conf.NewTextVar("addr", &addr, "address")
+	conf.NewJSON("retry", json.RawMessage(`{"attempts": 3}`), "retry policy")
+	conf.NewString("server.host", "localhost", "host")
+	conf.NewInt("server.port", 8080, "port")
+	conf.NewDuration("server.timeout", time.Second, "timeout")
+	conf.NewInt("server.limits.burst", 10, "burst")
+
+	cfg := Config{Ignored: "kept", Untagged: "kept"}
+	assert.NoError(t, conf.Unmarshal(&cfg))
+	assert.Equal(t, Config{
+		Common:   Common{Debug: true},
+		Name:     "api",
+		Ratio:    0.5,
+		Tags:     []string{"a", "b"},
+		Ports:    []int64{80, 443},
+		Labels:   map[string]string{"team": "core"},
+		Addr:     addr,
+		Retry:    Retry{Attempts: 3},
+		Server:   Server{Host: "localhost", Port: 8080, Timeout: time.Second, Limits: &Limits{Burst: 10}},
+		Ignored:  "kept",
+		Untagged: "kept",
+	}, cfg)
+
+	cfg.Tags[0] = "changed"
+	assert.Equal(t, []string{"a", "b"}, *conf.List("tags"), "slices are copied")
+
+	t.Run("test errors", func(t *testing.T) {
+		assert.Error(t, conf.Unmarshal(cfg))
+		assert.Error(t, conf.Unmarshal(new(int)))
+
+		var missing struct {
+			Port int `config:"prot"`
+		}
+		assert.EqualError(t, conf.Unmarshal(&missing), "field Port: no flag named prot")
+
+		var small struct {
+			Port int8 `config:"server.port"`
+		}
+		assert.ErrorContains(t, conf.Unmarshal(&small), "8080 does not fit in int8")
+
+		var wrong struct {
+			Name int `config:"name"`
+		}
+		assert.ErrorContains(t, conf.Unmarshal(&wrong), "cannot store string in int")
+	})
+}
@@ -0,0 +1,252 @@
+package configurable
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// UsageFunc renders help text from a View of the registered flags.
+type UsageFunc func(View) string
+
+// SetOutput sets where PrintUsage, and the -h/-help handling of Parse, write
+// help text. The default is os.Stderr.
+func (c *Configurable) SetOutput(w io.Writer) {
+	c.output = w
+	c.fs.SetOutput(w)
+	c.fs.Usage = c.PrintUsage
+}
+
+// SetUsageFunc replaces the text built by Usage, for programs that want to
+// colorize help or hand it to their own CLI framework.
+func (c *Configurable) SetUsageFunc(fn UsageFunc) {
+	c.usageFunc = fn
+	c.fs.Usage = c.PrintUsage
+}
+
+// PrintUsage writes Usage to the configured output.
+func (c *Configurable) PrintUsage() {
+	fmt.Fprint(c.out(), c.Usage())
+}
+
+func (c *Configurable) out() io.Writer {
+	if c.output == nil {
+		return os.Stderr
+	}
+	return c.output
+}
+
+const (
+	defaultUsageWidth = 80
+	maxNameColumn     = 24
+
+	ansiReset = "\x1b[0m"
+	ansiFlag  = "\x1b[1;36m"
+	ansiValue = "\x1b[33m"
+)
+
+// usageStyle controls how the built-in Usage text is laid out.
+type usageStyle struct {
+	width int
+	color bool
+}
+
+// usageStyle wraps to the terminal width and colorizes when help goes to an
+// interactive terminal. Redirected output falls back to $COLUMNS or 80 columns
+// without escape codes, as does any terminal when NO_COLOR is set and not empty.
+func (c *Configurable) usageStyle() usageStyle {
+	style := usageStyle{width: defaultUsageWidth}
+	if cols, ok := c.lookupEnv("COLUMNS"); ok {
+		if n, err := strconv.Atoi(cols); err == nil && n > 0 {
+			style.width = n
+		}
+	}
+	width, tty := terminalWidth(c.out())
+	if !tty {
+		return style
+	}
+	if width > 0 {
+		style.width = width
+	}
+	// As no-color.org says, an empty NO_COLOR does not turn color off.
+	noColor, _ := c.lookupEnv("NO_COLOR")
+	term, _ := c.lookupEnv("TERM")
+	style.color = noColor == "" && term != "dumb"
+	return style
+}
+
+func (s usageStyle) paint(text, code string) string {
+	if !s.color || text == "" || code == "" {
+		return text
+	}
+	return code + text + ansiReset
+}
+
+const helpFullFlag = "help-full"
+
+func (c *Configurable) Usage() string {
+	if c.usageFunc != nil {
+		return c.usageFunc(c.View())
+	}
+	return c.renderUsage(false)
+}
+
+// UsageFull is Usage extended with each flag's long-form help and examples.
+// Parse prints it for -help-full.
+func (c *Configurable) UsageFull() string {
+	return c.renderUsage(true)
+}
+
+func (c *Configurable) renderUsage(full bool) string {
+	style := c.usageStyle()
+	var flags []*flag.Flag
+	var groups []string
+	nameWidth := 0
+	c.visitAll(func(f *flag.Flag) {
+		if !c.listed(f.Name) {
+			return
+		}
+		flags = append(flags, f)
+		if n := len(c.usageName(f)); n > nameWidth && n <= maxNameColumn {
+			nameWidth = n
+		}
+		if m, ok := c.meta[f.Name]; ok && m.group != "" && !slices.Contains(groups, m.group) {
+			groups = append(groups, m.group)
+		}
+	})
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Usage of %s:\n", c.fs.Name())
+	for _, group := range append([]string{""}, groups...) {
+		if group != "" {
+			fmt.Fprintf(&sb, "\n%s:\n", group)
+		}
+		for _, f := range flags {
+			m, ok := c.meta[f.Name]
+			if (ok && m.group != group) || (!ok && group != "") {
+				continue
+			}
+			c.writeFlagUsage(&sb, f, m, nameWidth, full, style)
+		}
+	}
+	return sb.String()
+}
+
+// listed reports whether name belongs in help text: it is not a short alias
+// and was not registered WithHidden.
+func (c *Configurable) listed(name string) bool {
+	if _, alias := c.aliases[name]; alias {
+		return false
+	}
+	m, ok := c.meta[name]
+	return !ok || !m.hidden
+}
+
+func (c *Configurable) writeFlagUsage(sb *strings.Builder, f *flag.Flag, m *flagMeta, nameWidth int, full bool, style usageStyle) {
+	indent := 2 + nameWidth + 2
+	name := c.usageName(f)
+	sb.WriteString("  ")
+	sb.WriteString(style.paint(name, ansiFlag))
+	if len(name) > nameWidth {
+		sb.WriteString("\n")
+		sb.WriteString(strings.Repeat(" ", indent))
+	} else {
+		sb.WriteString(strings.Repeat(" ", nameWidth-len(name)+2))
+	}
+	words := usageWords(f.Usage)
+	if m != nil && m.unit != "" {
+		words = append(words, usageWord{text: "(default:"}, usageWord{text: f.DefValue, code: ansiValue}, usageWord{text: string(m.unit), suffix: ")"})
+	} else {
+		words = append(words, usageWord{text: "(default:"}, usageWord{text: f.DefValue, code: ansiValue, suffix: ")"})
+	}
+	if m != nil && m.bounds != nil {
+		words = append(words, usageWord{text: "(range:"}, usageWord{text: m.bounds.String(), code: ansiValue, suffix: ")"})
+	}
+	if m != nil && m.deprecated != "" {
+		words = append(words, usageWord{text: "DEPRECATED:"})
+		words = append(words, usageWords(m.deprecated)...)
+	}
+	writeWrapped(sb, words, indent, style)
+	sb.WriteString("\n")
+	if m != nil && full {
+		c.writeExtendedHelp(sb, m, indent, style)
+	}
+}
+
+func (c *Configurable) writeExtendedHelp(sb *strings.Builder, m *flagMeta, indent int, style usageStyle) {
+	pad := strings.Repeat(" ", indent)
+	for _, paragraph := range strings.Split(strings.TrimSpace(m.help), "\n\n") {
+		if paragraph == "" {
+			continue
+		}
+		sb.WriteString("\n")
+		sb.WriteString(pad)
+		writeWrapped(sb, usageWords(paragraph), indent, style)
+		sb.WriteString("\n")
+	}
+	for _, example := range m.examples {
+		sb.WriteString(pad)
+		sb.WriteString("Example: ")
+		sb.WriteString(style.paint(example, ansiValue))
+		sb.WriteString("\n")
+	}
+	if m.help != "" || len(m.examples) > 0 {
+		sb.WriteString("\n")
+	}
+}
+
+// usageName is how a flag is spelled in help text. Boolean flags advertise
+// their --no- form, and short names come first.
+func (c *Configurable) usageName(f *flag.Flag) string {
+	name := "-" + f.Name
+	if isBoolFlag(f) {
+		name = "-[" + negationPrefix + "]" + f.Name
+	}
+	if m, ok := c.meta[f.Name]; ok && m.short != "" {
+		name = "-" + m.short + ", " + name
+	}
+	return name
+}
+
+// usageWord is a unit of help text; code optionally colors text, but not the
+// trailing suffix.
+type usageWord struct {
+	text   string
+	code   string
+	suffix string
+}
+
+func usageWords(text string) []usageWord {
+	fields := strings.Fields(text)
+	words := make([]usageWord, len(fields))
+	for i, field := range fields {
+		words[i] = usageWord{text: field}
+	}
+	return words
+}
+
+// writeWrapped writes words starting at column indent, breaking lines so that
+// no line exceeds style.width unless a single word is longer than the space.
+func writeWrapped(sb *strings.Builder, words []usageWord, indent int, style usageStyle) {
+	col := indent
+	for i, w := range words {
+		n := len(w.text) + len(w.suffix)
+		if i > 0 {
+			if col+1+n > style.width {
+				sb.WriteString("\n")
+				sb.WriteString(strings.Repeat(" ", indent))
+				col = indent
+			} else {
+				sb.WriteByte(' ')
+				col++
+			}
+		}
+		sb.WriteString(style.paint(w.text, w.code))
+		sb.WriteString(w.suffix)
+		col += n
+	}
+}
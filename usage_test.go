@@ -0,0 +1,153 @@
+package configurable
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUsageOutput(t *testing.T) {
+	os.Clearenv()
+
+	t.Run("test SetOutput", func(t *testing.T) {
+		conf := newTestConfigurable(t)
+		conf.NewInt("port", 80, "listen port")
+		var buf bytes.Buffer
+		conf.SetOutput(&buf)
+		conf.PrintUsage()
+		assert.Contains(t, buf.String(), "  -port  listen port (default: 80)\n")
+	})
+
+	t.Run("test SetUsageFunc", func(t *testing.T) {
+		conf := newTestConfigurable(t)
+		conf.NewInt("port", 80, "listen port")
+		conf.NewString("host", "localhost", "listen host")
+		conf.SetUsageFunc(func(v View) string {
+			var sb strings.Builder
+			for _, name := range v.Names() {
+				fmt.Fprintf(&sb, "%s=%s;", name, v.Flag(name).DefValue)
+			}
+			return sb.String()
+		})
+		assert.Equal(t, "host=localhost;port=80;", conf.Usage())
+	})
+
+	t.Run("test help flag uses custom output", func(t *testing.T) {
+		conf := newTestConfigurable(t)
+		conf.NewInt("port", 80, "listen port")
+		var buf bytes.Buffer
+		conf.SetOutput(&buf)
+		conf.SetUsageFunc(func(View) string { return "custom help\n" })
+		assert.ErrorIs(t, conf.ParseArgs([]string{"-h"}), flag.ErrHelp)
+		assert.Equal(t, "custom help\n", buf.String())
+	})
+}
+
+func TestView(t *testing.T) {
+	os.Clearenv()
+
+	conf := newTestConfigurable(t)
+	conf.NewInt("port", 80, "port")
+	conf.NewList("tags", []string{"a"}, "tags")
+	view := conf.View()
+	assert.NoError(t, conf.Set("port", 8080))
+
+	assert.Equal(t, 80, view.Int("port"))
+	assert.Equal(t, []string{"a"}, view.List("tags"))
+	assert.Equal(t, 8080, conf.View().Int("port"))
+	_, ok := view.Lookup("missing")
+	assert.False(t, ok)
+	assert.Nil(t, view.Flag("missing"))
+}
+
+func TestUsageLayout(t *testing.T) {
+	os.Clearenv()
+
+	t.Run("test columns align", func(t *testing.T) {
+		conf := newTestConfigurable(t)
+		conf.NewInt("port", 80, "listen port")
+		conf.NewString("hostname", "localhost", "listen host")
+		usage := conf.Usage()
+		assert.Contains(t, usage, "  -hostname  listen host (default: localhost)\n")
+		assert.Contains(t, usage, "  -port      listen port (default: 80)\n")
+	})
+
+	t.Run("test wraps to COLUMNS", func(t *testing.T) {
+		conf := newTestConfigurable(t)
+		conf.SetEnv("COLUMNS", "40")
+		conf.NewString("name", "x", "a fairly long description that needs to wrap onto more lines")
+		for _, line := range strings.Split(strings.TrimSpace(conf.Usage()), "\n")[1:] {
+			assert.LessOrEqual(t, len(line), 40, line)
+			assert.True(t, strings.HasPrefix(line, "  "), line)
+		}
+	})
+
+	t.Run("test redirected output is not colorized", func(t *testing.T) {
+		conf := newTestConfigurable(t)
+		conf.NewInt("port", 80, "listen port")
+		conf.SetOutput(&bytes.Buffer{})
+		assert.NotContains(t, conf.Usage(), "\x1b[")
+	})
+
+	t.Run("test paint", func(t *testing.T) {
+		style := usageStyle{width: 80, color: true}
+		var sb strings.Builder
+		writeWrapped(&sb, []usageWord{{text: "(default:"}, {text: "80", code: ansiValue, suffix: ")"}}, 0, style)
+		assert.Equal(t, "(default: \x1b[33m80\x1b[0m)", sb.String())
+	})
+}
+
+func TestExtendedHelp(t *testing.T) {
+	os.Clearenv()
+
+	newConf := func(t *testing.T) *Configurable {
+		conf := newTestConfigurable(t)
+		conf.NewString("listen", ":80", "listen address",
+			WithHelp("Address the HTTP server binds to.\n\nUse an empty host to listen on every interface."),
+			WithExample("--listen :8080"),
+			WithExample("--listen 127.0.0.1:9000"))
+		conf.NewBool("debug", false, "enable debug logging")
+		return conf
+	}
+
+	t.Run("test Usage omits extended help", func(t *testing.T) {
+		conf := newConf(t)
+		assert.NotContains(t, conf.Usage(), "Example:")
+	})
+
+	t.Run("test UsageFull", func(t *testing.T) {
+		conf := newConf(t)
+		usage := conf.UsageFull()
+		assert.Contains(t, usage, "          Address the HTTP server binds to.\n")
+		assert.Contains(t, usage, "Use an empty host to listen on every interface.")
+		assert.Contains(t, usage, "          Example: --listen :8080\n")
+		assert.Contains(t, usage, "          Example: --listen 127.0.0.1:9000\n")
+	})
+
+	t.Run("test help-full flag", func(t *testing.T) {
+		conf := newConf(t)
+		var buf bytes.Buffer
+		conf.SetOutput(&buf)
+		assert.ErrorIs(t, conf.ParseArgs([]string{"--debug", "--help-full"}), flag.ErrHelp)
+		assert.Contains(t, buf.String(), "Example: --listen :8080")
+
+		conf = newConf(t)
+		conf.SetOutput(&buf)
+		assert.NoError(t, conf.ParseArgs([]string{"-listen", "--help-full"}), "a flag value is not a request for help")
+		assert.Equal(t, "--help-full", *conf.String("listen"))
+		assert.NoError(t, newConf(t).ParseArgs([]string{"--", "--help-full"}))
+	})
+
+	t.Run("test Docs", func(t *testing.T) {
+		conf := newConf(t)
+		docs := conf.Docs()
+		assert.Contains(t, docs, "## `--listen`\n\nlisten address\n\nDefault: `:80`\n")
+		assert.Contains(t, docs, "```\n--listen :8080\n--listen 127.0.0.1:9000\n```\n")
+		assert.Contains(t, docs, "## `--debug`")
+	})
+}
@@ -0,0 +1,139 @@
+package configurable
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ValidateFunc checks an invariant spanning several flags, such as
+// read-timeout being shorter than idle-timeout.
+type ValidateFunc func(v View) error
+
+// ValidateWith registers fn to check the whole configuration. Parse runs it
+// once every source has been applied, and loads after a successful Parse run
+// it against the would-be values, rejecting the load if it fails.
+func (c *Configurable) ValidateWith(fn ValidateFunc) {
+	c.validators = append(c.validators, fn)
+}
+
+// AddValidator adds fn to the validators of the registered flag name, as
+// WithValidator does at registration. Parse runs every validator and joins
+// their errors, each prefixed with its flag's name.
+func (c *Configurable) AddValidator(name string, fn func(v interface{}) error) {
+	if _, ok := c.flags[name]; !ok {
+		panic(fmt.Sprintf("configurable: AddValidator on %s, which is not a flag", name))
+	}
+	m := c.metaFor(name)
+	m.validators = append(m.validators, fn)
+}
+
+// Validate runs the functions registered with ValidateWith against the
+// current values and joins their errors.
+func (c *Configurable) Validate() error {
+	c.mu.RLock()
+	values := c.values()
+	c.mu.RUnlock()
+	return c.validate(values)
+}
+
+func (c *Configurable) validate(values map[string]interface{}) error {
+	view := &snapshot{values: values, fs: c.fs}
+	var errs []error
+	names := make([]string, 0, len(c.meta))
+	for name, m := range c.meta {
+		if len(m.validators) > 0 {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, fn := range c.meta[name].validators {
+			if err := fn(values[name]); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			}
+		}
+	}
+	for _, fn := range c.validators {
+		if err := fn(view); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// mergeValue returns current with incoming applied the way setValue applies
+// it: lists append and maps merge, while other values are replaced.
+func mergeValue(current, incoming interface{}) interface{} {
+	switch cur := current.(type) {
+	case []string:
+		return append(append([]string{}, cur...), incoming.([]string)...)
+	case map[string]string:
+		m := make(map[string]string, len(cur))
+		for k, v := range cur {
+			m[k] = v
+		}
+		for k, v := range incoming.(map[string]string) {
+			m[k] = v
+		}
+		return m
+	case []int:
+		return append(append([]int{}, cur...), incoming.([]int)...)
+	case []float64:
+		return append(append([]float64{}, cur...), incoming.([]float64)...)
+	case []bool:
+		return append(append([]bool{}, cur...), incoming.([]bool)...)
+	}
+	return incoming
+}
+
+// RequiredFlagsError is returned by Parse when flags registered WithRequired
+// were not set by any source.
+type RequiredFlagsError struct {
+	Names []string
+}
+
+func (e *RequiredFlagsError) Error() string {
+	return "missing required flags: " + strings.Join(e.Names, ", ")
+}
+
+// Require marks already registered flags as required, as WithRequired does
+// at registration, for flags a library or a Define call registered.
+func (c *Configurable) Require(names ...string) {
+	for _, name := range names {
+		if _, ok := c.flags[name]; !ok {
+			panic(fmt.Sprintf("configurable: Require on %s, which is not a flag", name))
+		}
+		c.metaFor(name).required = true
+	}
+}
+
+func (c *Configurable) checkRequired() error {
+	var missing []string
+	for name, m := range c.meta {
+		if _, set := c.sources[name]; m.required && !set {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	sort.Strings(missing)
+	return &RequiredFlagsError{Names: missing}
+}
+
+// warnDeprecated logs and records each deprecated flag that a source has set.
+func (c *Configurable) warnDeprecated() {
+	names := make([]string, 0, len(c.sources))
+	for name := range c.sources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if m, ok := c.meta[name]; ok && m.deprecated != "" {
+			c.logger().Warn("configurable: flag is deprecated", "flag", name, "source", c.sources[name].String(), "message", m.deprecated)
+			c.problem(DeprecatedFlag, name, c.sources[name].String(), m.deprecated)
+		}
+	}
+}
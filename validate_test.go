@@ -0,0 +1,102 @@
+package configurable
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateWith(t *testing.T) {
+	newConf := func(t *testing.T) *Configurable {
+		conf := newTestConfigurable(t)
+		conf.NewDuration("read-timeout", 5*time.Second, "read timeout")
+		conf.NewDuration("idle-timeout", time.Minute, "idle timeout")
+		conf.NewInt("replicas", 1, "replicas")
+		conf.NewInt("max-replicas", 3, "max replicas")
+		conf.ValidateWith(func(v View) error {
+			if v.Duration("read-timeout") >= v.Duration("idle-timeout") {
+				return errors.New("read-timeout must be shorter than idle-timeout")
+			}
+			return nil
+		})
+		conf.ValidateWith(func(v View) error {
+			if v.Int("replicas") > v.Int("max-replicas") {
+				return fmt.Errorf("replicas (%d) exceeds max-replicas (%d)", v.Int("replicas"), v.Int("max-replicas"))
+			}
+			return nil
+		})
+		conf.SetOutput(&bytes.Buffer{})
+		return conf
+	}
+
+	t.Run("test parse", func(t *testing.T) {
+		conf := newConf(t)
+		conf.SetArgs([]string{"-replicas", "2"})
+		assert.NoError(t, conf.Parse(""))
+
+		conf = newConf(t)
+		conf.SetArgs([]string{"-read-timeout", "2m", "-replicas", "5"})
+		assert.EqualError(t, conf.Parse(""), "read-timeout must be shorter than idle-timeout\nreplicas (5) exceeds max-replicas (3)")
+	})
+
+	t.Run("test validate", func(t *testing.T) {
+		conf := newConf(t)
+		assert.NoError(t, conf.Validate())
+		*conf.Int("replicas") = 4
+		assert.EqualError(t, conf.Validate(), "replicas (4) exceeds max-replicas (3)")
+	})
+
+	t.Run("test reload is rejected before applying", func(t *testing.T) {
+		conf := newConf(t)
+		conf.SetArgs([]string{})
+		assert.NoError(t, conf.Parse(""))
+
+		err := conf.LoadData("json", []byte(`{"replicas": 2, "read-timeout": "90s"}`))
+		assert.EqualError(t, err, "read-timeout must be shorter than idle-timeout")
+		assert.Equal(t, 1, *conf.Int("replicas"))
+		assert.Equal(t, 5*time.Second, *conf.Duration("read-timeout"))
+
+		assert.NoError(t, conf.LoadData("json", []byte(`{"replicas": 3, "idle-timeout": "2m", "read-timeout": "90s"}`)))
+		assert.Equal(t, 3, *conf.Int("replicas"))
+	})
+}
+
+func TestRequire(t *testing.T) {
+	newConf := func(t *testing.T) *Configurable {
+		conf := newTestConfigurable(t)
+		conf.NewString("region", "", "region")
+		conf.NewString("token", "", "token", WithEnv("APP_TOKEN"))
+		conf.NewInt("port", 80, "port")
+		conf.Require("token", "region")
+		conf.SetOutput(&bytes.Buffer{})
+		return conf
+	}
+
+	t.Run("test missing", func(t *testing.T) {
+		conf := newConf(t)
+		conf.SetArgs([]string{"-port", "8080"})
+		err := conf.Parse("")
+		var required *RequiredFlagsError
+		assert.ErrorAs(t, err, &required)
+		assert.Equal(t, []string{"region", "token"}, required.Names)
+	})
+
+	t.Run("test every source counts", func(t *testing.T) {
+		conf := newConf(t)
+		conf.SetEnv("APP_TOKEN", "secret")
+		conf.SetArgs([]string{})
+		assert.NoError(t, conf.LoadData("json", []byte(`{"region": "eu"}`)))
+		assert.NoError(t, conf.Parse(""))
+	})
+
+	t.Run("test unknown flag", func(t *testing.T) {
+		conf := newConf(t)
+		assert.PanicsWithValue(t, "configurable: Require on missing, which is not a flag", func() {
+			conf.Require("missing")
+		})
+	})
+}
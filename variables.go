@@ -0,0 +1,83 @@
+package configurable
+
+import (
+	"maps"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// variablePattern matches a ${namespace.key} reference.
+var variablePattern = regexp.MustCompile(`\$\{([A-Za-z0-9_-]+\.[A-Za-z0-9_.-]+)\}`)
+
+// SetVariables makes ${namespace.key} in values expand to vars[key], so
+// facts known only at runtime, such as the host's region, can be used in
+// configuration. Values from every source except the command line are
+// expanded as they are applied, and values already set, including defaults,
+// are expanded when the variables are set. References to unknown variables
+// are left as they are.
+func (c *Configurable) SetVariables(namespace string, vars map[string]string) {
+	_ = c.update(func() error {
+		merged := make(map[string]string)
+		if current := c.variables.Load(); current != nil {
+			maps.Copy(merged, *current)
+		}
+		for k, v := range vars {
+			merged[namespace+"."+k] = v
+		}
+		c.variables.Store(&merged)
+		for _, ptr := range c.flags {
+			value := valueOf(ptr)
+			if expanded := c.expand(value); !reflect.DeepEqual(value, expanded) {
+				assign(ptr, expanded)
+			}
+		}
+		return nil
+	})
+}
+
+// expand replaces the variable references in raw, a value as decoded from a
+// document or as returned by valueOf. Other values are returned as they are.
+func (c *Configurable) expand(raw interface{}) interface{} {
+	vars := c.variables.Load()
+	if vars == nil {
+		return raw
+	}
+	switch v := raw.(type) {
+	case string:
+		if !strings.Contains(v, "${") {
+			return v
+		}
+		return variablePattern.ReplaceAllStringFunc(v, func(ref string) string {
+			if value, ok := (*vars)[ref[2:len(ref)-1]]; ok {
+				return value
+			}
+			return ref
+		})
+	case []string:
+		out := make([]string, len(v))
+		for i, item := range v {
+			out[i] = c.expand(item).(string)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			out[i] = c.expand(item)
+		}
+		return out
+	case map[string]string:
+		out := make(map[string]string, len(v))
+		for k, item := range v {
+			out[k] = c.expand(item).(string)
+		}
+		return out
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			out[k] = c.expand(item)
+		}
+		return out
+	}
+	return raw
+}
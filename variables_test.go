@@ -0,0 +1,40 @@
+package configurable
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetVariables(t *testing.T) {
+	os.Clearenv()
+
+	t.Run("test defaults and documents", func(t *testing.T) {
+		conf := newTestConfigurable(t)
+		bucket := conf.NewString("bucket", "logs-${meta.region}", "bucket")
+		hosts := conf.NewList("hosts", nil, "hosts")
+		port := conf.NewInt("port", 80, "port")
+		other := conf.NewString("other", "${unknown.key} ${HOME}", "other")
+
+		conf.SetVariables("meta", map[string]string{"region": "eu-west-1", "port": "8443"})
+		assert.Equal(t, "logs-eu-west-1", *bucket)
+		assert.Equal(t, "logs-eu-west-1", conf.View().String("bucket"))
+
+		doc := `{"hosts": ["a.${meta.region}.example"], "port": "${meta.port}"}`
+		assert.NoError(t, conf.LoadData("json", []byte(doc)))
+		assert.Equal(t, []string{"a.eu-west-1.example"}, *hosts)
+		assert.Equal(t, 8443, *port)
+		assert.Equal(t, "${unknown.key} ${HOME}", *other)
+	})
+
+	t.Run("test env and Set", func(t *testing.T) {
+		conf := newTestConfigurable(t)
+		conf.NewString("zone", "", "zone")
+		conf.SetVariables("meta", map[string]string{"zone": "b"})
+		conf.SetEnv("zone", "${meta.zone}")
+		assert.Equal(t, "b", *conf.String("zone"))
+		assert.NoError(t, conf.Set("zone", "zone-${meta.zone}"))
+		assert.Equal(t, "zone-b", conf.View().String("zone"))
+	})
+}
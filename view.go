@@ -0,0 +1,221 @@
+package configurable
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"time"
+)
+
+// View is a read-only view of resolved configuration values. Unlike the
+// pointer-returning getters on IConfigurable, a View is a copy: later loads do
+// not change what it reports.
+type View interface {
+	Names() []string
+	Lookup(name string) (interface{}, bool)
+	Flag(name string) *flag.Flag
+
+	Int(name string) int
+	Int64(name string) int64
+	Float64(name string) float64
+	String(name string) string
+	Bool(name string) bool
+	Duration(name string) time.Duration
+	List(name string) []string
+	Map(name string) map[string]string
+}
+
+type snapshot struct {
+	id     uint64
+	values map[string]interface{}
+	fs     *flag.FlagSet
+}
+
+// View returns the current values. It does not block while values are being
+// loaded, and is safe to use from any goroutine.
+func (c *Configurable) View() View {
+	return c.current()
+}
+
+// values returns a copy of every registered flag's current value.
+func (c *Configurable) values() map[string]interface{} {
+	out := make(map[string]interface{}, len(c.flags))
+	for name, ptr := range c.flags {
+		out[name] = valueOf(ptr)
+	}
+	return out
+}
+
+// valueOf dereferences flag storage into a value that shares no memory with
+// it.
+func valueOf(ptr interface{}) interface{} {
+	switch v := ptr.(type) {
+	case *int:
+		return *v
+	case *int64:
+		return *v
+	case *float64:
+		return *v
+	case *string:
+		return *v
+	case *bool:
+		return *v
+	case *time.Duration:
+		return *v
+	case *ListFlag:
+		return append([]string{}, *v.values...)
+	case typedList:
+		return v.value()
+	case *MapFlag:
+		m := make(map[string]string, len(*v.values))
+		for k, val := range *v.values {
+			m[k] = val
+		}
+		return m
+	case *TextFlag:
+		return v.text()
+	case *JSONFlag:
+		return append(json.RawMessage{}, *v.value...)
+	}
+	return nil
+}
+
+// assign replaces the contents of flag storage with value, as returned by
+// valueOf or convert for the same flag.
+func assign(ptr, value interface{}) {
+	switch p := ptr.(type) {
+	case *int:
+		*p = value.(int)
+	case *int64:
+		*p = value.(int64)
+	case *float64:
+		*p = value.(float64)
+	case *string:
+		*p = value.(string)
+	case *bool:
+		*p = value.(bool)
+	case *time.Duration:
+		*p = value.(time.Duration)
+	case *ListFlag:
+		*p.values = append([]string{}, value.([]string)...)
+	case typedList:
+		p.assign(value)
+	case *MapFlag:
+		*p.values = maps.Clone(value.(map[string]string))
+	case *TextFlag:
+		_ = p.Set(value.(string))
+	case *JSONFlag:
+		*p.value = append(json.RawMessage{}, value.(json.RawMessage)...)
+	}
+}
+
+// convert turns a raw value from a document into the Go value the flag name
+// holds, without touching the flag. Lists and maps are replaced, not merged.
+func (c *Configurable) convert(name string, raw interface{}) (interface{}, error) {
+	raw, err := c.withUnit(name, raw)
+	if err != nil {
+		return nil, err
+	}
+	raw = c.withPath(name, raw)
+	var storage interface{}
+	switch ptr := c.flags[name].(type) {
+	case *int:
+		storage = new(int)
+	case *int64:
+		storage = new(int64)
+	case *float64:
+		storage = new(float64)
+	case *string:
+		storage = new(string)
+	case *bool:
+		storage = new(bool)
+	case *time.Duration:
+		storage = new(time.Duration)
+	case *ListFlag:
+		storage = &ListFlag{values: &[]string{}}
+	case typedList:
+		storage = ptr.empty()
+	case *MapFlag:
+		storage = &MapFlag{values: &map[string]string{}}
+	case *TextFlag:
+		storage = ptr.empty()
+	case *JSONFlag:
+		storage = &JSONFlag{value: new(json.RawMessage)}
+	default:
+		return nil, fmt.Errorf("unknown flag %s", name)
+	}
+	if err := c.setValue(storage, raw); err != nil {
+		return nil, err
+	}
+	value := valueOf(storage)
+	if err := c.checkConstraints(name, value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+func (s *snapshot) Names() []string {
+	names := make([]string, 0, len(s.values))
+	for name := range s.values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (s *snapshot) Lookup(name string) (interface{}, bool) {
+	v, ok := s.values[name]
+	return v, ok
+}
+
+func (s *snapshot) Flag(name string) *flag.Flag {
+	if _, ok := s.values[name]; !ok {
+		return nil
+	}
+	return s.fs.Lookup(name)
+}
+
+func (s *snapshot) Int(name string) int {
+	v, _ := s.values[name].(int)
+	return v
+}
+
+func (s *snapshot) Int64(name string) int64 {
+	v, _ := s.values[name].(int64)
+	return v
+}
+
+func (s *snapshot) Float64(name string) float64 {
+	v, _ := s.values[name].(float64)
+	return v
+}
+
+func (s *snapshot) String(name string) string {
+	v, _ := s.values[name].(string)
+	return v
+}
+
+func (s *snapshot) Bool(name string) bool {
+	v, _ := s.values[name].(bool)
+	return v
+}
+
+func (s *snapshot) Duration(name string) time.Duration {
+	v, _ := s.values[name].(time.Duration)
+	return v
+}
+
+// List returns a copy, since snapshots can be shared.
+func (s *snapshot) List(name string) []string {
+	v, _ := s.values[name].([]string)
+	return slices.Clone(v)
+}
+
+// Map returns a copy, since snapshots can be shared.
+func (s *snapshot) Map(name string) map[string]string {
+	v, _ := s.values[name].(map[string]string)
+	return maps.Clone(v)
+}
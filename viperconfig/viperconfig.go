@@ -0,0 +1,117 @@
+// Package viperconfig bridges a Configurable and spf13/viper, so services
+// migrating off viper can run both side by side.
+//
+// FromViper makes a viper.Viper a provider of a Configurable: every flag
+// whose name viper has a value for, from its files, environment or
+// defaults, takes that value. Dotted flag names match nested viper keys and,
+// as in viper, case is ignored. ToViper goes the other way, for code still
+// reading from viper.
+package viperconfig
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/andreimerlescu/configurable"
+	"github.com/spf13/viper"
+)
+
+// Provider reads the settings of a viper.Viper.
+type Provider struct {
+	v    *viper.Viper
+	conf configurable.IConfigurable
+}
+
+// FromViper adds v as a provider of conf, so Parse and LoadProviders load
+// the values v resolves.
+func FromViper(conf configurable.IConfigurable, v *viper.Viper, opts ...configurable.SourceOption) {
+	conf.AddProvider(&Provider{v: v, conf: conf}, opts...)
+}
+
+func (p *Provider) Name() string {
+	return "viper"
+}
+
+// Load returns the value v holds for each of conf's flags, read as the
+// flag's type.
+func (p *Provider) Load(ctx context.Context) (map[string]interface{}, error) {
+	out := make(map[string]interface{})
+	for _, info := range p.conf.Flags() {
+		if !p.v.IsSet(info.Name) {
+			continue
+		}
+		out[info.Name] = get(p.v, info.Name, info.Type)
+	}
+	return out, nil
+}
+
+// get reads key from v as a value of the Configurable flag type typ.
+func get(v *viper.Viper, key, typ string) interface{} {
+	switch typ {
+	case "int":
+		return v.GetInt(key)
+	case "int64":
+		return v.GetInt64(key)
+	case "float64":
+		return v.GetFloat64(key)
+	case "bool":
+		return v.GetBool(key)
+	case "duration":
+		return v.GetDuration(key).String()
+	case "list":
+		return v.GetStringSlice(key)
+	case "map":
+		return v.GetStringMapString(key)
+	case "string":
+		return v.GetString(key)
+	}
+	return v.Get(key)
+}
+
+// ToViper returns a viper.Viper holding conf's current values. Values
+// conf took from a source are set; the rest are viper defaults, as are the
+// defaults of overridden flags other than lists and maps.
+func ToViper(conf configurable.IConfigurable) *viper.Viper {
+	v := viper.New()
+	view := conf.View()
+	for _, info := range conf.Flags() {
+		value, ok := view.Lookup(info.Name)
+		if !ok {
+			continue
+		}
+		if info.Source == "default" {
+			v.SetDefault(info.Name, value)
+			continue
+		}
+		if def, ok := parseDefault(info.Default, info.Type); ok {
+			v.SetDefault(info.Name, def)
+		}
+		v.Set(info.Name, value)
+	}
+	return v
+}
+
+// parseDefault converts the default of a scalar flag, shown as a string,
+// to the flag's type.
+func parseDefault(s, typ string) (interface{}, bool) {
+	var value interface{}
+	var err error
+	switch typ {
+	case "int":
+		value, err = strconv.Atoi(s)
+	case "int64":
+		value, err = strconv.ParseInt(s, 10, 64)
+	case "float64":
+		value, err = strconv.ParseFloat(s, 64)
+	case "bool":
+		value, err = strconv.ParseBool(s)
+	case "duration":
+		value, err = time.ParseDuration(s)
+	case "string":
+		value = s
+	default:
+		return nil, false
+	}
+	return value, err == nil
+}
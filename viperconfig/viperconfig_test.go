@@ -0,0 +1,77 @@
+package viperconfig
+
+import (
+	"flag"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andreimerlescu/configurable"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func newConf(t *testing.T) configurable.IConfigurable {
+	return configurable.New(configurable.WithFlagSet(flag.NewFlagSet(t.Name(), flag.ContinueOnError)))
+}
+
+func TestFromViper(t *testing.T) {
+	os.Clearenv()
+	v := viper.New()
+	v.SetConfigType("yaml")
+	assert.NoError(t, v.ReadConfig(strings.NewReader(`
+server:
+  port: 8080
+  timeout: 5s
+hosts: [a, b]
+labels:
+  team: core
+`)))
+	v.SetDefault("maxConns", 20)
+
+	conf := newConf(t)
+	port := conf.NewInt("server.port", 80, "port")
+	timeout := conf.NewDuration("server.timeout", time.Second, "timeout")
+	hosts := conf.NewList("hosts", nil, "hosts")
+	labels := conf.NewMap("labels", nil, "labels")
+	maxConns := conf.NewInt("maxConns", 10, "connections")
+	name := conf.NewString("name", "api", "name")
+	FromViper(conf, v)
+	conf.SetArgs([]string{})
+	assert.NoError(t, conf.Parse(""))
+
+	assert.Equal(t, 8080, *port)
+	assert.Equal(t, 5*time.Second, *timeout)
+	assert.Equal(t, []string{"a", "b"}, *hosts)
+	assert.Equal(t, map[string]string{"team": "core"}, *labels)
+	assert.Equal(t, 20, *maxConns)
+	assert.Equal(t, "api", *name)
+	assert.Equal(t, "remote viper", sourceOf(conf, "server.port"))
+}
+
+func sourceOf(conf configurable.IConfigurable, name string) string {
+	for _, info := range conf.Flags() {
+		if info.Name == name {
+			return info.Source
+		}
+	}
+	return ""
+}
+
+func TestToViper(t *testing.T) {
+	os.Clearenv()
+	conf := newConf(t)
+	conf.NewInt("server.port", 80, "port")
+	conf.NewDuration("timeout", time.Second, "timeout")
+	conf.NewList("hosts", []string{"a"}, "hosts")
+	conf.SetArgs([]string{"-server.port", "8080"})
+	assert.NoError(t, conf.Parse(""))
+
+	v := ToViper(conf)
+	assert.Equal(t, 8080, v.GetInt("server.port"))
+	assert.Equal(t, 8080, v.Sub("server").GetInt("port"))
+	assert.Equal(t, time.Second, v.GetDuration("timeout"))
+	assert.Equal(t, []string{"a"}, v.GetStringSlice("hosts"))
+	assert.ElementsMatch(t, []string{"hosts", "server.port", "timeout"}, v.AllKeys())
+}
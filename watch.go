@@ -0,0 +1,105 @@
+//go:build !(js && wasm) && !tinygo
+
+package configurable
+
+import (
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// fileWatchQuiet is how long Watch waits for a burst of events, such as an
+// editor's write and rename, to end before reloading.
+const fileWatchQuiet = 100 * time.Millisecond
+
+// Watch reloads filename with LoadFile whenever it changes, until stop or
+// Close is called. Change callbacks run as for any load, and a change that fails to
+// decode or validate is logged and recorded as a RejectedUpdate problem,
+// leaving the previous values in place. The file's directory is watched, so
+// editors that replace the file and Kubernetes ConfigMap updates, which swap
+// a symlink, are seen too. Stopping waits for a reload in progress, so stop
+// must not be called from a change function that reload runs.
+func (c *Configurable) Watch(filename string) (stop func(), err error) {
+	filename = filepath.Clean(c.resolvePath(filename))
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	if err := w.Add(filepath.Dir(filename)); err != nil {
+		w.Close()
+		return nil, err
+	}
+	source := ValueSource{Kind: SourceFile, Name: filename}.String()
+	// reloads tracks the reload in progress, which stopping waits for;
+	// stopped, guarded by mu, keeps a reload from starting after that.
+	var (
+		mu      sync.Mutex
+		stopped bool
+		reloads sync.WaitGroup
+	)
+	reload := newDebouncer(c.clock(), fileWatchQuiet, func() {
+		mu.Lock()
+		if stopped {
+			mu.Unlock()
+			return
+		}
+		reloads.Add(1)
+		mu.Unlock()
+		defer reloads.Done()
+		if err := c.LoadFile(filename); err != nil {
+			c.logger().Warn("configurable: file update rejected", "file", filename, "error", err)
+			c.problem(RejectedUpdate, filename, "", err.Error())
+		}
+	})
+	c.recordWatch(source, true, nil)
+	target, _ := filepath.EvalSymlinks(filename)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			select {
+			case e, ok := <-w.Events:
+				if !ok {
+					return
+				}
+				// A swapped symlink changes the file without an event
+				// naming it.
+				now, _ := filepath.EvalSymlinks(filename)
+				if filepath.Clean(e.Name) == filename && e.Has(fsnotify.Write|fsnotify.Create) || now != "" && now != target {
+					target = now
+					reload.trigger()
+				}
+			case err, ok := <-w.Errors:
+				if !ok {
+					return
+				}
+				c.recordWatch(source, true, err)
+			}
+		}
+	}()
+	var once sync.Once
+	stopWatch := func() (err error) {
+		once.Do(func() {
+			err = w.Close()
+			<-done
+			reload.stop()
+			mu.Lock()
+			stopped = true
+			mu.Unlock()
+			reloads.Wait()
+			c.recordWatch(source, false, nil)
+		})
+		return err
+	}
+	remove, err := c.onClose(stopWatch)
+	if err != nil {
+		stopWatch()
+		return nil, err
+	}
+	return func() {
+		remove()
+		stopWatch()
+	}, nil
+}
@@ -0,0 +1,10 @@
+//go:build (js && wasm) || tinygo
+
+package configurable
+
+import "errors"
+
+// Watch is not supported without a native file system.
+func (c *Configurable) Watch(filename string) (stop func(), err error) {
+	return nil, errors.ErrUnsupported
+}
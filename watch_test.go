@@ -0,0 +1,125 @@
+//go:build !(js && wasm) && !tinygo
+
+package configurable
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWatch(t *testing.T) {
+	os.Clearenv()
+
+	newConf := func(t *testing.T) (*Configurable, string, chan []string) {
+		path := filepath.Join(t.TempDir(), "config.json")
+		assert.NoError(t, os.WriteFile(path, []byte(`{"port": 8080}`), 0o644))
+		conf := newTestConfigurable(t)
+		conf.NewInt("port", 80, "port", WithValidator(Check(func(port int) error {
+			if port == 0 {
+				return os.ErrInvalid
+			}
+			return nil
+		})))
+		conf.SetArgs([]string{})
+		assert.NoError(t, conf.Parse(path))
+		changes := make(chan []string, 10)
+		conf.OnChange(func(v View, changed []string) {
+			changes <- changed
+		})
+		return conf, path, changes
+	}
+	wait := func(t *testing.T, changes chan []string) []string {
+		select {
+		case changed := <-changes:
+			return changed
+		case <-time.After(5 * time.Second):
+			t.Fatal("no change")
+			return nil
+		}
+	}
+
+	t.Run("test write", func(t *testing.T) {
+		conf, path, changes := newConf(t)
+		stop, err := conf.Watch(path)
+		assert.NoError(t, err)
+		defer stop()
+		assert.NoError(t, os.WriteFile(path, []byte(`{"port": 9090}`), 0o644))
+		assert.Equal(t, []string{"port"}, wait(t, changes))
+		assert.Equal(t, 9090, *conf.Int("port"))
+	})
+
+	t.Run("test replace", func(t *testing.T) {
+		conf, path, changes := newConf(t)
+		stop, err := conf.Watch(path)
+		assert.NoError(t, err)
+		defer stop()
+		tmp := filepath.Join(filepath.Dir(path), "config.json.tmp")
+		assert.NoError(t, os.WriteFile(tmp, []byte(`{"port": 7070}`), 0o644))
+		assert.NoError(t, os.Rename(tmp, path))
+		wait(t, changes)
+		assert.Equal(t, 7070, *conf.Int("port"))
+	})
+
+	t.Run("test rejected", func(t *testing.T) {
+		conf, path, changes := newConf(t)
+		stop, err := conf.Watch(path)
+		assert.NoError(t, err)
+		defer stop()
+		assert.NoError(t, os.WriteFile(path, []byte(`{"port": 0}`), 0o644))
+		assert.Eventually(t, func() bool { return len(conf.Problems()) > 0 }, 5*time.Second, 10*time.Millisecond)
+		assert.Equal(t, RejectedUpdate, conf.Problems()[0].Kind)
+		assert.Equal(t, 8080, *conf.Int("port"))
+
+		assert.NoError(t, os.WriteFile(path, []byte(`{"port": 6060}`), 0o644))
+		wait(t, changes)
+		assert.Equal(t, 6060, *conf.Int("port"))
+	})
+
+	t.Run("test stop", func(t *testing.T) {
+		conf, path, changes := newConf(t)
+		stop, err := conf.Watch(path)
+		assert.NoError(t, err)
+		stop()
+		stop()
+		assert.NoError(t, os.WriteFile(path, []byte(`{"port": 9090}`), 0o644))
+		time.Sleep(3 * fileWatchQuiet)
+		assert.Empty(t, changes)
+		assert.Equal(t, 8080, *conf.Int("port"))
+	})
+
+	t.Run("test stop waits for a reload", func(t *testing.T) {
+		conf, path, _ := newConf(t)
+		reloading, release := make(chan struct{}), make(chan struct{})
+		conf.OnChange(func(View, []string) {
+			close(reloading)
+			<-release
+		})
+		stop, err := conf.Watch(path)
+		assert.NoError(t, err)
+		assert.NoError(t, os.WriteFile(path, []byte(`{"port": 9090}`), 0o644))
+		<-reloading
+		stopped := make(chan struct{})
+		go func() {
+			stop()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+			t.Fatal("stop returned during the reload")
+		case <-time.After(3 * fileWatchQuiet):
+		}
+		close(release)
+		<-stopped
+		assert.Equal(t, 9090, *conf.Int("port"))
+	})
+
+	t.Run("test missing directory", func(t *testing.T) {
+		conf := newTestConfigurable(t)
+		_, err := conf.Watch(filepath.Join(t.TempDir(), "missing", "config.json"))
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,294 @@
+// Package webhook reports configuration changes to an HTTP endpoint, so a
+// configuration dashboard knows which instances picked up a change.
+//
+// After every change the Notifier POSTs a JSON Payload naming the instance,
+// the changed values and a hash of the configuration. Delivery is
+// retried with backoff and never delays the change itself. Closing the
+// Configurable, or the Notifier, cancels deliveries still in flight.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/andreimerlescu/configurable"
+)
+
+// Payload is the body of each notification.
+type Payload struct {
+	Instance string    `json:"instance"`
+	Time     time.Time `json:"time"`
+	// Hash is the SHA-256 of every value after the change but those of
+	// secret flags, in hex. Instances running the same configuration report
+	// the same hash.
+	Hash    string   `json:"hash"`
+	Changes []Change `json:"changes,omitempty"`
+	// Canary is set when the instance held changes back for a canary
+	// rollout, or applied the changes it held.
+	Canary *configurable.CanaryStatus `json:"canary,omitempty"`
+}
+
+// Change is the old and new value of one flag. Durations are given in their
+// string form and values of secret flags are redacted.
+type Change struct {
+	Name string      `json:"name"`
+	Old  interface{} `json:"old"`
+	New  interface{} `json:"new"`
+}
+
+const redacted = "[redacted]"
+
+// Notifier posts a Payload for every change of a Configurable.
+type Notifier struct {
+	url      string
+	instance string
+	client   *http.Client
+	attempts int
+	backoff  time.Duration
+	log      *slog.Logger
+	clock    configurable.Clock
+
+	conf    configurable.IConfigurable
+	mu      sync.Mutex
+	last    configurable.View
+	pending sync.WaitGroup
+	// ctx is canceled by Close, stopping deliveries in flight.
+	ctx    context.Context
+	cancel context.CancelFunc
+	remove func()
+}
+
+// Option configures a Notifier.
+type Option func(*Notifier)
+
+// WithInstanceID sets the instance reported in each Payload. The default is
+// the host name.
+func WithInstanceID(id string) Option {
+	return func(n *Notifier) {
+		n.instance = id
+	}
+}
+
+// WithClient sets the HTTP client, for timeouts and authentication. The
+// default has a 10 second timeout.
+func WithClient(client *http.Client) Option {
+	return func(n *Notifier) {
+		n.client = client
+	}
+}
+
+// WithRetries sets how many times a failed delivery is retried, waiting
+// backoff before the first retry and twice as long before each next one. The
+// default is 3 retries from 1 second.
+func WithRetries(retries int, backoff time.Duration) Option {
+	return func(n *Notifier) {
+		n.attempts, n.backoff = retries+1, backoff
+	}
+}
+
+// WithLogger sets where deliveries that fail every attempt are logged. The
+// default is slog.Default().
+func WithLogger(logger *slog.Logger) Option {
+	return func(n *Notifier) {
+		n.log = logger
+	}
+}
+
+// WithClock sets the clock of the Payload times and the retry backoff, so
+// tests can drive it with a configurable.ManualClock. The default is the
+// system clock.
+func WithClock(clock configurable.Clock) Option {
+	return func(n *Notifier) {
+		n.clock = clock
+	}
+}
+
+// New returns a Notifier posting the changes of conf to url, registered
+// through conf.OnChange. Call it after Parse so that only reloads and
+// runtime changes are reported. Closing conf closes the Notifier.
+func New(conf configurable.IConfigurable, url string, opts ...Option) *Notifier {
+	n := &Notifier{
+		url:      url,
+		client:   &http.Client{Timeout: 10 * time.Second},
+		attempts: 4,
+		backoff:  time.Second,
+		log:      slog.Default(),
+		clock:    configurable.SystemClock(),
+		conf:     conf,
+		last:     conf.View(),
+	}
+	n.instance, _ = os.Hostname()
+	for _, opt := range opts {
+		opt(n)
+	}
+	n.ctx, n.cancel = context.WithCancel(context.Background())
+	remove, err := conf.OnClose(func() error {
+		n.close()
+		return nil
+	})
+	if err != nil {
+		// conf is closed already, so nothing is delivered.
+		n.cancel()
+		return n
+	}
+	n.remove = remove
+	conf.OnChange(n.changed)
+	conf.OnCanary(n.canary)
+	return n
+}
+
+// Wait blocks until every pending delivery has succeeded or given up.
+func (n *Notifier) Wait() {
+	n.pending.Wait()
+}
+
+// Close cancels the deliveries in flight, waits for them to return and stops
+// delivering later changes. Closing again does nothing.
+func (n *Notifier) Close() {
+	if n.remove != nil {
+		n.remove()
+	}
+	n.close()
+}
+
+func (n *Notifier) close() {
+	n.mu.Lock()
+	n.cancel()
+	n.mu.Unlock()
+	n.pending.Wait()
+	n.client.CloseIdleConnections()
+}
+
+func (n *Notifier) changed(v configurable.View, names []string) {
+	n.mu.Lock()
+	before := n.last
+	n.last = v
+	n.mu.Unlock()
+
+	// Looked up for each payload, since flags may be registered after New.
+	secret := secrets(n.conf)
+	p := Payload{Instance: n.instance, Time: n.clock.Now().UTC(), Hash: hash(v, secret)}
+	for _, name := range names {
+		c := Change{Name: name}
+		c.Old, _ = before.Lookup(name)
+		c.New, _ = v.Lookup(name)
+		switch {
+		case secret[name]:
+			c.Old, c.New = redacted, redacted
+		case isDuration(c.New):
+			c.Old, c.New = fmt.Sprint(c.Old), fmt.Sprint(c.New)
+		}
+		p.Changes = append(p.Changes, c)
+	}
+	n.send(p)
+}
+
+func (n *Notifier) canary(s configurable.CanaryStatus) {
+	n.mu.Lock()
+	v := n.last
+	n.mu.Unlock()
+	n.send(Payload{Instance: n.instance, Time: n.clock.Now().UTC(), Hash: hash(v, secrets(n.conf)), Canary: &s})
+}
+
+// send delivers p in the background.
+func (n *Notifier) send(p Payload) {
+	body, err := json.Marshal(p)
+	if err != nil {
+		n.log.Warn("configurable: change notification failed", "url", n.url, "error", err)
+		return
+	}
+	// Checked under mu, so close does not wait for a delivery added after it.
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.ctx.Err() != nil {
+		return
+	}
+	n.pending.Add(1)
+	go func() {
+		defer n.pending.Done()
+		if err := n.deliver(body); err != nil && n.ctx.Err() == nil {
+			n.log.Warn("configurable: change notification failed", "url", n.url, "error", err)
+		}
+	}()
+}
+
+// deliver posts body, retrying failures.
+func (n *Notifier) deliver(body []byte) error {
+	var err error
+	wait := n.backoff
+	for attempt := 0; attempt < n.attempts; attempt++ {
+		if attempt > 0 {
+			waited := make(chan struct{})
+			timer := n.clock.AfterFunc(wait, func() { close(waited) })
+			select {
+			case <-n.ctx.Done():
+				timer.Stop()
+				return n.ctx.Err()
+			case <-waited:
+			}
+			wait *= 2
+		}
+		if err = n.post(body); err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+func (n *Notifier) post(body []byte) error {
+	req, err := http.NewRequestWithContext(n.ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("POST %s: %s", n.url, resp.Status)
+	}
+	return nil
+}
+
+func isDuration(v interface{}) bool {
+	_, ok := v.(time.Duration)
+	return ok
+}
+
+// secrets returns the names of the secret flags of conf.
+func secrets(conf configurable.IConfigurable) map[string]bool {
+	secret := make(map[string]bool)
+	for _, f := range conf.Flags() {
+		if f.Secret {
+			secret[f.Name] = true
+		}
+	}
+	return secret
+}
+
+// hash returns the SHA-256 of every value in v but those of the flags in
+// secret, so the hash cannot be used to guess them.
+func hash(v configurable.View, secret map[string]bool) string {
+	values := make(map[string]interface{})
+	for _, name := range v.Names() {
+		if !secret[name] {
+			values[name], _ = v.Lookup(name)
+		}
+	}
+	data, _ := json.Marshal(values)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,204 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/andreimerlescu/configurable"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/goleak"
+)
+
+func TestNotifier(t *testing.T) {
+	newConf := func(t *testing.T) configurable.IConfigurable {
+		conf := configurable.New(configurable.WithFlagSet(flag.NewFlagSet(t.Name(), flag.ContinueOnError)))
+		conf.NewDuration("timeout", time.Second, "timeout")
+		conf.NewString("token", "a", "token", configurable.WithSecret())
+		conf.NewInt("workers", 1, "workers")
+		conf.SetArgs([]string{})
+		assert.NoError(t, conf.Parse(""))
+		return conf
+	}
+
+	t.Run("test notify with retries", func(t *testing.T) {
+		var mu sync.Mutex
+		var payloads []Payload
+		calls := 0
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			defer mu.Unlock()
+			calls++
+			if calls == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			var p Payload
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&p))
+			payloads = append(payloads, p)
+		}))
+		defer srv.Close()
+
+		conf := newConf(t)
+		start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+		clock := configurable.NewManualClock(start)
+		n := New(conf, srv.URL, WithInstanceID("pod-1"), WithRetries(2, time.Minute), WithClock(clock))
+		assert.NoError(t, conf.LoadData("json", []byte(`{"timeout": "5s", "token": "b", "workers": 1}`)))
+		assert.Eventually(t, func() bool {
+			clock.Advance(time.Minute)
+			mu.Lock()
+			defer mu.Unlock()
+			return len(payloads) == 1
+		}, time.Second, time.Millisecond, "the retry waits on the clock")
+		n.Wait()
+
+		assert.Equal(t, 2, calls)
+		assert.Len(t, payloads, 1)
+		p := payloads[0]
+		assert.Equal(t, "pod-1", p.Instance)
+		assert.True(t, start.Equal(p.Time), p.Time)
+		assert.Equal(t, hash(conf.View(), secrets(conf)), p.Hash)
+		assert.Len(t, p.Hash, 64)
+		assert.Equal(t, []Change{
+			{Name: "timeout", Old: "1s", New: "5s"},
+			{Name: "token", Old: redacted, New: redacted},
+		}, p.Changes)
+	})
+
+	t.Run("test give up", func(t *testing.T) {
+		calls := 0
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer srv.Close()
+
+		conf := newConf(t)
+		n := New(conf, srv.URL, WithRetries(1, time.Millisecond))
+		assert.NoError(t, conf.Set("workers", 2))
+		n.Wait()
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("test close cancels retries", func(t *testing.T) {
+		defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+		called := make(chan struct{}, 1)
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case called <- struct{}{}:
+			default:
+			}
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer srv.Close()
+
+		conf := newConf(t)
+		New(conf, srv.URL, WithRetries(3, time.Hour))
+		assert.NoError(t, conf.Set("workers", 2))
+		<-called
+		assert.NoError(t, conf.Close(), "closing the Configurable closes the Notifier")
+
+		n := New(conf, srv.URL)
+		assert.NoError(t, conf.Set("workers", 3))
+		n.Wait()
+		n.Close()
+		assert.Len(t, called, 0, "a Notifier of a closed Configurable delivers nothing")
+	})
+
+	t.Run("test same configuration same hash", func(t *testing.T) {
+		a, b := newConf(t), newConf(t)
+		assert.NoError(t, a.Set("workers", 3))
+		assert.NoError(t, b.Set("workers", 3))
+		assert.Equal(t, hash(a.View(), secrets(a)), hash(b.View(), secrets(b)))
+		assert.NoError(t, b.Set("token", "b"))
+		assert.Equal(t, hash(a.View(), secrets(a)), hash(b.View(), secrets(b)), "secrets are left out")
+		assert.NoError(t, b.Set("workers", 4))
+		assert.NotEqual(t, hash(a.View(), secrets(a)), hash(b.View(), secrets(b)))
+	})
+
+	t.Run("test secrets registered after New", func(t *testing.T) {
+		var mu sync.Mutex
+		var payloads []Payload
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var p Payload
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&p))
+			mu.Lock()
+			defer mu.Unlock()
+			payloads = append(payloads, p)
+		}))
+		defer srv.Close()
+
+		conf := newConf(t)
+		n := New(conf, srv.URL)
+		conf.NewString("password", "", "password", configurable.WithSecret())
+		assert.NoError(t, conf.Set("password", "hunter2"))
+		n.Wait()
+		assert.Len(t, payloads, 1)
+		assert.Equal(t, []Change{{Name: "password", Old: redacted, New: redacted}}, payloads[0].Changes)
+	})
+
+	t.Run("test canary status", func(t *testing.T) {
+		var mu sync.Mutex
+		var payloads []Payload
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var p Payload
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&p))
+			mu.Lock()
+			defer mu.Unlock()
+			payloads = append(payloads, p)
+		}))
+		defer srv.Close()
+
+		conf := configurable.New(configurable.WithFlagSet(flag.NewFlagSet(t.Name(), flag.ContinueOnError)))
+		conf.NewCanaryPercent("canary", 0, "canary percentage")
+		conf.NewBool("feature", false, "feature", configurable.WithCanary())
+		conf.SetArgs([]string{})
+		assert.NoError(t, conf.Parse(""))
+		p := &pushProvider{docs: make(chan map[string]interface{}), results: make(chan error)}
+		conf.AddProvider(p)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go conf.WatchProviders(ctx)
+
+		n := New(conf, srv.URL, WithInstanceID("pod-1"))
+		p.docs <- map[string]interface{}{"feature": true}
+		assert.NoError(t, <-p.results)
+		n.Wait()
+
+		mu.Lock()
+		defer mu.Unlock()
+		assert.Len(t, payloads, 1)
+		assert.Empty(t, payloads[0].Changes)
+		assert.Equal(t, "pod-1", payloads[0].Instance)
+		if assert.NotNil(t, payloads[0].Canary) {
+			assert.False(t, payloads[0].Canary.Included)
+			assert.Equal(t, []string{"feature"}, payloads[0].Canary.Held)
+		}
+	})
+}
+
+// pushProvider delivers each document sent on docs to Watch.
+type pushProvider struct {
+	docs    chan map[string]interface{}
+	results chan error
+}
+
+func (p *pushProvider) Name() string { return "push" }
+
+func (p *pushProvider) Load(context.Context) (map[string]interface{}, error) { return nil, nil }
+
+func (p *pushProvider) Watch(ctx context.Context, apply func(map[string]interface{}) error) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case doc := <-p.docs:
+			p.results <- apply(doc)
+		}
+	}
+}
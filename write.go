@@ -0,0 +1,221 @@
+package configurable
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/go-ini/ini"
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// Dump serializes the effective configuration in the given format ("json",
+// "yaml", "toml" or "ini", with or without a leading dot). Values are rendered the way
+// an operator would write them: durations as "1h30m0s", sizes of flags
+// registered WithUnit(Bytes) as "512KiB" and lists and maps in the codec's
+// native form.
+func (c *Configurable) Dump(format string) ([]byte, error) {
+	switch strings.TrimPrefix(strings.ToLower(format), ".") {
+	case "json":
+		return c.encodeJSON()
+	case "yaml", "yml":
+		return c.encodeYAML()
+	case "toml":
+		return c.encodeTOML()
+	case "ini":
+		return c.encodeINI()
+	default:
+		return nil, errors.New("unsupported file extension")
+	}
+}
+
+// WriteFile serializes the effective configuration to filename, choosing the
+// encoding from its extension. When the target is INI and an INI document was
+// loaded earlier, that document is edited in place so comments and key order
+// written by operators survive automated updates. The file is replaced
+// atomically, with the permissions chosen by SetFileMode, under the lock
+// EditFile takes.
+func (c *Configurable) WriteFile(filename string) error {
+	unlock, err := lockFile(filename)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return c.writeFile(filename)
+}
+
+// writeFile is WriteFile for a caller holding the lock.
+func (c *Configurable) writeFile(filename string) error {
+	data, err := c.Dump(filepath.Ext(filename))
+	if err != nil {
+		return err
+	}
+	return writeFile(filename, data, c.writeMode())
+}
+
+// Permissions of the files the package writes.
+const (
+	publicFileMode  fs.FileMode = 0644
+	privateFileMode fs.FileMode = 0600
+	cacheDirMode    fs.FileMode = 0700
+)
+
+// SetFileMode sets the permissions of files WriteFile creates. By default
+// they are 0644, or 0600 if any flag is registered WithSecret. Cache files
+// always get 0600 in a directory created 0700, as they hold every value.
+func (c *Configurable) SetFileMode(mode fs.FileMode) {
+	c.fileMode = mode
+}
+
+// writeMode returns the permissions for files WriteFile creates.
+func (c *Configurable) writeMode() fs.FileMode {
+	if c.fileMode != 0 {
+		return c.fileMode
+	}
+	for _, m := range c.meta {
+		if m.secret {
+			return privateFileMode
+		}
+	}
+	return publicFileMode
+}
+
+// exportValues is values with each entry passed through formatValue, and
+// sizes written with their unit.
+func (c *Configurable) exportValues() map[string]interface{} {
+	c.mu.RLock()
+	out := c.values()
+	c.mu.RUnlock()
+	for name, v := range out {
+		if size, ok := c.size(name, v); ok {
+			out[name] = size
+			continue
+		}
+		out[name] = formatValue(v)
+	}
+	return out
+}
+
+// formatValue converts values whose Go representation is unreadable once
+// encoded (a time.Duration marshals as int64 nanoseconds) into strings.
+func formatValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case time.Duration:
+		return val.String()
+	case json.RawMessage:
+		var decoded interface{}
+		if err := json.Unmarshal(val, &decoded); err != nil {
+			return string(val)
+		}
+		return decoded
+	default:
+		return v
+	}
+}
+
+// encodeJSON renders the values as a JSON object with keys in registration
+// order.
+func (c *Configurable) encodeJSON() ([]byte, error) {
+	values := c.exportValues()
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, name := range c.order {
+		key, err := json.Marshal(name)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.MarshalIndent(values[name], "  ", "  ")
+		if err != nil {
+			return nil, err
+		}
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		buf.WriteString("\n  ")
+		buf.Write(key)
+		buf.WriteString(": ")
+		buf.Write(value)
+	}
+	if len(c.order) > 0 {
+		buf.WriteByte('\n')
+	}
+	buf.WriteString("}\n")
+	return buf.Bytes(), nil
+}
+
+// encodeYAML renders the values as a YAML mapping with keys in registration
+// order.
+func (c *Configurable) encodeYAML() ([]byte, error) {
+	values := c.exportValues()
+	doc := &yaml.Node{Kind: yaml.MappingNode}
+	for _, name := range c.order {
+		var key, value yaml.Node
+		if err := key.Encode(name); err != nil {
+			return nil, err
+		}
+		if err := value.Encode(values[name]); err != nil {
+			return nil, err
+		}
+		doc.Content = append(doc.Content, &key, &value)
+	}
+	return yaml.Marshal(doc)
+}
+
+// encodeTOML renders the values as TOML with keys in registration order. Maps
+// are written as inline tables, so that every key stays at the top level.
+func (c *Configurable) encodeTOML() ([]byte, error) {
+	values := c.exportValues()
+	var buf bytes.Buffer
+	enc := toml.NewEncoder(&buf).SetTablesInline(true)
+	for _, name := range c.order {
+		if err := enc.Encode(map[string]interface{}{name: values[name]}); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// encodeINI updates the retained INI document, or a fresh one, with the
+// current flag values. Existing keys keep their position and comments; keys
+// the document did not have are appended to the default section.
+func (c *Configurable) encodeINI() ([]byte, error) {
+	// The document is edited in place, so writers are excluded.
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cfg := c.ini
+	if cfg == nil {
+		cfg = ini.Empty()
+	}
+	for _, name := range c.order {
+		section, keyName := iniKey(cfg, name)
+		if list, ok := c.flags[name].(*ListFlag); ok && section.HasKey(keyName+"[]") && len(*list.values) > 0 {
+			// Keep the "name[] = item" form the document used.
+			section.DeleteKey(keyName + "[]")
+			key, err := section.NewKey(keyName+"[]", (*list.values)[0])
+			if err != nil {
+				return nil, err
+			}
+			for _, item := range (*list.values)[1:] {
+				if err := key.AddShadow(item); err != nil {
+					return nil, err
+				}
+			}
+			continue
+		}
+		if size, ok := c.size(name, valueOf(c.flags[name])); ok {
+			section.Key(keyName).SetValue(size)
+		} else if f := c.fs.Lookup(name); f != nil {
+			section.Key(keyName).SetValue(f.Value.String())
+		}
+	}
+	var buf bytes.Buffer
+	if _, err := cfg.WriteTo(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,181 @@
+package configurable
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteFile(t *testing.T) {
+	os.Clearenv()
+	dir := t.TempDir()
+
+	t.Run("test INI round-trip keeps comments and order", func(t *testing.T) {
+		conf := newTestConfigurable(t)
+		port := conf.NewInt("port", 80, "port")
+		conf.NewString("name", "", "name")
+		conf.NewBool("debug", false, "debug")
+
+		src := "; managed by ops\n\n# keep this on 80 in staging\nport = 80\n; service name\nname = api\n"
+		assert.NoError(t, conf.LoadData("ini", []byte(src)))
+		*port = 8080
+
+		target := filepath.Join(dir, "app.ini")
+		assert.NoError(t, conf.WriteFile(target))
+		out, err := os.ReadFile(target)
+		assert.NoError(t, err)
+		got := string(out)
+		assert.Contains(t, got, "; managed by ops")
+		assert.Contains(t, got, "# keep this on 80 in staging")
+		assert.Regexp(t, `port\s*= 8080`, got)
+		assert.Less(t, strings.Index(got, "port"), strings.Index(got, "name"))
+		assert.Less(t, strings.Index(got, "name"), strings.Index(got, "debug"))
+	})
+
+	t.Run("test JSON, YAML and TOML", func(t *testing.T) {
+		conf := newTestConfigurable(t)
+		conf.NewString("name", "api", "name")
+		for _, ext := range []string{".json", ".yaml", ".toml"} {
+			target := filepath.Join(dir, "app"+ext)
+			assert.NoError(t, conf.WriteFile(target))
+			reload := newTestConfigurable(t)
+			name := reload.NewString("name", "", "name")
+			assert.NoError(t, reload.LoadFile(target))
+			assert.Equal(t, "api", *name)
+		}
+	})
+
+	t.Run("test unsupported extension", func(t *testing.T) {
+		conf := newTestConfigurable(t)
+		assert.Error(t, conf.WriteFile(filepath.Join(dir, "app.txt")))
+	})
+
+	t.Run("test permissions", func(t *testing.T) {
+		dir := t.TempDir()
+		mode := func(name string) os.FileMode {
+			info, err := os.Stat(filepath.Join(dir, name))
+			assert.NoError(t, err)
+			return info.Mode().Perm()
+		}
+		conf := newTestConfigurable(t)
+		conf.NewString("name", "api", "name")
+		assert.NoError(t, conf.WriteFile(filepath.Join(dir, "public.json")))
+		assert.Equal(t, os.FileMode(0644), mode("public.json"))
+
+		conf.NewString("token", "", "token", WithSecret())
+		assert.NoError(t, conf.WriteFile(filepath.Join(dir, "secret.json")))
+		assert.Equal(t, os.FileMode(0600), mode("secret.json"))
+
+		conf.SetFileMode(0640)
+		assert.NoError(t, conf.WriteFile(filepath.Join(dir, "secret.json")))
+		assert.Equal(t, os.FileMode(0640), mode("secret.json"))
+
+		temps, err := filepath.Glob(filepath.Join(dir, ".*.tmp-*"))
+		assert.NoError(t, err)
+		assert.Empty(t, temps, "no temporary files are left behind")
+	})
+}
+
+func TestDump(t *testing.T) {
+	os.Clearenv()
+
+	conf := newTestConfigurable(t)
+	conf.NewDuration("timeout", 90*time.Minute, "timeout")
+	conf.NewList("tags", []string{"a", "b"}, "tags")
+	conf.NewMap("labels", map[string]string{"team": "core"}, "labels")
+
+	t.Run("test JSON", func(t *testing.T) {
+		data, err := conf.Dump("json")
+		assert.NoError(t, err)
+		assert.Contains(t, string(data), `"timeout": "1h30m0s"`)
+		assert.Contains(t, string(data), `"tags": [`)
+		assert.Contains(t, string(data), `"team": "core"`)
+	})
+
+	t.Run("test YAML", func(t *testing.T) {
+		data, err := conf.Dump(".yaml")
+		assert.NoError(t, err)
+		assert.Contains(t, string(data), "timeout: 1h30m0s")
+		assert.Contains(t, string(data), "- a")
+		assert.Contains(t, string(data), "team: core")
+	})
+
+	t.Run("test INI", func(t *testing.T) {
+		data, err := conf.Dump("ini")
+		assert.NoError(t, err)
+		assert.Regexp(t, `timeout\s*= 1h30m0s`, string(data))
+		assert.Regexp(t, `tags\s*= a,b`, string(data))
+	})
+
+	t.Run("test TOML", func(t *testing.T) {
+		data, err := conf.Dump("toml")
+		assert.NoError(t, err)
+		assert.Equal(t, "timeout = '1h30m0s'\ntags = ['a', 'b']\nlabels = {team = 'core'}\n", string(data))
+
+		reload := newTestConfigurable(t)
+		timeout := reload.NewDuration("timeout", 0, "timeout")
+		tags := reload.NewList("tags", nil, "tags")
+		labels := reload.NewMap("labels", nil, "labels")
+		assert.NoError(t, reload.LoadData("toml", data))
+		assert.Equal(t, 90*time.Minute, *timeout)
+		assert.Equal(t, []string{"a", "b"}, *tags)
+		assert.Equal(t, map[string]string{"team": "core"}, *labels)
+	})
+
+	t.Run("test dump reloads", func(t *testing.T) {
+		data, err := conf.Dump("json")
+		assert.NoError(t, err)
+		reload := newTestConfigurable(t)
+		timeout := reload.NewDuration("timeout", 0, "timeout")
+		assert.NoError(t, reload.LoadData("json", data))
+		assert.Equal(t, 90*time.Minute, *timeout)
+	})
+
+	t.Run("test sizes", func(t *testing.T) {
+		conf := newTestConfigurable(t)
+		conf.NewInt64("cache", 0, "cache", WithUnit(Bytes))
+		conf.NewInt("buffer", 0, "buffer", WithUnit(Bytes))
+		conf.NewInt("odd", 0, "odd", WithUnit(Bytes))
+		assert.NoError(t, conf.LoadData("json", []byte(`{"cache": "1GiB", "buffer": "10MB", "odd": "1001"}`)))
+
+		data, err := conf.Dump("json")
+		assert.NoError(t, err)
+		assert.Contains(t, string(data), `"cache": "1GiB"`)
+		assert.Contains(t, string(data), `"buffer": "10MB"`)
+		assert.Contains(t, string(data), `"odd": 1001`)
+
+		ini, err := conf.Dump("ini")
+		assert.NoError(t, err)
+		assert.Regexp(t, `cache\s*= 1GiB`, string(ini))
+
+		reload := newTestConfigurable(t)
+		cache := reload.NewInt64("cache", 0, "cache", WithUnit(Bytes))
+		buffer := reload.NewInt("buffer", 0, "buffer", WithUnit(Bytes))
+		reload.NewInt("odd", 0, "odd", WithUnit(Bytes))
+		assert.NoError(t, reload.LoadData("json", data))
+		assert.Equal(t, int64(1<<30), *cache)
+		assert.Equal(t, 10000000, *buffer)
+	})
+
+	t.Run("test registration order", func(t *testing.T) {
+		data, err := conf.Dump("json")
+		assert.NoError(t, err)
+		assert.Regexp(t, `(?s)"timeout".*"tags".*"labels"`, string(data))
+
+		data, err = conf.Dump("yaml")
+		assert.NoError(t, err)
+		assert.Regexp(t, `(?s)^timeout:.*\ntags:.*\nlabels:`, string(data))
+
+		data, err = conf.Dump("ini")
+		assert.NoError(t, err)
+		assert.Regexp(t, `(?s)timeout.*tags.*labels`, string(data))
+
+		assert.Regexp(t, `(?s)-timeout.*-tags.*-labels`, conf.Usage())
+		flags := conf.Flags()
+		assert.Equal(t, []string{"timeout", "tags", "labels"}, []string{flags[0].Name, flags[1].Name, flags[2].Name})
+	})
+}
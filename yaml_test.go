@@ -0,0 +1,56 @@
+package configurable
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestYAMLAnchors(t *testing.T) {
+	os.Clearenv()
+
+	const doc = `
+defaults: &defaults
+  port: 80
+  timeout: 5s
+  tags: [a, b]
+
+server:
+  <<: *defaults
+  port: 8080
+
+worker:
+  <<: *defaults
+`
+
+	t.Run("test merge keys flatten into dotted names", func(t *testing.T) {
+		conf := newTestConfigurable(t)
+		serverPort := conf.NewInt("server.port", 0, "server port")
+		serverTimeout := conf.NewDuration("server.timeout", 0, "server timeout")
+		workerPort := conf.NewInt("worker.port", 0, "worker port")
+		workerTags := conf.NewList("worker.tags", []string{}, "worker tags")
+
+		assert.NoError(t, conf.LoadData("yaml", []byte(doc)))
+		assert.Equal(t, 8080, *serverPort)
+		assert.Equal(t, "5s", serverTimeout.String())
+		assert.Equal(t, 80, *workerPort)
+		assert.Equal(t, []string{"a", "b"}, *workerTags)
+	})
+
+	t.Run("test multiple merge sources", func(t *testing.T) {
+		conf := newTestConfigurable(t)
+		x := conf.NewInt("m.x", 0, "x")
+		y := conf.NewInt("m.y", 0, "y")
+		doc := "base: &b {x: 1}\nextra: &e {y: 2}\nm:\n  <<: [*b, *e]\n  x: 3\n"
+		assert.NoError(t, conf.LoadData("yaml", []byte(doc)))
+		assert.Equal(t, 3, *x)
+		assert.Equal(t, 2, *y)
+	})
+
+	t.Run("test recursive alias is rejected", func(t *testing.T) {
+		conf := newTestConfigurable(t)
+		conf.NewString("a", "", "a")
+		assert.Error(t, conf.LoadData("yaml", []byte("a: &a\n  b: *a\n")))
+	})
+}